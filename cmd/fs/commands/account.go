@@ -0,0 +1,125 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-dev-frame/sponge/pkg/gocrypto"
+
+	"fs/cmd/fs/initial"
+	"fs/internal/auth"
+	"fs/internal/cache"
+	"fs/internal/dao"
+	"fs/internal/database"
+	"fs/internal/model"
+)
+
+const accountUsage = `usage: fs account create [-c config] [-role player|builder|admin] -name name < password
+       fs account role [-c config] -name name -role player|builder|admin`
+
+func init() {
+	commands["account"] = command{usage: accountUsage, run: account}
+}
+
+// account manage the accounts without the api, the first admin is made this way since only an
+// admin can set roles through the api
+func account(args []string) error {
+	if len(args) == 0 {
+		return errors.New(accountUsage)
+	}
+	switch args[0] {
+	case "create":
+		return accountCreate(args[1:])
+	case "role":
+		return accountRole(args[1:])
+	}
+	return errors.New(accountUsage)
+}
+
+// accountCreate create an account, the password is the first line of stdin so that it is not
+// in the shell history nor in the process list
+func accountCreate(args []string) error {
+	flags := flag.NewFlagSet("fs account create", flag.ContinueOnError)
+	configFile := flags.String("c", "", "configuration file")
+	name := flags.String("name", "", "username")
+	role := flags.String("role", auth.RolePlayer, "player, builder or admin")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *name == "" || !auth.ValidRole(*role) {
+		return errors.New(accountUsage)
+	}
+
+	fmt.Fprint(os.Stderr, "password: ")
+	password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && password == "" {
+		return errors.New("no password on stdin")
+	}
+	password = strings.TrimRight(password, "\r\n")
+	if len(password) < auth.MinPasswordLen {
+		return fmt.Errorf("passwords are at least %d characters", auth.MinPasswordLen)
+	}
+	hash, err := gocrypto.HashAndSaltPassword(password)
+	if err != nil {
+		return err
+	}
+
+	initial.InitCommand(*configFile)
+	defer initial.CloseCommand()
+
+	ctx := context.Background()
+	d := accountDao()
+	if _, err = d.GetByUsername(ctx, *name); err == nil {
+		return fmt.Errorf("account %s already exists, use fs account role to change its role", *name)
+	} else if !errors.Is(err, database.ErrRecordNotFound) {
+		return err
+	}
+	acc := &model.Account{Username: *name, PasswordHash: hash, Role: *role}
+	if err = d.Create(ctx, acc); err != nil {
+		return err
+	}
+	fmt.Printf("account %s created with id %d and role %s\n", acc.Username, acc.ID, acc.Role)
+	return nil
+}
+
+// accountRole set the role of an account, e.g. of one created by a player in the game
+func accountRole(args []string) error {
+	flags := flag.NewFlagSet("fs account role", flag.ContinueOnError)
+	configFile := flags.String("c", "", "configuration file")
+	name := flags.String("name", "", "username")
+	role := flags.String("role", "", "player, builder or admin")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *name == "" || !auth.ValidRole(*role) {
+		return errors.New(accountUsage)
+	}
+
+	initial.InitCommand(*configFile)
+	defer initial.CloseCommand()
+
+	ctx := context.Background()
+	d := accountDao()
+	acc, err := d.GetByUsername(ctx, *name)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			return fmt.Errorf("no account %s", *name)
+		}
+		return err
+	}
+	acc.Role = *role
+	if err = d.UpdateByID(ctx, acc); err != nil {
+		return err
+	}
+	fmt.Printf("account %s has the role %s, it is in the tokens issued from now on\n", acc.Username, acc.Role)
+	return nil
+}
+
+func accountDao() dao.AccountDao {
+	return dao.NewAccountDao(database.GetDB(), cache.NewAccountCache(database.GetCacheType()))
+}
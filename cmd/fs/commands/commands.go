@@ -0,0 +1,50 @@
+// Package commands are the subcommands of fs that run once and exit instead of
+// starting the services, e.g. fs world export.
+package commands
+
+import (
+	"fmt"
+	"os"
+	"sort"
+)
+
+// command runs with the arguments after its name
+type command struct {
+	usage string
+	run   func(args []string) error
+}
+
+var commands = map[string]command{}
+
+// Has check if name is a subcommand
+func Has(name string) bool {
+	_, ok := commands[name]
+	return ok
+}
+
+// Run a subcommand and return the exit code
+func Run(name string, args []string) int {
+	cmd, ok := commands[name]
+	if !ok {
+		Usage()
+		return 2
+	}
+	if err := cmd.run(args); err != nil {
+		fmt.Fprintf(os.Stderr, "fs %s: %v\n", name, err)
+		return 1
+	}
+	return 0
+}
+
+// Usage print the subcommands
+func Usage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintln(os.Stderr, "usage: fs [-c config] [-version v]  start the services")
+	for _, name := range names {
+		fmt.Fprintln(os.Stderr, commands[name].usage)
+	}
+}
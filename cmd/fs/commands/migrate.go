@@ -0,0 +1,104 @@
+package commands
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"fs/cmd/fs/initial"
+	"fs/internal/database"
+	"fs/internal/migrate"
+)
+
+const migrateUsage = `usage: fs migrate up [-c config] [-steps n]
+       fs migrate down [-c config] [-steps n]
+       fs migrate status [-c config]`
+
+func init() {
+	commands["migrate"] = command{usage: migrateUsage, run: migrateSchema}
+}
+
+func migrateSchema(args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+	switch args[0] {
+	case "up":
+		return migrateUp(args[1:])
+	case "down":
+		return migrateDown(args[1:])
+	case "status":
+		return migrateStatus(args[1:])
+	}
+	return errors.New(migrateUsage)
+}
+
+// migrateUp apply the pending migrations
+func migrateUp(args []string) error {
+	flags := flag.NewFlagSet("fs migrate up", flag.ContinueOnError)
+	configFile := flags.String("c", "", "configuration file")
+	steps := flags.Int("steps", 0, "number of migrations to apply, 0 applies all of them")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	initial.InitCommand(*configFile)
+	defer initial.CloseCommand()
+
+	migrations, err := migrate.Up(database.GetDB(), *steps)
+	for _, m := range migrations {
+		fmt.Printf("applied %04d_%s\n", m.Version, m.Name)
+	}
+	if err == nil && len(migrations) == 0 {
+		fmt.Println("the schema is up to date")
+	}
+	return err
+}
+
+// migrateDown revert the latest migrations
+func migrateDown(args []string) error {
+	flags := flag.NewFlagSet("fs migrate down", flag.ContinueOnError)
+	configFile := flags.String("c", "", "configuration file")
+	steps := flags.Int("steps", 1, "number of migrations to revert, 0 reverts all of them")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	initial.InitCommand(*configFile)
+	defer initial.CloseCommand()
+
+	migrations, err := migrate.Down(database.GetDB(), *steps)
+	for _, m := range migrations {
+		fmt.Printf("reverted %04d_%s\n", m.Version, m.Name)
+	}
+	return err
+}
+
+// migrateStatus print the versions of the binary and of the database
+func migrateStatus(args []string) error {
+	flags := flag.NewFlagSet("fs migrate status", flag.ContinueOnError)
+	configFile := flags.String("c", "", "configuration file")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	initial.InitCommand(*configFile)
+	defer initial.CloseCommand()
+
+	states, err := migrate.Status(database.GetDB())
+	if err != nil {
+		return err
+	}
+	for _, s := range states {
+		status := "pending"
+		if s.AppliedAt != nil {
+			status = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		if s.Unknown {
+			status += ", unknown to this binary"
+		}
+		fmt.Fprintf(os.Stdout, "%04d_%-24s %s\n", s.Version, s.Name, status)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"fs/cmd/fs/initial"
+	"fs/internal/config"
+)
+
+const trashUsage = `usage: fs trash purge [-c config] [-days n]`
+
+func init() {
+	commands["trash"] = command{usage: trashUsage, run: trash}
+}
+
+func trash(args []string) error {
+	if len(args) == 0 || args[0] != "purge" {
+		return errors.New(trashUsage)
+	}
+	return trashPurge(args[1:])
+}
+
+// trashPurge remove for good the rooms, mobs and items that have been in the trash for some days,
+// meant to be run every day, e.g. by cron
+func trashPurge(args []string) error {
+	flags := flag.NewFlagSet("fs trash purge", flag.ContinueOnError)
+	configFile := flags.String("c", "", "configuration file")
+	days := flags.Int("days", -1, "days in the trash, default is database.trashDays in the config, 0 empties the trash")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	initial.InitCommand(*configFile)
+	defer initial.CloseCommand()
+
+	if *days < 0 {
+		*days = config.Get().Database.TrashDays
+	}
+	before := time.Now().AddDate(0, 0, -*days)
+	ctx := context.Background()
+	d := worldDaos()
+
+	rooms, err := d.Rooms.PurgeDeleted(ctx, before)
+	if err != nil {
+		return err
+	}
+	mobs, err := d.Mobs.PurgeDeleted(ctx, before)
+	if err != nil {
+		return err
+	}
+	items, err := d.Items.PurgeDeleted(ctx, before)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("purged %d rooms, %d mobs and %d items deleted before %s\n",
+		rooms, mobs, items, before.Format("2006-01-02 15:04:05"))
+	return nil
+}
@@ -0,0 +1,237 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"gorm.io/gorm"
+
+	"fs/cmd/fs/initial"
+	"fs/internal/auth"
+	"fs/internal/bundle"
+	"fs/internal/cache"
+	"fs/internal/config"
+	"fs/internal/dao"
+	"fs/internal/database"
+	"fs/internal/lpc"
+	"fs/internal/model"
+	"fs/internal/rule"
+)
+
+const worldUsage = `usage: fs world export [-c config] [-format json|yaml] [-o file]
+       fs world import [-c config] [-format json|yaml] [-dry-run] [-conflict skip|overwrite|fail] file
+       fs world lpc [-c config] [-format json|yaml] [-o file] [-mob-id n] [-item-id n] mudlib [dir ...]`
+
+func init() {
+	commands["world"] = command{usage: worldUsage, run: world}
+}
+
+func world(args []string) error {
+	if len(args) == 0 {
+		return errors.New(worldUsage)
+	}
+	switch args[0] {
+	case "export":
+		return worldExport(args[1:])
+	case "import":
+		return worldImport(args[1:])
+	case "lpc":
+		return worldLPC(args[1:])
+	}
+	return errors.New(worldUsage)
+}
+
+// worldExport write the world to a file, or to stdout
+func worldExport(args []string) error {
+	flags := flag.NewFlagSet("fs world export", flag.ContinueOnError)
+	configFile := flags.String("c", "", "configuration file")
+	formatName := flags.String("format", "", "json or yaml, by default from the extension of -o, else json")
+	out := flags.String("o", "", "file to write, default is stdout")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	format := bundle.FormatOf(*out)
+	if *formatName != "" {
+		var ok bool
+		if format, ok = bundle.ParseFormat(*formatName); !ok {
+			return fmt.Errorf("unknown format %q", *formatName)
+		}
+	}
+
+	initial.InitCommand(*configFile)
+	defer initial.CloseCommand()
+
+	b, err := bundle.Export(context.Background(), worldDaos())
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close() //nolint
+		w = f
+	}
+	if err = bundle.Encode(w, b, format); err != nil {
+		return err
+	}
+	if *out != "" {
+		fmt.Fprintf(os.Stderr, "exported %d rooms, %d room exits, %d mobs and %d items to %s\n",
+			len(b.Rooms), len(b.RoomExits), len(b.Mobs), len(b.Items), *out)
+	}
+	return nil
+}
+
+// worldImport load a bundle file and print the report
+func worldImport(args []string) error {
+	flags := flag.NewFlagSet("fs world import", flag.ContinueOnError)
+	configFile := flags.String("c", "", "configuration file")
+	formatName := flags.String("format", "", "json or yaml, by default from the extension of the file")
+	dryRun := flags.Bool("dry-run", false, "report what would be written without writing it")
+	conflictName := flags.String("conflict", string(bundle.ConflictFail), "what to do with records that exist: skip, overwrite or fail")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New(worldUsage)
+	}
+	file := flags.Arg(0)
+
+	format := bundle.FormatOf(file)
+	if *formatName != "" {
+		var ok bool
+		if format, ok = bundle.ParseFormat(*formatName); !ok {
+			return fmt.Errorf("unknown format %q", *formatName)
+		}
+	}
+	conflict, ok := bundle.ParseConflict(*conflictName)
+	if !ok {
+		return fmt.Errorf("unknown conflict strategy %q", *conflictName)
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close() //nolint
+	b, err := bundle.Decode(f, format)
+	if err != nil {
+		return err
+	}
+
+	initial.InitCommand(*configFile)
+	defer initial.CloseCommand()
+
+	mobRules, err := rule.NewMob(config.Get().Rules.Mob, nil)
+	if err != nil {
+		return err
+	}
+	itemRules, err := rule.NewItem(config.Get().Rules.Item, nil)
+	if err != nil {
+		return err
+	}
+
+	ctx := auth.WithName(context.Background(), "fs world import") // the actor of the revisions
+	report, err := bundle.Import(ctx, database.GetDB(), worldDaos(), b,
+		bundle.Options{DryRun: *dryRun, Conflict: conflict, MobRules: mobRules, ItemRules: itemRules})
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+// worldLPC convert the room, npc and object files of a legacy mudlib into a bundle
+// for fs world import, what could not be mapped is printed to stderr. The ids of the
+// mobs and items follow the largest ones in the database unless they are given.
+func worldLPC(args []string) error {
+	flags := flag.NewFlagSet("fs world lpc", flag.ContinueOnError)
+	configFile := flags.String("c", "", "configuration file, for the database when -mob-id or -item-id is 0")
+	formatName := flags.String("format", "", "json or yaml, by default from the extension of -o, else json")
+	out := flags.String("o", "", "file to write, default is stdout")
+	mobID := flags.Uint64("mob-id", 0, "id of the first mob, 0 is after the largest mob id in the database")
+	itemID := flags.Uint64("item-id", 0, "id of the first item, 0 is after the largest item id in the database")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() < 1 {
+		return errors.New(worldUsage)
+	}
+
+	format := bundle.FormatOf(*out)
+	if *formatName != "" {
+		var ok bool
+		if format, ok = bundle.ParseFormat(*formatName); !ok {
+			return fmt.Errorf("unknown format %q", *formatName)
+		}
+	}
+
+	objs, err := lpc.Load(flags.Arg(0), flags.Args()[1:]...)
+	if err != nil {
+		return err
+	}
+	if *mobID == 0 || *itemID == 0 {
+		initial.InitCommand(*configFile)
+		defer initial.CloseCommand()
+		db := database.GetDB()
+		if *mobID == 0 {
+			if *mobID, err = nextID(db, &model.Mob{}); err != nil {
+				return err
+			}
+		}
+		if *itemID == 0 {
+			if *itemID, err = nextID(db, &model.Item{}); err != nil {
+				return err
+			}
+		}
+	}
+	b, report := lpc.Convert(objs, lpc.Options{FirstMobID: *mobID, FirstItemID: *itemID})
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close() //nolint
+		w = f
+	}
+	if err = bundle.Encode(w, b, format); err != nil {
+		return err
+	}
+
+	for _, issue := range report.Issues {
+		fmt.Fprintln(os.Stderr, issue)
+	}
+	fmt.Fprintf(os.Stderr, "converted %d files to %d rooms, %d room exits, %d mobs and %d items, %d issues\n",
+		report.Files, report.Rooms, report.RoomExits, report.Mobs, report.Items, len(report.Issues))
+	return nil
+}
+
+// nextID the id after the largest one of a table, the records in the trash included as an
+// import would conflict with them
+func nextID(db *gorm.DB, table interface{}) (uint64, error) {
+	var maxID uint64
+	err := db.Unscoped().Model(table).Select("COALESCE(MAX(id), 0)").Scan(&maxID).Error
+	return maxID + 1, err
+}
+
+func worldDaos() bundle.Daos {
+	db := database.GetDB()
+	return bundle.Daos{
+		Rooms:     dao.NewRoomDao(db, cache.NewRoomCache(database.GetCacheType())),
+		RoomExits: dao.NewRoomExitDao(db, cache.NewRoomExitCache(database.GetCacheType())),
+		Mobs:      dao.NewMobDao(db, cache.NewMobCache(database.GetCacheType())),
+		Items:     dao.NewItemDao(db, cache.NewItemCache(database.GetCacheType())),
+	}
+}
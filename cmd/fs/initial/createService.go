@@ -2,8 +2,11 @@ package initial
 
 import (
 	"strconv"
+	"time"
 
+	"fs/internal/auth"
 	"fs/internal/config"
+	"fs/internal/game"
 	"fs/internal/server"
 
 	"github.com/go-dev-frame/sponge/pkg/app"
@@ -14,6 +17,16 @@ func CreateServices() []app.IServer {
 	var cfg = config.Get()
 	var servers []app.IServer
 
+	// initializing jwt signing, only the http service issues and checks tokens
+	err := auth.Init(auth.Options{
+		SignKey:       []byte(cfg.Jwt.SignKey),
+		Expire:        time.Duration(cfg.Jwt.Expire) * time.Minute,
+		RefreshExpire: time.Duration(cfg.Jwt.RefreshExpire) * time.Hour,
+	})
+	if err != nil {
+		panic("jwt error: " + err.Error() + ", generate a key with: openssl rand -hex 32")
+	}
+
 	// create a http service
 	httpAddr := ":" + strconv.Itoa(cfg.HTTP.Port)
 	httpServer := server.NewHTTPServer(httpAddr,
@@ -24,3 +37,22 @@ func CreateServices() []app.IServer {
 
 	return servers
 }
+
+// CreateGameServices create telnet game service
+func CreateGameServices() []app.IServer {
+	var cfg = config.Get()
+	var servers []app.IServer
+
+	// create a telnet game service
+	gameAddr := ":" + strconv.Itoa(cfg.Game.Port)
+	gameServer := server.NewGameServer(gameAddr,
+		game.WithStartRoom(cfg.Game.StartRoom),
+		game.WithIdleTimeout(time.Duration(cfg.Game.IdleTimeout)*time.Minute),
+		game.WithResetInterval(time.Duration(cfg.Game.ResetInterval)*time.Second),
+		game.WithSaveInterval(time.Duration(cfg.Game.SaveInterval)*time.Minute),
+		game.WithHeartbeat(time.Duration(cfg.Game.Heartbeat)*time.Second),
+	)
+	servers = append(servers, gameServer)
+
+	return servers
+}
@@ -14,6 +14,7 @@ import (
 	"fs/configs"
 	"fs/internal/config"
 	"fs/internal/database"
+	"fs/internal/migrate"
 )
 
 var (
@@ -42,7 +43,7 @@ func InitApp() {
 	if err != nil {
 		panic(err)
 	}
-	logger.Debug(config.Show())
+	logger.Debug(config.Show(`"signKey"`))
 	logger.Info("[logger] was initialized")
 
 	// initializing tracing
@@ -71,6 +72,15 @@ func InitApp() {
 	// initializing database
 	database.InitDB()
 	logger.Infof("[%s] was initialized", cfg.Database.Driver)
+	if cfg.Database.AutoMigrate {
+		migrations, err := migrate.Up(database.GetDB(), 0)
+		if err != nil {
+			panic("migrate error: " + err.Error())
+		}
+		for _, m := range migrations {
+			logger.Infof("[migration] %04d_%s was applied", m.Version, m.Name)
+		}
+	}
 	database.InitCache(cfg.App.CacheType)
 	if cfg.App.CacheType != "" {
 		logger.Infof("[%s] was initialized", cfg.App.CacheType)
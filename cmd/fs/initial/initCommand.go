@@ -0,0 +1,33 @@
+package initial
+
+import (
+	"github.com/go-dev-frame/sponge/pkg/logger"
+
+	"fs/internal/config"
+	"fs/internal/database"
+)
+
+// InitCommand initial the configuration, log, database and cache of a command
+// that runs once instead of starting the services, an empty file is configs/fs.yml
+func InitCommand(file string) {
+	configFile = file
+	getConfigFromLocal()
+	cfg := config.Get()
+
+	// commands may write their output to stdout, only warnings are logged
+	_, err := logger.Init(logger.WithLevel("warn"), logger.WithFormat(cfg.Logger.Format))
+	if err != nil {
+		panic(err)
+	}
+
+	database.InitDB()
+	database.InitCache(cfg.App.CacheType)
+}
+
+// CloseCommand releasing the resources of InitCommand
+func CloseCommand() {
+	_ = database.CloseDB()
+	if config.Get().App.CacheType == "redis" {
+		_ = database.CloseRedis()
+	}
+}
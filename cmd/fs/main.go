@@ -2,8 +2,11 @@
 package main
 
 import (
+	"os"
+
 	"github.com/go-dev-frame/sponge/pkg/app"
 
+	"fs/cmd/fs/commands"
 	"fs/cmd/fs/initial"
 )
 
@@ -17,6 +20,11 @@ import (
 // @name Authorization
 // @description Type Bearer your-jwt-token to Value
 func main() {
+	// subcommands such as fs world export run once instead of starting the services
+	if len(os.Args) > 1 && commands.Has(os.Args[1]) {
+		os.Exit(commands.Run(os.Args[1], os.Args[2:]))
+	}
+
 	initial.InitApp()
 	services := initial.CreateServices()
 	closes := initial.Close(services)
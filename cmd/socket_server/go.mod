@@ -1,3 +0,0 @@
-module server
-
-go 1.23.3
@@ -0,0 +1,202 @@
+// Package auth issues the jwt tokens of the /api/v1 routes and checks the role
+// carried in them, roles are ordered player < builder < admin.
+package auth
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/go-dev-frame/sponge/pkg/gin/middleware"
+	"github.com/go-dev-frame/sponge/pkg/gin/response"
+	"github.com/go-dev-frame/sponge/pkg/jwt"
+
+	"fs/internal/ecode"
+	"fs/internal/model"
+)
+
+// account roles, the value is stored in account.role
+const (
+	RolePlayer  = "player"  // read the world
+	RoleBuilder = "builder" // also create, update and delete the world
+	RoleAdmin   = "admin"   // also manage accounts
+)
+
+// Roles all roles from the least to the most allowed
+var Roles = []string{RolePlayer, RoleBuilder, RoleAdmin}
+
+// MinPasswordLen shortest password accepted for a new account
+const MinPasswordLen = 6
+
+// claim fields
+const (
+	fieldRole    = "role"
+	fieldName    = "name"
+	fieldRefresh = "refresh" // true in refresh tokens, they are not accepted by Middleware
+)
+
+// default token lifetimes
+const (
+	defaultExpire        = 2 * time.Hour
+	defaultRefreshExpire = 7 * 24 * time.Hour
+)
+
+// ErrNotRefreshToken the token given to Refresh is an access token
+var ErrNotRefreshToken = errors.New("not a refresh token")
+
+// ErrNoSignKey no signing key is configured, the tokens would be signed with the public default key of sponge
+var ErrNoSignKey = errors.New("jwt.signKey is not set in the config")
+
+// Options signing key and token lifetimes, zero values use the defaults
+type Options struct {
+	SignKey       []byte
+	Expire        time.Duration
+	RefreshExpire time.Duration
+}
+
+var opts = Options{Expire: defaultExpire, RefreshExpire: defaultRefreshExpire}
+
+// Init set the signing key and token lifetimes, the key is required
+func Init(o Options) error {
+	if len(o.SignKey) == 0 {
+		return ErrNoSignKey
+	}
+	if o.Expire <= 0 {
+		o.Expire = defaultExpire
+	}
+	if o.RefreshExpire <= 0 {
+		o.RefreshExpire = defaultRefreshExpire
+	}
+	opts = o
+	return nil
+}
+
+// Tokens an access token and the refresh token to get the next one with
+type Tokens struct {
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken"`
+}
+
+// ValidRole check if a role exists
+func ValidRole(role string) bool {
+	return rank(role) >= 0
+}
+
+// HasRole check if a role is allowed what the min role is allowed
+func HasRole(role string, min string) bool {
+	r := rank(role)
+	return r >= 0 && r >= rank(min)
+}
+
+func rank(role string) int {
+	for i, r := range Roles {
+		if r == role {
+			return i
+		}
+	}
+	return -1
+}
+
+// RoleOf the role of an account, accounts without a role are players
+func RoleOf(account *model.Account) string {
+	if account.Role == "" {
+		return RolePlayer
+	}
+	return account.Role
+}
+
+// GenerateTokens issue the tokens of an account, the account id is the uid of the claims
+func GenerateTokens(account *model.Account) (*Tokens, error) {
+	uid := strconv.FormatUint(account.ID, 10)
+	fields := map[string]interface{}{fieldRole: RoleOf(account), fieldName: account.Username}
+	_, access, err := jwt.GenerateToken(uid, tokenOptions(fields, opts.Expire)...)
+	if err != nil {
+		return nil, err
+	}
+
+	fields[fieldRefresh] = true
+	_, refresh, err := jwt.GenerateToken(uid, tokenOptions(fields, opts.RefreshExpire)...)
+	if err != nil {
+		return nil, err
+	}
+	return &Tokens{AccessToken: access, RefreshToken: refresh}, nil
+}
+
+func tokenOptions(fields map[string]interface{}, expire time.Duration) []jwt.GenerateTokenOption {
+	o := []jwt.GenerateTokenOption{
+		jwt.WithGenerateTokenFields(fields),
+		jwt.WithGenerateTokenClaims(jwt.WithExpires(expire)),
+	}
+	if len(opts.SignKey) > 0 {
+		o = append(o, jwt.WithGenerateTokenSignKey(opts.SignKey))
+	}
+	return o
+}
+
+// ParseRefreshToken validate a refresh token and return the account id it was issued to
+func ParseRefreshToken(token string) (uint64, error) {
+	claims, err := jwt.ValidateToken(token, jwt.WithValidateTokenSignKey(opts.SignKey))
+	if err != nil {
+		return 0, err
+	}
+	if refresh, _ := claims.GetBool(fieldRefresh); !refresh {
+		return 0, ErrNotRefreshToken
+	}
+	return strconv.ParseUint(claims.UID, 10, 64)
+}
+
+// Middleware require a valid access token, 401 ecode.Unauthorized if there is none
+func Middleware() gin.HandlerFunc {
+	return middleware.Auth(
+		middleware.WithSignKey(opts.SignKey),
+		middleware.WithExtraVerify(func(claims *jwt.Claims, c *gin.Context) error {
+			if refresh, _ := claims.GetBool(fieldRefresh); refresh {
+				return errors.New("refresh token used as access token")
+			}
+			// the daos get the context of the request, e.g. to record who changed the world
+			name, _ := claims.GetString(fieldName)
+			c.Request = c.Request.WithContext(WithName(c.Request.Context(), name))
+			return nil
+		}),
+	)
+}
+
+// Require allow only tokens with at least the given role, 403 ecode.Forbidden otherwise,
+// it is used after Middleware
+func Require(min string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !HasRole(Role(c), min) {
+			response.Out(c, ecode.Forbidden)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// Role the role in the access token of the request, empty if there is none
+func Role(c *gin.Context) string {
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		return ""
+	}
+	role, _ := claims.GetString(fieldRole)
+	return role
+}
+
+type nameKey struct{}
+
+// WithName a context carrying the name of the account that does something, Middleware
+// puts the name in the access token into the context of the request
+func WithName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, nameKey{}, name)
+}
+
+// NameOf the account name carried by a context, empty if there is none
+func NameOf(ctx context.Context) string {
+	name, _ := ctx.Value(nameKey{}).(string)
+	return name
+}
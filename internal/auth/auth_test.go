@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"fs/internal/model"
+)
+
+func TestHasRole(t *testing.T) {
+	assert.True(t, HasRole(RoleAdmin, RoleBuilder))
+	assert.True(t, HasRole(RoleBuilder, RoleBuilder))
+	assert.False(t, HasRole(RolePlayer, RoleBuilder))
+	assert.False(t, HasRole("", RolePlayer))
+	assert.False(t, HasRole("god", RolePlayer))
+	assert.Equal(t, RolePlayer, RoleOf(&model.Account{}))
+}
+
+func TestParseRefreshToken(t *testing.T) {
+	defer func(o Options) { opts = o }(opts)
+	require.NoError(t, Init(Options{SignKey: []byte("test key"), Expire: time.Minute}))
+	assert.Equal(t, defaultRefreshExpire, opts.RefreshExpire)
+	assert.ErrorIs(t, Init(Options{Expire: time.Minute}), ErrNoSignKey)
+
+	tokens, err := GenerateTokens(&model.Account{ID: 7, Username: "alice"})
+	require.NoError(t, err)
+
+	id, err := ParseRefreshToken(tokens.RefreshToken)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(7), id)
+
+	_, err = ParseRefreshToken(tokens.AccessToken)
+	assert.ErrorIs(t, err, ErrNotRefreshToken)
+
+	require.NoError(t, Init(Options{SignKey: []byte("other key")}))
+	_, err = ParseRefreshToken(tokens.RefreshToken)
+	assert.Error(t, err)
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	g := r.Group("/", Middleware())
+	g.GET("/read", func(c *gin.Context) { c.String(http.StatusOK, Role(c)) })
+	g.POST("/write", Require(RoleBuilder), func(c *gin.Context) { c.Status(http.StatusOK) })
+	g.GET("/name", func(c *gin.Context) { c.String(http.StatusOK, NameOf(c.Request.Context())) })
+
+	do := func(method string, path string, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	player, err := GenerateTokens(&model.Account{ID: 1, Username: "alice"})
+	require.NoError(t, err)
+	builder, err := GenerateTokens(&model.Account{ID: 2, Username: "bob", Role: RoleBuilder})
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusUnauthorized, do(http.MethodGet, "/read", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, do(http.MethodGet, "/read", player.RefreshToken).Code)
+
+	w := do(http.MethodGet, "/read", player.AccessToken)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, RolePlayer, w.Body.String())
+
+	assert.Equal(t, http.StatusForbidden, do(http.MethodPost, "/write", player.AccessToken).Code)
+	assert.Equal(t, http.StatusOK, do(http.MethodPost, "/write", builder.AccessToken).Code)
+
+	w = do(http.MethodGet, "/name", builder.AccessToken)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "bob", w.Body.String())
+}
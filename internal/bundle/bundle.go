@@ -0,0 +1,162 @@
+// Package bundle moves a whole world between databases, the rooms, room exits, mobs
+// and items are written to a versioned JSON or YAML bundle and loaded back in one transaction.
+package bundle
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"fs/internal/dao"
+	"fs/internal/model"
+)
+
+// Version of the bundle format written by Export, Decode reads this version only
+const Version = 1
+
+// ErrVersion the bundle was written in a format version this build does not read
+var ErrVersion = errors.New("unsupported bundle version")
+
+// Bundle the world at the time it was exported
+type Bundle struct {
+	Version    int               `json:"version"`
+	ExportedAt time.Time         `json:"exportedAt"`
+	Rooms      []*model.Room     `json:"rooms"`
+	RoomExits  []*model.RoomExit `json:"roomExits"`
+	Mobs       []*model.Mob      `json:"mobs"`
+	Items      []*model.Item     `json:"items"`
+}
+
+// Daos the tables of a bundle
+type Daos struct {
+	Rooms     dao.RoomDao
+	RoomExits dao.RoomExitDao
+	Mobs      dao.MobDao
+	Items     dao.ItemDao
+}
+
+// Export read the whole world
+func Export(ctx context.Context, d Daos) (*Bundle, error) {
+	b := &Bundle{Version: Version, ExportedAt: time.Now().UTC()}
+	var err error
+	if b.Rooms, err = dao.GetAllRooms(ctx, d.Rooms); err != nil {
+		return nil, err
+	}
+	if b.RoomExits, err = dao.GetAllRoomExits(ctx, d.RoomExits); err != nil {
+		return nil, err
+	}
+	if b.Mobs, err = dao.GetAllMobs(ctx, d.Mobs); err != nil {
+		return nil, err
+	}
+	if b.Items, err = dao.GetAllItems(ctx, d.Items); err != nil {
+		return nil, err
+	}
+
+	// the versions belong to the database the bundle is exported from, an import keeps the ones of its database
+	for _, r := range b.Rooms {
+		r.Version = 0
+	}
+	for _, m := range b.Mobs {
+		m.Version = 0
+	}
+	for _, i := range b.Items {
+		i.Version = 0
+	}
+	return b, nil
+}
+
+// Format how a bundle is written
+type Format string
+
+// bundle formats
+const (
+	FormatJSON Format = "json"
+	FormatYAML Format = "yaml"
+)
+
+// ParseFormat parse a format name, yml is the same as yaml
+func ParseFormat(s string) (Format, bool) {
+	switch strings.ToLower(s) {
+	case "json":
+		return FormatJSON, true
+	case "yaml", "yml":
+		return FormatYAML, true
+	}
+	return "", false
+}
+
+// FormatOf the format of a bundle file by its extension, json if it is not a yaml file
+func FormatOf(filename string) Format {
+	if f, ok := ParseFormat(strings.TrimPrefix(filepath.Ext(filename), ".")); ok {
+		return f
+	}
+	return FormatJSON
+}
+
+// Encode write a bundle, the YAML keys are the same as the JSON keys
+func Encode(w io.Writer, b *Bundle, f Format) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	if f != FormatYAML {
+		_, err = w.Write(append(data, '\n'))
+		return err
+	}
+
+	// JSON is YAML, only the flow style and the quotes are reset to the block style
+	var node yaml.Node
+	if err = yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	resetStyle(&node)
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err = enc.Encode(&node); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+func resetStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, c := range n.Content {
+		resetStyle(c)
+	}
+}
+
+// Decode read a bundle, ErrVersion if it has another format version
+func Decode(r io.Reader, f Format) (*Bundle, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if f == FormatYAML {
+		var v interface{}
+		if err = yaml.Unmarshal(data, &v); err != nil {
+			return nil, err
+		}
+		if data, err = json.Marshal(v); err != nil {
+			return nil, err
+		}
+	}
+
+	b := &Bundle{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err = dec.Decode(b); err != nil {
+		return nil, err
+	}
+	if b.Version != Version {
+		return nil, fmt.Errorf("%w %d, want %d", ErrVersion, b.Version, Version)
+	}
+	return b, nil
+}
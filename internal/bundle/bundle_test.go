@@ -0,0 +1,153 @@
+package bundle
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-dev-frame/sponge/pkg/gotest"
+	"github.com/go-dev-frame/sponge/pkg/sgorm"
+
+	"fs/internal/dao"
+	"fs/internal/model"
+)
+
+func testBundle() *Bundle {
+	yes := sgorm.TinyBool(true)
+	return &Bundle{
+		Version:    Version,
+		ExportedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		Rooms:      []*model.Room{{ID: "square", Title: "Town Square", Desc: "A busy square: \"hello\"", Mobs: "1"}},
+		RoomExits:  []*model.RoomExit{{ID: 7, RoomID: "square", Direction: "north", ToRoomID: "inn", Door: &yes, Cost: 1}},
+		Mobs:       []*model.Mob{{ID: 1, MobID: "guard", MobName: "guard", MobCname: "守衛", Attackable: &yes, Hp: 100}},
+		Items:      []*model.Item{{ID: 2, ItemID: "sword", ItemName: "sword", Attack: 5, Classifier: "w"}},
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	for _, f := range []Format{FormatJSON, FormatYAML} {
+		buf := &bytes.Buffer{}
+		require.NoError(t, Encode(buf, testBundle(), f))
+		if f == FormatYAML {
+			assert.Contains(t, buf.String(), "mobCname: 守衛\n")
+			assert.Contains(t, buf.String(), "roomExits:\n")
+		}
+
+		b, err := Decode(buf, f)
+		require.NoError(t, err, f)
+		assert.Equal(t, testBundle(), b, f)
+	}
+
+	_, err := Decode(strings.NewReader(`{"version": 2}`), FormatJSON)
+	assert.ErrorIs(t, err, ErrVersion)
+	_, err = Decode(strings.NewReader("version: 1\nrooms: []\nspawns: []\n"), FormatYAML)
+	assert.Error(t, err)
+}
+
+func TestFormatOf(t *testing.T) {
+	assert.Equal(t, FormatYAML, FormatOf("world.yml"))
+	assert.Equal(t, FormatYAML, FormatOf("world.YAML"))
+	assert.Equal(t, FormatJSON, FormatOf("world.json"))
+	assert.Equal(t, FormatJSON, FormatOf("world"))
+}
+
+func newTestImport() (*gotest.Dao, Daos) {
+	d := gotest.NewDao(nil, nil)
+	return d, Daos{
+		Rooms:     dao.NewRoomDao(d.DB, nil),
+		RoomExits: dao.NewRoomExitDao(d.DB, nil),
+		Mobs:      dao.NewMobDao(d.DB, nil),
+		Items:     dao.NewItemDao(d.DB, nil),
+	}
+}
+
+func TestImport(t *testing.T) {
+	d, daos := newTestImport()
+	defer d.Close()
+
+	// the room and the item are new, the mob and the exit are written over, then rolled back
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectQuery("SELECT \\* FROM `room`").WithArgs("square").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	d.SQLMock.ExpectExec("INSERT INTO `room`").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	d.SQLMock.ExpectQuery("SELECT \\* FROM `mob` WHERE id IN \\(\\?\\)$").WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "version", "deleted_at"}).AddRow(1, 4, time.Now()))
+	// every column is written, zero values included, based on the version read in the transaction,
+	// and the mob in the trash is restored
+	d.SQLMock.ExpectExec("UPDATE `mob` SET `mob_id`=\\?,`mob_name`=\\?,`mob_cname`=\\?,`mob_desc`=\\?,`attackable`=\\?,`hp`=\\?,`mp`=\\?,`attack`=\\?,`defence`=\\?,`dodge`=\\?,`version`=\\?,`deleted_at`=\\? WHERE version = \\? AND `id` = \\?").
+		WithArgs("guard", "guard", "守衛", "", 1, 100, 0, 0, 0, 0, 5, nil, 4, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	d.SQLMock.ExpectQuery("SELECT \\* FROM `item`").WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	d.SQLMock.ExpectExec("INSERT INTO `item`").
+		WillReturnResult(sqlmock.NewResult(2, 1))
+	d.SQLMock.ExpectQuery("SELECT \\* FROM `room_exit`").WithArgs("square").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "room_id", "direction"}).AddRow(3, "square", "north"))
+	d.SQLMock.ExpectExec("UPDATE `room_exit` SET .*`door`=\\?,`locked`=\\?,`hidden`=\\?,`cost`=\\? WHERE `id` = \\?").
+		WithArgs("square", "north", "inn", 1, nil, nil, 1, 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	d.SQLMock.ExpectRollback()
+
+	report, err := Import(context.Background(), d.DB, daos, testBundle(), Options{DryRun: true, Conflict: ConflictOverwrite})
+	require.NoError(t, err)
+	assert.Equal(t, &Report{
+		DryRun:    true,
+		Rooms:     Counts{Created: 1},
+		RoomExits: Counts{Updated: 1},
+		Mobs:      Counts{Restored: 1},
+		Items:     Counts{Created: 1},
+	}, report)
+	assert.NoError(t, d.SQLMock.ExpectationsWereMet())
+}
+
+func TestImport_skip(t *testing.T) {
+	d, daos := newTestImport()
+	defer d.Close()
+
+	d.SQLMock.ExpectBegin()
+	for _, row := range []struct {
+		table string
+		id    driver.Value
+	}{{"room", "square"}, {"mob", 1}, {"item", 2}} {
+		d.SQLMock.ExpectQuery("SELECT \\* FROM `" + row.table + "`").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(row.id))
+	}
+	d.SQLMock.ExpectQuery("SELECT \\* FROM `room_exit`").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "room_id", "direction"}).AddRow(3, "square", "south"))
+	d.SQLMock.ExpectExec("INSERT INTO `room_exit`").
+		WillReturnResult(sqlmock.NewResult(4, 1))
+	d.SQLMock.ExpectCommit()
+
+	report, err := Import(context.Background(), d.DB, daos, testBundle(), Options{Conflict: ConflictSkip})
+	require.NoError(t, err)
+	assert.Equal(t, &Report{
+		Rooms:     Counts{Skipped: 1},
+		RoomExits: Counts{Created: 1},
+		Mobs:      Counts{Skipped: 1},
+		Items:     Counts{Skipped: 1},
+	}, report)
+	assert.NoError(t, d.SQLMock.ExpectationsWereMet())
+}
+
+func TestImport_fail(t *testing.T) {
+	d, daos := newTestImport()
+	defer d.Close()
+
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectQuery("SELECT \\* FROM `room`").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("square"))
+	d.SQLMock.ExpectRollback()
+
+	_, err := Import(context.Background(), d.DB, daos, testBundle(), Options{})
+	assert.ErrorIs(t, err, ErrConflict)
+	assert.Contains(t, err.Error(), "room square")
+	assert.NoError(t, d.SQLMock.ExpectationsWereMet())
+}
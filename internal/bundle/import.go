@@ -0,0 +1,341 @@
+package bundle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"fs/internal/dao"
+	"fs/internal/model"
+	"fs/internal/rule"
+)
+
+// Conflict what Import does with a record that already exists
+type Conflict string
+
+// conflict strategies
+const (
+	ConflictSkip      Conflict = "skip"      // keep the record in the database
+	ConflictOverwrite Conflict = "overwrite" // write every field of the bundle over it, a record in the trash is restored
+	ConflictFail      Conflict = "fail"      // roll back the whole import
+)
+
+// ParseConflict parse a conflict strategy, an empty string is ConflictFail
+func ParseConflict(s string) (Conflict, bool) {
+	switch c := Conflict(s); c {
+	case ConflictSkip, ConflictOverwrite, ConflictFail:
+		return c, true
+	case "":
+		return ConflictFail, true
+	}
+	return "", false
+}
+
+// ErrConflict a record of the bundle already exists, in the trash or not, and the strategy is ConflictFail
+var ErrConflict = errors.New("record already exists")
+
+// allFields the fields of the PatchByTx methods to write a record of the bundle as it is, zero values included
+var allFields = []string{"*"}
+
+// RulesError a mob or an item of the bundle breaks the validation rules, the import is rolled back
+type RulesError struct {
+	Table      string // mob or item
+	Key        string // id of the record
+	Violations []rule.Violation
+}
+
+func (e *RulesError) Error() string {
+	list := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		list = append(list, v.Field+" "+v.Message)
+	}
+	return e.Table + " " + e.Key + " breaks the validation rules: " + strings.Join(list, ", ")
+}
+
+// errDryRun rolls back the transaction of a dry run
+var errDryRun = errors.New("dry run")
+
+// Options how a bundle is imported
+type Options struct {
+	DryRun   bool // run the import and roll it back, the report tells what would happen
+	Conflict Conflict
+
+	// the rules every mob and item written is checked against as a new one, all its fields are written.
+	// The unique ids are left to the unique indexes of the database, the rules check them outside the
+	// transaction, and are not checked if the rules are nil.
+	MobRules  *rule.Rules
+	ItemRules *rule.Rules
+}
+
+// Counts what happened to the records of a table
+type Counts struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Skipped int `json:"skipped"`
+
+	Restored int `json:"restored"` // in the trash and overwritten, restored with the fields of the bundle
+}
+
+// Report what an import did, or would do in a dry run
+type Report struct {
+	DryRun    bool   `json:"dryRun"`
+	Rooms     Counts `json:"rooms"`
+	RoomExits Counts `json:"roomExits"`
+	Mobs      Counts `json:"mobs"`
+	Items     Counts `json:"items"`
+}
+
+// Import write a bundle in one transaction through the CreateByTx and PatchByTx methods
+// of the daos. Rooms, mobs and items keep their ids as rooms and spawns refer to them,
+// room exits are matched by room and direction. A room, mob or item in the trash with the
+// id of a record of the bundle is a conflict as the others. A mob or an item breaking the
+// rules of the options is a *RulesError. An error rolls back everything.
+func Import(ctx context.Context, db *gorm.DB, d Daos, b *Bundle, o Options) (*Report, error) {
+	if o.Conflict == "" {
+		o.Conflict = ConflictFail
+	}
+	report := &Report{DryRun: o.DryRun}
+
+	ctx, notify := dao.CollectChanges(ctx)
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		rooms := &table[model.Room, string]{
+			name: "room",
+			key:  func(r *model.Room) string { return r.ID },
+			existing: func(tx *gorm.DB, records []*model.Room) ([]*model.Room, error) {
+				return findIn[model.Room](tx.Unscoped(), "id", keysOf(records, func(r *model.Room) string { return r.ID }))
+			},
+			create: func(tx *gorm.DB, r *model.Room) error {
+				_, err := d.Rooms.CreateByTx(ctx, tx, r)
+				return err
+			},
+			update: func(tx *gorm.DB, r, old *model.Room) error {
+				r.Version = old.Version // read in this transaction
+				return d.Rooms.PatchByTx(ctx, tx.Unscoped(), r, allFields)
+			},
+			trashed: func(r *model.Room) bool { return r.DeletedAt.Valid },
+		}
+		if err := rooms.write(tx, b.Rooms, o.Conflict, &report.Rooms); err != nil {
+			return err
+		}
+
+		mobs := &table[model.Mob, uint64]{
+			name:  "mob",
+			rules: o.MobRules,
+			key:   func(m *model.Mob) uint64 { return m.ID },
+			existing: func(tx *gorm.DB, records []*model.Mob) ([]*model.Mob, error) {
+				return findIn[model.Mob](tx.Unscoped(), "id", keysOf(records, func(m *model.Mob) uint64 { return m.ID }))
+			},
+			create: func(tx *gorm.DB, m *model.Mob) error {
+				_, err := d.Mobs.CreateByTx(ctx, tx, m)
+				return err
+			},
+			update: func(tx *gorm.DB, m, old *model.Mob) error {
+				m.Version = old.Version // read in this transaction
+				return d.Mobs.PatchByTx(ctx, tx.Unscoped(), m, allFields)
+			},
+			trashed: func(m *model.Mob) bool { return m.DeletedAt.Valid },
+		}
+		if err := mobs.write(tx, b.Mobs, o.Conflict, &report.Mobs); err != nil {
+			return err
+		}
+
+		items := &table[model.Item, uint64]{
+			name:  "item",
+			rules: o.ItemRules,
+			key:   func(i *model.Item) uint64 { return i.ID },
+			existing: func(tx *gorm.DB, records []*model.Item) ([]*model.Item, error) {
+				return findIn[model.Item](tx.Unscoped(), "id", keysOf(records, func(i *model.Item) uint64 { return i.ID }))
+			},
+			create: func(tx *gorm.DB, i *model.Item) error {
+				_, err := d.Items.CreateByTx(ctx, tx, i)
+				return err
+			},
+			update: func(tx *gorm.DB, i, old *model.Item) error {
+				i.Version = old.Version // read in this transaction
+				return d.Items.PatchByTx(ctx, tx.Unscoped(), i, allFields)
+			},
+			trashed: func(i *model.Item) bool { return i.DeletedAt.Valid },
+		}
+		if err := items.write(tx, b.Items, o.Conflict, &report.Items); err != nil {
+			return err
+		}
+
+		// the ids of exits differ between databases, a new exit gets a new id
+		exits := &table[model.RoomExit, exitKey]{
+			name: "room exit",
+			key:  keyOfExit,
+			existing: func(tx *gorm.DB, records []*model.RoomExit) ([]*model.RoomExit, error) {
+				return findIn[model.RoomExit](tx, "room_id", keysOf(records, func(e *model.RoomExit) string { return e.RoomID }))
+			},
+			create: func(tx *gorm.DB, e *model.RoomExit) error {
+				exit := *e
+				exit.ID = 0
+				_, err := d.RoomExits.CreateByTx(ctx, tx, &exit)
+				return err
+			},
+			update: func(tx *gorm.DB, e, old *model.RoomExit) error {
+				exit := *e
+				exit.ID = old.ID
+				return d.RoomExits.SaveByTx(ctx, tx, &exit)
+			},
+		}
+		if err := exits.write(tx, b.RoomExits, o.Conflict, &report.RoomExits); err != nil {
+			return err
+		}
+
+		// postgresql does not move the sequences of the ids past the ids written above
+		if tx.Dialector.Name() == "postgres" {
+			for _, name := range []string{"mob", "item"} {
+				if err := resetSequence(tx, name); err != nil {
+					return err
+				}
+			}
+		}
+
+		if o.DryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, errDryRun) {
+			return report, nil
+		}
+		return nil, err
+	}
+	notify()
+	return report, nil
+}
+
+// resetSequence let the sequence of the id of a postgresql table continue after the largest id
+func resetSequence(tx *gorm.DB, name string) error {
+	return tx.Exec("SELECT setval(pg_get_serial_sequence(?, 'id'), COALESCE(MAX(id), 0) + 1, false) FROM "+
+		tx.Statement.Quote(name), name).Error
+}
+
+// table how the records of a table are matched against the database and written
+type table[T any, K comparable] struct {
+	name     string // used in conflict errors
+	key      func(*T) K
+	existing func(tx *gorm.DB, records []*T) ([]*T, error)
+	create   func(tx *gorm.DB, record *T) error
+	update   func(tx *gorm.DB, record *T, old *T) error
+	trashed  func(*T) bool // nil for the tables without a trash
+	rules    *rule.Rules   // nil for the tables without rules
+}
+
+func (t *table[T, K]) write(tx *gorm.DB, records []*T, conflict Conflict, counts *Counts) error {
+	if len(records) == 0 {
+		return nil
+	}
+	list, err := t.existing(tx, records)
+	if err != nil {
+		return err
+	}
+	existing := make(map[K]*T, len(list))
+	for _, old := range list {
+		existing[t.key(old)] = old
+	}
+
+	for _, record := range records {
+		old, ok := existing[t.key(record)]
+		if !ok {
+			if err = t.check(tx, record); err != nil {
+				return err
+			}
+			if err = t.create(tx, record); err != nil {
+				return t.duplicated(record, err)
+			}
+			counts.Created++
+			continue
+		}
+
+		switch conflict {
+		case ConflictSkip:
+			counts.Skipped++
+		case ConflictOverwrite:
+			if err = t.check(tx, record); err != nil {
+				return err
+			}
+			if err = t.update(tx, record, old); err != nil {
+				return t.duplicated(record, err)
+			}
+			if t.isTrashed(old) {
+				counts.Restored++
+			} else {
+				counts.Updated++
+			}
+		default:
+			if t.isTrashed(old) {
+				return fmt.Errorf("%w: %s %v in the trash", ErrConflict, t.name, t.key(record))
+			}
+			return fmt.Errorf("%w: %s %v", ErrConflict, t.name, t.key(record))
+		}
+	}
+	return nil
+}
+
+func (t *table[T, K]) isTrashed(record *T) bool {
+	return t.trashed != nil && t.trashed(record)
+}
+
+// check a record about to be written against the rules of the table
+func (t *table[T, K]) check(tx *gorm.DB, record *T) error {
+	if t.rules == nil {
+		return nil
+	}
+	violations, err := t.rules.CheckCreate(tx.Statement.Context, record)
+	if err != nil {
+		return err
+	}
+	if len(violations) > 0 {
+		return &RulesError{Table: t.name, Key: fmt.Sprint(t.key(record)), Violations: violations}
+	}
+	return nil
+}
+
+// duplicated the error of a write refused by a unique index is a violation of the unique field
+func (t *table[T, K]) duplicated(record *T, err error) error {
+	if t.rules == nil || !errors.Is(err, gorm.ErrDuplicatedKey) {
+		return err
+	}
+	return &RulesError{Table: t.name, Key: fmt.Sprint(t.key(record)), Violations: []rule.Violation{t.rules.UniqueViolation(record)}}
+}
+
+// exitKey what identifies an exit across databases
+type exitKey struct {
+	RoomID    string
+	Direction string
+}
+
+func keyOfExit(e *model.RoomExit) exitKey {
+	return exitKey{RoomID: e.RoomID, Direction: e.Direction}
+}
+
+// keysOf the distinct keys of records
+func keysOf[T any, K comparable](records []*T, key func(*T) K) []K {
+	seen := make(map[K]bool, len(records))
+	keys := make([]K, 0, len(records))
+	for _, r := range records {
+		k := key(r)
+		if !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// findIn the records of a table whose column is one of the values
+func findIn[T any, K comparable](tx *gorm.DB, column string, values []K) ([]*T, error) {
+	var list []*T
+	err := tx.Where(column+" IN ?", values).Find(&list).Error
+	return list, err
+}
+
+func (k exitKey) String() string {
+	return k.RoomID + " " + k.Direction
+}
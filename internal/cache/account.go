@@ -0,0 +1,153 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/go-dev-frame/sponge/pkg/cache"
+	"github.com/go-dev-frame/sponge/pkg/encoding"
+	"github.com/go-dev-frame/sponge/pkg/utils"
+
+	"fs/internal/database"
+	"fs/internal/model"
+)
+
+const (
+	// cache prefix key, must end with a colon
+	accountCachePrefixKey = "account:"
+	// AccountExpireTime expire time
+	AccountExpireTime = 5 * time.Minute
+)
+
+var _ AccountCache = (*accountCache)(nil)
+
+// AccountCache cache interface
+type AccountCache interface {
+	Set(ctx context.Context, id uint64, data *model.Account, duration time.Duration) error
+	Get(ctx context.Context, id uint64) (*model.Account, error)
+	MultiGet(ctx context.Context, ids []uint64) (map[uint64]*model.Account, error)
+	MultiSet(ctx context.Context, data []*model.Account, duration time.Duration) error
+	Del(ctx context.Context, id uint64) error
+	SetPlaceholder(ctx context.Context, id uint64) error
+	IsPlaceholderErr(err error) bool
+}
+
+// accountCache define a cache struct
+type accountCache struct {
+	cache cache.Cache
+}
+
+// NewAccountCache new a cache
+func NewAccountCache(cacheType *database.CacheType) AccountCache {
+	jsonEncoding := encoding.JSONEncoding{}
+	cachePrefix := ""
+
+	cType := strings.ToLower(cacheType.CType)
+	switch cType {
+	case "redis":
+		c := cache.NewRedisCache(cacheType.Rdb, cachePrefix, jsonEncoding, func() interface{} {
+			return &model.Account{}
+		})
+		return &accountCache{cache: c}
+	case "memory":
+		c := cache.NewMemoryCache(cachePrefix, jsonEncoding, func() interface{} {
+			return &model.Account{}
+		})
+		return &accountCache{cache: c}
+	}
+
+	return nil // no cache
+}
+
+// GetAccountCacheKey cache key
+func (c *accountCache) GetAccountCacheKey(id uint64) string {
+	return accountCachePrefixKey + utils.Uint64ToStr(id)
+}
+
+// Set write to cache
+func (c *accountCache) Set(ctx context.Context, id uint64, data *model.Account, duration time.Duration) error {
+	if data == nil || id == 0 {
+		return nil
+	}
+	cacheKey := c.GetAccountCacheKey(id)
+	err := c.cache.Set(ctx, cacheKey, data, duration)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Get cache value
+func (c *accountCache) Get(ctx context.Context, id uint64) (*model.Account, error) {
+	var data *model.Account
+	cacheKey := c.GetAccountCacheKey(id)
+	err := c.cache.Get(ctx, cacheKey, &data)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// MultiSet multiple set cache
+func (c *accountCache) MultiSet(ctx context.Context, data []*model.Account, duration time.Duration) error {
+	valMap := make(map[string]interface{})
+	for _, v := range data {
+		cacheKey := c.GetAccountCacheKey(v.ID)
+		valMap[cacheKey] = v
+	}
+
+	err := c.cache.MultiSet(ctx, valMap, duration)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// MultiGet multiple get cache, return key in map is id value
+func (c *accountCache) MultiGet(ctx context.Context, ids []uint64) (map[uint64]*model.Account, error) {
+	var keys []string
+	for _, v := range ids {
+		cacheKey := c.GetAccountCacheKey(v)
+		keys = append(keys, cacheKey)
+	}
+
+	itemMap := make(map[string]*model.Account)
+	err := c.cache.MultiGet(ctx, keys, itemMap)
+	if err != nil {
+		return nil, err
+	}
+
+	retMap := make(map[uint64]*model.Account)
+	for _, id := range ids {
+		val, ok := itemMap[c.GetAccountCacheKey(id)]
+		if ok {
+			retMap[id] = val
+		}
+	}
+
+	return retMap, nil
+}
+
+// Del delete cache
+func (c *accountCache) Del(ctx context.Context, id uint64) error {
+	cacheKey := c.GetAccountCacheKey(id)
+	err := c.cache.Del(ctx, cacheKey)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// SetPlaceholder set placeholder value to cache
+func (c *accountCache) SetPlaceholder(ctx context.Context, id uint64) error {
+	cacheKey := c.GetAccountCacheKey(id)
+	return c.cache.SetCacheWithNotFound(ctx, cacheKey)
+}
+
+// IsPlaceholderErr check if cache is placeholder error
+func (c *accountCache) IsPlaceholderErr(err error) bool {
+	return errors.Is(err, cache.ErrPlaceholder)
+}
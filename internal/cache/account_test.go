@@ -0,0 +1,147 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-dev-frame/sponge/pkg/gotest"
+	"github.com/go-dev-frame/sponge/pkg/utils"
+
+	"fs/internal/database"
+	"fs/internal/model"
+)
+
+func newAccountCache() *gotest.Cache {
+	record1 := &model.Account{}
+	record1.ID = 1
+	record2 := &model.Account{}
+	record2.ID = 2
+	testData := map[string]interface{}{
+		utils.Uint64ToStr(record1.ID): record1,
+		utils.Uint64ToStr(record2.ID): record2,
+	}
+
+	c := gotest.NewCache(testData)
+	c.ICache = NewAccountCache(&database.CacheType{
+		CType: "redis",
+		Rdb:   c.RedisClient,
+	})
+	return c
+}
+
+func Test_accountCache_Set(t *testing.T) {
+	c := newAccountCache()
+	defer c.Close()
+
+	record := c.TestDataSlice[0].(*model.Account)
+	err := c.ICache.(AccountCache).Set(c.Ctx, record.ID, record, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// nil data
+	err = c.ICache.(AccountCache).Set(c.Ctx, 0, nil, time.Hour)
+	assert.NoError(t, err)
+}
+
+func Test_accountCache_Get(t *testing.T) {
+	c := newAccountCache()
+	defer c.Close()
+
+	record := c.TestDataSlice[0].(*model.Account)
+	err := c.ICache.(AccountCache).Set(c.Ctx, record.ID, record, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := c.ICache.(AccountCache).Get(c.Ctx, record.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, record, got)
+
+	// zero key error
+	_, err = c.ICache.(AccountCache).Get(c.Ctx, 0)
+	assert.Error(t, err)
+}
+
+func Test_accountCache_MultiGet(t *testing.T) {
+	c := newAccountCache()
+	defer c.Close()
+
+	var testData []*model.Account
+	for _, data := range c.TestDataSlice {
+		testData = append(testData, data.(*model.Account))
+	}
+
+	err := c.ICache.(AccountCache).MultiSet(c.Ctx, testData, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := c.ICache.(AccountCache).MultiGet(c.Ctx, c.GetIDs())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := c.GetTestData()
+	for k, v := range expected {
+		assert.Equal(t, got[utils.StrToUint64(k)], v.(*model.Account))
+	}
+}
+
+func Test_accountCache_MultiSet(t *testing.T) {
+	c := newAccountCache()
+	defer c.Close()
+
+	var testData []*model.Account
+	for _, data := range c.TestDataSlice {
+		testData = append(testData, data.(*model.Account))
+	}
+
+	err := c.ICache.(AccountCache).MultiSet(c.Ctx, testData, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_accountCache_Del(t *testing.T) {
+	c := newAccountCache()
+	defer c.Close()
+
+	record := c.TestDataSlice[0].(*model.Account)
+	err := c.ICache.(AccountCache).Del(c.Ctx, record.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_accountCache_SetCacheWithNotFound(t *testing.T) {
+	c := newAccountCache()
+	defer c.Close()
+
+	record := c.TestDataSlice[0].(*model.Account)
+	err := c.ICache.(AccountCache).SetPlaceholder(c.Ctx, record.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := c.ICache.(AccountCache).IsPlaceholderErr(err)
+	t.Log(b)
+}
+
+func TestNewAccountCache(t *testing.T) {
+	c := NewAccountCache(&database.CacheType{
+		CType: "",
+	})
+	assert.Nil(t, c)
+	c = NewAccountCache(&database.CacheType{
+		CType: "memory",
+	})
+	assert.NotNil(t, c)
+	c = NewAccountCache(&database.CacheType{
+		CType: "redis",
+	})
+	assert.NotNil(t, c)
+}
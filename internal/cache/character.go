@@ -0,0 +1,153 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/go-dev-frame/sponge/pkg/cache"
+	"github.com/go-dev-frame/sponge/pkg/encoding"
+	"github.com/go-dev-frame/sponge/pkg/utils"
+
+	"fs/internal/database"
+	"fs/internal/model"
+)
+
+const (
+	// cache prefix key, must end with a colon
+	characterCachePrefixKey = "character:"
+	// CharacterExpireTime expire time
+	CharacterExpireTime = 5 * time.Minute
+)
+
+var _ CharacterCache = (*characterCache)(nil)
+
+// CharacterCache cache interface
+type CharacterCache interface {
+	Set(ctx context.Context, id uint64, data *model.Character, duration time.Duration) error
+	Get(ctx context.Context, id uint64) (*model.Character, error)
+	MultiGet(ctx context.Context, ids []uint64) (map[uint64]*model.Character, error)
+	MultiSet(ctx context.Context, data []*model.Character, duration time.Duration) error
+	Del(ctx context.Context, id uint64) error
+	SetPlaceholder(ctx context.Context, id uint64) error
+	IsPlaceholderErr(err error) bool
+}
+
+// characterCache define a cache struct
+type characterCache struct {
+	cache cache.Cache
+}
+
+// NewCharacterCache new a cache
+func NewCharacterCache(cacheType *database.CacheType) CharacterCache {
+	jsonEncoding := encoding.JSONEncoding{}
+	cachePrefix := ""
+
+	cType := strings.ToLower(cacheType.CType)
+	switch cType {
+	case "redis":
+		c := cache.NewRedisCache(cacheType.Rdb, cachePrefix, jsonEncoding, func() interface{} {
+			return &model.Character{}
+		})
+		return &characterCache{cache: c}
+	case "memory":
+		c := cache.NewMemoryCache(cachePrefix, jsonEncoding, func() interface{} {
+			return &model.Character{}
+		})
+		return &characterCache{cache: c}
+	}
+
+	return nil // no cache
+}
+
+// GetCharacterCacheKey cache key
+func (c *characterCache) GetCharacterCacheKey(id uint64) string {
+	return characterCachePrefixKey + utils.Uint64ToStr(id)
+}
+
+// Set write to cache
+func (c *characterCache) Set(ctx context.Context, id uint64, data *model.Character, duration time.Duration) error {
+	if data == nil || id == 0 {
+		return nil
+	}
+	cacheKey := c.GetCharacterCacheKey(id)
+	err := c.cache.Set(ctx, cacheKey, data, duration)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Get cache value
+func (c *characterCache) Get(ctx context.Context, id uint64) (*model.Character, error) {
+	var data *model.Character
+	cacheKey := c.GetCharacterCacheKey(id)
+	err := c.cache.Get(ctx, cacheKey, &data)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// MultiSet multiple set cache
+func (c *characterCache) MultiSet(ctx context.Context, data []*model.Character, duration time.Duration) error {
+	valMap := make(map[string]interface{})
+	for _, v := range data {
+		cacheKey := c.GetCharacterCacheKey(v.ID)
+		valMap[cacheKey] = v
+	}
+
+	err := c.cache.MultiSet(ctx, valMap, duration)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// MultiGet multiple get cache, return key in map is id value
+func (c *characterCache) MultiGet(ctx context.Context, ids []uint64) (map[uint64]*model.Character, error) {
+	var keys []string
+	for _, v := range ids {
+		cacheKey := c.GetCharacterCacheKey(v)
+		keys = append(keys, cacheKey)
+	}
+
+	itemMap := make(map[string]*model.Character)
+	err := c.cache.MultiGet(ctx, keys, itemMap)
+	if err != nil {
+		return nil, err
+	}
+
+	retMap := make(map[uint64]*model.Character)
+	for _, id := range ids {
+		val, ok := itemMap[c.GetCharacterCacheKey(id)]
+		if ok {
+			retMap[id] = val
+		}
+	}
+
+	return retMap, nil
+}
+
+// Del delete cache
+func (c *characterCache) Del(ctx context.Context, id uint64) error {
+	cacheKey := c.GetCharacterCacheKey(id)
+	err := c.cache.Del(ctx, cacheKey)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// SetPlaceholder set placeholder value to cache
+func (c *characterCache) SetPlaceholder(ctx context.Context, id uint64) error {
+	cacheKey := c.GetCharacterCacheKey(id)
+	return c.cache.SetCacheWithNotFound(ctx, cacheKey)
+}
+
+// IsPlaceholderErr check if cache is placeholder error
+func (c *characterCache) IsPlaceholderErr(err error) bool {
+	return errors.Is(err, cache.ErrPlaceholder)
+}
@@ -0,0 +1,147 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-dev-frame/sponge/pkg/gotest"
+	"github.com/go-dev-frame/sponge/pkg/utils"
+
+	"fs/internal/database"
+	"fs/internal/model"
+)
+
+func newCharacterCache() *gotest.Cache {
+	record1 := &model.Character{}
+	record1.ID = 1
+	record2 := &model.Character{}
+	record2.ID = 2
+	testData := map[string]interface{}{
+		utils.Uint64ToStr(record1.ID): record1,
+		utils.Uint64ToStr(record2.ID): record2,
+	}
+
+	c := gotest.NewCache(testData)
+	c.ICache = NewCharacterCache(&database.CacheType{
+		CType: "redis",
+		Rdb:   c.RedisClient,
+	})
+	return c
+}
+
+func Test_characterCache_Set(t *testing.T) {
+	c := newCharacterCache()
+	defer c.Close()
+
+	record := c.TestDataSlice[0].(*model.Character)
+	err := c.ICache.(CharacterCache).Set(c.Ctx, record.ID, record, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// nil data
+	err = c.ICache.(CharacterCache).Set(c.Ctx, 0, nil, time.Hour)
+	assert.NoError(t, err)
+}
+
+func Test_characterCache_Get(t *testing.T) {
+	c := newCharacterCache()
+	defer c.Close()
+
+	record := c.TestDataSlice[0].(*model.Character)
+	err := c.ICache.(CharacterCache).Set(c.Ctx, record.ID, record, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := c.ICache.(CharacterCache).Get(c.Ctx, record.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, record, got)
+
+	// zero key error
+	_, err = c.ICache.(CharacterCache).Get(c.Ctx, 0)
+	assert.Error(t, err)
+}
+
+func Test_characterCache_MultiGet(t *testing.T) {
+	c := newCharacterCache()
+	defer c.Close()
+
+	var testData []*model.Character
+	for _, data := range c.TestDataSlice {
+		testData = append(testData, data.(*model.Character))
+	}
+
+	err := c.ICache.(CharacterCache).MultiSet(c.Ctx, testData, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := c.ICache.(CharacterCache).MultiGet(c.Ctx, c.GetIDs())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := c.GetTestData()
+	for k, v := range expected {
+		assert.Equal(t, got[utils.StrToUint64(k)], v.(*model.Character))
+	}
+}
+
+func Test_characterCache_MultiSet(t *testing.T) {
+	c := newCharacterCache()
+	defer c.Close()
+
+	var testData []*model.Character
+	for _, data := range c.TestDataSlice {
+		testData = append(testData, data.(*model.Character))
+	}
+
+	err := c.ICache.(CharacterCache).MultiSet(c.Ctx, testData, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_characterCache_Del(t *testing.T) {
+	c := newCharacterCache()
+	defer c.Close()
+
+	record := c.TestDataSlice[0].(*model.Character)
+	err := c.ICache.(CharacterCache).Del(c.Ctx, record.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_characterCache_SetCacheWithNotFound(t *testing.T) {
+	c := newCharacterCache()
+	defer c.Close()
+
+	record := c.TestDataSlice[0].(*model.Character)
+	err := c.ICache.(CharacterCache).SetPlaceholder(c.Ctx, record.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := c.ICache.(CharacterCache).IsPlaceholderErr(err)
+	t.Log(b)
+}
+
+func TestNewCharacterCache(t *testing.T) {
+	c := NewCharacterCache(&database.CacheType{
+		CType: "",
+	})
+	assert.Nil(t, c)
+	c = NewCharacterCache(&database.CacheType{
+		CType: "memory",
+	})
+	assert.NotNil(t, c)
+	c = NewCharacterCache(&database.CacheType{
+		CType: "redis",
+	})
+	assert.NotNil(t, c)
+}
@@ -0,0 +1,153 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/go-dev-frame/sponge/pkg/cache"
+	"github.com/go-dev-frame/sponge/pkg/encoding"
+	"github.com/go-dev-frame/sponge/pkg/utils"
+
+	"fs/internal/database"
+	"fs/internal/model"
+)
+
+const (
+	// cache prefix key, must end with a colon
+	itemInstanceCachePrefixKey = "item_instance:"
+	// ItemInstanceExpireTime expire time
+	ItemInstanceExpireTime = 5 * time.Minute
+)
+
+var _ ItemInstanceCache = (*itemInstanceCache)(nil)
+
+// ItemInstanceCache cache interface
+type ItemInstanceCache interface {
+	Set(ctx context.Context, id uint64, data *model.ItemInstance, duration time.Duration) error
+	Get(ctx context.Context, id uint64) (*model.ItemInstance, error)
+	MultiGet(ctx context.Context, ids []uint64) (map[uint64]*model.ItemInstance, error)
+	MultiSet(ctx context.Context, data []*model.ItemInstance, duration time.Duration) error
+	Del(ctx context.Context, id uint64) error
+	SetPlaceholder(ctx context.Context, id uint64) error
+	IsPlaceholderErr(err error) bool
+}
+
+// itemInstanceCache define a cache struct
+type itemInstanceCache struct {
+	cache cache.Cache
+}
+
+// NewItemInstanceCache new a cache
+func NewItemInstanceCache(cacheType *database.CacheType) ItemInstanceCache {
+	jsonEncoding := encoding.JSONEncoding{}
+	cachePrefix := ""
+
+	cType := strings.ToLower(cacheType.CType)
+	switch cType {
+	case "redis":
+		c := cache.NewRedisCache(cacheType.Rdb, cachePrefix, jsonEncoding, func() interface{} {
+			return &model.ItemInstance{}
+		})
+		return &itemInstanceCache{cache: c}
+	case "memory":
+		c := cache.NewMemoryCache(cachePrefix, jsonEncoding, func() interface{} {
+			return &model.ItemInstance{}
+		})
+		return &itemInstanceCache{cache: c}
+	}
+
+	return nil // no cache
+}
+
+// GetItemInstanceCacheKey cache key
+func (c *itemInstanceCache) GetItemInstanceCacheKey(id uint64) string {
+	return itemInstanceCachePrefixKey + utils.Uint64ToStr(id)
+}
+
+// Set write to cache
+func (c *itemInstanceCache) Set(ctx context.Context, id uint64, data *model.ItemInstance, duration time.Duration) error {
+	if data == nil || id == 0 {
+		return nil
+	}
+	cacheKey := c.GetItemInstanceCacheKey(id)
+	err := c.cache.Set(ctx, cacheKey, data, duration)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Get cache value
+func (c *itemInstanceCache) Get(ctx context.Context, id uint64) (*model.ItemInstance, error) {
+	var data *model.ItemInstance
+	cacheKey := c.GetItemInstanceCacheKey(id)
+	err := c.cache.Get(ctx, cacheKey, &data)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// MultiSet multiple set cache
+func (c *itemInstanceCache) MultiSet(ctx context.Context, data []*model.ItemInstance, duration time.Duration) error {
+	valMap := make(map[string]interface{})
+	for _, v := range data {
+		cacheKey := c.GetItemInstanceCacheKey(v.ID)
+		valMap[cacheKey] = v
+	}
+
+	err := c.cache.MultiSet(ctx, valMap, duration)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// MultiGet multiple get cache, return key in map is id value
+func (c *itemInstanceCache) MultiGet(ctx context.Context, ids []uint64) (map[uint64]*model.ItemInstance, error) {
+	var keys []string
+	for _, v := range ids {
+		cacheKey := c.GetItemInstanceCacheKey(v)
+		keys = append(keys, cacheKey)
+	}
+
+	itemMap := make(map[string]*model.ItemInstance)
+	err := c.cache.MultiGet(ctx, keys, itemMap)
+	if err != nil {
+		return nil, err
+	}
+
+	retMap := make(map[uint64]*model.ItemInstance)
+	for _, id := range ids {
+		val, ok := itemMap[c.GetItemInstanceCacheKey(id)]
+		if ok {
+			retMap[id] = val
+		}
+	}
+
+	return retMap, nil
+}
+
+// Del delete cache
+func (c *itemInstanceCache) Del(ctx context.Context, id uint64) error {
+	cacheKey := c.GetItemInstanceCacheKey(id)
+	err := c.cache.Del(ctx, cacheKey)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// SetPlaceholder set placeholder value to cache
+func (c *itemInstanceCache) SetPlaceholder(ctx context.Context, id uint64) error {
+	cacheKey := c.GetItemInstanceCacheKey(id)
+	return c.cache.SetCacheWithNotFound(ctx, cacheKey)
+}
+
+// IsPlaceholderErr check if cache is placeholder error
+func (c *itemInstanceCache) IsPlaceholderErr(err error) bool {
+	return errors.Is(err, cache.ErrPlaceholder)
+}
@@ -0,0 +1,147 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-dev-frame/sponge/pkg/gotest"
+	"github.com/go-dev-frame/sponge/pkg/utils"
+
+	"fs/internal/database"
+	"fs/internal/model"
+)
+
+func newItemInstanceCache() *gotest.Cache {
+	record1 := &model.ItemInstance{}
+	record1.ID = 1
+	record2 := &model.ItemInstance{}
+	record2.ID = 2
+	testData := map[string]interface{}{
+		utils.Uint64ToStr(record1.ID): record1,
+		utils.Uint64ToStr(record2.ID): record2,
+	}
+
+	c := gotest.NewCache(testData)
+	c.ICache = NewItemInstanceCache(&database.CacheType{
+		CType: "redis",
+		Rdb:   c.RedisClient,
+	})
+	return c
+}
+
+func Test_itemInstanceCache_Set(t *testing.T) {
+	c := newItemInstanceCache()
+	defer c.Close()
+
+	record := c.TestDataSlice[0].(*model.ItemInstance)
+	err := c.ICache.(ItemInstanceCache).Set(c.Ctx, record.ID, record, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// nil data
+	err = c.ICache.(ItemInstanceCache).Set(c.Ctx, 0, nil, time.Hour)
+	assert.NoError(t, err)
+}
+
+func Test_itemInstanceCache_Get(t *testing.T) {
+	c := newItemInstanceCache()
+	defer c.Close()
+
+	record := c.TestDataSlice[0].(*model.ItemInstance)
+	err := c.ICache.(ItemInstanceCache).Set(c.Ctx, record.ID, record, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := c.ICache.(ItemInstanceCache).Get(c.Ctx, record.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, record, got)
+
+	// zero key error
+	_, err = c.ICache.(ItemInstanceCache).Get(c.Ctx, 0)
+	assert.Error(t, err)
+}
+
+func Test_itemInstanceCache_MultiGet(t *testing.T) {
+	c := newItemInstanceCache()
+	defer c.Close()
+
+	var testData []*model.ItemInstance
+	for _, data := range c.TestDataSlice {
+		testData = append(testData, data.(*model.ItemInstance))
+	}
+
+	err := c.ICache.(ItemInstanceCache).MultiSet(c.Ctx, testData, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := c.ICache.(ItemInstanceCache).MultiGet(c.Ctx, c.GetIDs())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := c.GetTestData()
+	for k, v := range expected {
+		assert.Equal(t, got[utils.StrToUint64(k)], v.(*model.ItemInstance))
+	}
+}
+
+func Test_itemInstanceCache_MultiSet(t *testing.T) {
+	c := newItemInstanceCache()
+	defer c.Close()
+
+	var testData []*model.ItemInstance
+	for _, data := range c.TestDataSlice {
+		testData = append(testData, data.(*model.ItemInstance))
+	}
+
+	err := c.ICache.(ItemInstanceCache).MultiSet(c.Ctx, testData, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_itemInstanceCache_Del(t *testing.T) {
+	c := newItemInstanceCache()
+	defer c.Close()
+
+	record := c.TestDataSlice[0].(*model.ItemInstance)
+	err := c.ICache.(ItemInstanceCache).Del(c.Ctx, record.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_itemInstanceCache_SetCacheWithNotFound(t *testing.T) {
+	c := newItemInstanceCache()
+	defer c.Close()
+
+	record := c.TestDataSlice[0].(*model.ItemInstance)
+	err := c.ICache.(ItemInstanceCache).SetPlaceholder(c.Ctx, record.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := c.ICache.(ItemInstanceCache).IsPlaceholderErr(err)
+	t.Log(b)
+}
+
+func TestNewItemInstanceCache(t *testing.T) {
+	c := NewItemInstanceCache(&database.CacheType{
+		CType: "",
+	})
+	assert.Nil(t, c)
+	c = NewItemInstanceCache(&database.CacheType{
+		CType: "memory",
+	})
+	assert.NotNil(t, c)
+	c = NewItemInstanceCache(&database.CacheType{
+		CType: "redis",
+	})
+	assert.NotNil(t, c)
+}
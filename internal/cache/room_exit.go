@@ -0,0 +1,153 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/go-dev-frame/sponge/pkg/cache"
+	"github.com/go-dev-frame/sponge/pkg/encoding"
+	"github.com/go-dev-frame/sponge/pkg/utils"
+
+	"fs/internal/database"
+	"fs/internal/model"
+)
+
+const (
+	// cache prefix key, must end with a colon
+	roomExitCachePrefixKey = "room_exit:"
+	// RoomExitExpireTime expire time
+	RoomExitExpireTime = 5 * time.Minute
+)
+
+var _ RoomExitCache = (*roomExitCache)(nil)
+
+// RoomExitCache cache interface
+type RoomExitCache interface {
+	Set(ctx context.Context, id uint64, data *model.RoomExit, duration time.Duration) error
+	Get(ctx context.Context, id uint64) (*model.RoomExit, error)
+	MultiGet(ctx context.Context, ids []uint64) (map[uint64]*model.RoomExit, error)
+	MultiSet(ctx context.Context, data []*model.RoomExit, duration time.Duration) error
+	Del(ctx context.Context, id uint64) error
+	SetPlaceholder(ctx context.Context, id uint64) error
+	IsPlaceholderErr(err error) bool
+}
+
+// roomExitCache define a cache struct
+type roomExitCache struct {
+	cache cache.Cache
+}
+
+// NewRoomExitCache new a cache
+func NewRoomExitCache(cacheType *database.CacheType) RoomExitCache {
+	jsonEncoding := encoding.JSONEncoding{}
+	cachePrefix := ""
+
+	cType := strings.ToLower(cacheType.CType)
+	switch cType {
+	case "redis":
+		c := cache.NewRedisCache(cacheType.Rdb, cachePrefix, jsonEncoding, func() interface{} {
+			return &model.RoomExit{}
+		})
+		return &roomExitCache{cache: c}
+	case "memory":
+		c := cache.NewMemoryCache(cachePrefix, jsonEncoding, func() interface{} {
+			return &model.RoomExit{}
+		})
+		return &roomExitCache{cache: c}
+	}
+
+	return nil // no cache
+}
+
+// GetRoomExitCacheKey cache key
+func (c *roomExitCache) GetRoomExitCacheKey(id uint64) string {
+	return roomExitCachePrefixKey + utils.Uint64ToStr(id)
+}
+
+// Set write to cache
+func (c *roomExitCache) Set(ctx context.Context, id uint64, data *model.RoomExit, duration time.Duration) error {
+	if data == nil || id == 0 {
+		return nil
+	}
+	cacheKey := c.GetRoomExitCacheKey(id)
+	err := c.cache.Set(ctx, cacheKey, data, duration)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Get cache value
+func (c *roomExitCache) Get(ctx context.Context, id uint64) (*model.RoomExit, error) {
+	var data *model.RoomExit
+	cacheKey := c.GetRoomExitCacheKey(id)
+	err := c.cache.Get(ctx, cacheKey, &data)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// MultiSet multiple set cache
+func (c *roomExitCache) MultiSet(ctx context.Context, data []*model.RoomExit, duration time.Duration) error {
+	valMap := make(map[string]interface{})
+	for _, v := range data {
+		cacheKey := c.GetRoomExitCacheKey(v.ID)
+		valMap[cacheKey] = v
+	}
+
+	err := c.cache.MultiSet(ctx, valMap, duration)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// MultiGet multiple get cache, return key in map is id value
+func (c *roomExitCache) MultiGet(ctx context.Context, ids []uint64) (map[uint64]*model.RoomExit, error) {
+	var keys []string
+	for _, v := range ids {
+		cacheKey := c.GetRoomExitCacheKey(v)
+		keys = append(keys, cacheKey)
+	}
+
+	itemMap := make(map[string]*model.RoomExit)
+	err := c.cache.MultiGet(ctx, keys, itemMap)
+	if err != nil {
+		return nil, err
+	}
+
+	retMap := make(map[uint64]*model.RoomExit)
+	for _, id := range ids {
+		val, ok := itemMap[c.GetRoomExitCacheKey(id)]
+		if ok {
+			retMap[id] = val
+		}
+	}
+
+	return retMap, nil
+}
+
+// Del delete cache
+func (c *roomExitCache) Del(ctx context.Context, id uint64) error {
+	cacheKey := c.GetRoomExitCacheKey(id)
+	err := c.cache.Del(ctx, cacheKey)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// SetPlaceholder set placeholder value to cache
+func (c *roomExitCache) SetPlaceholder(ctx context.Context, id uint64) error {
+	cacheKey := c.GetRoomExitCacheKey(id)
+	return c.cache.SetCacheWithNotFound(ctx, cacheKey)
+}
+
+// IsPlaceholderErr check if cache is placeholder error
+func (c *roomExitCache) IsPlaceholderErr(err error) bool {
+	return errors.Is(err, cache.ErrPlaceholder)
+}
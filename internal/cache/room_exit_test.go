@@ -0,0 +1,147 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-dev-frame/sponge/pkg/gotest"
+	"github.com/go-dev-frame/sponge/pkg/utils"
+
+	"fs/internal/database"
+	"fs/internal/model"
+)
+
+func newRoomExitCache() *gotest.Cache {
+	record1 := &model.RoomExit{}
+	record1.ID = 1
+	record2 := &model.RoomExit{}
+	record2.ID = 2
+	testData := map[string]interface{}{
+		utils.Uint64ToStr(record1.ID): record1,
+		utils.Uint64ToStr(record2.ID): record2,
+	}
+
+	c := gotest.NewCache(testData)
+	c.ICache = NewRoomExitCache(&database.CacheType{
+		CType: "redis",
+		Rdb:   c.RedisClient,
+	})
+	return c
+}
+
+func Test_roomExitCache_Set(t *testing.T) {
+	c := newRoomExitCache()
+	defer c.Close()
+
+	record := c.TestDataSlice[0].(*model.RoomExit)
+	err := c.ICache.(RoomExitCache).Set(c.Ctx, record.ID, record, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// nil data
+	err = c.ICache.(RoomExitCache).Set(c.Ctx, 0, nil, time.Hour)
+	assert.NoError(t, err)
+}
+
+func Test_roomExitCache_Get(t *testing.T) {
+	c := newRoomExitCache()
+	defer c.Close()
+
+	record := c.TestDataSlice[0].(*model.RoomExit)
+	err := c.ICache.(RoomExitCache).Set(c.Ctx, record.ID, record, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := c.ICache.(RoomExitCache).Get(c.Ctx, record.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, record, got)
+
+	// zero key error
+	_, err = c.ICache.(RoomExitCache).Get(c.Ctx, 0)
+	assert.Error(t, err)
+}
+
+func Test_roomExitCache_MultiGet(t *testing.T) {
+	c := newRoomExitCache()
+	defer c.Close()
+
+	var testData []*model.RoomExit
+	for _, data := range c.TestDataSlice {
+		testData = append(testData, data.(*model.RoomExit))
+	}
+
+	err := c.ICache.(RoomExitCache).MultiSet(c.Ctx, testData, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := c.ICache.(RoomExitCache).MultiGet(c.Ctx, c.GetIDs())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := c.GetTestData()
+	for k, v := range expected {
+		assert.Equal(t, got[utils.StrToUint64(k)], v.(*model.RoomExit))
+	}
+}
+
+func Test_roomExitCache_MultiSet(t *testing.T) {
+	c := newRoomExitCache()
+	defer c.Close()
+
+	var testData []*model.RoomExit
+	for _, data := range c.TestDataSlice {
+		testData = append(testData, data.(*model.RoomExit))
+	}
+
+	err := c.ICache.(RoomExitCache).MultiSet(c.Ctx, testData, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_roomExitCache_Del(t *testing.T) {
+	c := newRoomExitCache()
+	defer c.Close()
+
+	record := c.TestDataSlice[0].(*model.RoomExit)
+	err := c.ICache.(RoomExitCache).Del(c.Ctx, record.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_roomExitCache_SetCacheWithNotFound(t *testing.T) {
+	c := newRoomExitCache()
+	defer c.Close()
+
+	record := c.TestDataSlice[0].(*model.RoomExit)
+	err := c.ICache.(RoomExitCache).SetPlaceholder(c.Ctx, record.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := c.ICache.(RoomExitCache).IsPlaceholderErr(err)
+	t.Log(b)
+}
+
+func TestNewRoomExitCache(t *testing.T) {
+	c := NewRoomExitCache(&database.CacheType{
+		CType: "",
+	})
+	assert.Nil(t, c)
+	c = NewRoomExitCache(&database.CacheType{
+		CType: "memory",
+	})
+	assert.NotNil(t, c)
+	c = NewRoomExitCache(&database.CacheType{
+		CType: "redis",
+	})
+	assert.NotNil(t, c)
+}
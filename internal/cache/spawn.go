@@ -0,0 +1,153 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/go-dev-frame/sponge/pkg/cache"
+	"github.com/go-dev-frame/sponge/pkg/encoding"
+	"github.com/go-dev-frame/sponge/pkg/utils"
+
+	"fs/internal/database"
+	"fs/internal/model"
+)
+
+const (
+	// cache prefix key, must end with a colon
+	spawnCachePrefixKey = "spawn:"
+	// SpawnExpireTime expire time
+	SpawnExpireTime = 5 * time.Minute
+)
+
+var _ SpawnCache = (*spawnCache)(nil)
+
+// SpawnCache cache interface
+type SpawnCache interface {
+	Set(ctx context.Context, id uint64, data *model.Spawn, duration time.Duration) error
+	Get(ctx context.Context, id uint64) (*model.Spawn, error)
+	MultiGet(ctx context.Context, ids []uint64) (map[uint64]*model.Spawn, error)
+	MultiSet(ctx context.Context, data []*model.Spawn, duration time.Duration) error
+	Del(ctx context.Context, id uint64) error
+	SetPlaceholder(ctx context.Context, id uint64) error
+	IsPlaceholderErr(err error) bool
+}
+
+// spawnCache define a cache struct
+type spawnCache struct {
+	cache cache.Cache
+}
+
+// NewSpawnCache new a cache
+func NewSpawnCache(cacheType *database.CacheType) SpawnCache {
+	jsonEncoding := encoding.JSONEncoding{}
+	cachePrefix := ""
+
+	cType := strings.ToLower(cacheType.CType)
+	switch cType {
+	case "redis":
+		c := cache.NewRedisCache(cacheType.Rdb, cachePrefix, jsonEncoding, func() interface{} {
+			return &model.Spawn{}
+		})
+		return &spawnCache{cache: c}
+	case "memory":
+		c := cache.NewMemoryCache(cachePrefix, jsonEncoding, func() interface{} {
+			return &model.Spawn{}
+		})
+		return &spawnCache{cache: c}
+	}
+
+	return nil // no cache
+}
+
+// GetSpawnCacheKey cache key
+func (c *spawnCache) GetSpawnCacheKey(id uint64) string {
+	return spawnCachePrefixKey + utils.Uint64ToStr(id)
+}
+
+// Set write to cache
+func (c *spawnCache) Set(ctx context.Context, id uint64, data *model.Spawn, duration time.Duration) error {
+	if data == nil || id == 0 {
+		return nil
+	}
+	cacheKey := c.GetSpawnCacheKey(id)
+	err := c.cache.Set(ctx, cacheKey, data, duration)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Get cache value
+func (c *spawnCache) Get(ctx context.Context, id uint64) (*model.Spawn, error) {
+	var data *model.Spawn
+	cacheKey := c.GetSpawnCacheKey(id)
+	err := c.cache.Get(ctx, cacheKey, &data)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// MultiSet multiple set cache
+func (c *spawnCache) MultiSet(ctx context.Context, data []*model.Spawn, duration time.Duration) error {
+	valMap := make(map[string]interface{})
+	for _, v := range data {
+		cacheKey := c.GetSpawnCacheKey(v.ID)
+		valMap[cacheKey] = v
+	}
+
+	err := c.cache.MultiSet(ctx, valMap, duration)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// MultiGet multiple get cache, return key in map is id value
+func (c *spawnCache) MultiGet(ctx context.Context, ids []uint64) (map[uint64]*model.Spawn, error) {
+	var keys []string
+	for _, v := range ids {
+		cacheKey := c.GetSpawnCacheKey(v)
+		keys = append(keys, cacheKey)
+	}
+
+	itemMap := make(map[string]*model.Spawn)
+	err := c.cache.MultiGet(ctx, keys, itemMap)
+	if err != nil {
+		return nil, err
+	}
+
+	retMap := make(map[uint64]*model.Spawn)
+	for _, id := range ids {
+		val, ok := itemMap[c.GetSpawnCacheKey(id)]
+		if ok {
+			retMap[id] = val
+		}
+	}
+
+	return retMap, nil
+}
+
+// Del delete cache
+func (c *spawnCache) Del(ctx context.Context, id uint64) error {
+	cacheKey := c.GetSpawnCacheKey(id)
+	err := c.cache.Del(ctx, cacheKey)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// SetPlaceholder set placeholder value to cache
+func (c *spawnCache) SetPlaceholder(ctx context.Context, id uint64) error {
+	cacheKey := c.GetSpawnCacheKey(id)
+	return c.cache.SetCacheWithNotFound(ctx, cacheKey)
+}
+
+// IsPlaceholderErr check if cache is placeholder error
+func (c *spawnCache) IsPlaceholderErr(err error) bool {
+	return errors.Is(err, cache.ErrPlaceholder)
+}
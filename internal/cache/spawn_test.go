@@ -0,0 +1,147 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-dev-frame/sponge/pkg/gotest"
+	"github.com/go-dev-frame/sponge/pkg/utils"
+
+	"fs/internal/database"
+	"fs/internal/model"
+)
+
+func newSpawnCache() *gotest.Cache {
+	record1 := &model.Spawn{}
+	record1.ID = 1
+	record2 := &model.Spawn{}
+	record2.ID = 2
+	testData := map[string]interface{}{
+		utils.Uint64ToStr(record1.ID): record1,
+		utils.Uint64ToStr(record2.ID): record2,
+	}
+
+	c := gotest.NewCache(testData)
+	c.ICache = NewSpawnCache(&database.CacheType{
+		CType: "redis",
+		Rdb:   c.RedisClient,
+	})
+	return c
+}
+
+func Test_spawnCache_Set(t *testing.T) {
+	c := newSpawnCache()
+	defer c.Close()
+
+	record := c.TestDataSlice[0].(*model.Spawn)
+	err := c.ICache.(SpawnCache).Set(c.Ctx, record.ID, record, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// nil data
+	err = c.ICache.(SpawnCache).Set(c.Ctx, 0, nil, time.Hour)
+	assert.NoError(t, err)
+}
+
+func Test_spawnCache_Get(t *testing.T) {
+	c := newSpawnCache()
+	defer c.Close()
+
+	record := c.TestDataSlice[0].(*model.Spawn)
+	err := c.ICache.(SpawnCache).Set(c.Ctx, record.ID, record, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := c.ICache.(SpawnCache).Get(c.Ctx, record.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, record, got)
+
+	// zero key error
+	_, err = c.ICache.(SpawnCache).Get(c.Ctx, 0)
+	assert.Error(t, err)
+}
+
+func Test_spawnCache_MultiGet(t *testing.T) {
+	c := newSpawnCache()
+	defer c.Close()
+
+	var testData []*model.Spawn
+	for _, data := range c.TestDataSlice {
+		testData = append(testData, data.(*model.Spawn))
+	}
+
+	err := c.ICache.(SpawnCache).MultiSet(c.Ctx, testData, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := c.ICache.(SpawnCache).MultiGet(c.Ctx, c.GetIDs())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := c.GetTestData()
+	for k, v := range expected {
+		assert.Equal(t, got[utils.StrToUint64(k)], v.(*model.Spawn))
+	}
+}
+
+func Test_spawnCache_MultiSet(t *testing.T) {
+	c := newSpawnCache()
+	defer c.Close()
+
+	var testData []*model.Spawn
+	for _, data := range c.TestDataSlice {
+		testData = append(testData, data.(*model.Spawn))
+	}
+
+	err := c.ICache.(SpawnCache).MultiSet(c.Ctx, testData, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_spawnCache_Del(t *testing.T) {
+	c := newSpawnCache()
+	defer c.Close()
+
+	record := c.TestDataSlice[0].(*model.Spawn)
+	err := c.ICache.(SpawnCache).Del(c.Ctx, record.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_spawnCache_SetCacheWithNotFound(t *testing.T) {
+	c := newSpawnCache()
+	defer c.Close()
+
+	record := c.TestDataSlice[0].(*model.Spawn)
+	err := c.ICache.(SpawnCache).SetPlaceholder(c.Ctx, record.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := c.ICache.(SpawnCache).IsPlaceholderErr(err)
+	t.Log(b)
+}
+
+func TestNewSpawnCache(t *testing.T) {
+	c := NewSpawnCache(&database.CacheType{
+		CType: "",
+	})
+	assert.Nil(t, c)
+	c = NewSpawnCache(&database.CacheType{
+		CType: "memory",
+	})
+	assert.NotNil(t, c)
+	c = NewSpawnCache(&database.CacheType{
+		CType: "redis",
+	})
+	assert.NotNil(t, c)
+}
@@ -0,0 +1,98 @@
+// Package combat holds the formulas of a fight: whether an attack lands and how
+// much damage it does, the game server and the combat simulator both use them.
+package combat
+
+import (
+	"fs/internal/equip"
+	"fs/internal/model"
+)
+
+// hit chance limits, in percent
+const (
+	baseHitChance = 50 // between an attack and a dodge of the same value
+	hitPerPoint   = 2  // added for each point of attack over the dodge
+	minHitChance  = 5
+	maxHitChance  = 95
+)
+
+// Stats what a fighter brings to a fight
+type Stats struct {
+	Hp      int `json:"hp"`
+	Attack  int `json:"attack"`
+	Defence int `json:"defence"`
+	Dodge   int `json:"dodge"`
+}
+
+// New the combat stats of a fighter with the given stats, strength adds to
+// attack, constitution to defence and dexterity to dodge
+func New(s equip.Stats) Stats {
+	return Stats{
+		Hp:      s.Hp,
+		Attack:  s.Attack + s.Str,
+		Defence: s.Defence + s.Con,
+		Dodge:   s.Dodge + s.Dex,
+	}
+}
+
+// MobBase the stats of a mob prototype before its items
+func MobBase(m *model.Mob) equip.Stats {
+	return equip.Stats{Hp: m.Hp, Mp: m.Mp, Attack: m.Attack, Defence: m.Defence, Dodge: m.Dodge}
+}
+
+// CharacterBase the stats of a character before its items
+func CharacterBase(ch *model.Character) equip.Stats {
+	return equip.Stats{
+		Hp:   ch.Hp,
+		Mp:   ch.Mp,
+		Str:  ch.Str,
+		Cor:  ch.Cor,
+		Inte: ch.Inte,
+		Dex:  ch.Dex,
+		Con:  ch.Con,
+		Kar:  ch.Kar,
+	}
+}
+
+// Attackable check if a mob can be attacked, shopkeepers and quest npcs have attackable
+// set to false, mobs without the flag can be attacked
+func Attackable(m *model.Mob) bool {
+	return m.Attackable == nil || bool(*m.Attackable)
+}
+
+// HitChance the percent chance that an attack lands, 50 between an attack and a dodge
+// of the same value, each point of attack over the dodge adds 2, kept within 5 and 95
+func HitChance(a Stats, d Stats) int {
+	chance := baseHitChance + hitPerPoint*(a.Attack-d.Dodge)
+	if chance < minHitChance {
+		return minHitChance
+	}
+	if chance > maxHitChance {
+		return maxHitChance
+	}
+	return chance
+}
+
+// DamageRange the least and the most damage of an attack that lands, the attack plus up to
+// half of it again, less half the defence, a hit does at least 1
+func DamageRange(a Stats, d Stats) (int, int) {
+	least := a.Attack - d.Defence/2
+	if least < 1 {
+		least = 1
+	}
+	return least, least + a.Attack/2
+}
+
+// Hit the outcome of an attack
+type Hit struct {
+	Landed bool // false if the defender dodged
+	Damage int
+}
+
+// Strike resolve an attack of a on d, roll returns a random number in [0, n)
+func Strike(a Stats, d Stats, roll func(n int) int) Hit {
+	if roll(100) >= HitChance(a, d) {
+		return Hit{}
+	}
+	least, most := DamageRange(a, d)
+	return Hit{Landed: true, Damage: least + roll(most-least+1)}
+}
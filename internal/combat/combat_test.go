@@ -0,0 +1,100 @@
+package combat
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-dev-frame/sponge/pkg/sgorm"
+
+	"fs/internal/equip"
+	"fs/internal/model"
+)
+
+func TestNew(t *testing.T) {
+	ch := &model.Character{Hp: 100, Str: 10, Con: 8, Dex: 12}
+	s := New(equip.Effective(CharacterBase(ch), equip.Outfit{equip.SlotWeapon: {Classifier: "w", Attack: 5}}))
+	assert.Equal(t, Stats{Hp: 100, Attack: 15, Defence: 8, Dodge: 12}, s)
+
+	mob := &model.Mob{Hp: 30, Attack: 4, Defence: 2, Dodge: 3}
+	assert.Equal(t, Stats{Hp: 30, Attack: 4, Defence: 2, Dodge: 3}, New(MobBase(mob)))
+}
+
+func TestAttackable(t *testing.T) {
+	no, yes := sgorm.TinyBool(false), sgorm.TinyBool(true)
+	assert.True(t, Attackable(&model.Mob{}))
+	assert.True(t, Attackable(&model.Mob{Attackable: &yes}))
+	assert.False(t, Attackable(&model.Mob{Attackable: &no}))
+}
+
+func TestHitChance(t *testing.T) {
+	assert.Equal(t, 50, HitChance(Stats{Attack: 10}, Stats{Dodge: 10}))
+	assert.Equal(t, 60, HitChance(Stats{Attack: 15}, Stats{Dodge: 10}))
+	assert.Equal(t, 95, HitChance(Stats{Attack: 100}, Stats{Dodge: 1}))
+	assert.Equal(t, 5, HitChance(Stats{Attack: 1}, Stats{Dodge: 100}))
+}
+
+func TestDamageRange(t *testing.T) {
+	least, most := DamageRange(Stats{Attack: 10}, Stats{Defence: 4})
+	assert.Equal(t, 8, least)
+	assert.Equal(t, 13, most)
+
+	least, most = DamageRange(Stats{Attack: 1}, Stats{Defence: 40})
+	assert.Equal(t, 1, least)
+	assert.Equal(t, 1, most)
+}
+
+func TestStrike(t *testing.T) {
+	a, d := Stats{Attack: 10}, Stats{Dodge: 10}
+	rolls := func(values ...int) func(int) int {
+		return func(n int) int {
+			v := values[0]
+			values = values[1:]
+			return v % n
+		}
+	}
+
+	assert.Equal(t, Hit{}, Strike(a, d, rolls(50)))
+	assert.Equal(t, Hit{Landed: true, Damage: 10}, Strike(a, d, rolls(49, 0)))
+	assert.Equal(t, Hit{Landed: true, Damage: 15}, Strike(a, d, rolls(0, 5)))
+}
+
+func TestFight(t *testing.T) {
+	player := Stats{Hp: 10, Attack: 10}
+	mob := Stats{Hp: 25, Attack: 4}
+	always := func(int) int { return 0 }
+
+	// the player strikes first, 10 a round, the mob strikes back with 4
+	r := Fight(player, mob, 100, always)
+	assert.Equal(t, FightResult{Outcome: Win, Rounds: 3, Dealt: 30, Taken: 8}, r)
+
+	r = Fight(player, Stats{Hp: 100, Attack: 4}, 100, always)
+	assert.Equal(t, FightResult{Outcome: Loss, Rounds: 3, Dealt: 30, Taken: 12}, r)
+
+	r = Fight(player, Stats{Hp: 100, Attack: 4}, 2, always)
+	assert.Equal(t, FightResult{Outcome: Draw, Rounds: 2, Dealt: 20, Taken: 8}, r)
+}
+
+func TestSimulate(t *testing.T) {
+	player := Stats{Hp: 100, Attack: 10, Defence: 10, Dodge: 10}
+	mob := Stats{Hp: 60, Attack: 12, Defence: 4, Dodge: 8}
+
+	sim := Simulate(player, mob, 500, 100, 42)
+	assert.Equal(t, 500, sim.Fights)
+	assert.Equal(t, 500, sim.Wins+sim.Losses+sim.Draws)
+	assert.InDelta(t, float64(sim.Wins)/500, sim.WinRate, 1e-9)
+	assert.LessOrEqual(t, sim.Rounds.Min, sim.Rounds.P50)
+	assert.LessOrEqual(t, sim.Rounds.P50, sim.Rounds.P90)
+	assert.LessOrEqual(t, sim.Rounds.P90, sim.Rounds.Max)
+	assert.Equal(t, sim.Rounds.Mean, sim.AvgRounds)
+
+	// seeded
+	assert.Equal(t, sim, Simulate(player, mob, 500, 100, 42))
+	assert.NotEqual(t, sim, Simulate(player, mob, 500, 100, 7))
+}
+
+func TestNewDistribution(t *testing.T) {
+	d := newDistribution([]int{5, 1, 3, 2, 4, 6, 7, 8, 9, 10})
+	assert.Equal(t, Distribution{Min: 1, Max: 10, Mean: 5.5, P50: 5, P90: 9}, d)
+	assert.Equal(t, Distribution{}, newDistribution(nil))
+}
@@ -0,0 +1,134 @@
+package combat
+
+import (
+	"math/rand"
+	"sort"
+)
+
+// Outcome how a fight ended for the player
+type Outcome int
+
+// fight outcomes
+const (
+	Draw Outcome = iota // nobody died within the round limit
+	Win
+	Loss
+)
+
+// FightResult a fight between a player and a mob
+type FightResult struct {
+	Outcome Outcome
+	Rounds  int
+	Dealt   int // damage done by the player
+	Taken   int // damage done by the mob
+}
+
+// Fight run rounds until one side dies or maxRounds have passed. As in the game
+// heartbeat the player strikes first each round and the mob strikes back if it lives.
+func Fight(player Stats, mob Stats, maxRounds int, roll func(n int) int) FightResult {
+	var r FightResult
+	playerHp, mobHp := player.Hp, mob.Hp
+	for r.Rounds < maxRounds {
+		r.Rounds++
+
+		hit := Strike(player, mob, roll)
+		r.Dealt += hit.Damage
+		mobHp -= hit.Damage
+		if mobHp <= 0 {
+			r.Outcome = Win
+			return r
+		}
+
+		hit = Strike(mob, player, roll)
+		r.Taken += hit.Damage
+		playerHp -= hit.Damage
+		if playerHp <= 0 {
+			r.Outcome = Loss
+			return r
+		}
+	}
+	return r
+}
+
+// Distribution how a number varied over the fights
+type Distribution struct {
+	Min  int     `json:"min"`
+	Max  int     `json:"max"`
+	Mean float64 `json:"mean"`
+	P50  int     `json:"p50"` // median
+	P90  int     `json:"p90"` // 9 in 10 fights are at most this
+}
+
+func newDistribution(values []int) Distribution {
+	if len(values) == 0 {
+		return Distribution{}
+	}
+	sorted := append([]int(nil), values...)
+	sort.Ints(sorted)
+	sum := 0
+	for _, v := range sorted {
+		sum += v
+	}
+	return Distribution{
+		Min:  sorted[0],
+		Max:  sorted[len(sorted)-1],
+		Mean: float64(sum) / float64(len(sorted)),
+		P50:  percentile(sorted, 50),
+		P90:  percentile(sorted, 90),
+	}
+}
+
+// percentile nearest rank of a sorted list
+func percentile(sorted []int, p int) int {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// Simulation the summary of many fights between the same player and mob
+type Simulation struct {
+	Fights    int          `json:"fights"`
+	Wins      int          `json:"wins"`
+	Losses    int          `json:"losses"`
+	Draws     int          `json:"draws"` // fights that reached the round limit
+	WinRate   float64      `json:"winRate"`
+	AvgRounds float64      `json:"avgRounds"`
+	Rounds    Distribution `json:"rounds"`
+	Dealt     Distribution `json:"dealt"` // damage done by the player in a fight
+	Taken     Distribution `json:"taken"` // damage done by the mob in a fight
+}
+
+// Simulate run a number of fights with random numbers from the seed, the same
+// seed and stats give the same result
+func Simulate(player Stats, mob Stats, fights int, maxRounds int, seed int64) *Simulation {
+	roll := rand.New(rand.NewSource(seed)).Intn
+	sim := &Simulation{Fights: fights}
+	rounds := make([]int, 0, fights)
+	dealt := make([]int, 0, fights)
+	taken := make([]int, 0, fights)
+	for i := 0; i < fights; i++ {
+		r := Fight(player, mob, maxRounds, roll)
+		switch r.Outcome {
+		case Win:
+			sim.Wins++
+		case Loss:
+			sim.Losses++
+		default:
+			sim.Draws++
+		}
+		rounds = append(rounds, r.Rounds)
+		dealt = append(dealt, r.Dealt)
+		taken = append(taken, r.Taken)
+	}
+
+	sim.Rounds = newDistribution(rounds)
+	sim.Dealt = newDistribution(dealt)
+	sim.Taken = newDistribution(taken)
+	if fights > 0 {
+		sim.WinRate = float64(sim.Wins) / float64(fights)
+	}
+	sim.AvgRounds = sim.Rounds.Mean
+	return sim
+}
@@ -33,13 +33,16 @@ type Config struct {
 	Consul     Consul       `yaml:"consul" json:"consul"`
 	Database   Database     `yaml:"database" json:"database"`
 	Etcd       Etcd         `yaml:"etcd" json:"etcd"`
+	Game       Game         `yaml:"game" json:"game"`
 	Grpc       Grpc         `yaml:"grpc" json:"grpc"`
 	GrpcClient []GrpcClient `yaml:"grpcClient" json:"grpcClient"`
 	HTTP       HTTP         `yaml:"http" json:"http"`
 	Jaeger     Jaeger       `yaml:"jaeger" json:"jaeger"`
+	Jwt        Jwt          `yaml:"jwt" json:"jwt"`
 	Logger     Logger       `yaml:"logger" json:"logger"`
 	NacosRd    NacosRd      `yaml:"nacosRd" json:"nacosRd"`
 	Redis      Redis        `yaml:"redis" json:"redis"`
+	Rules      Rules        `yaml:"rules" json:"rules"`
 }
 
 type Consul struct {
@@ -144,11 +147,13 @@ type Redis struct {
 }
 
 type Database struct {
-	Driver     string     `yaml:"driver" json:"driver"`
-	Mongodb    Mongodb    `yaml:"mongodb" json:"mongodb"`
-	Mysql      Mysql      `yaml:"mysql" json:"mysql"`
-	Postgresql Postgresql `yaml:"postgresql" json:"postgresql"`
-	Sqlite     Sqlite     `yaml:"sqlite" json:"sqlite"`
+	AutoMigrate bool       `yaml:"autoMigrate" json:"autoMigrate"`
+	Driver      string     `yaml:"driver" json:"driver"`
+	Mongodb     Mongodb    `yaml:"mongodb" json:"mongodb"`
+	Mysql       Mysql      `yaml:"mysql" json:"mysql"`
+	Postgresql  Postgresql `yaml:"postgresql" json:"postgresql"`
+	Sqlite      Sqlite     `yaml:"sqlite" json:"sqlite"`
+	TrashDays   int        `yaml:"trashDays" json:"trashDays"`
 }
 
 type Mongodb struct {
@@ -174,6 +179,36 @@ type NacosRd struct {
 	Port        int    `yaml:"port" json:"port"`
 }
 
+type Game struct {
+	Heartbeat     int    `yaml:"heartbeat" json:"heartbeat"`
+	IdleTimeout   int    `yaml:"idleTimeout" json:"idleTimeout"`
+	Port          int    `yaml:"port" json:"port"`
+	ResetInterval int    `yaml:"resetInterval" json:"resetInterval"`
+	SaveInterval  int    `yaml:"saveInterval" json:"saveInterval"`
+	StartRoom     string `yaml:"startRoom" json:"startRoom"`
+}
+
+type Rules struct {
+	Item RuleSet `yaml:"item" json:"item"`
+	Mob  RuleSet `yaml:"mob" json:"mob"`
+}
+
+type RuleSet struct {
+	Ranges   map[string]Range  `yaml:"ranges" json:"ranges"`
+	Requires map[string]string `yaml:"requires" json:"requires"`
+}
+
+type Range struct {
+	Max int `yaml:"max" json:"max"`
+	Min int `yaml:"min" json:"min"`
+}
+
+type Jwt struct {
+	Expire        int    `yaml:"expire" json:"expire"`
+	RefreshExpire int    `yaml:"refreshExpire" json:"refreshExpire"`
+	SignKey       string `yaml:"signKey" json:"signKey"`
+}
+
 type HTTP struct {
 	Port    int `yaml:"port" json:"port"`
 	Timeout int `yaml:"timeout" json:"timeout"`
@@ -0,0 +1,291 @@
+package dao
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-dev-frame/sponge/pkg/gotest"
+	"github.com/go-dev-frame/sponge/pkg/sgorm/query"
+	"github.com/go-dev-frame/sponge/pkg/utils"
+	"github.com/stretchr/testify/assert"
+
+	"fs/internal/cache"
+	"fs/internal/database"
+	"fs/internal/model"
+)
+
+func newAccountDao() *gotest.Dao {
+	testData := &model.Account{}
+	testData.ID = 1
+	testData.Username = "alice"
+	testData.PasswordHash = "$2a$10$hash"
+	testData.Role = "builder"
+
+	// init mock cache
+	//c := gotest.NewCache(map[string]interface{}{"no cache": testData}) // to test mysql, disable caching
+	c := gotest.NewCache(map[string]interface{}{utils.Uint64ToStr(testData.ID): testData})
+	c.ICache = cache.NewAccountCache(&database.CacheType{
+		CType: "redis",
+		Rdb:   c.RedisClient,
+	})
+
+	// init mock dao
+	d := gotest.NewDao(c, testData)
+	d.IDao = NewAccountDao(d.DB, c.ICache.(cache.AccountCache))
+
+	return d
+}
+
+func Test_accountDao_Create(t *testing.T) {
+	d := newAccountDao()
+	defer d.Close()
+	testData := d.TestData.(*model.Account)
+
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectExec("INSERT INTO .*").
+		WithArgs(d.GetAnyArgs(testData)...).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	d.SQLMock.ExpectCommit()
+
+	err := d.IDao.(AccountDao).Create(d.Ctx, testData)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_accountDao_DeleteByID(t *testing.T) {
+	d := newAccountDao()
+	defer d.Close()
+	testData := d.TestData.(*model.Account)
+	expectedSQLForDeletion := "DELETE .*"
+
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectExec(expectedSQLForDeletion).
+		WithArgs(testData.ID).
+		WillReturnResult(sqlmock.NewResult(int64(testData.ID), 1))
+	d.SQLMock.ExpectCommit()
+
+	err := d.IDao.(AccountDao).DeleteByID(d.Ctx, testData.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// zero id error
+	err = d.IDao.(AccountDao).DeleteByID(d.Ctx, 0)
+	assert.Error(t, err)
+}
+
+func Test_accountDao_UpdateByID(t *testing.T) {
+	d := newAccountDao()
+	defer d.Close()
+	testData := d.TestData.(*model.Account)
+
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectExec("UPDATE .*").
+		WithArgs(testData.PasswordHash, testData.Role, testData.Username, testData.ID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	d.SQLMock.ExpectCommit()
+
+	err := d.IDao.(AccountDao).UpdateByID(d.Ctx, testData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// zero id error
+	err = d.IDao.(AccountDao).UpdateByID(d.Ctx, &model.Account{})
+	assert.Error(t, err)
+
+}
+
+func Test_accountDao_GetByID(t *testing.T) {
+	d := newAccountDao()
+	defer d.Close()
+	testData := d.TestData.(*model.Account)
+
+	// column names and corresponding data
+	rows := sqlmock.NewRows([]string{"id"}).
+		AddRow(testData.ID)
+
+	d.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(testData.ID, 1).
+		WillReturnRows(rows)
+
+	_, err := d.IDao.(AccountDao).GetByID(d.Ctx, testData.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = d.SQLMock.ExpectationsWereMet()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// error test
+	d.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(2).
+		WillReturnRows(rows)
+	_, err = d.IDao.(AccountDao).GetByID(d.Ctx, 2)
+	assert.Error(t, err)
+
+	d.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(3, 4).
+		WillReturnRows(rows)
+	_, err = d.IDao.(AccountDao).GetByID(d.Ctx, 4)
+	assert.Error(t, err)
+}
+
+func Test_accountDao_GetByIDs(t *testing.T) {
+	d := newAccountDao()
+	defer d.Close()
+	testData := d.TestData.(*model.Account)
+
+	rows := sqlmock.NewRows([]string{"id"}).
+		AddRow(testData.ID)
+	d.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(testData.ID, 2).
+		WillReturnRows(rows)
+
+	records, err := d.IDao.(AccountDao).GetByIDs(d.Ctx, []uint64{testData.ID, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, records, 1)
+	assert.NotNil(t, records[testData.ID])
+
+	// 1 is cached, the placeholder of 2 is not returned by MultiGet, one query settles it
+	d.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	records, err = d.IDao.(AccountDao).GetByIDs(d.Ctx, []uint64{testData.ID, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, records, 1)
+
+	err = d.SQLMock.ExpectationsWereMet()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_accountDao_GetByColumns(t *testing.T) {
+	d := newAccountDao()
+	defer d.Close()
+	testData := d.TestData.(*model.Account)
+
+	// column names and corresponding data
+	rows := sqlmock.NewRows([]string{"id"}).
+		AddRow(testData.ID)
+
+	d.SQLMock.ExpectQuery("SELECT .*").WillReturnRows(rows)
+
+	_, _, err := d.IDao.(AccountDao).GetByColumns(d.Ctx, &query.Params{
+		Page:  0,
+		Limit: 10,
+		Sort:  "ignore count", // ignore test count(*)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = d.SQLMock.ExpectationsWereMet()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// err test
+	_, _, err = d.IDao.(AccountDao).GetByColumns(d.Ctx, &query.Params{
+		Page:  0,
+		Limit: 10,
+		Columns: []query.Column{
+			{
+				Name:  "id",
+				Exp:   "<",
+				Value: 0,
+			},
+		},
+	})
+	assert.Error(t, err)
+
+	// error test
+	dao := &accountDao{}
+	_, _, err = dao.GetByColumns(context.Background(), &query.Params{Columns: []query.Column{{}}})
+	t.Log(err)
+}
+
+func Test_accountDao_GetByUsername(t *testing.T) {
+	d := newAccountDao()
+	defer d.Close()
+	testData := d.TestData.(*model.Account)
+
+	rows := sqlmock.NewRows([]string{"id", "username"}).
+		AddRow(testData.ID, testData.Username)
+	d.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(testData.Username, 1).
+		WillReturnRows(rows)
+
+	record, err := d.IDao.(AccountDao).GetByUsername(d.Ctx, testData.Username)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, testData.ID, record.ID)
+
+	d.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs("bob", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	_, err = d.IDao.(AccountDao).GetByUsername(d.Ctx, "bob")
+	assert.ErrorIs(t, err, database.ErrRecordNotFound)
+}
+
+func Test_accountDao_CreateByTx(t *testing.T) {
+	d := newAccountDao()
+	defer d.Close()
+	testData := d.TestData.(*model.Account)
+
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectExec("INSERT INTO .*").
+		WithArgs(d.GetAnyArgs(testData)...).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	d.SQLMock.ExpectCommit()
+
+	_, err := d.IDao.(AccountDao).CreateByTx(d.Ctx, d.DB, testData)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_accountDao_DeleteByTx(t *testing.T) {
+	d := newAccountDao()
+	defer d.Close()
+	testData := d.TestData.(*model.Account)
+	expectedSQLForDeletion := "DELETE .*"
+
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectExec(expectedSQLForDeletion).
+		WithArgs(testData.ID).
+		WillReturnResult(sqlmock.NewResult(int64(testData.ID), 1))
+	d.SQLMock.ExpectCommit()
+
+	err := d.IDao.(AccountDao).DeleteByTx(d.Ctx, d.DB, testData.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_accountDao_UpdateByTx(t *testing.T) {
+	d := newAccountDao()
+	defer d.Close()
+	testData := d.TestData.(*model.Account)
+
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectExec("UPDATE .*").
+		WithArgs(testData.PasswordHash, testData.Role, testData.Username, testData.ID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	d.SQLMock.ExpectCommit()
+
+	err := d.IDao.(AccountDao).UpdateByTx(d.Ctx, d.DB, testData)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,351 @@
+package dao
+
+import (
+	"context"
+	"errors"
+
+	"golang.org/x/sync/singleflight"
+	"gorm.io/gorm"
+
+	"github.com/go-dev-frame/sponge/pkg/logger"
+	"github.com/go-dev-frame/sponge/pkg/sgorm/query"
+	"github.com/go-dev-frame/sponge/pkg/utils"
+
+	"fs/internal/cache"
+	"fs/internal/database"
+	"fs/internal/model"
+)
+
+var _ CharacterDao = (*characterDao)(nil)
+
+// CharacterDao defining the dao interface
+type CharacterDao interface {
+	Create(ctx context.Context, table *model.Character) error
+	DeleteByID(ctx context.Context, id uint64) error
+	UpdateByID(ctx context.Context, table *model.Character) error
+	SaveByID(ctx context.Context, table *model.Character) error
+	GetByID(ctx context.Context, id uint64) (*model.Character, error)
+	GetByIDs(ctx context.Context, ids []uint64) (map[uint64]*model.Character, error)
+	GetByColumns(ctx context.Context, params *query.Params) ([]*model.Character, int64, error)
+	GetByName(ctx context.Context, name string) (*model.Character, error)
+	GetByAccountID(ctx context.Context, accountID uint64) ([]*model.Character, error)
+
+	CreateByTx(ctx context.Context, tx *gorm.DB, table *model.Character) (uint64, error)
+	DeleteByTx(ctx context.Context, tx *gorm.DB, id uint64) error
+	UpdateByTx(ctx context.Context, tx *gorm.DB, table *model.Character) error
+	SaveByTx(ctx context.Context, tx *gorm.DB, table *model.Character) error
+}
+
+type characterDao struct {
+	db    *gorm.DB
+	cache cache.CharacterCache // if nil, the cache is not used.
+	sfg   *singleflight.Group  // if cache is nil, the sfg is not used.
+}
+
+// NewCharacterDao creating the dao interface
+func NewCharacterDao(db *gorm.DB, xCache cache.CharacterCache) CharacterDao {
+	if xCache == nil {
+		return &characterDao{db: db}
+	}
+	return &characterDao{
+		db:    db,
+		cache: xCache,
+		sfg:   new(singleflight.Group),
+	}
+}
+
+func (d *characterDao) deleteCache(ctx context.Context, id uint64) error {
+	if d.cache != nil {
+		return d.cache.Del(ctx, id)
+	}
+	return nil
+}
+
+// Create a new character, insert the record and the id value is written back to the table
+func (d *characterDao) Create(ctx context.Context, table *model.Character) error {
+	return d.db.WithContext(ctx).Create(table).Error
+}
+
+// DeleteByID delete a character by id
+func (d *characterDao) DeleteByID(ctx context.Context, id uint64) error {
+	err := d.db.WithContext(ctx).Where("id = ?", id).Delete(&model.Character{}).Error
+	if err != nil {
+		return err
+	}
+
+	// delete cache
+	_ = d.deleteCache(ctx, id)
+
+	return nil
+}
+
+// UpdateByID update a character by id, support partial update
+func (d *characterDao) UpdateByID(ctx context.Context, table *model.Character) error {
+	err := d.updateDataByID(ctx, d.db, table)
+
+	// delete cache
+	_ = d.deleteCache(ctx, table.ID)
+
+	return err
+}
+
+// SaveByID write every column of a character by id, zero values included, e.g. the hp of a dead character
+func (d *characterDao) SaveByID(ctx context.Context, table *model.Character) error {
+	err := d.saveDataByID(ctx, d.db, table)
+
+	// delete cache
+	_ = d.deleteCache(ctx, table.ID)
+
+	return err
+}
+
+func (d *characterDao) saveDataByID(ctx context.Context, db *gorm.DB, table *model.Character) error {
+	if table.ID < 1 {
+		return errors.New("id cannot be 0")
+	}
+	return db.WithContext(ctx).Model(table).Select("*").Omit("id").Updates(table).Error
+}
+
+func (d *characterDao) updateDataByID(ctx context.Context, db *gorm.DB, table *model.Character) error {
+	if table.ID < 1 {
+		return errors.New("id cannot be 0")
+	}
+
+	update := map[string]interface{}{}
+
+	if table.AccountID != 0 {
+		update["account_id"] = table.AccountID
+	}
+	if table.Name != "" {
+		update["name"] = table.Name
+	}
+	if table.RoomID != "" {
+		update["room_id"] = table.RoomID
+	}
+	if table.Hp != 0 {
+		update["hp"] = table.Hp
+	}
+	if table.Mp != 0 {
+		update["mp"] = table.Mp
+	}
+	if table.Str != 0 {
+		update["str"] = table.Str
+	}
+	if table.Cor != 0 {
+		update["cor"] = table.Cor
+	}
+	if table.Inte != 0 {
+		update["inte"] = table.Inte
+	}
+	if table.Dex != 0 {
+		update["dex"] = table.Dex
+	}
+	if table.Con != 0 {
+		update["con"] = table.Con
+	}
+	if table.Kar != 0 {
+		update["kar"] = table.Kar
+	}
+
+	return db.WithContext(ctx).Model(table).Updates(update).Error
+}
+
+// GetByID get a character by id
+func (d *characterDao) GetByID(ctx context.Context, id uint64) (*model.Character, error) {
+	// no cache
+	if d.cache == nil {
+		record := &model.Character{}
+		err := d.db.WithContext(ctx).Where("id = ?", id).First(record).Error
+		return record, err
+	}
+
+	// get from cache
+	record, err := d.cache.Get(ctx, id)
+	if err == nil {
+		return record, nil
+	}
+
+	// get from database
+	if errors.Is(err, database.ErrCacheNotFound) {
+		// for the same id, prevent high concurrent simultaneous access to database
+		val, err, _ := d.sfg.Do(utils.Uint64ToStr(id), func() (interface{}, error) { //nolint
+			table := &model.Character{}
+			err = d.db.WithContext(ctx).Where("id = ?", id).First(table).Error
+			if err != nil {
+				if errors.Is(err, database.ErrRecordNotFound) {
+					// set placeholder cache to prevent cache penetration, default expiration time 10 minutes
+					if err = d.cache.SetPlaceholder(ctx, id); err != nil {
+						logger.Warn("cache.SetPlaceholder error", logger.Err(err), logger.Any("id", id))
+					}
+					return nil, database.ErrRecordNotFound
+				}
+				return nil, err
+			}
+			// set cache
+			if err = d.cache.Set(ctx, id, table, cache.CharacterExpireTime); err != nil {
+				logger.Warn("cache.Set error", logger.Err(err), logger.Any("id", id))
+			}
+			return table, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		table, ok := val.(*model.Character)
+		if !ok {
+			return nil, database.ErrRecordNotFound
+		}
+		return table, nil
+	}
+
+	if d.cache.IsPlaceholderErr(err) {
+		return nil, database.ErrRecordNotFound
+	}
+
+	return nil, err
+}
+
+// GetByIDs get characters by ids, ids that do not exist are not in the returned map
+func (d *characterDao) GetByIDs(ctx context.Context, ids []uint64) (map[uint64]*model.Character, error) {
+	// no cache
+	if d.cache == nil {
+		var records []*model.Character
+		err := d.db.WithContext(ctx).Where("id IN (?)", ids).Find(&records).Error
+		if err != nil {
+			return nil, err
+		}
+		itemMap := make(map[uint64]*model.Character)
+		for _, record := range records {
+			itemMap[record.ID] = record
+		}
+		return itemMap, nil
+	}
+
+	// get from cache
+	itemMap, err := d.cache.MultiGet(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	var missedIDs []uint64
+	for _, id := range ids {
+		if _, ok := itemMap[id]; !ok {
+			missedIDs = append(missedIDs, id)
+		}
+	}
+	if len(missedIDs) == 0 {
+		return itemMap, nil
+	}
+
+	// get the missed ids from database with one query, MultiGet leaves out the placeholders
+	// like the ids that are not cached, so the ids known not to exist are in the query too
+	var records []*model.Character
+	err = d.db.WithContext(ctx).Where("id IN (?)", missedIDs).Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(records) > 0 {
+		for _, record := range records {
+			itemMap[record.ID] = record
+		}
+		if err = d.cache.MultiSet(ctx, records, cache.CharacterExpireTime); err != nil {
+			logger.Warn("cache.MultiSet error", logger.Err(err), logger.Any("ids", missedIDs))
+		}
+	}
+
+	// set placeholder cache for the ids that do not exist to prevent cache penetration in GetByID
+	for _, id := range missedIDs {
+		if _, ok := itemMap[id]; !ok {
+			if err = d.cache.SetPlaceholder(ctx, id); err != nil {
+				logger.Warn("cache.SetPlaceholder error", logger.Err(err), logger.Any("id", id))
+			}
+		}
+	}
+
+	return itemMap, nil
+}
+
+// GetByColumns get a paginated list of characters by custom conditions.
+// For more details, please refer to https://go-sponge.com/component/data/custom-page-query.html
+func (d *characterDao) GetByColumns(ctx context.Context, params *query.Params) ([]*model.Character, int64, error) {
+	quoted, whitelist := quoteColumns(d.db, params, model.CharacterColumnNames)
+	queryStr, args, err := quoted.ConvertToGormConditions(query.WithWhitelistNames(whitelist))
+	if err != nil {
+		return nil, 0, errors.New("query params error: " + err.Error())
+	}
+
+	var total int64
+	if params.Sort != "ignore count" { // determine if count is required
+		err = d.db.WithContext(ctx).Model(&model.Character{}).Where(queryStr, args...).Count(&total).Error
+		if err != nil {
+			return nil, 0, err
+		}
+		if total == 0 {
+			return nil, total, nil
+		}
+	}
+
+	records := []*model.Character{}
+	order, limit, offset := quoted.ConvertToPage()
+	err = d.db.WithContext(ctx).Order(order).Limit(limit).Offset(offset).Where(queryStr, args...).Find(&records).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return records, total, err
+}
+
+// GetByName get a character by name
+func (d *characterDao) GetByName(ctx context.Context, name string) (*model.Character, error) {
+	record := &model.Character{}
+	err := d.db.WithContext(ctx).Where("name = ?", name).First(record).Error
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// GetByAccountID get the characters of an account
+func (d *characterDao) GetByAccountID(ctx context.Context, accountID uint64) ([]*model.Character, error) {
+	records := []*model.Character{}
+	err := d.db.WithContext(ctx).Where("account_id = ?", accountID).Order("id").Find(&records).Error
+	return records, err
+}
+
+// CreateByTx create a record in the database using the provided transaction
+func (d *characterDao) CreateByTx(ctx context.Context, tx *gorm.DB, table *model.Character) (uint64, error) {
+	err := tx.WithContext(ctx).Create(table).Error
+	return table.ID, err
+}
+
+// DeleteByTx delete a record by id in the database using the provided transaction
+func (d *characterDao) DeleteByTx(ctx context.Context, tx *gorm.DB, id uint64) error {
+	err := tx.WithContext(ctx).Where("id = ?", id).Delete(&model.Character{}).Error
+	if err != nil {
+		return err
+	}
+
+	// delete cache
+	_ = d.deleteCache(ctx, id)
+
+	return nil
+}
+
+// UpdateByTx update a record by id in the database using the provided transaction
+func (d *characterDao) UpdateByTx(ctx context.Context, tx *gorm.DB, table *model.Character) error {
+	err := d.updateDataByID(ctx, tx, table)
+
+	// delete cache
+	_ = d.deleteCache(ctx, table.ID)
+
+	return err
+}
+
+// SaveByTx write every column of a character by id using the provided transaction, zero values included
+func (d *characterDao) SaveByTx(ctx context.Context, tx *gorm.DB, table *model.Character) error {
+	err := d.saveDataByID(ctx, tx, table)
+
+	// delete cache
+	_ = d.deleteCache(ctx, table.ID)
+
+	return err
+}
@@ -0,0 +1,304 @@
+package dao
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-dev-frame/sponge/pkg/gotest"
+	"github.com/go-dev-frame/sponge/pkg/sgorm/query"
+	"github.com/go-dev-frame/sponge/pkg/utils"
+	"github.com/stretchr/testify/assert"
+
+	"fs/internal/cache"
+	"fs/internal/database"
+	"fs/internal/model"
+)
+
+func newCharacterDao() *gotest.Dao {
+	testData := &model.Character{}
+	testData.ID = 1
+	testData.AccountID = 1
+	testData.Name = "Alice"
+	testData.RoomID = "hall"
+	testData.Hp = 90
+	testData.Str = 12
+
+	// init mock cache
+	//c := gotest.NewCache(map[string]interface{}{"no cache": testData}) // to test mysql, disable caching
+	c := gotest.NewCache(map[string]interface{}{utils.Uint64ToStr(testData.ID): testData})
+	c.ICache = cache.NewCharacterCache(&database.CacheType{
+		CType: "redis",
+		Rdb:   c.RedisClient,
+	})
+
+	// init mock dao
+	d := gotest.NewDao(c, testData)
+	d.IDao = NewCharacterDao(d.DB, c.ICache.(cache.CharacterCache))
+
+	return d
+}
+
+func Test_characterDao_Create(t *testing.T) {
+	d := newCharacterDao()
+	defer d.Close()
+	testData := d.TestData.(*model.Character)
+
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectExec("INSERT INTO .*").
+		WithArgs(d.GetAnyArgs(testData)...).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	d.SQLMock.ExpectCommit()
+
+	err := d.IDao.(CharacterDao).Create(d.Ctx, testData)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_characterDao_DeleteByID(t *testing.T) {
+	d := newCharacterDao()
+	defer d.Close()
+	testData := d.TestData.(*model.Character)
+	expectedSQLForDeletion := "DELETE .*"
+
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectExec(expectedSQLForDeletion).
+		WithArgs(testData.ID).
+		WillReturnResult(sqlmock.NewResult(int64(testData.ID), 1))
+	d.SQLMock.ExpectCommit()
+
+	err := d.IDao.(CharacterDao).DeleteByID(d.Ctx, testData.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// zero id error
+	err = d.IDao.(CharacterDao).DeleteByID(d.Ctx, 0)
+	assert.Error(t, err)
+}
+
+func Test_characterDao_UpdateByID(t *testing.T) {
+	d := newCharacterDao()
+	defer d.Close()
+	testData := d.TestData.(*model.Character)
+
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectExec("UPDATE .*").
+		WithArgs(testData.AccountID, testData.Hp, testData.Name, testData.RoomID, testData.Str, testData.ID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	d.SQLMock.ExpectCommit()
+
+	err := d.IDao.(CharacterDao).UpdateByID(d.Ctx, testData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// zero id error
+	err = d.IDao.(CharacterDao).UpdateByID(d.Ctx, &model.Character{})
+	assert.Error(t, err)
+
+}
+
+func Test_characterDao_GetByID(t *testing.T) {
+	d := newCharacterDao()
+	defer d.Close()
+	testData := d.TestData.(*model.Character)
+
+	// column names and corresponding data
+	rows := sqlmock.NewRows([]string{"id"}).
+		AddRow(testData.ID)
+
+	d.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(testData.ID, 1).
+		WillReturnRows(rows)
+
+	_, err := d.IDao.(CharacterDao).GetByID(d.Ctx, testData.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = d.SQLMock.ExpectationsWereMet()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// error test
+	d.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(2).
+		WillReturnRows(rows)
+	_, err = d.IDao.(CharacterDao).GetByID(d.Ctx, 2)
+	assert.Error(t, err)
+
+	d.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(3, 4).
+		WillReturnRows(rows)
+	_, err = d.IDao.(CharacterDao).GetByID(d.Ctx, 4)
+	assert.Error(t, err)
+}
+
+func Test_characterDao_GetByIDs(t *testing.T) {
+	d := newCharacterDao()
+	defer d.Close()
+	testData := d.TestData.(*model.Character)
+
+	rows := sqlmock.NewRows([]string{"id"}).
+		AddRow(testData.ID)
+	d.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(testData.ID, 2).
+		WillReturnRows(rows)
+
+	records, err := d.IDao.(CharacterDao).GetByIDs(d.Ctx, []uint64{testData.ID, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, records, 1)
+	assert.NotNil(t, records[testData.ID])
+
+	// 1 is cached, the placeholder of 2 is not returned by MultiGet, one query settles it
+	d.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	records, err = d.IDao.(CharacterDao).GetByIDs(d.Ctx, []uint64{testData.ID, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, records, 1)
+
+	err = d.SQLMock.ExpectationsWereMet()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_characterDao_GetByColumns(t *testing.T) {
+	d := newCharacterDao()
+	defer d.Close()
+	testData := d.TestData.(*model.Character)
+
+	// column names and corresponding data
+	rows := sqlmock.NewRows([]string{"id"}).
+		AddRow(testData.ID)
+
+	d.SQLMock.ExpectQuery("SELECT .*").WillReturnRows(rows)
+
+	_, _, err := d.IDao.(CharacterDao).GetByColumns(d.Ctx, &query.Params{
+		Page:  0,
+		Limit: 10,
+		Sort:  "ignore count", // ignore test count(*)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = d.SQLMock.ExpectationsWereMet()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// err test
+	_, _, err = d.IDao.(CharacterDao).GetByColumns(d.Ctx, &query.Params{
+		Page:  0,
+		Limit: 10,
+		Columns: []query.Column{
+			{
+				Name:  "id",
+				Exp:   "<",
+				Value: 0,
+			},
+		},
+	})
+	assert.Error(t, err)
+
+	// error test
+	dao := &characterDao{}
+	_, _, err = dao.GetByColumns(context.Background(), &query.Params{Columns: []query.Column{{}}})
+	t.Log(err)
+}
+
+func Test_characterDao_GetByName(t *testing.T) {
+	d := newCharacterDao()
+	defer d.Close()
+	testData := d.TestData.(*model.Character)
+
+	rows := sqlmock.NewRows([]string{"id", "account_id", "name"}).
+		AddRow(testData.ID, testData.AccountID, testData.Name)
+	d.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(testData.Name, 1).
+		WillReturnRows(rows)
+
+	record, err := d.IDao.(CharacterDao).GetByName(d.Ctx, testData.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, testData.ID, record.ID)
+
+	rows = sqlmock.NewRows([]string{"id", "account_id", "name"}).
+		AddRow(testData.ID, testData.AccountID, testData.Name)
+	d.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(testData.AccountID).
+		WillReturnRows(rows)
+
+	records, err := d.IDao.(CharacterDao).GetByAccountID(d.Ctx, testData.AccountID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, records, 1)
+
+	err = d.SQLMock.ExpectationsWereMet()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_characterDao_CreateByTx(t *testing.T) {
+	d := newCharacterDao()
+	defer d.Close()
+	testData := d.TestData.(*model.Character)
+
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectExec("INSERT INTO .*").
+		WithArgs(d.GetAnyArgs(testData)...).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	d.SQLMock.ExpectCommit()
+
+	_, err := d.IDao.(CharacterDao).CreateByTx(d.Ctx, d.DB, testData)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_characterDao_DeleteByTx(t *testing.T) {
+	d := newCharacterDao()
+	defer d.Close()
+	testData := d.TestData.(*model.Character)
+	expectedSQLForDeletion := "DELETE .*"
+
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectExec(expectedSQLForDeletion).
+		WithArgs(testData.ID).
+		WillReturnResult(sqlmock.NewResult(int64(testData.ID), 1))
+	d.SQLMock.ExpectCommit()
+
+	err := d.IDao.(CharacterDao).DeleteByTx(d.Ctx, d.DB, testData.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_characterDao_UpdateByTx(t *testing.T) {
+	d := newCharacterDao()
+	defer d.Close()
+	testData := d.TestData.(*model.Character)
+
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectExec("UPDATE .*").
+		WithArgs(testData.AccountID, testData.Hp, testData.Name, testData.RoomID, testData.Str, testData.ID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	d.SQLMock.ExpectCommit()
+
+	err := d.IDao.(CharacterDao).UpdateByTx(d.Ctx, d.DB, testData)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
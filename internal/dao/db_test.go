@@ -0,0 +1,374 @@
+package dao
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-dev-frame/sponge/pkg/sgorm"
+	"github.com/go-dev-frame/sponge/pkg/sgorm/mysql"
+	"github.com/go-dev-frame/sponge/pkg/sgorm/query"
+	"github.com/go-dev-frame/sponge/pkg/utils"
+
+	"fs/internal/database"
+	"fs/internal/migrate"
+	"fs/internal/model"
+)
+
+// the daos against real databases, the other tests mock the sql. The tests always run
+// against sqlite in memory, and against mysql and postgresql when FS_TEST_MYSQL_DSN and
+// FS_TEST_POSTGRES_DSN are set, the tables of the models in those databases are dropped.
+// make test-db sets them to databases it starts in docker, the ci runs it.
+
+func testDatabases(t *testing.T) map[string]func(t *testing.T) *sgorm.DB {
+	dbs := map[string]func(t *testing.T) *sgorm.DB{
+		sgorm.DBDriverSqlite: func(t *testing.T) *sgorm.DB {
+			db, err := database.OpenSqlite(":memory:")
+			require.NoError(t, err)
+			return db
+		},
+	}
+	if dsn := os.Getenv("FS_TEST_MYSQL_DSN"); dsn != "" {
+		dbs[sgorm.DBDriverMysql] = func(t *testing.T) *sgorm.DB {
+			db, err := mysql.Init(utils.AdaptiveMysqlDsn(dsn))
+			require.NoError(t, err)
+			return resetTables(t, db)
+		}
+	}
+	if dsn := os.Getenv("FS_TEST_POSTGRES_DSN"); dsn != "" {
+		dbs[sgorm.DBDriverPostgresql] = func(t *testing.T) *sgorm.DB {
+			db, err := database.OpenPostgresql(utils.AdaptivePostgresqlDsn(dsn))
+			require.NoError(t, err)
+			return resetTables(t, db)
+		}
+	}
+	return dbs
+}
+
+// resetTables drop the tables of the models and apply the migrations
+func resetTables(t *testing.T, db *sgorm.DB) *sgorm.DB {
+	require.NoError(t, db.Migrator().DropTable(append(database.Models, "schema_version")...))
+	_, err := migrate.Up(db, 0)
+	require.NoError(t, err)
+	return db
+}
+
+// forEachDB run the test against each database
+func forEachDB(t *testing.T, test func(t *testing.T, db *sgorm.DB)) {
+	for name, open := range testDatabases(t) {
+		t.Run(name, func(t *testing.T) {
+			db := open(t)
+			t.Cleanup(func() { _ = sgorm.CloseDB(db) })
+			test(t, db)
+		})
+	}
+}
+
+func Test_db_roomDao(t *testing.T) {
+	forEachDB(t, func(t *testing.T, db *sgorm.DB) {
+		ctx := context.Background()
+		d := NewRoomDao(db, nil)
+
+		for _, r := range []*model.Room{
+			{ID: "square", Title: "Town Square", Desc: "A busy square.", Mobs: "1"},
+			{ID: "inn", Title: "Inn", Desc: "A warm inn."},
+			{ID: "yard", Title: "Yard"},
+		} {
+			require.NoError(t, d.Create(ctx, r))
+		}
+
+		room, err := d.GetByID(ctx, "square")
+		require.NoError(t, err)
+		assert.Equal(t, "A busy square.", room.Desc)
+
+		require.NoError(t, d.UpdateByID(ctx, &model.Room{ID: "inn", Desc: "A cold inn.", Version: 1}))
+		room, err = d.GetByID(ctx, "inn")
+		require.NoError(t, err)
+		assert.Equal(t, &model.Room{ID: "inn", Title: "Inn", Desc: "A cold inn.", Version: 2}, room)
+
+		// an update based on an old version is refused, the room graph is kept
+		var changed []string
+		unregister := OnRoomChange(func(roomID string) {
+			changed = append(changed, roomID)
+		})
+		err = d.UpdateByID(ctx, &model.Room{ID: "inn", Desc: "A dark inn.", Version: 1})
+		assert.ErrorIs(t, err, database.ErrVersionConflict)
+		err = d.PatchByID(ctx, &model.Room{ID: "inn", Version: 1}, []string{"Desc"})
+		assert.ErrorIs(t, err, database.ErrVersionConflict)
+		unregister()
+		assert.Empty(t, changed)
+		room, err = d.GetByID(ctx, "inn")
+		require.NoError(t, err)
+		assert.Equal(t, "A cold inn.", room.Desc)
+
+		// desc is a reserved word of mysql and postgresql
+		rooms, total, err := d.GetByColumns(ctx, &query.Params{
+			Page:    0,
+			Limit:   10,
+			Sort:    "id",
+			Columns: []query.Column{{Name: "desc", Exp: query.Like, Value: "%inn%"}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		require.Len(t, rooms, 1)
+		assert.Equal(t, "inn", rooms[0].ID)
+
+		rooms, total, err = d.GetByColumns(ctx, &query.Params{
+			Page:  0,
+			Limit: 10,
+			Sort:  "-desc,id",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), total)
+		require.Len(t, rooms, 3)
+		assert.Equal(t, "inn", rooms[0].ID)
+		assert.Equal(t, "square", rooms[1].ID)
+
+		byIDs, err := d.GetByIDs(ctx, []string{"square", "yard", "nowhere"})
+		require.NoError(t, err)
+		assert.Len(t, byIDs, 2)
+
+		// desc can be cleared with a patch
+		require.NoError(t, d.PatchByID(ctx, &model.Room{ID: "inn", Version: 2}, []string{"Desc"}))
+		room, err = d.GetByID(ctx, "inn")
+		require.NoError(t, err)
+		assert.Equal(t, &model.Room{ID: "inn", Title: "Inn", Version: 3}, room)
+
+		// save writes the zero values and creates missing rooms
+		require.NoError(t, d.Save(ctx, &model.Room{ID: "inn", Title: "Inn"}))
+		room, err = d.GetByID(ctx, "inn")
+		require.NoError(t, err)
+		assert.Equal(t, &model.Room{ID: "inn", Title: "Inn", Version: 4}, room)
+		require.NoError(t, d.Save(ctx, &model.Room{ID: "cellar", Title: "Cellar"}))
+		room, err = d.GetByID(ctx, "cellar")
+		require.NoError(t, err)
+		assert.Equal(t, "Cellar", room.Title)
+
+		// deleted rooms are in the trash until they are restored or purged
+		require.NoError(t, d.DeleteByID(ctx, "yard"))
+		_, err = d.GetByID(ctx, "yard")
+		assert.ErrorIs(t, err, database.ErrRecordNotFound)
+		_, total, err = d.GetByColumns(ctx, &query.Params{Page: 0, Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), total)
+		trash, total, err := d.GetDeleted(ctx, 0, 10)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		require.Len(t, trash, 1)
+		assert.Equal(t, "yard", trash[0].ID)
+		assert.True(t, trash[0].DeletedAt.Valid)
+
+		require.NoError(t, d.RestoreByID(ctx, "yard"))
+		room, err = d.GetByID(ctx, "yard")
+		require.NoError(t, err)
+		assert.Equal(t, "Yard", room.Title)
+		assert.Equal(t, 2, room.Version)
+		assert.ErrorIs(t, d.RestoreByID(ctx, "yard"), database.ErrRecordNotFound)
+
+		// the exits of a room in the trash are purged with it, a room created with the same id has none
+		exits := NewRoomExitDao(db, nil)
+		require.NoError(t, exits.Create(ctx, &model.RoomExit{RoomID: "yard", Direction: "west", ToRoomID: "square"}))
+		require.NoError(t, exits.Create(ctx, &model.RoomExit{RoomID: "square", Direction: "east", ToRoomID: "inn"}))
+		require.NoError(t, d.DeleteByID(ctx, "yard"))
+		n, err := d.PurgeDeleted(ctx, time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+		assert.Zero(t, n)
+		n, err = d.PurgeDeleted(ctx, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), n)
+		assert.ErrorIs(t, d.RestoreByID(ctx, "yard"), database.ErrRecordNotFound)
+		var left []*model.RoomExit
+		require.NoError(t, db.Find(&left).Error)
+		require.Len(t, left, 1)
+		assert.Equal(t, "square", left[0].RoomID)
+	})
+}
+
+func Test_db_mobDao(t *testing.T) {
+	forEachDB(t, func(t *testing.T, db *sgorm.DB) {
+		ctx := context.Background()
+		d := NewMobDao(db, nil)
+
+		yes := sgorm.TinyBool(true)
+		guard := &model.Mob{MobID: "guard", MobName: "guard", MobCname: "守衛", Attackable: &yes, Hp: 80}
+		rat := &model.Mob{MobID: "rat", MobName: "rat", MobCname: "老鼠"}
+		require.NoError(t, d.Create(ctx, guard))
+		require.NoError(t, d.Create(ctx, rat))
+		assert.Equal(t, uint64(1), guard.ID)
+		assert.Equal(t, uint64(2), rat.ID)
+
+		// zero values take the defaults of the table
+		mob, err := d.GetByID(ctx, rat.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 100, mob.Hp)
+		assert.Equal(t, 1, mob.Attack)
+		assert.Nil(t, mob.Attackable)
+
+		require.NoError(t, d.UpdateByID(ctx, &model.Mob{ID: guard.ID, Attack: 9, Version: 1}))
+		mob, err = d.GetByID(ctx, guard.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 9, mob.Attack)
+		assert.Equal(t, 80, mob.Hp)
+		require.NotNil(t, mob.Attackable)
+		assert.True(t, bool(*mob.Attackable))
+
+		mobs, total, err := d.GetByColumns(ctx, &query.Params{
+			Page:    0,
+			Limit:   10,
+			Columns: []query.Column{{Name: "hp", Exp: query.Gt, Value: 90}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		require.Len(t, mobs, 1)
+		assert.Equal(t, "rat", mobs[0].MobID)
+
+		// the tinyint(1) of mysql is a smallint in postgresql, both hold 0 or 1
+		no := sgorm.TinyBool(false)
+		require.NoError(t, d.UpdateByID(ctx, &model.Mob{ID: rat.ID, Attackable: &no, Version: 1}))
+		mob, err = d.GetByID(ctx, rat.ID)
+		require.NoError(t, err)
+		require.NotNil(t, mob.Attackable)
+		assert.False(t, bool(*mob.Attackable))
+
+		mobs, total, err = d.GetByColumns(ctx, &query.Params{
+			Page:    0,
+			Limit:   10,
+			Columns: []query.Column{{Name: "attackable", Value: 1}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		require.Len(t, mobs, 1)
+		assert.Equal(t, "guard", mobs[0].MobID)
+
+		all, err := GetAllMobs(ctx, d)
+		require.NoError(t, err)
+		assert.Len(t, all, 2)
+
+		// the mob id is unique, the error is translated for the rules
+		err = d.Create(ctx, &model.Mob{MobID: "rat", MobName: "rat", MobCname: "大老鼠"})
+		assert.ErrorIs(t, err, database.ErrDuplicatedKey)
+
+		tx := d.(*mobDao).db.Begin()
+		_, err = d.CreateByTx(ctx, tx, &model.Mob{MobID: "ghost", MobName: "ghost", MobCname: "鬼"})
+		require.NoError(t, err)
+		require.NoError(t, tx.Rollback().Error)
+		all, err = GetAllMobs(ctx, d)
+		require.NoError(t, err)
+		assert.Len(t, all, 2)
+	})
+}
+
+func Test_db_itemDao(t *testing.T) {
+	forEachDB(t, func(t *testing.T, db *sgorm.DB) {
+		ctx := context.Background()
+		d := NewItemDao(db, nil)
+
+		sword := &model.Item{ItemID: "sword", ItemName: "sword", Attack: 5, Str: 1, Classifier: "w"}
+		ring := &model.Item{ItemID: "ring", ItemName: "ring", Inte: 2, Classifier: "r"}
+		require.NoError(t, d.Create(ctx, sword))
+		require.NoError(t, d.Create(ctx, ring))
+
+		require.NoError(t, d.UpdateByID(ctx, &model.Item{ID: sword.ID, Dex: 3, Version: 1}))
+		item, err := d.GetByID(ctx, sword.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 5, item.Attack)
+		assert.Equal(t, 3, item.Dex)
+
+		// a patch writes the zero values of its fields and leaves the other fields alone
+		patch := &model.Item{ID: sword.ID, Version: 2, Dex: 4}
+		require.NoError(t, d.PatchByID(ctx, patch, []string{"Attack", "Str", "Dex"}))
+		assert.Equal(t, 3, patch.Version)
+		item, err = d.GetByID(ctx, sword.ID)
+		require.NoError(t, err)
+		assert.Equal(t, &model.Item{ID: sword.ID, ItemID: "sword", ItemName: "sword", Dex: 4, Classifier: "w", Version: 3}, item)
+		err = d.PatchByID(ctx, &model.Item{ID: sword.ID, Version: 2}, []string{"Dex"})
+		assert.ErrorIs(t, err, database.ErrVersionConflict)
+
+		items, total, err := d.GetByColumns(ctx, &query.Params{
+			Page:  0,
+			Limit: 10,
+			Sort:  "item_id",
+			Columns: []query.Column{
+				{Name: "classifier", Value: "w", Logic: "||"},
+				{Name: "inte", Exp: query.Gte, Value: 2},
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+		require.Len(t, items, 2)
+		assert.Equal(t, "ring", items[0].ItemID)
+
+		byIDs, err := d.GetByIDs(ctx, []uint64{sword.ID, ring.ID})
+		require.NoError(t, err)
+		assert.Len(t, byIDs, 2)
+
+		require.NoError(t, d.DeleteByID(ctx, ring.ID))
+		_, err = d.GetByID(ctx, ring.ID)
+		assert.ErrorIs(t, err, database.ErrRecordNotFound)
+
+		// an item in the trash keeps its item id
+		err = d.Create(ctx, &model.Item{ItemID: "ring", ItemName: "ring", Classifier: "r"})
+		assert.ErrorIs(t, err, database.ErrDuplicatedKey)
+	})
+}
+
+func Test_db_itemInstanceDao(t *testing.T) {
+	forEachDB(t, func(t *testing.T, db *sgorm.DB) {
+		ctx := context.Background()
+		d := NewItemInstanceDao(db, nil)
+
+		// a new instance without durability is intact
+		sword := &model.ItemInstance{ProtoID: 1, LocationType: model.ItemLocationPlayer, OwnerID: "bob"}
+		require.NoError(t, d.Create(ctx, sword))
+		inst, err := d.GetByID(ctx, sword.ID)
+		require.NoError(t, err)
+		require.NotNil(t, inst.Durability)
+		assert.Equal(t, 100, *inst.Durability)
+
+		// a broken item is saved with durability 0 and stays broken after a partial update
+		inst.Durability = new(int)
+		require.NoError(t, d.SaveByID(ctx, inst))
+		require.NoError(t, d.UpdateByID(ctx, &model.ItemInstance{ID: sword.ID, OwnerID: "alice"}))
+		inst, err = d.GetByID(ctx, sword.ID)
+		require.NoError(t, err)
+		require.NotNil(t, inst.Durability)
+		assert.Zero(t, *inst.Durability)
+		assert.Equal(t, "alice", inst.OwnerID)
+
+		// save writes every column, the overrides are cleared
+		attack := 12
+		inst.Overrides = &model.ItemStatOverrides{Attack: &attack}
+		require.NoError(t, d.SaveByID(ctx, inst))
+		inst.Overrides = nil
+		require.NoError(t, d.SaveByID(ctx, inst))
+		inst, err = d.GetByID(ctx, sword.ID)
+		require.NoError(t, err)
+		assert.Nil(t, inst.Overrides)
+	})
+}
+
+func Test_db_characterDao(t *testing.T) {
+	forEachDB(t, func(t *testing.T, db *sgorm.DB) {
+		ctx := context.Background()
+		d := NewCharacterDao(db, nil)
+
+		bob := &model.Character{AccountID: 1, Name: "bob", RoomID: "square", Hp: 100, Mp: 50, Str: 12}
+		require.NoError(t, d.Create(ctx, bob))
+
+		// the stats that fell to 0 are saved, a partial update would skip them
+		bob.Hp, bob.Mp, bob.Str, bob.RoomID = 0, 0, 0, ""
+		require.NoError(t, d.SaveByID(ctx, bob))
+		ch, err := d.GetByID(ctx, bob.ID)
+		require.NoError(t, err)
+		assert.Equal(t, bob, ch)
+
+		ch, err = d.GetByName(ctx, "bob")
+		require.NoError(t, err)
+		assert.Zero(t, ch.Hp)
+		assert.Zero(t, ch.Mp)
+	})
+}
@@ -0,0 +1,123 @@
+package dao
+
+import (
+	"context"
+	"sync"
+)
+
+// hooks the functions called after rows of a table are written through the dao
+type hooks[T any] struct {
+	mu     sync.RWMutex
+	lastID int
+	fns    map[int]func(T)
+}
+
+func (h *hooks[T]) add(fn func(T)) (unregister func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.fns == nil {
+		h.fns = map[int]func(T){}
+	}
+	h.lastID++
+	id := h.lastID
+	h.fns[id] = fn
+	return func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(h.fns, id)
+	}
+}
+
+func (h *hooks[T]) notify(v T) {
+	h.mu.RLock()
+	fns := make([]func(T), 0, len(h.fns))
+	for _, fn := range h.fns {
+		fns = append(fns, fn)
+	}
+	h.mu.RUnlock()
+
+	for _, fn := range fns {
+		fn(v)
+	}
+}
+
+var (
+	roomChangeHooks  hooks[string]
+	spawnChangeHooks hooks[uint64]
+)
+
+// OnRoomChange register a function called after a room or a room exit is created, updated
+// or deleted through the dao, roomID is the room that was written or the room of the exit.
+// It is used to drop data built from the whole world, such as the room graph. The returned
+// function removes the hook, it is called when the owner of the hook is done with it.
+func OnRoomChange(fn func(roomID string)) (unregister func()) {
+	return roomChangeHooks.add(fn)
+}
+
+// OnSpawnChange register a function called after a spawn is created, updated or deleted
+// through the dao, the returned function removes it as with OnRoomChange
+func OnSpawnChange(fn func(id uint64)) (unregister func()) {
+	return spawnChangeHooks.add(fn)
+}
+
+func notifyRoomChange(roomID string) {
+	roomChangeHooks.notify(roomID)
+}
+
+func notifySpawnChange(id uint64) {
+	spawnChangeHooks.notify(id)
+}
+
+type changesKey struct{}
+
+// changes the hooks of the rows written in a transaction, called once it is committed
+type changes struct {
+	mu      sync.Mutex
+	pending []func()
+}
+
+// CollectChanges return a context for the *ByTx methods of the daos, the hooks of the changes
+// written with it are held back until notify is called. The caller calls notify once the
+// transaction is committed and drops the changes if it is rolled back, so a hook never rebuilds
+// its data from rows that are not committed yet.
+//
+//	ctx, notify := dao.CollectChanges(ctx)
+//	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error { ... })
+//	if err == nil {
+//		notify()
+//	}
+func CollectChanges(ctx context.Context) (txCtx context.Context, notify func()) {
+	c := &changes{}
+	return context.WithValue(ctx, changesKey{}, c), func() {
+		c.mu.Lock()
+		pending := c.pending
+		c.pending = nil
+		c.mu.Unlock()
+		for _, fn := range pending {
+			fn()
+		}
+	}
+}
+
+// afterCommit call fn once the transaction of ctx is committed if ctx collects the changes,
+// else at once as the methods without a transaction do
+func afterCommit(ctx context.Context, fn func()) {
+	c, ok := ctx.Value(changesKey{}).(*changes)
+	if !ok {
+		fn()
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pending = append(c.pending, fn)
+}
+
+// notifyRoomChangeByTx notify a room change written in a transaction, see afterCommit
+func notifyRoomChangeByTx(ctx context.Context, roomID string) {
+	afterCommit(ctx, func() { notifyRoomChange(roomID) })
+}
+
+// notifySpawnChangeByTx notify a spawn change written in a transaction, see afterCommit
+func notifySpawnChangeByTx(ctx context.Context, id uint64) {
+	afterCommit(ctx, func() { notifySpawnChange(id) })
+}
@@ -3,6 +3,7 @@ package dao
 import (
 	"context"
 	"errors"
+	"time"
 
 	"golang.org/x/sync/singleflight"
 	"gorm.io/gorm"
@@ -23,12 +24,21 @@ type ItemDao interface {
 	Create(ctx context.Context, table *model.Item) error
 	DeleteByID(ctx context.Context, id uint64) error
 	UpdateByID(ctx context.Context, table *model.Item) error
+	PatchByID(ctx context.Context, table *model.Item, fields []string) error
+	Save(ctx context.Context, table *model.Item) error
 	GetByID(ctx context.Context, id uint64) (*model.Item, error)
+	GetByIDs(ctx context.Context, ids []uint64) (map[uint64]*model.Item, error)
 	GetByColumns(ctx context.Context, params *query.Params) ([]*model.Item, int64, error)
+	ExistsItemID(ctx context.Context, itemID string, exceptID uint64) (bool, error)
+
+	RestoreByID(ctx context.Context, id uint64) error
+	GetDeleted(ctx context.Context, page int, limit int) ([]*model.Item, int64, error)
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
 
 	CreateByTx(ctx context.Context, tx *gorm.DB, table *model.Item) (uint64, error)
 	DeleteByTx(ctx context.Context, tx *gorm.DB, id uint64) error
 	UpdateByTx(ctx context.Context, tx *gorm.DB, table *model.Item) error
+	PatchByTx(ctx context.Context, tx *gorm.DB, table *model.Item, fields []string) error
 }
 
 type itemDao struct {
@@ -61,7 +71,7 @@ func (d *itemDao) Create(ctx context.Context, table *model.Item) error {
 	return d.db.WithContext(ctx).Create(table).Error
 }
 
-// DeleteByID delete a item by id
+// DeleteByID move a item to the trash by id, it can be brought back with RestoreByID
 func (d *itemDao) DeleteByID(ctx context.Context, id uint64) error {
 	err := d.db.WithContext(ctx).Where("id = ?", id).Delete(&model.Item{}).Error
 	if err != nil {
@@ -75,6 +85,8 @@ func (d *itemDao) DeleteByID(ctx context.Context, id uint64) error {
 }
 
 // UpdateByID update a item by id, support partial update
+// table.Version must be the version that was read, database.ErrVersionConflict is returned if the item has
+// been changed since then, on success table.Version is the new version
 func (d *itemDao) UpdateByID(ctx context.Context, table *model.Item) error {
 	err := d.updateDataByID(ctx, d.db, table)
 
@@ -84,10 +96,55 @@ func (d *itemDao) UpdateByID(ctx context.Context, table *model.Item) error {
 	return err
 }
 
+// PatchByID update the fields of a item by id, zero values included, fields are the names of the fields
+// of model.Item, with the same version check as UpdateByID
+func (d *itemDao) PatchByID(ctx context.Context, table *model.Item, fields []string) error {
+	err := d.patchDataByID(ctx, d.db, table, fields)
+
+	// delete cache
+	_ = d.deleteCache(ctx, table.ID)
+
+	return err
+}
+
+func (d *itemDao) patchDataByID(ctx context.Context, db *gorm.DB, table *model.Item, fields []string) error {
+	if table.ID < 1 {
+		return errors.New("id cannot be 0")
+	}
+	if table.Version < 1 {
+		return errors.New("version cannot be 0")
+	}
+	return patchByVersion(db.WithContext(ctx), table, &table.Version, fields)
+}
+
+// Save write all the fields of a item, including zero values, the item is created if it does not exist
+func (d *itemDao) Save(ctx context.Context, table *model.Item) error {
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// the version goes on from the stored one, so an update read before the save gets a conflict
+		version, err := nextVersion(tx, &model.Item{}, table.ID)
+		if err != nil {
+			return err
+		}
+		table.Version = version
+		return tx.Unscoped().Save(table).Error // a item in the trash is overwritten and restored
+	})
+	if err != nil {
+		return err
+	}
+
+	// delete cache
+	_ = d.deleteCache(ctx, table.ID)
+
+	return nil
+}
+
 func (d *itemDao) updateDataByID(ctx context.Context, db *gorm.DB, table *model.Item) error {
 	if table.ID < 1 {
 		return errors.New("id cannot be 0")
 	}
+	if table.Version < 1 {
+		return errors.New("version cannot be 0")
+	}
 
 	update := map[string]interface{}{}
 
@@ -140,7 +197,19 @@ func (d *itemDao) updateDataByID(ctx context.Context, db *gorm.DB, table *model.
 		update["classifier"] = table.Classifier
 	}
 
-	return db.WithContext(ctx).Model(table).Updates(update).Error
+	version := table.Version
+	update["version"] = gorm.Expr("version + 1")
+
+	result := db.WithContext(ctx).Model(table).Where("version = ?", version).Updates(update)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return database.ErrVersionConflict // changed or deleted since the version was read
+	}
+	table.Version = version + 1
+
+	return nil
 }
 
 // GetByID get a item by id
@@ -197,10 +266,71 @@ func (d *itemDao) GetByID(ctx context.Context, id uint64) (*model.Item, error) {
 	return nil, err
 }
 
+// GetByIDs get items by ids, ids that do not exist are not in the returned map
+func (d *itemDao) GetByIDs(ctx context.Context, ids []uint64) (map[uint64]*model.Item, error) {
+	// no cache
+	if d.cache == nil {
+		var records []*model.Item
+		err := d.db.WithContext(ctx).Where("id IN (?)", ids).Find(&records).Error
+		if err != nil {
+			return nil, err
+		}
+		itemMap := make(map[uint64]*model.Item)
+		for _, record := range records {
+			itemMap[record.ID] = record
+		}
+		return itemMap, nil
+	}
+
+	// get from cache
+	itemMap, err := d.cache.MultiGet(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	var missedIDs []uint64
+	for _, id := range ids {
+		if _, ok := itemMap[id]; !ok {
+			missedIDs = append(missedIDs, id)
+		}
+	}
+	if len(missedIDs) == 0 {
+		return itemMap, nil
+	}
+
+	// get the missed ids from database with one query, MultiGet leaves out the placeholders
+	// like the ids that are not cached, so the ids known not to exist are in the query too
+	var records []*model.Item
+	err = d.db.WithContext(ctx).Where("id IN (?)", missedIDs).Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(records) > 0 {
+		for _, record := range records {
+			itemMap[record.ID] = record
+		}
+		if err = d.cache.MultiSet(ctx, records, cache.ItemExpireTime); err != nil {
+			logger.Warn("cache.MultiSet error", logger.Err(err), logger.Any("ids", missedIDs))
+		}
+	}
+
+	// set placeholder cache for the ids that do not exist to prevent cache penetration in GetByID
+	for _, id := range missedIDs {
+		if _, ok := itemMap[id]; !ok {
+			if err = d.cache.SetPlaceholder(ctx, id); err != nil {
+				logger.Warn("cache.SetPlaceholder error", logger.Err(err), logger.Any("id", id))
+			}
+		}
+	}
+
+	return itemMap, nil
+}
+
 // GetByColumns get a paginated list of items by custom conditions.
 // For more details, please refer to https://go-sponge.com/component/data/custom-page-query.html
 func (d *itemDao) GetByColumns(ctx context.Context, params *query.Params) ([]*model.Item, int64, error) {
-	queryStr, args, err := params.ConvertToGormConditions(query.WithWhitelistNames(model.ItemColumnNames))
+	quoted, whitelist := quoteColumns(d.db, params, model.ItemColumnNames)
+	queryStr, args, err := quoted.ConvertToGormConditions(query.WithWhitelistNames(whitelist))
 	if err != nil {
 		return nil, 0, errors.New("query params error: " + err.Error())
 	}
@@ -217,7 +347,7 @@ func (d *itemDao) GetByColumns(ctx context.Context, params *query.Params) ([]*mo
 	}
 
 	records := []*model.Item{}
-	order, limit, offset := params.ConvertToPage()
+	order, limit, offset := quoted.ConvertToPage()
 	err = d.db.WithContext(ctx).Order(order).Limit(limit).Offset(offset).Where(queryStr, args...).Find(&records).Error
 	if err != nil {
 		return nil, 0, err
@@ -226,6 +356,58 @@ func (d *itemDao) GetByColumns(ctx context.Context, params *query.Params) ([]*mo
 	return records, total, err
 }
 
+// ExistsItemID check if a item other than exceptID has the item_id, the items in the trash included as they can be restored
+func (d *itemDao) ExistsItemID(ctx context.Context, itemID string, exceptID uint64) (bool, error) {
+	var total int64
+	err := d.db.WithContext(ctx).Unscoped().Model(&model.Item{}).
+		Where("item_id = ? AND id <> ?", itemID, exceptID).
+		Count(&total).Error
+	return total > 0, err
+}
+
+// RestoreByID take a item out of the trash by id, database.ErrRecordNotFound if it is not in the trash
+func (d *itemDao) RestoreByID(ctx context.Context, id uint64) error {
+	result := d.db.WithContext(ctx).Unscoped().Model(&model.Item{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Updates(map[string]interface{}{"deleted_at": nil, "version": gorm.Expr("version + 1")})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return database.ErrRecordNotFound
+	}
+
+	// delete cache
+	_ = d.deleteCache(ctx, id)
+
+	return nil
+}
+
+// GetDeleted get a page of the items in the trash, the latest deleted first, page starts from 0
+func (d *itemDao) GetDeleted(ctx context.Context, page int, limit int) ([]*model.Item, int64, error) {
+	db := d.db.WithContext(ctx).Unscoped().Model(&model.Item{}).Where("deleted_at IS NOT NULL")
+
+	var total int64
+	err := db.Count(&total).Error
+	if err != nil || total == 0 {
+		return nil, total, err
+	}
+
+	records := []*model.Item{}
+	err = db.Order("deleted_at DESC").Limit(limit).Offset(page * limit).Find(&records).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return records, total, nil
+}
+
+// PurgeDeleted remove for good the items that were moved to the trash before the given time
+func (d *itemDao) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	result := d.db.WithContext(ctx).Unscoped().Where("deleted_at < ?", before).Delete(&model.Item{})
+	return result.RowsAffected, result.Error
+}
+
 // CreateByTx create a record in the database using the provided transaction
 func (d *itemDao) CreateByTx(ctx context.Context, tx *gorm.DB, table *model.Item) (uint64, error) {
 	err := tx.WithContext(ctx).Create(table).Error
@@ -246,6 +428,7 @@ func (d *itemDao) DeleteByTx(ctx context.Context, tx *gorm.DB, id uint64) error
 }
 
 // UpdateByTx update a record by id in the database using the provided transaction
+// with the same version check as UpdateByID
 func (d *itemDao) UpdateByTx(ctx context.Context, tx *gorm.DB, table *model.Item) error {
 	err := d.updateDataByID(ctx, tx, table)
 
@@ -254,3 +437,19 @@ func (d *itemDao) UpdateByTx(ctx context.Context, tx *gorm.DB, table *model.Item
 
 	return err
 }
+
+// PatchByTx update the fields of a item by id using the provided transaction, zero values included,
+// with the same version check as UpdateByID
+func (d *itemDao) PatchByTx(ctx context.Context, tx *gorm.DB, table *model.Item, fields []string) error {
+	err := d.patchDataByID(ctx, tx, table, fields)
+
+	// delete cache
+	_ = d.deleteCache(ctx, table.ID)
+
+	return err
+}
+
+// GetAllItems read every item page by page through GetByColumns, for the whole world checks and tools
+func GetAllItems(ctx context.Context, d ItemDao) ([]*model.Item, error) {
+	return getAllPages(ctx, d.GetByColumns)
+}
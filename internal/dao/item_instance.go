@@ -0,0 +1,313 @@
+package dao
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"golang.org/x/sync/singleflight"
+	"gorm.io/gorm"
+
+	"github.com/go-dev-frame/sponge/pkg/logger"
+	"github.com/go-dev-frame/sponge/pkg/sgorm/query"
+	"github.com/go-dev-frame/sponge/pkg/utils"
+
+	"fs/internal/cache"
+	"fs/internal/database"
+	"fs/internal/model"
+)
+
+var _ ItemInstanceDao = (*itemInstanceDao)(nil)
+
+// ItemInstanceDao defining the dao interface
+type ItemInstanceDao interface {
+	Create(ctx context.Context, table *model.ItemInstance) error
+	DeleteByID(ctx context.Context, id uint64) error
+	UpdateByID(ctx context.Context, table *model.ItemInstance) error
+	SaveByID(ctx context.Context, table *model.ItemInstance) error
+	GetByID(ctx context.Context, id uint64) (*model.ItemInstance, error)
+	GetByIDs(ctx context.Context, ids []uint64) (map[uint64]*model.ItemInstance, error)
+	GetByColumns(ctx context.Context, params *query.Params) ([]*model.ItemInstance, int64, error)
+	GetByOwner(ctx context.Context, locationType string, ownerID string) ([]*model.ItemInstance, error)
+
+	CreateByTx(ctx context.Context, tx *gorm.DB, table *model.ItemInstance) (uint64, error)
+	DeleteByTx(ctx context.Context, tx *gorm.DB, id uint64) error
+	UpdateByTx(ctx context.Context, tx *gorm.DB, table *model.ItemInstance) error
+}
+
+type itemInstanceDao struct {
+	db    *gorm.DB
+	cache cache.ItemInstanceCache // if nil, the cache is not used.
+	sfg   *singleflight.Group     // if cache is nil, the sfg is not used.
+}
+
+// NewItemInstanceDao creating the dao interface
+func NewItemInstanceDao(db *gorm.DB, xCache cache.ItemInstanceCache) ItemInstanceDao {
+	if xCache == nil {
+		return &itemInstanceDao{db: db}
+	}
+	return &itemInstanceDao{
+		db:    db,
+		cache: xCache,
+		sfg:   new(singleflight.Group),
+	}
+}
+
+func (d *itemInstanceDao) deleteCache(ctx context.Context, id uint64) error {
+	if d.cache != nil {
+		return d.cache.Del(ctx, id)
+	}
+	return nil
+}
+
+// Create a new item, insert the record and the id value is written back to the table
+func (d *itemInstanceDao) Create(ctx context.Context, table *model.ItemInstance) error {
+	return d.db.WithContext(ctx).Create(table).Error
+}
+
+// DeleteByID delete an item instance by id
+func (d *itemInstanceDao) DeleteByID(ctx context.Context, id uint64) error {
+	err := d.db.WithContext(ctx).Where("id = ?", id).Delete(&model.ItemInstance{}).Error
+	if err != nil {
+		return err
+	}
+
+	// delete cache
+	_ = d.deleteCache(ctx, id)
+
+	return nil
+}
+
+// UpdateByID update an item instance by id, support partial update
+func (d *itemInstanceDao) UpdateByID(ctx context.Context, table *model.ItemInstance) error {
+	err := d.updateDataByID(ctx, d.db, table)
+
+	// delete cache
+	_ = d.deleteCache(ctx, table.ID)
+
+	return err
+}
+
+// SaveByID write every column of an item instance by id, zero values included
+func (d *itemInstanceDao) SaveByID(ctx context.Context, table *model.ItemInstance) error {
+	if table.ID < 1 {
+		return errors.New("id cannot be 0")
+	}
+	err := d.db.WithContext(ctx).Model(table).Select("*").Omit("id").Updates(table).Error
+
+	// delete cache
+	_ = d.deleteCache(ctx, table.ID)
+
+	return err
+}
+
+func (d *itemInstanceDao) updateDataByID(ctx context.Context, db *gorm.DB, table *model.ItemInstance) error {
+	if table.ID < 1 {
+		return errors.New("id cannot be 0")
+	}
+
+	update := map[string]interface{}{}
+
+	if table.ProtoID != 0 {
+		update["proto_id"] = table.ProtoID
+	}
+	if table.LocationType != "" {
+		update["location_type"] = table.LocationType
+	}
+	if table.OwnerID != "" {
+		update["owner_id"] = table.OwnerID
+	}
+	if table.Durability != nil {
+		update["durability"] = table.Durability
+	}
+	if table.Overrides != nil {
+		// map updates skip the json serializer of the field
+		data, err := json.Marshal(table.Overrides)
+		if err != nil {
+			return err
+		}
+		update["overrides"] = string(data)
+	}
+
+	return db.WithContext(ctx).Model(table).Updates(update).Error
+}
+
+// GetByID get an item instance by id
+func (d *itemInstanceDao) GetByID(ctx context.Context, id uint64) (*model.ItemInstance, error) {
+	// no cache
+	if d.cache == nil {
+		record := &model.ItemInstance{}
+		err := d.db.WithContext(ctx).Where("id = ?", id).First(record).Error
+		return record, err
+	}
+
+	// get from cache
+	record, err := d.cache.Get(ctx, id)
+	if err == nil {
+		return record, nil
+	}
+
+	// get from database
+	if errors.Is(err, database.ErrCacheNotFound) {
+		// for the same id, prevent high concurrent simultaneous access to database
+		val, err, _ := d.sfg.Do(utils.Uint64ToStr(id), func() (interface{}, error) { //nolint
+			table := &model.ItemInstance{}
+			err = d.db.WithContext(ctx).Where("id = ?", id).First(table).Error
+			if err != nil {
+				if errors.Is(err, database.ErrRecordNotFound) {
+					// set placeholder cache to prevent cache penetration, default expiration time 10 minutes
+					if err = d.cache.SetPlaceholder(ctx, id); err != nil {
+						logger.Warn("cache.SetPlaceholder error", logger.Err(err), logger.Any("id", id))
+					}
+					return nil, database.ErrRecordNotFound
+				}
+				return nil, err
+			}
+			// set cache
+			if err = d.cache.Set(ctx, id, table, cache.ItemInstanceExpireTime); err != nil {
+				logger.Warn("cache.Set error", logger.Err(err), logger.Any("id", id))
+			}
+			return table, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		table, ok := val.(*model.ItemInstance)
+		if !ok {
+			return nil, database.ErrRecordNotFound
+		}
+		return table, nil
+	}
+
+	if d.cache.IsPlaceholderErr(err) {
+		return nil, database.ErrRecordNotFound
+	}
+
+	return nil, err
+}
+
+// GetByIDs get item instances by ids, ids that do not exist are not in the returned map
+func (d *itemInstanceDao) GetByIDs(ctx context.Context, ids []uint64) (map[uint64]*model.ItemInstance, error) {
+	// no cache
+	if d.cache == nil {
+		var records []*model.ItemInstance
+		err := d.db.WithContext(ctx).Where("id IN (?)", ids).Find(&records).Error
+		if err != nil {
+			return nil, err
+		}
+		itemMap := make(map[uint64]*model.ItemInstance)
+		for _, record := range records {
+			itemMap[record.ID] = record
+		}
+		return itemMap, nil
+	}
+
+	// get from cache
+	itemMap, err := d.cache.MultiGet(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	var missedIDs []uint64
+	for _, id := range ids {
+		if _, ok := itemMap[id]; !ok {
+			missedIDs = append(missedIDs, id)
+		}
+	}
+	if len(missedIDs) == 0 {
+		return itemMap, nil
+	}
+
+	// get the missed ids from database with one query, MultiGet leaves out the placeholders
+	// like the ids that are not cached, so the ids known not to exist are in the query too
+	var records []*model.ItemInstance
+	err = d.db.WithContext(ctx).Where("id IN (?)", missedIDs).Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(records) > 0 {
+		for _, record := range records {
+			itemMap[record.ID] = record
+		}
+		if err = d.cache.MultiSet(ctx, records, cache.ItemInstanceExpireTime); err != nil {
+			logger.Warn("cache.MultiSet error", logger.Err(err), logger.Any("ids", missedIDs))
+		}
+	}
+
+	// set placeholder cache for the ids that do not exist to prevent cache penetration in GetByID
+	for _, id := range missedIDs {
+		if _, ok := itemMap[id]; !ok {
+			if err = d.cache.SetPlaceholder(ctx, id); err != nil {
+				logger.Warn("cache.SetPlaceholder error", logger.Err(err), logger.Any("id", id))
+			}
+		}
+	}
+
+	return itemMap, nil
+}
+
+// GetByColumns get a paginated list of item instances by custom conditions.
+// For more details, please refer to https://go-sponge.com/component/data/custom-page-query.html
+func (d *itemInstanceDao) GetByColumns(ctx context.Context, params *query.Params) ([]*model.ItemInstance, int64, error) {
+	quoted, whitelist := quoteColumns(d.db, params, model.ItemInstanceColumnNames)
+	queryStr, args, err := quoted.ConvertToGormConditions(query.WithWhitelistNames(whitelist))
+	if err != nil {
+		return nil, 0, errors.New("query params error: " + err.Error())
+	}
+
+	var total int64
+	if params.Sort != "ignore count" { // determine if count is required
+		err = d.db.WithContext(ctx).Model(&model.ItemInstance{}).Where(queryStr, args...).Count(&total).Error
+		if err != nil {
+			return nil, 0, err
+		}
+		if total == 0 {
+			return nil, total, nil
+		}
+	}
+
+	records := []*model.ItemInstance{}
+	order, limit, offset := quoted.ConvertToPage()
+	err = d.db.WithContext(ctx).Order(order).Limit(limit).Offset(offset).Where(queryStr, args...).Find(&records).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return records, total, err
+}
+
+// GetByOwner get the item instances at a location, e.g. the items lying in a room or carried by a player
+func (d *itemInstanceDao) GetByOwner(ctx context.Context, locationType string, ownerID string) ([]*model.ItemInstance, error) {
+	records := []*model.ItemInstance{}
+	err := d.db.WithContext(ctx).Where("location_type = ? AND owner_id = ?", locationType, ownerID).Order("id").Find(&records).Error
+	return records, err
+}
+
+// CreateByTx create a record in the database using the provided transaction
+func (d *itemInstanceDao) CreateByTx(ctx context.Context, tx *gorm.DB, table *model.ItemInstance) (uint64, error) {
+	err := tx.WithContext(ctx).Create(table).Error
+	return table.ID, err
+}
+
+// DeleteByTx delete a record by id in the database using the provided transaction
+func (d *itemInstanceDao) DeleteByTx(ctx context.Context, tx *gorm.DB, id uint64) error {
+	err := tx.WithContext(ctx).Where("id = ?", id).Delete(&model.ItemInstance{}).Error
+	if err != nil {
+		return err
+	}
+
+	// delete cache
+	_ = d.deleteCache(ctx, id)
+
+	return nil
+}
+
+// UpdateByTx update a record by id in the database using the provided transaction
+func (d *itemInstanceDao) UpdateByTx(ctx context.Context, tx *gorm.DB, table *model.ItemInstance) error {
+	err := d.updateDataByID(ctx, tx, table)
+
+	// delete cache
+	_ = d.deleteCache(ctx, table.ID)
+
+	return err
+}
@@ -0,0 +1,297 @@
+package dao
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-dev-frame/sponge/pkg/gotest"
+	"github.com/go-dev-frame/sponge/pkg/sgorm/query"
+	"github.com/go-dev-frame/sponge/pkg/utils"
+	"github.com/stretchr/testify/assert"
+
+	"fs/internal/cache"
+	"fs/internal/database"
+	"fs/internal/model"
+)
+
+func newItemInstanceDao() *gotest.Dao {
+	testData := &model.ItemInstance{}
+	testData.ID = 1
+	testData.ProtoID = 1
+	testData.LocationType = model.ItemLocationRoom
+	testData.OwnerID = "hall"
+	durability := 80
+	testData.Durability = &durability
+	attack := 12
+	testData.Overrides = &model.ItemStatOverrides{Attack: &attack}
+
+	// init mock cache
+	//c := gotest.NewCache(map[string]interface{}{"no cache": testData}) // to test mysql, disable caching
+	c := gotest.NewCache(map[string]interface{}{utils.Uint64ToStr(testData.ID): testData})
+	c.ICache = cache.NewItemInstanceCache(&database.CacheType{
+		CType: "redis",
+		Rdb:   c.RedisClient,
+	})
+
+	// init mock dao
+	d := gotest.NewDao(c, testData)
+	d.IDao = NewItemInstanceDao(d.DB, c.ICache.(cache.ItemInstanceCache))
+
+	return d
+}
+
+func Test_itemInstanceDao_Create(t *testing.T) {
+	d := newItemInstanceDao()
+	defer d.Close()
+	testData := d.TestData.(*model.ItemInstance)
+
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectExec("INSERT INTO .*").
+		WithArgs(d.GetAnyArgs(testData)...).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	d.SQLMock.ExpectCommit()
+
+	err := d.IDao.(ItemInstanceDao).Create(d.Ctx, testData)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_itemInstanceDao_DeleteByID(t *testing.T) {
+	d := newItemInstanceDao()
+	defer d.Close()
+	testData := d.TestData.(*model.ItemInstance)
+	expectedSQLForDeletion := "DELETE .*"
+
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectExec(expectedSQLForDeletion).
+		WithArgs(testData.ID).
+		WillReturnResult(sqlmock.NewResult(int64(testData.ID), 1))
+	d.SQLMock.ExpectCommit()
+
+	err := d.IDao.(ItemInstanceDao).DeleteByID(d.Ctx, testData.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// zero id error
+	err = d.IDao.(ItemInstanceDao).DeleteByID(d.Ctx, 0)
+	assert.Error(t, err)
+}
+
+func Test_itemInstanceDao_UpdateByID(t *testing.T) {
+	d := newItemInstanceDao()
+	defer d.Close()
+	testData := d.TestData.(*model.ItemInstance)
+
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectExec("UPDATE .*").
+		WithArgs(*testData.Durability, testData.LocationType, `{"attack":12}`, testData.OwnerID, testData.ProtoID, testData.ID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	d.SQLMock.ExpectCommit()
+
+	err := d.IDao.(ItemInstanceDao).UpdateByID(d.Ctx, testData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// zero id error
+	err = d.IDao.(ItemInstanceDao).UpdateByID(d.Ctx, &model.ItemInstance{})
+	assert.Error(t, err)
+
+}
+
+func Test_itemInstanceDao_GetByID(t *testing.T) {
+	d := newItemInstanceDao()
+	defer d.Close()
+	testData := d.TestData.(*model.ItemInstance)
+
+	// column names and corresponding data
+	rows := sqlmock.NewRows([]string{"id"}).
+		AddRow(testData.ID)
+
+	d.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(testData.ID, 1).
+		WillReturnRows(rows)
+
+	_, err := d.IDao.(ItemInstanceDao).GetByID(d.Ctx, testData.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = d.SQLMock.ExpectationsWereMet()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// error test
+	d.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(2).
+		WillReturnRows(rows)
+	_, err = d.IDao.(ItemInstanceDao).GetByID(d.Ctx, 2)
+	assert.Error(t, err)
+
+	d.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(3, 4).
+		WillReturnRows(rows)
+	_, err = d.IDao.(ItemInstanceDao).GetByID(d.Ctx, 4)
+	assert.Error(t, err)
+}
+
+func Test_itemInstanceDao_GetByIDs(t *testing.T) {
+	d := newItemInstanceDao()
+	defer d.Close()
+	testData := d.TestData.(*model.ItemInstance)
+
+	rows := sqlmock.NewRows([]string{"id"}).
+		AddRow(testData.ID)
+	d.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(testData.ID, 2).
+		WillReturnRows(rows)
+
+	items, err := d.IDao.(ItemInstanceDao).GetByIDs(d.Ctx, []uint64{testData.ID, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, items, 1)
+	assert.NotNil(t, items[testData.ID])
+
+	// 1 is cached, the placeholder of 2 is not returned by MultiGet, one query settles it
+	d.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	items, err = d.IDao.(ItemInstanceDao).GetByIDs(d.Ctx, []uint64{testData.ID, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, items, 1)
+
+	err = d.SQLMock.ExpectationsWereMet()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_itemInstanceDao_GetByColumns(t *testing.T) {
+	d := newItemInstanceDao()
+	defer d.Close()
+	testData := d.TestData.(*model.ItemInstance)
+
+	// column names and corresponding data
+	rows := sqlmock.NewRows([]string{"id"}).
+		AddRow(testData.ID)
+
+	d.SQLMock.ExpectQuery("SELECT .*").WillReturnRows(rows)
+
+	_, _, err := d.IDao.(ItemInstanceDao).GetByColumns(d.Ctx, &query.Params{
+		Page:  0,
+		Limit: 10,
+		Sort:  "ignore count", // ignore test count(*)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = d.SQLMock.ExpectationsWereMet()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// err test
+	_, _, err = d.IDao.(ItemInstanceDao).GetByColumns(d.Ctx, &query.Params{
+		Page:  0,
+		Limit: 10,
+		Columns: []query.Column{
+			{
+				Name:  "id",
+				Exp:   "<",
+				Value: 0,
+			},
+		},
+	})
+	assert.Error(t, err)
+
+	// error test
+	dao := &itemInstanceDao{}
+	_, _, err = dao.GetByColumns(context.Background(), &query.Params{Columns: []query.Column{{}}})
+	t.Log(err)
+}
+
+func Test_itemInstanceDao_GetByOwner(t *testing.T) {
+	d := newItemInstanceDao()
+	defer d.Close()
+	testData := d.TestData.(*model.ItemInstance)
+
+	rows := sqlmock.NewRows([]string{"id", "proto_id", "location_type", "owner_id", "overrides"}).
+		AddRow(testData.ID, testData.ProtoID, testData.LocationType, testData.OwnerID, `{"attack":12}`)
+	d.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(testData.LocationType, testData.OwnerID).
+		WillReturnRows(rows)
+
+	records, err := d.IDao.(ItemInstanceDao).GetByOwner(d.Ctx, testData.LocationType, testData.OwnerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, records, 1)
+	item := records[0].Overrides.Apply(&model.Item{Hp: 3, Attack: 5})
+	assert.Equal(t, 3, item.Hp)
+	assert.Equal(t, 12, item.Attack)
+
+	err = d.SQLMock.ExpectationsWereMet()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_itemInstanceDao_CreateByTx(t *testing.T) {
+	d := newItemInstanceDao()
+	defer d.Close()
+	testData := d.TestData.(*model.ItemInstance)
+
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectExec("INSERT INTO .*").
+		WithArgs(d.GetAnyArgs(testData)...).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	d.SQLMock.ExpectCommit()
+
+	_, err := d.IDao.(ItemInstanceDao).CreateByTx(d.Ctx, d.DB, testData)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_itemInstanceDao_DeleteByTx(t *testing.T) {
+	d := newItemInstanceDao()
+	defer d.Close()
+	testData := d.TestData.(*model.ItemInstance)
+	expectedSQLForDeletion := "DELETE .*"
+
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectExec(expectedSQLForDeletion).
+		WithArgs(testData.ID).
+		WillReturnResult(sqlmock.NewResult(int64(testData.ID), 1))
+	d.SQLMock.ExpectCommit()
+
+	err := d.IDao.(ItemInstanceDao).DeleteByTx(d.Ctx, d.DB, testData.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_itemInstanceDao_UpdateByTx(t *testing.T) {
+	d := newItemInstanceDao()
+	defer d.Close()
+	testData := d.TestData.(*model.ItemInstance)
+
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectExec("UPDATE .*").
+		WithArgs(*testData.Durability, testData.LocationType, `{"attack":12}`, testData.OwnerID, testData.ProtoID, testData.ID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	d.SQLMock.ExpectCommit()
+
+	err := d.IDao.(ItemInstanceDao).UpdateByTx(d.Ctx, d.DB, testData)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
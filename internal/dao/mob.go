@@ -3,6 +3,7 @@ package dao
 import (
 	"context"
 	"errors"
+	"time"
 
 	"golang.org/x/sync/singleflight"
 	"gorm.io/gorm"
@@ -23,12 +24,21 @@ type MobDao interface {
 	Create(ctx context.Context, table *model.Mob) error
 	DeleteByID(ctx context.Context, id uint64) error
 	UpdateByID(ctx context.Context, table *model.Mob) error
+	PatchByID(ctx context.Context, table *model.Mob, fields []string) error
+	Save(ctx context.Context, table *model.Mob) error
 	GetByID(ctx context.Context, id uint64) (*model.Mob, error)
+	GetByIDs(ctx context.Context, ids []uint64) (map[uint64]*model.Mob, error)
 	GetByColumns(ctx context.Context, params *query.Params) ([]*model.Mob, int64, error)
+	ExistsMobID(ctx context.Context, mobID string, exceptID uint64) (bool, error)
+
+	RestoreByID(ctx context.Context, id uint64) error
+	GetDeleted(ctx context.Context, page int, limit int) ([]*model.Mob, int64, error)
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
 
 	CreateByTx(ctx context.Context, tx *gorm.DB, table *model.Mob) (uint64, error)
 	DeleteByTx(ctx context.Context, tx *gorm.DB, id uint64) error
 	UpdateByTx(ctx context.Context, tx *gorm.DB, table *model.Mob) error
+	PatchByTx(ctx context.Context, tx *gorm.DB, table *model.Mob, fields []string) error
 }
 
 type mobDao struct {
@@ -61,7 +71,7 @@ func (d *mobDao) Create(ctx context.Context, table *model.Mob) error {
 	return d.db.WithContext(ctx).Create(table).Error
 }
 
-// DeleteByID delete a mob by id
+// DeleteByID move a mob to the trash by id, it can be brought back with RestoreByID
 func (d *mobDao) DeleteByID(ctx context.Context, id uint64) error {
 	err := d.db.WithContext(ctx).Where("id = ?", id).Delete(&model.Mob{}).Error
 	if err != nil {
@@ -75,6 +85,8 @@ func (d *mobDao) DeleteByID(ctx context.Context, id uint64) error {
 }
 
 // UpdateByID update a mob by id, support partial update
+// table.Version must be the version that was read, database.ErrVersionConflict is returned if the mob has
+// been changed since then, on success table.Version is the new version
 func (d *mobDao) UpdateByID(ctx context.Context, table *model.Mob) error {
 	err := d.updateDataByID(ctx, d.db, table)
 
@@ -84,10 +96,55 @@ func (d *mobDao) UpdateByID(ctx context.Context, table *model.Mob) error {
 	return err
 }
 
+// PatchByID update the fields of a mob by id, zero values included, fields are the names of the fields
+// of model.Mob, with the same version check as UpdateByID
+func (d *mobDao) PatchByID(ctx context.Context, table *model.Mob, fields []string) error {
+	err := d.patchDataByID(ctx, d.db, table, fields)
+
+	// delete cache
+	_ = d.deleteCache(ctx, table.ID)
+
+	return err
+}
+
+func (d *mobDao) patchDataByID(ctx context.Context, db *gorm.DB, table *model.Mob, fields []string) error {
+	if table.ID < 1 {
+		return errors.New("id cannot be 0")
+	}
+	if table.Version < 1 {
+		return errors.New("version cannot be 0")
+	}
+	return patchByVersion(db.WithContext(ctx), table, &table.Version, fields)
+}
+
+// Save write all the fields of a mob, including zero values, the mob is created if it does not exist
+func (d *mobDao) Save(ctx context.Context, table *model.Mob) error {
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// the version goes on from the stored one, so an update read before the save gets a conflict
+		version, err := nextVersion(tx, &model.Mob{}, table.ID)
+		if err != nil {
+			return err
+		}
+		table.Version = version
+		return tx.Unscoped().Save(table).Error // a mob in the trash is overwritten and restored
+	})
+	if err != nil {
+		return err
+	}
+
+	// delete cache
+	_ = d.deleteCache(ctx, table.ID)
+
+	return nil
+}
+
 func (d *mobDao) updateDataByID(ctx context.Context, db *gorm.DB, table *model.Mob) error {
 	if table.ID < 1 {
 		return errors.New("id cannot be 0")
 	}
+	if table.Version < 1 {
+		return errors.New("version cannot be 0")
+	}
 
 	update := map[string]interface{}{}
 
@@ -122,7 +179,19 @@ func (d *mobDao) updateDataByID(ctx context.Context, db *gorm.DB, table *model.M
 		update["dodge"] = table.Dodge
 	}
 
-	return db.WithContext(ctx).Model(table).Updates(update).Error
+	version := table.Version
+	update["version"] = gorm.Expr("version + 1")
+
+	result := db.WithContext(ctx).Model(table).Where("version = ?", version).Updates(update)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return database.ErrVersionConflict // changed or deleted since the version was read
+	}
+	table.Version = version + 1
+
+	return nil
 }
 
 // GetByID get a mob by id
@@ -179,10 +248,71 @@ func (d *mobDao) GetByID(ctx context.Context, id uint64) (*model.Mob, error) {
 	return nil, err
 }
 
+// GetByIDs get mobs by ids, ids that do not exist are not in the returned map
+func (d *mobDao) GetByIDs(ctx context.Context, ids []uint64) (map[uint64]*model.Mob, error) {
+	// no cache
+	if d.cache == nil {
+		var records []*model.Mob
+		err := d.db.WithContext(ctx).Where("id IN (?)", ids).Find(&records).Error
+		if err != nil {
+			return nil, err
+		}
+		itemMap := make(map[uint64]*model.Mob)
+		for _, record := range records {
+			itemMap[record.ID] = record
+		}
+		return itemMap, nil
+	}
+
+	// get from cache
+	itemMap, err := d.cache.MultiGet(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	var missedIDs []uint64
+	for _, id := range ids {
+		if _, ok := itemMap[id]; !ok {
+			missedIDs = append(missedIDs, id)
+		}
+	}
+	if len(missedIDs) == 0 {
+		return itemMap, nil
+	}
+
+	// get the missed ids from database with one query, MultiGet leaves out the placeholders
+	// like the ids that are not cached, so the ids known not to exist are in the query too
+	var records []*model.Mob
+	err = d.db.WithContext(ctx).Where("id IN (?)", missedIDs).Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(records) > 0 {
+		for _, record := range records {
+			itemMap[record.ID] = record
+		}
+		if err = d.cache.MultiSet(ctx, records, cache.MobExpireTime); err != nil {
+			logger.Warn("cache.MultiSet error", logger.Err(err), logger.Any("ids", missedIDs))
+		}
+	}
+
+	// set placeholder cache for the ids that do not exist to prevent cache penetration in GetByID
+	for _, id := range missedIDs {
+		if _, ok := itemMap[id]; !ok {
+			if err = d.cache.SetPlaceholder(ctx, id); err != nil {
+				logger.Warn("cache.SetPlaceholder error", logger.Err(err), logger.Any("id", id))
+			}
+		}
+	}
+
+	return itemMap, nil
+}
+
 // GetByColumns get a paginated list of mobs by custom conditions.
 // For more details, please refer to https://go-sponge.com/component/data/custom-page-query.html
 func (d *mobDao) GetByColumns(ctx context.Context, params *query.Params) ([]*model.Mob, int64, error) {
-	queryStr, args, err := params.ConvertToGormConditions(query.WithWhitelistNames(model.MobColumnNames))
+	quoted, whitelist := quoteColumns(d.db, params, model.MobColumnNames)
+	queryStr, args, err := quoted.ConvertToGormConditions(query.WithWhitelistNames(whitelist))
 	if err != nil {
 		return nil, 0, errors.New("query params error: " + err.Error())
 	}
@@ -199,7 +329,7 @@ func (d *mobDao) GetByColumns(ctx context.Context, params *query.Params) ([]*mod
 	}
 
 	records := []*model.Mob{}
-	order, limit, offset := params.ConvertToPage()
+	order, limit, offset := quoted.ConvertToPage()
 	err = d.db.WithContext(ctx).Order(order).Limit(limit).Offset(offset).Where(queryStr, args...).Find(&records).Error
 	if err != nil {
 		return nil, 0, err
@@ -208,6 +338,58 @@ func (d *mobDao) GetByColumns(ctx context.Context, params *query.Params) ([]*mod
 	return records, total, err
 }
 
+// ExistsMobID check if a mob other than exceptID has the mob_id, the mobs in the trash included as they can be restored
+func (d *mobDao) ExistsMobID(ctx context.Context, mobID string, exceptID uint64) (bool, error) {
+	var total int64
+	err := d.db.WithContext(ctx).Unscoped().Model(&model.Mob{}).
+		Where("mob_id = ? AND id <> ?", mobID, exceptID).
+		Count(&total).Error
+	return total > 0, err
+}
+
+// RestoreByID take a mob out of the trash by id, database.ErrRecordNotFound if it is not in the trash
+func (d *mobDao) RestoreByID(ctx context.Context, id uint64) error {
+	result := d.db.WithContext(ctx).Unscoped().Model(&model.Mob{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Updates(map[string]interface{}{"deleted_at": nil, "version": gorm.Expr("version + 1")})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return database.ErrRecordNotFound
+	}
+
+	// delete cache
+	_ = d.deleteCache(ctx, id)
+
+	return nil
+}
+
+// GetDeleted get a page of the mobs in the trash, the latest deleted first, page starts from 0
+func (d *mobDao) GetDeleted(ctx context.Context, page int, limit int) ([]*model.Mob, int64, error) {
+	db := d.db.WithContext(ctx).Unscoped().Model(&model.Mob{}).Where("deleted_at IS NOT NULL")
+
+	var total int64
+	err := db.Count(&total).Error
+	if err != nil || total == 0 {
+		return nil, total, err
+	}
+
+	records := []*model.Mob{}
+	err = db.Order("deleted_at DESC").Limit(limit).Offset(page * limit).Find(&records).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return records, total, nil
+}
+
+// PurgeDeleted remove for good the mobs that were moved to the trash before the given time
+func (d *mobDao) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	result := d.db.WithContext(ctx).Unscoped().Where("deleted_at < ?", before).Delete(&model.Mob{})
+	return result.RowsAffected, result.Error
+}
+
 // CreateByTx create a record in the database using the provided transaction
 func (d *mobDao) CreateByTx(ctx context.Context, tx *gorm.DB, table *model.Mob) (uint64, error) {
 	err := tx.WithContext(ctx).Create(table).Error
@@ -228,6 +410,7 @@ func (d *mobDao) DeleteByTx(ctx context.Context, tx *gorm.DB, id uint64) error {
 }
 
 // UpdateByTx update a record by id in the database using the provided transaction
+// with the same version check as UpdateByID
 func (d *mobDao) UpdateByTx(ctx context.Context, tx *gorm.DB, table *model.Mob) error {
 	err := d.updateDataByID(ctx, tx, table)
 
@@ -236,3 +419,19 @@ func (d *mobDao) UpdateByTx(ctx context.Context, tx *gorm.DB, table *model.Mob)
 
 	return err
 }
+
+// PatchByTx update the fields of a mob by id using the provided transaction, zero values included,
+// with the same version check as UpdateByID
+func (d *mobDao) PatchByTx(ctx context.Context, tx *gorm.DB, table *model.Mob, fields []string) error {
+	err := d.patchDataByID(ctx, tx, table, fields)
+
+	// delete cache
+	_ = d.deleteCache(ctx, table.ID)
+
+	return err
+}
+
+// GetAllMobs read every mob page by page through GetByColumns, for the whole world checks and tools
+func GetAllMobs(ctx context.Context, d MobDao) ([]*model.Mob, error) {
+	return getAllPages(ctx, d.GetByColumns)
+}
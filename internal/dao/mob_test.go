@@ -2,7 +2,9 @@ package dao
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/go-dev-frame/sponge/pkg/gotest"
@@ -58,11 +60,11 @@ func Test_mobDao_DeleteByID(t *testing.T) {
 	d := newMobDao()
 	defer d.Close()
 	testData := d.TestData.(*model.Mob)
-	expectedSQLForDeletion := "DELETE .*"
+	expectedSQLForDeletion := "UPDATE .* SET `deleted_at`=.*" // moved to the trash
 
 	d.SQLMock.ExpectBegin()
 	d.SQLMock.ExpectExec(expectedSQLForDeletion).
-		WithArgs(testData.ID).
+		WithArgs(d.AnyTime, testData.ID).
 		WillReturnResult(sqlmock.NewResult(int64(testData.ID), 1))
 	d.SQLMock.ExpectCommit()
 
@@ -80,10 +82,12 @@ func Test_mobDao_UpdateByID(t *testing.T) {
 	d := newMobDao()
 	defer d.Close()
 	testData := d.TestData.(*model.Mob)
+	testData.Version = 1
+	expectedSQLForUpdate := "UPDATE `mob` SET `version`=version \\+ 1 WHERE version = \\? .*`id` = \\?"
 
 	d.SQLMock.ExpectBegin()
-	d.SQLMock.ExpectExec("UPDATE .*").
-		WithArgs(d.AnyTime, testData.ID).
+	d.SQLMock.ExpectExec(expectedSQLForUpdate).
+		WithArgs(1, testData.ID).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	d.SQLMock.ExpectCommit()
 
@@ -91,11 +95,182 @@ func Test_mobDao_UpdateByID(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
+	assert.Equal(t, 2, testData.Version)
+
+	// changed since the version was read
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectExec(expectedSQLForUpdate).
+		WithArgs(2, testData.ID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	d.SQLMock.ExpectCommit()
+	err = d.IDao.(MobDao).UpdateByID(d.Ctx, testData)
+	assert.ErrorIs(t, err, database.ErrVersionConflict)
+	assert.Equal(t, 2, testData.Version)
 
 	// zero id error
 	err = d.IDao.(MobDao).UpdateByID(d.Ctx, &model.Mob{})
 	assert.Error(t, err)
 
+	// zero version error
+	err = d.IDao.(MobDao).UpdateByID(d.Ctx, &model.Mob{ID: testData.ID})
+	assert.Error(t, err)
+}
+
+func Test_mobDao_PatchByID(t *testing.T) {
+	d := newMobDao()
+	defer d.Close()
+	testData := d.TestData.(*model.Mob)
+
+	// the zero values of the fields are written
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectExec("UPDATE `mob` SET `mob_desc`=\\?,`hp`=\\?,`version`=\\? WHERE version = \\?").
+		WithArgs("", 0, 2, 1, testData.ID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	d.SQLMock.ExpectCommit()
+
+	patch := &model.Mob{ID: testData.ID, Version: 1}
+	err := d.IDao.(MobDao).PatchByID(d.Ctx, patch, []string{"MobDesc", "Hp"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 2, patch.Version)
+
+	// changed since the version was read
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectExec("UPDATE `mob` SET `hp`=\\?,`version`=\\? WHERE version = \\?").
+		WithArgs(0, 3, 2, testData.ID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	d.SQLMock.ExpectCommit()
+	err = d.IDao.(MobDao).PatchByID(d.Ctx, patch, []string{"Hp"})
+	assert.ErrorIs(t, err, database.ErrVersionConflict)
+	assert.Equal(t, 2, patch.Version)
+
+	// zero id and zero version error
+	err = d.IDao.(MobDao).PatchByID(d.Ctx, &model.Mob{Version: 1}, []string{"Hp"})
+	assert.Error(t, err)
+	err = d.IDao.(MobDao).PatchByID(d.Ctx, &model.Mob{ID: testData.ID}, []string{"Hp"})
+	assert.Error(t, err)
+}
+
+func Test_mobDao_Save(t *testing.T) {
+	d := newMobDao()
+	defer d.Close()
+	testData := d.TestData.(*model.Mob)
+
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectQuery("SELECT `version` FROM `mob` WHERE id = \\?").
+		WithArgs(testData.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(3))
+	d.SQLMock.ExpectExec("UPDATE .*").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	d.SQLMock.ExpectCommit()
+
+	err := d.IDao.(MobDao).Save(d.Ctx, testData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 4, testData.Version) // goes on from the stored version
+
+	// error test
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectQuery("SELECT `version` .*").
+		WillReturnRows(sqlmock.NewRows([]string{"version"}))
+	d.SQLMock.ExpectExec("UPDATE .*").
+		WillReturnError(errors.New("db error"))
+	d.SQLMock.ExpectRollback()
+	err = d.IDao.(MobDao).Save(d.Ctx, testData)
+	assert.Error(t, err)
+}
+
+func Test_mobDao_RestoreByID(t *testing.T) {
+	d := newMobDao()
+	defer d.Close()
+	testData := d.TestData.(*model.Mob)
+
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectExec("UPDATE .* SET `deleted_at`=.* WHERE id = \\? AND deleted_at IS NOT NULL").
+		WithArgs(nil, testData.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	d.SQLMock.ExpectCommit()
+
+	err := d.IDao.(MobDao).RestoreByID(d.Ctx, testData.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// not in the trash
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectExec("UPDATE .*").
+		WithArgs(nil, 2).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	d.SQLMock.ExpectCommit()
+	err = d.IDao.(MobDao).RestoreByID(d.Ctx, 2)
+	assert.ErrorIs(t, err, database.ErrRecordNotFound)
+}
+
+func Test_mobDao_ExistsMobID(t *testing.T) {
+	d := newMobDao()
+	defer d.Close()
+	testData := d.TestData.(*model.Mob)
+
+	// no deleted_at condition, the mobs in the trash count
+	d.SQLMock.ExpectQuery("SELECT count\\(\\*\\) FROM `mob` WHERE mob_id = \\? AND id <> \\?$").
+		WithArgs("guard", testData.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	exists, err := d.IDao.(MobDao).ExistsMobID(d.Ctx, "guard", testData.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, exists)
+
+	err = d.SQLMock.ExpectationsWereMet()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_mobDao_GetDeleted(t *testing.T) {
+	d := newMobDao()
+	defer d.Close()
+	testData := d.TestData.(*model.Mob)
+
+	d.SQLMock.ExpectQuery("SELECT count\\(\\*\\) FROM .* WHERE deleted_at IS NOT NULL$").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	d.SQLMock.ExpectQuery("SELECT .* WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC LIMIT .*").
+		WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "deleted_at"}).AddRow(testData.ID, time.Now()))
+
+	records, total, err := d.IDao.(MobDao).GetDeleted(d.Ctx, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, int64(1), total)
+	assert.Len(t, records, 1)
+	assert.True(t, records[0].DeletedAt.Valid)
+
+	err = d.SQLMock.ExpectationsWereMet()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_mobDao_PurgeDeleted(t *testing.T) {
+	d := newMobDao()
+	defer d.Close()
+
+	before := time.Now().AddDate(0, 0, -30)
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectExec("DELETE FROM .* WHERE deleted_at < \\?").
+		WithArgs(before).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	d.SQLMock.ExpectCommit()
+
+	n, err := d.IDao.(MobDao).PurgeDeleted(d.Ctx, before)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, int64(2), n)
 }
 
 func Test_mobDao_GetByID(t *testing.T) {
@@ -135,6 +310,40 @@ func Test_mobDao_GetByID(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func Test_mobDao_GetByIDs(t *testing.T) {
+	d := newMobDao()
+	defer d.Close()
+	testData := d.TestData.(*model.Mob)
+
+	rows := sqlmock.NewRows([]string{"id"}).
+		AddRow(testData.ID)
+	d.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(testData.ID, 2).
+		WillReturnRows(rows)
+
+	mobs, err := d.IDao.(MobDao).GetByIDs(d.Ctx, []uint64{testData.ID, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, mobs, 1)
+	assert.NotNil(t, mobs[testData.ID])
+
+	// 1 is cached, the placeholder of 2 is not returned by MultiGet, one query settles it
+	d.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mobs, err = d.IDao.(MobDao).GetByIDs(d.Ctx, []uint64{testData.ID, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, mobs, 1)
+
+	err = d.SQLMock.ExpectationsWereMet()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
 func Test_mobDao_GetByColumns(t *testing.T) {
 	d := newMobDao()
 	defer d.Close()
@@ -201,11 +410,11 @@ func Test_mobDao_DeleteByTx(t *testing.T) {
 	d := newMobDao()
 	defer d.Close()
 	testData := d.TestData.(*model.Mob)
-	expectedSQLForDeletion := "DELETE .*"
+	expectedSQLForDeletion := "UPDATE .* SET `deleted_at`=.*" // moved to the trash
 
 	d.SQLMock.ExpectBegin()
 	d.SQLMock.ExpectExec(expectedSQLForDeletion).
-		WithArgs(testData.ID).
+		WithArgs(d.AnyTime, testData.ID).
 		WillReturnResult(sqlmock.NewResult(int64(testData.ID), 1))
 	d.SQLMock.ExpectCommit()
 
@@ -219,10 +428,11 @@ func Test_mobDao_UpdateByTx(t *testing.T) {
 	d := newMobDao()
 	defer d.Close()
 	testData := d.TestData.(*model.Mob)
+	testData.Version = 1
 
 	d.SQLMock.ExpectBegin()
-	d.SQLMock.ExpectExec("UPDATE .*").
-		WithArgs(d.AnyTime, testData.ID).
+	d.SQLMock.ExpectExec("UPDATE .* WHERE version = \\?").
+		WithArgs(1, testData.ID).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	d.SQLMock.ExpectCommit()
 
@@ -230,4 +440,13 @@ func Test_mobDao_UpdateByTx(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
+
+	// changed since the version was read
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectExec("UPDATE .* WHERE version = \\?").
+		WithArgs(1, testData.ID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	d.SQLMock.ExpectCommit()
+	err = d.IDao.(MobDao).UpdateByTx(d.Ctx, d.DB, &model.Mob{ID: testData.ID, Version: 1})
+	assert.ErrorIs(t, err, database.ErrVersionConflict)
 }
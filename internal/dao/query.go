@@ -0,0 +1,53 @@
+package dao
+
+import (
+	"strings"
+
+	"github.com/go-dev-frame/sponge/pkg/sgorm/query"
+	"gorm.io/gorm"
+)
+
+// quoteColumns copy the params with the column names of the conditions and of the sort
+// quoted for the database of db, the query builder writes them as they are and a column
+// such as desc is a reserved word of mysql and postgresql. The returned whitelist holds the
+// quoted names, params with a column name that is not in the whitelist are returned as they
+// are and rejected by ConvertToGormConditions.
+func quoteColumns(db *gorm.DB, params *query.Params, whitelist map[string]bool) (*query.Params, map[string]bool) {
+	for _, column := range params.Columns {
+		if !whitelist[column.Name] {
+			return params, whitelist
+		}
+	}
+
+	quote := func(name string) string {
+		if whitelist[name] {
+			return db.Statement.Quote(name)
+		}
+		return name
+	}
+
+	quoted := *params
+	quoted.Columns = make([]query.Column, len(params.Columns))
+	for i, column := range params.Columns {
+		column.Name = quote(column.Name)
+		quoted.Columns[i] = column
+	}
+
+	if params.Sort != "ignore count" {
+		names := strings.Split(strings.ReplaceAll(params.Sort, " ", ""), ",")
+		for i, name := range names {
+			if strings.HasPrefix(name, "-") {
+				names[i] = "-" + quote(name[1:])
+			} else {
+				names[i] = quote(name)
+			}
+		}
+		quoted.Sort = strings.Join(names, ",")
+	}
+
+	quotedWhitelist := make(map[string]bool, len(whitelist))
+	for name, ok := range whitelist {
+		quotedWhitelist[db.Statement.Quote(name)] = ok
+	}
+	return &quoted, quotedWhitelist
+}
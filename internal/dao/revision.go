@@ -0,0 +1,90 @@
+package dao
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/go-dev-frame/sponge/pkg/sgorm/query"
+
+	"fs/internal/model"
+)
+
+var _ RevisionDao = (*revisionDao)(nil)
+
+// RevisionDao defining the dao interface, the revisions are written by revision.Plugin
+// and are not changed afterwards, so they are not cached
+type RevisionDao interface {
+	GetByID(ctx context.Context, id uint64) (*model.Revision, error)
+	GetByColumns(ctx context.Context, params *query.Params) ([]*model.Revision, int64, error)
+	GetByEntity(ctx context.Context, entityType string, entityID string, page int, limit int) ([]*model.Revision, int64, error)
+}
+
+type revisionDao struct {
+	db *gorm.DB
+}
+
+// NewRevisionDao creating the dao interface
+func NewRevisionDao(db *gorm.DB) RevisionDao {
+	return &revisionDao{db: db}
+}
+
+// GetByID get a revision by id
+func (d *revisionDao) GetByID(ctx context.Context, id uint64) (*model.Revision, error) {
+	record := &model.Revision{}
+	err := d.db.WithContext(ctx).Where("id = ?", id).First(record).Error
+	return record, err
+}
+
+// GetByColumns get a paginated list of revisions by custom conditions.
+// For more details, please refer to https://go-sponge.com/component/data/custom-page-query.html
+func (d *revisionDao) GetByColumns(ctx context.Context, params *query.Params) ([]*model.Revision, int64, error) {
+	if params.Sort == "" {
+		params.Sort = "-id"
+	}
+	quoted, whitelist := quoteColumns(d.db, params, model.RevisionColumnNames)
+	queryStr, args, err := quoted.ConvertToGormConditions(query.WithWhitelistNames(whitelist))
+	if err != nil {
+		return nil, 0, errors.New("query params error: " + err.Error())
+	}
+
+	var total int64
+	if params.Sort != "ignore count" { // determine if count is required
+		err = d.db.WithContext(ctx).Model(&model.Revision{}).Where(queryStr, args...).Count(&total).Error
+		if err != nil {
+			return nil, 0, err
+		}
+		if total == 0 {
+			return nil, total, nil
+		}
+	}
+
+	records := []*model.Revision{}
+	order, limit, offset := quoted.ConvertToPage()
+	err = d.db.WithContext(ctx).Order(order).Limit(limit).Offset(offset).Where(queryStr, args...).Find(&records).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return records, total, err
+}
+
+// GetByEntity get a page of the revisions of a record, newest first, page starts from 0
+func (d *revisionDao) GetByEntity(ctx context.Context, entityType string, entityID string, page int, limit int) ([]*model.Revision, int64, error) {
+	db := d.db.WithContext(ctx).Model(&model.Revision{}).Where("entity_type = ? AND entity_id = ?", entityType, entityID)
+
+	var total int64
+	err := db.Count(&total).Error
+	if err != nil || total == 0 {
+		return nil, total, err
+	}
+
+	records := []*model.Revision{}
+	err = db.Order("id DESC").Limit(limit).Offset(page * limit).Find(&records).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return records, total, nil
+}
@@ -0,0 +1,120 @@
+package dao
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-dev-frame/sponge/pkg/gotest"
+	"github.com/go-dev-frame/sponge/pkg/sgorm/query"
+	"github.com/stretchr/testify/assert"
+
+	"fs/internal/model"
+)
+
+func newRevisionDao() *gotest.Dao {
+	testData := &model.Revision{}
+	testData.ID = 1
+	testData.EntityType = "room"
+	testData.EntityID = "inn"
+	testData.Action = "update"
+	testData.CreatedAt = time.Now()
+
+	// init mock dao
+	d := gotest.NewDao(nil, testData)
+	d.IDao = NewRevisionDao(d.DB)
+
+	return d
+}
+
+func Test_revisionDao_GetByID(t *testing.T) {
+	d := newRevisionDao()
+	defer d.Close()
+	testData := d.TestData.(*model.Revision)
+
+	rows := sqlmock.NewRows([]string{"id", "entity_type", "entity_id"}).
+		AddRow(testData.ID, testData.EntityType, testData.EntityID)
+	d.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(testData.ID, 1).
+		WillReturnRows(rows)
+
+	record, err := d.IDao.(RevisionDao).GetByID(d.Ctx, testData.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "inn", record.EntityID)
+
+	// error test
+	d.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(2, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	_, err = d.IDao.(RevisionDao).GetByID(d.Ctx, 2)
+	assert.Error(t, err)
+}
+
+func Test_revisionDao_GetByColumns(t *testing.T) {
+	d := newRevisionDao()
+	defer d.Close()
+	testData := d.TestData.(*model.Revision)
+
+	rows := sqlmock.NewRows([]string{"id"}).
+		AddRow(testData.ID)
+	d.SQLMock.ExpectQuery("SELECT .*").WillReturnRows(rows)
+
+	_, _, err := d.IDao.(RevisionDao).GetByColumns(d.Ctx, &query.Params{
+		Page:  0,
+		Limit: 10,
+		Sort:  "ignore count", // ignore test count(*)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = d.SQLMock.ExpectationsWereMet()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// err test
+	_, _, err = d.IDao.(RevisionDao).GetByColumns(d.Ctx, &query.Params{
+		Page:    0,
+		Limit:   10,
+		Columns: []query.Column{{Name: "before_data", Value: "x"}},
+	})
+	assert.Error(t, err)
+}
+
+func Test_revisionDao_GetByEntity(t *testing.T) {
+	d := newRevisionDao()
+	defer d.Close()
+	testData := d.TestData.(*model.Revision)
+
+	d.SQLMock.ExpectQuery("SELECT count(.*)").
+		WithArgs(testData.EntityType, testData.EntityID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	d.SQLMock.ExpectQuery("SELECT .* ORDER BY id DESC LIMIT .* OFFSET .*").
+		WithArgs(testData.EntityType, testData.EntityID, 10, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "entity_type", "entity_id"}).
+			AddRow(testData.ID, testData.EntityType, testData.EntityID))
+
+	records, total, err := d.IDao.(RevisionDao).GetByEntity(d.Ctx, testData.EntityType, testData.EntityID, 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, int64(1), total)
+	assert.Len(t, records, 1)
+
+	// no revisions
+	d.SQLMock.ExpectQuery("SELECT count(.*)").
+		WithArgs(testData.EntityType, "hall").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	records, total, err = d.IDao.(RevisionDao).GetByEntity(d.Ctx, testData.EntityType, "hall", 0, 10)
+	assert.NoError(t, err)
+	assert.Zero(t, total)
+	assert.Empty(t, records)
+
+	err = d.SQLMock.ExpectationsWereMet()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -3,6 +3,7 @@ package dao
 import (
 	"context"
 	"errors"
+	"time"
 
 	"golang.org/x/sync/singleflight"
 	"gorm.io/gorm"
@@ -22,12 +23,20 @@ type RoomDao interface {
 	Create(ctx context.Context, table *model.Room) error
 	DeleteByID(ctx context.Context, id string) error
 	UpdateByID(ctx context.Context, table *model.Room) error
+	PatchByID(ctx context.Context, table *model.Room, fields []string) error
+	Save(ctx context.Context, table *model.Room) error
 	GetByID(ctx context.Context, id string) (*model.Room, error)
+	GetByIDs(ctx context.Context, ids []string) (map[string]*model.Room, error)
 	GetByColumns(ctx context.Context, params *query.Params) ([]*model.Room, int64, error)
 
+	RestoreByID(ctx context.Context, id string) error
+	GetDeleted(ctx context.Context, page int, limit int) ([]*model.Room, int64, error)
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
+
 	CreateByTx(ctx context.Context, tx *gorm.DB, table *model.Room) (string, error)
 	DeleteByTx(ctx context.Context, tx *gorm.DB, id string) error
 	UpdateByTx(ctx context.Context, tx *gorm.DB, table *model.Room) error
+	PatchByTx(ctx context.Context, tx *gorm.DB, table *model.Room, fields []string) error
 }
 
 type roomDao struct {
@@ -57,10 +66,14 @@ func (d *roomDao) deleteCache(ctx context.Context, id string) error {
 
 // Create a new room, insert the record and the id value is written back to the table
 func (d *roomDao) Create(ctx context.Context, table *model.Room) error {
-	return d.db.WithContext(ctx).Create(table).Error
+	err := d.db.WithContext(ctx).Create(table).Error
+	if err == nil {
+		notifyRoomChange(table.ID)
+	}
+	return err
 }
 
-// DeleteByID delete a room by id
+// DeleteByID move a room to the trash by id, it can be brought back with RestoreByID
 func (d *roomDao) DeleteByID(ctx context.Context, id string) error {
 	err := d.db.WithContext(ctx).Where("id = ?", id).Delete(&model.Room{}).Error
 	if err != nil {
@@ -69,24 +82,79 @@ func (d *roomDao) DeleteByID(ctx context.Context, id string) error {
 
 	// delete cache
 	_ = d.deleteCache(ctx, id)
+	notifyRoomChange(id)
 
 	return nil
 }
 
 // UpdateByID update a room by id
+// table.Version must be the version that was read, database.ErrVersionConflict is returned if the room has
+// been changed since then, on success table.Version is the new version
 func (d *roomDao) UpdateByID(ctx context.Context, table *model.Room) error {
 	err := d.updateDataByID(ctx, d.db, table)
 
 	// delete cache
 	_ = d.deleteCache(ctx, table.ID)
+	if err == nil {
+		notifyRoomChange(table.ID)
+	}
+
+	return err
+}
+
+// PatchByID update the fields of a room by id, zero values included, fields are the names of the fields
+// of model.Room, with the same version check as UpdateByID
+func (d *roomDao) PatchByID(ctx context.Context, table *model.Room, fields []string) error {
+	err := d.patchDataByID(ctx, d.db, table, fields)
+
+	// delete cache
+	_ = d.deleteCache(ctx, table.ID)
+	if err == nil {
+		notifyRoomChange(table.ID)
+	}
 
 	return err
 }
 
+func (d *roomDao) patchDataByID(ctx context.Context, db *gorm.DB, table *model.Room, fields []string) error {
+	if table.ID == "" {
+		return errors.New("id cannot be empty")
+	}
+	if table.Version < 1 {
+		return errors.New("version cannot be 0")
+	}
+	return patchByVersion(db.WithContext(ctx), table, &table.Version, fields)
+}
+
+// Save write all the fields of a room, including zero values, the room is created if it does not exist
+func (d *roomDao) Save(ctx context.Context, table *model.Room) error {
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// the version goes on from the stored one, so an update read before the save gets a conflict
+		version, err := nextVersion(tx, &model.Room{}, table.ID)
+		if err != nil {
+			return err
+		}
+		table.Version = version
+		return tx.Unscoped().Save(table).Error // a room in the trash is overwritten and restored
+	})
+	if err != nil {
+		return err
+	}
+
+	// delete cache
+	_ = d.deleteCache(ctx, table.ID)
+	notifyRoomChange(table.ID)
+
+	return nil
+}
+
 func (d *roomDao) updateDataByID(ctx context.Context, db *gorm.DB, table *model.Room) error {
 	if table.ID == "" {
 		return errors.New("id cannot be empty")
 	}
+	if table.Version < 1 {
+		return errors.New("version cannot be 0")
+	}
 
 	update := map[string]interface{}{}
 
@@ -103,7 +171,19 @@ func (d *roomDao) updateDataByID(ctx context.Context, db *gorm.DB, table *model.
 		update["mobs"] = table.Mobs
 	}
 
-	return db.WithContext(ctx).Model(table).Updates(update).Error
+	version := table.Version
+	update["version"] = gorm.Expr("version + 1")
+
+	result := db.WithContext(ctx).Model(table).Where("version = ?", version).Updates(update)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return database.ErrVersionConflict // changed or deleted since the version was read
+	}
+	table.Version = version + 1
+
+	return nil
 }
 
 // GetByID get a room by id
@@ -161,13 +241,74 @@ func (d *roomDao) GetByID(ctx context.Context, id string) (*model.Room, error) {
 	return nil, err
 }
 
+// GetByIDs get rooms by ids, ids that do not exist are not in the returned map
+func (d *roomDao) GetByIDs(ctx context.Context, ids []string) (map[string]*model.Room, error) {
+	// no cache
+	if d.cache == nil {
+		var records []*model.Room
+		err := d.db.WithContext(ctx).Where("id IN (?)", ids).Find(&records).Error
+		if err != nil {
+			return nil, err
+		}
+		itemMap := make(map[string]*model.Room)
+		for _, record := range records {
+			itemMap[record.ID] = record
+		}
+		return itemMap, nil
+	}
+
+	// get from cache
+	itemMap, err := d.cache.MultiGet(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	var missedIDs []string
+	for _, id := range ids {
+		if _, ok := itemMap[id]; !ok {
+			missedIDs = append(missedIDs, id)
+		}
+	}
+	if len(missedIDs) == 0 {
+		return itemMap, nil
+	}
+
+	// get the missed ids from database with one query, MultiGet leaves out the placeholders
+	// like the ids that are not cached, so the ids known not to exist are in the query too
+	var records []*model.Room
+	err = d.db.WithContext(ctx).Where("id IN (?)", missedIDs).Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(records) > 0 {
+		for _, record := range records {
+			itemMap[record.ID] = record
+		}
+		if err = d.cache.MultiSet(ctx, records, cache.RoomExpireTime); err != nil {
+			logger.Warn("cache.MultiSet error", logger.Err(err), logger.Any("ids", missedIDs))
+		}
+	}
+
+	// set placeholder cache for the ids that do not exist to prevent cache penetration in GetByID
+	for _, id := range missedIDs {
+		if _, ok := itemMap[id]; !ok {
+			if err = d.cache.SetPlaceholder(ctx, id); err != nil {
+				logger.Warn("cache.SetPlaceholder error", logger.Err(err), logger.Any("id", id))
+			}
+		}
+	}
+
+	return itemMap, nil
+}
+
 // GetByColumns get a paginated list of rooms by custom conditions.
 // For more details, please refer to https://go-sponge.com/component/data/custom-page-query.html
 func (d *roomDao) GetByColumns(ctx context.Context, params *query.Params) ([]*model.Room, int64, error) {
 	if params.Sort == "" {
 		params.Sort = "-id"
 	}
-	queryStr, args, err := params.ConvertToGormConditions(query.WithWhitelistNames(model.RoomColumnNames))
+	quoted, whitelist := quoteColumns(d.db, params, model.RoomColumnNames)
+	queryStr, args, err := quoted.ConvertToGormConditions(query.WithWhitelistNames(whitelist))
 	if err != nil {
 		return nil, 0, errors.New("query params error: " + err.Error())
 	}
@@ -184,7 +325,7 @@ func (d *roomDao) GetByColumns(ctx context.Context, params *query.Params) ([]*mo
 	}
 
 	records := []*model.Room{}
-	order, limit, offset := params.ConvertToPage()
+	order, limit, offset := quoted.ConvertToPage()
 	err = d.db.WithContext(ctx).Order(order).Limit(limit).Offset(offset).Where(queryStr, args...).Find(&records).Error
 	if err != nil {
 		return nil, 0, err
@@ -193,9 +334,67 @@ func (d *roomDao) GetByColumns(ctx context.Context, params *query.Params) ([]*mo
 	return records, total, err
 }
 
+// RestoreByID take a room out of the trash by id, database.ErrRecordNotFound if it is not in the trash
+func (d *roomDao) RestoreByID(ctx context.Context, id string) error {
+	result := d.db.WithContext(ctx).Unscoped().Model(&model.Room{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Updates(map[string]interface{}{"deleted_at": nil, "version": gorm.Expr("version + 1")})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return database.ErrRecordNotFound
+	}
+
+	// delete cache
+	_ = d.deleteCache(ctx, id)
+
+	notifyRoomChange(id)
+
+	return nil
+}
+
+// GetDeleted get a page of the rooms in the trash, the latest deleted first, page starts from 0
+func (d *roomDao) GetDeleted(ctx context.Context, page int, limit int) ([]*model.Room, int64, error) {
+	db := d.db.WithContext(ctx).Unscoped().Model(&model.Room{}).Where("deleted_at IS NOT NULL")
+
+	var total int64
+	err := db.Count(&total).Error
+	if err != nil || total == 0 {
+		return nil, total, err
+	}
+
+	records := []*model.Room{}
+	err = db.Order("deleted_at DESC").Limit(limit).Offset(page * limit).Find(&records).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return records, total, nil
+}
+
+// PurgeDeleted remove for good the rooms that were moved to the trash before the given time, with their
+// exits, which stay in the room exit table while a room is in the trash so that a restore brings them back
+func (d *roomDao) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	var n int64
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		purged := tx.Unscoped().Model(&model.Room{}).Select("id").Where("deleted_at < ?", before)
+		if err := tx.Where("room_id IN (?)", purged).Delete(&model.RoomExit{}).Error; err != nil {
+			return err
+		}
+		result := tx.Unscoped().Where("deleted_at < ?", before).Delete(&model.Room{})
+		n = result.RowsAffected
+		return result.Error
+	})
+	return n, err
+}
+
 // CreateByTx create a record in the database using the provided transaction
 func (d *roomDao) CreateByTx(ctx context.Context, tx *gorm.DB, table *model.Room) (string, error) {
 	err := tx.WithContext(ctx).Create(table).Error
+	if err == nil {
+		notifyRoomChangeByTx(ctx, table.ID)
+	}
 	return table.ID, err
 }
 
@@ -208,16 +407,62 @@ func (d *roomDao) DeleteByTx(ctx context.Context, tx *gorm.DB, id string) error
 
 	// delete cache
 	_ = d.deleteCache(ctx, id)
+	notifyRoomChangeByTx(ctx, id)
 
 	return nil
 }
 
 // UpdateByTx update a record by id in the database using the provided transaction
+// with the same version check as UpdateByID
 func (d *roomDao) UpdateByTx(ctx context.Context, tx *gorm.DB, table *model.Room) error {
 	err := d.updateDataByID(ctx, tx, table)
 
 	// delete cache
 	_ = d.deleteCache(ctx, table.ID)
+	if err == nil {
+		notifyRoomChangeByTx(ctx, table.ID)
+	}
+
+	return err
+}
+
+// PatchByTx update the fields of a room by id using the provided transaction, zero values included,
+// with the same version check as UpdateByID
+func (d *roomDao) PatchByTx(ctx context.Context, tx *gorm.DB, table *model.Room, fields []string) error {
+	err := d.patchDataByID(ctx, tx, table, fields)
+
+	// delete cache
+	_ = d.deleteCache(ctx, table.ID)
+	if err == nil {
+		notifyRoomChangeByTx(ctx, table.ID)
+	}
 
 	return err
 }
+
+// GetAllRooms read every room page by page through GetByColumns, for the whole world checks and tools
+func GetAllRooms(ctx context.Context, d RoomDao) ([]*model.Room, error) {
+	return getAllPages(ctx, d.GetByColumns)
+}
+
+// getAllPagesSize number of records read per query by getAllPages
+const getAllPagesSize = 100
+
+// getAllPages call a GetByColumns method page by page until all records are read
+func getAllPages[T any](ctx context.Context, getByColumns func(context.Context, *query.Params) ([]T, int64, error)) ([]T, error) {
+	var records []T
+	for page := 0; ; page++ {
+		list, _, err := getByColumns(ctx, &query.Params{
+			Page:  page,
+			Limit: getAllPagesSize,
+			Sort:  "ignore count",
+		})
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, list...)
+		if len(list) < getAllPagesSize {
+			return records, nil
+		}
+	}
+}
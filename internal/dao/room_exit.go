@@ -0,0 +1,303 @@
+package dao
+
+import (
+	"context"
+	"errors"
+
+	"golang.org/x/sync/singleflight"
+	"gorm.io/gorm"
+
+	"github.com/go-dev-frame/sponge/pkg/logger"
+	"github.com/go-dev-frame/sponge/pkg/sgorm/query"
+	"github.com/go-dev-frame/sponge/pkg/utils"
+
+	"fs/internal/cache"
+	"fs/internal/database"
+	"fs/internal/model"
+)
+
+var _ RoomExitDao = (*roomExitDao)(nil)
+
+// RoomExitDao defining the dao interface
+type RoomExitDao interface {
+	Create(ctx context.Context, table *model.RoomExit) error
+	DeleteByID(ctx context.Context, id uint64) error
+	UpdateByID(ctx context.Context, table *model.RoomExit) error
+	GetByID(ctx context.Context, id uint64) (*model.RoomExit, error)
+	GetByColumns(ctx context.Context, params *query.Params) ([]*model.RoomExit, int64, error)
+	GetByRoomID(ctx context.Context, roomID string) ([]*model.RoomExit, error)
+	GetByToRoomID(ctx context.Context, toRoomID string) ([]*model.RoomExit, error)
+
+	CreateByTx(ctx context.Context, tx *gorm.DB, table *model.RoomExit) (uint64, error)
+	DeleteByTx(ctx context.Context, tx *gorm.DB, id uint64) error
+	UpdateByTx(ctx context.Context, tx *gorm.DB, table *model.RoomExit) error
+	SaveByTx(ctx context.Context, tx *gorm.DB, table *model.RoomExit) error
+}
+
+type roomExitDao struct {
+	db    *gorm.DB
+	cache cache.RoomExitCache // if nil, the cache is not used.
+	sfg   *singleflight.Group // if cache is nil, the sfg is not used.
+}
+
+// NewRoomExitDao creating the dao interface
+func NewRoomExitDao(db *gorm.DB, xCache cache.RoomExitCache) RoomExitDao {
+	if xCache == nil {
+		return &roomExitDao{db: db}
+	}
+	return &roomExitDao{
+		db:    db,
+		cache: xCache,
+		sfg:   new(singleflight.Group),
+	}
+}
+
+func (d *roomExitDao) deleteCache(ctx context.Context, id uint64) error {
+	if d.cache != nil {
+		return d.cache.Del(ctx, id)
+	}
+	return nil
+}
+
+// Create a new room exit, insert the record and the id value is written back to the table
+func (d *roomExitDao) Create(ctx context.Context, table *model.RoomExit) error {
+	err := d.db.WithContext(ctx).Create(table).Error
+	if err == nil {
+		notifyRoomChange(table.RoomID)
+	}
+	return err
+}
+
+// DeleteByID delete a room exit by id
+func (d *roomExitDao) DeleteByID(ctx context.Context, id uint64) error {
+	roomID, err := roomIDOfExit(ctx, d.db, id)
+	if err != nil {
+		return err
+	}
+	err = d.db.WithContext(ctx).Where("id = ?", id).Delete(&model.RoomExit{}).Error
+	if err != nil {
+		return err
+	}
+
+	// delete cache
+	_ = d.deleteCache(ctx, id)
+	notifyRoomChange(roomID)
+
+	return nil
+}
+
+// UpdateByID update a room exit by id, support partial update
+func (d *roomExitDao) UpdateByID(ctx context.Context, table *model.RoomExit) error {
+	err := d.updateDataByID(ctx, d.db, table)
+
+	// delete cache
+	_ = d.deleteCache(ctx, table.ID)
+	if err == nil {
+		notifyRoomChange(table.RoomID)
+	}
+
+	return err
+}
+
+// roomIDOfExit the room of an exit, for the room change hooks of a delete by id. An exit that does
+// not exist has no room, deleting it is a no-op as it was.
+func roomIDOfExit(ctx context.Context, db *gorm.DB, id uint64) (string, error) {
+	var roomIDs []string
+	err := db.WithContext(ctx).Model(&model.RoomExit{}).Where("id = ?", id).Limit(1).Pluck("room_id", &roomIDs).Error
+	if err != nil || len(roomIDs) == 0 {
+		return "", err
+	}
+	return roomIDs[0], nil
+}
+
+func (d *roomExitDao) updateDataByID(ctx context.Context, db *gorm.DB, table *model.RoomExit) error {
+	if table.ID < 1 {
+		return errors.New("id cannot be 0")
+	}
+
+	update := map[string]interface{}{}
+
+	if table.RoomID != "" {
+		update["room_id"] = table.RoomID
+	}
+	if table.Direction != "" {
+		update["direction"] = table.Direction
+	}
+	if table.ToRoomID != "" {
+		update["to_room_id"] = table.ToRoomID
+	}
+	if table.Door != nil {
+		update["door"] = table.Door
+	}
+	if table.Locked != nil {
+		update["locked"] = table.Locked
+	}
+	if table.Hidden != nil {
+		update["hidden"] = table.Hidden
+	}
+	if table.Cost > 0 {
+		update["cost"] = table.Cost
+	}
+
+	return db.WithContext(ctx).Model(table).Updates(update).Error
+}
+
+// GetByID get a room exit by id
+func (d *roomExitDao) GetByID(ctx context.Context, id uint64) (*model.RoomExit, error) {
+	// no cache
+	if d.cache == nil {
+		record := &model.RoomExit{}
+		err := d.db.WithContext(ctx).Where("id = ?", id).First(record).Error
+		return record, err
+	}
+
+	// get from cache
+	record, err := d.cache.Get(ctx, id)
+	if err == nil {
+		return record, nil
+	}
+
+	// get from database
+	if errors.Is(err, database.ErrCacheNotFound) {
+		// for the same id, prevent high concurrent simultaneous access to database
+		val, err, _ := d.sfg.Do(utils.Uint64ToStr(id), func() (interface{}, error) { //nolint
+			table := &model.RoomExit{}
+			err = d.db.WithContext(ctx).Where("id = ?", id).First(table).Error
+			if err != nil {
+				if errors.Is(err, database.ErrRecordNotFound) {
+					// set placeholder cache to prevent cache penetration, default expiration time 10 minutes
+					if err = d.cache.SetPlaceholder(ctx, id); err != nil {
+						logger.Warn("cache.SetPlaceholder error", logger.Err(err), logger.Any("id", id))
+					}
+					return nil, database.ErrRecordNotFound
+				}
+				return nil, err
+			}
+			// set cache
+			if err = d.cache.Set(ctx, id, table, cache.RoomExitExpireTime); err != nil {
+				logger.Warn("cache.Set error", logger.Err(err), logger.Any("id", id))
+			}
+			return table, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		table, ok := val.(*model.RoomExit)
+		if !ok {
+			return nil, database.ErrRecordNotFound
+		}
+		return table, nil
+	}
+
+	if d.cache.IsPlaceholderErr(err) {
+		return nil, database.ErrRecordNotFound
+	}
+
+	return nil, err
+}
+
+// GetByColumns get a paginated list of room exits by custom conditions.
+// For more details, please refer to https://go-sponge.com/component/data/custom-page-query.html
+func (d *roomExitDao) GetByColumns(ctx context.Context, params *query.Params) ([]*model.RoomExit, int64, error) {
+	quoted, whitelist := quoteColumns(d.db, params, model.RoomExitColumnNames)
+	queryStr, args, err := quoted.ConvertToGormConditions(query.WithWhitelistNames(whitelist))
+	if err != nil {
+		return nil, 0, errors.New("query params error: " + err.Error())
+	}
+
+	var total int64
+	if params.Sort != "ignore count" { // determine if count is required
+		err = d.db.WithContext(ctx).Model(&model.RoomExit{}).Where(queryStr, args...).Count(&total).Error
+		if err != nil {
+			return nil, 0, err
+		}
+		if total == 0 {
+			return nil, total, nil
+		}
+	}
+
+	records := []*model.RoomExit{}
+	order, limit, offset := quoted.ConvertToPage()
+	err = d.db.WithContext(ctx).Order(order).Limit(limit).Offset(offset).Where(queryStr, args...).Find(&records).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return records, total, err
+}
+
+// GetByRoomID get the exits leading out of a room
+func (d *roomExitDao) GetByRoomID(ctx context.Context, roomID string) ([]*model.RoomExit, error) {
+	records := []*model.RoomExit{}
+	err := d.db.WithContext(ctx).Where("room_id = ?", roomID).Order("id").Find(&records).Error
+	return records, err
+}
+
+// GetByToRoomID get the exits leading into a room
+func (d *roomExitDao) GetByToRoomID(ctx context.Context, toRoomID string) ([]*model.RoomExit, error) {
+	records := []*model.RoomExit{}
+	err := d.db.WithContext(ctx).Where("to_room_id = ?", toRoomID).Order("id").Find(&records).Error
+	return records, err
+}
+
+// CreateByTx create a record in the database using the provided transaction
+func (d *roomExitDao) CreateByTx(ctx context.Context, tx *gorm.DB, table *model.RoomExit) (uint64, error) {
+	err := tx.WithContext(ctx).Create(table).Error
+	if err == nil {
+		notifyRoomChangeByTx(ctx, table.RoomID)
+	}
+	return table.ID, err
+}
+
+// DeleteByTx delete a record by id in the database using the provided transaction
+func (d *roomExitDao) DeleteByTx(ctx context.Context, tx *gorm.DB, id uint64) error {
+	roomID, err := roomIDOfExit(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	err = tx.WithContext(ctx).Where("id = ?", id).Delete(&model.RoomExit{}).Error
+	if err != nil {
+		return err
+	}
+
+	// delete cache
+	_ = d.deleteCache(ctx, id)
+	notifyRoomChangeByTx(ctx, roomID)
+
+	return nil
+}
+
+// UpdateByTx update a record by id in the database using the provided transaction
+func (d *roomExitDao) UpdateByTx(ctx context.Context, tx *gorm.DB, table *model.RoomExit) error {
+	err := d.updateDataByID(ctx, tx, table)
+
+	// delete cache
+	_ = d.deleteCache(ctx, table.ID)
+	if err == nil {
+		notifyRoomChangeByTx(ctx, table.RoomID)
+	}
+
+	return err
+}
+
+// SaveByTx write every column of a room exit by id using the provided transaction, zero values
+// included, e.g. a cost or a door that is reset
+func (d *roomExitDao) SaveByTx(ctx context.Context, tx *gorm.DB, table *model.RoomExit) error {
+	if table.ID < 1 {
+		return errors.New("id cannot be 0")
+	}
+	err := tx.WithContext(ctx).Model(table).Select("*").Omit("id").Updates(table).Error
+
+	// delete cache
+	_ = d.deleteCache(ctx, table.ID)
+	if err == nil {
+		notifyRoomChangeByTx(ctx, table.RoomID)
+	}
+
+	return err
+}
+
+// GetAllRoomExits read every room exit page by page through GetByColumns
+func GetAllRoomExits(ctx context.Context, d RoomExitDao) ([]*model.RoomExit, error) {
+	return getAllPages(ctx, d.GetByColumns)
+}
@@ -0,0 +1,301 @@
+package dao
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-dev-frame/sponge/pkg/gotest"
+	"github.com/go-dev-frame/sponge/pkg/sgorm/query"
+	"github.com/go-dev-frame/sponge/pkg/utils"
+	"github.com/stretchr/testify/assert"
+
+	"fs/internal/cache"
+	"fs/internal/database"
+	"fs/internal/model"
+)
+
+func newRoomExitDao() *gotest.Dao {
+	testData := &model.RoomExit{}
+	testData.ID = 1
+	testData.RoomID = "hall"
+	testData.Direction = "north"
+	testData.ToRoomID = "yard"
+
+	// init mock cache
+	//c := gotest.NewCache(map[string]interface{}{"no cache": testData}) // to test mysql, disable caching
+	c := gotest.NewCache(map[string]interface{}{utils.Uint64ToStr(testData.ID): testData})
+	c.ICache = cache.NewRoomExitCache(&database.CacheType{
+		CType: "redis",
+		Rdb:   c.RedisClient,
+	})
+
+	// init mock dao
+	d := gotest.NewDao(c, testData)
+	d.IDao = NewRoomExitDao(d.DB, c.ICache.(cache.RoomExitCache))
+
+	return d
+}
+
+func Test_roomExitDao_Create(t *testing.T) {
+	d := newRoomExitDao()
+	defer d.Close()
+	testData := d.TestData.(*model.RoomExit)
+
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectExec("INSERT INTO .*").
+		WithArgs(d.GetAnyArgs(testData)...).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	d.SQLMock.ExpectCommit()
+
+	var changed []string
+	unregister := OnRoomChange(func(roomID string) {
+		changed = append(changed, roomID)
+	})
+	defer unregister()
+
+	err := d.IDao.(RoomExitDao).Create(d.Ctx, testData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"hall"}, changed)
+}
+
+func Test_roomExitDao_DeleteByID(t *testing.T) {
+	d := newRoomExitDao()
+	defer d.Close()
+	testData := d.TestData.(*model.RoomExit)
+	expectedSQLForDeletion := "DELETE .*"
+
+	d.SQLMock.ExpectQuery("SELECT `room_id` FROM `room_exit` WHERE id = \\?").
+		WithArgs(testData.ID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"room_id"}).AddRow(testData.RoomID))
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectExec(expectedSQLForDeletion).
+		WithArgs(testData.ID).
+		WillReturnResult(sqlmock.NewResult(int64(testData.ID), 1))
+	d.SQLMock.ExpectCommit()
+
+	var changed []string
+	unregister := OnRoomChange(func(roomID string) {
+		changed = append(changed, roomID)
+	})
+	defer unregister()
+
+	err := d.IDao.(RoomExitDao).DeleteByID(d.Ctx, testData.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"hall"}, changed) // the room of the exit, read before the delete
+
+	// zero id error
+	err = d.IDao.(RoomExitDao).DeleteByID(d.Ctx, 0)
+	assert.Error(t, err)
+}
+
+func Test_roomExitDao_UpdateByID(t *testing.T) {
+	d := newRoomExitDao()
+	defer d.Close()
+	testData := d.TestData.(*model.RoomExit)
+
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectExec("UPDATE .*").
+		WithArgs(testData.Direction, testData.RoomID, testData.ToRoomID, testData.ID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	d.SQLMock.ExpectCommit()
+
+	err := d.IDao.(RoomExitDao).UpdateByID(d.Ctx, testData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// zero id error
+	err = d.IDao.(RoomExitDao).UpdateByID(d.Ctx, &model.RoomExit{})
+	assert.Error(t, err)
+}
+
+func Test_roomExitDao_GetByID(t *testing.T) {
+	d := newRoomExitDao()
+	defer d.Close()
+	testData := d.TestData.(*model.RoomExit)
+
+	// column names and corresponding data
+	rows := sqlmock.NewRows([]string{"id"}).
+		AddRow(testData.ID)
+
+	d.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(testData.ID, 1).
+		WillReturnRows(rows)
+
+	_, err := d.IDao.(RoomExitDao).GetByID(d.Ctx, testData.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = d.SQLMock.ExpectationsWereMet()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// error test
+	d.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(2).
+		WillReturnRows(rows)
+	_, err = d.IDao.(RoomExitDao).GetByID(d.Ctx, 2)
+	assert.Error(t, err)
+
+	d.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(3, 4).
+		WillReturnRows(rows)
+	_, err = d.IDao.(RoomExitDao).GetByID(d.Ctx, 4)
+	assert.Error(t, err)
+}
+
+func Test_roomExitDao_GetByColumns(t *testing.T) {
+	d := newRoomExitDao()
+	defer d.Close()
+	testData := d.TestData.(*model.RoomExit)
+
+	// column names and corresponding data
+	rows := sqlmock.NewRows([]string{"id"}).
+		AddRow(testData.ID)
+
+	d.SQLMock.ExpectQuery("SELECT .*").WillReturnRows(rows)
+
+	_, _, err := d.IDao.(RoomExitDao).GetByColumns(d.Ctx, &query.Params{
+		Page:  0,
+		Limit: 10,
+		Sort:  "ignore count", // ignore test count(*)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = d.SQLMock.ExpectationsWereMet()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// err test
+	_, _, err = d.IDao.(RoomExitDao).GetByColumns(d.Ctx, &query.Params{
+		Page:  0,
+		Limit: 10,
+		Columns: []query.Column{
+			{
+				Name:  "id",
+				Exp:   "<",
+				Value: 0,
+			},
+		},
+	})
+	assert.Error(t, err)
+
+	// error test
+	dao := &roomExitDao{}
+	_, _, err = dao.GetByColumns(context.Background(), &query.Params{Columns: []query.Column{{}}})
+	t.Log(err)
+}
+
+func Test_roomExitDao_GetByRoomID(t *testing.T) {
+	d := newRoomExitDao()
+	defer d.Close()
+	testData := d.TestData.(*model.RoomExit)
+
+	rows := sqlmock.NewRows([]string{"id", "room_id", "direction", "to_room_id"}).
+		AddRow(testData.ID, testData.RoomID, testData.Direction, testData.ToRoomID)
+	d.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(testData.RoomID).
+		WillReturnRows(rows)
+
+	exits, err := d.IDao.(RoomExitDao).GetByRoomID(d.Ctx, testData.RoomID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, exits, 1)
+	assert.Equal(t, testData.ToRoomID, exits[0].ToRoomID)
+
+	rows = sqlmock.NewRows([]string{"id", "room_id", "direction", "to_room_id"}).
+		AddRow(testData.ID, testData.RoomID, testData.Direction, testData.ToRoomID)
+	d.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(testData.ToRoomID).
+		WillReturnRows(rows)
+
+	exits, err = d.IDao.(RoomExitDao).GetByToRoomID(d.Ctx, testData.ToRoomID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, exits, 1)
+
+	err = d.SQLMock.ExpectationsWereMet()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_roomExitDao_CreateByTx(t *testing.T) {
+	d := newRoomExitDao()
+	defer d.Close()
+	testData := d.TestData.(*model.RoomExit)
+
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectExec("INSERT INTO .*").
+		WithArgs(d.GetAnyArgs(testData)...).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	d.SQLMock.ExpectCommit()
+
+	_, err := d.IDao.(RoomExitDao).CreateByTx(d.Ctx, d.DB, testData)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_roomExitDao_DeleteByTx(t *testing.T) {
+	d := newRoomExitDao()
+	defer d.Close()
+	testData := d.TestData.(*model.RoomExit)
+	expectedSQLForDeletion := "DELETE .*"
+
+	d.SQLMock.ExpectQuery("SELECT `room_id` FROM `room_exit` WHERE id = \\?").
+		WithArgs(testData.ID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"room_id"}).AddRow(testData.RoomID))
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectExec(expectedSQLForDeletion).
+		WithArgs(testData.ID).
+		WillReturnResult(sqlmock.NewResult(int64(testData.ID), 1))
+	d.SQLMock.ExpectCommit()
+
+	var changed []string
+	unregister := OnRoomChange(func(roomID string) {
+		changed = append(changed, roomID)
+	})
+	defer unregister()
+
+	// the change is held back until the transaction is committed
+	ctx, notify := CollectChanges(d.Ctx)
+	err := d.IDao.(RoomExitDao).DeleteByTx(ctx, d.DB, testData.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, changed)
+	notify()
+	assert.Equal(t, []string{"hall"}, changed)
+	notify()
+	assert.Len(t, changed, 1)
+}
+
+func Test_roomExitDao_UpdateByTx(t *testing.T) {
+	d := newRoomExitDao()
+	defer d.Close()
+	testData := d.TestData.(*model.RoomExit)
+
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectExec("UPDATE .*").
+		WithArgs(testData.Direction, testData.RoomID, testData.ToRoomID, testData.ID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	d.SQLMock.ExpectCommit()
+
+	err := d.IDao.(RoomExitDao).UpdateByTx(d.Ctx, d.DB, testData)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
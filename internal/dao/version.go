@@ -0,0 +1,35 @@
+package dao
+
+import (
+	"gorm.io/gorm"
+
+	"fs/internal/database"
+)
+
+// nextVersion the version a record gets when all its fields are written, 1 if the record does not exist,
+// model is an empty value of the table
+func nextVersion(tx *gorm.DB, model interface{}, id interface{}) (int, error) {
+	var versions []int
+	err := tx.Unscoped().Model(model).Where("id = ?", id).Pluck("version", &versions).Error
+	if err != nil || len(versions) == 0 {
+		return 1, err
+	}
+	return versions[0] + 1, nil
+}
+
+// patchByVersion write the fields of a record, zero values included, if the record is still at the version
+// that was read, version points to the Version field of table and is bumped on success
+func patchByVersion(db *gorm.DB, table interface{}, version *int, fields []string) error {
+	read := *version
+	*version = read + 1
+	result := db.Model(table).Select(append(fields, "Version")).Where("version = ?", read).Updates(table)
+	if result.Error != nil {
+		*version = read
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		*version = read
+		return database.ErrVersionConflict // changed or deleted since the version was read
+	}
+	return nil
+}
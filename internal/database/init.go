@@ -2,12 +2,15 @@
 package database
 
 import (
+	"errors"
 	"strings"
 	"sync"
 
 	"github.com/go-dev-frame/sponge/pkg/sgorm"
+	"gorm.io/gorm"
 
 	"fs/internal/config"
+	"fs/internal/revision"
 )
 
 var (
@@ -15,6 +18,10 @@ var (
 	gdbOnce sync.Once
 
 	ErrRecordNotFound = sgorm.ErrRecordNotFound
+	// ErrVersionConflict the record has been changed since its version was read
+	ErrVersionConflict = errors.New("record version conflict")
+	// ErrDuplicatedKey a unique index refused the record, the errors of the drivers are translated to it
+	ErrDuplicatedKey = gorm.ErrDuplicatedKey
 )
 
 // InitDB connect database
@@ -23,10 +30,20 @@ func InitDB() {
 	switch strings.ToLower(dbDriver) {
 	case sgorm.DBDriverMysql, sgorm.DBDriverTidb:
 		gdb = InitMysql()
+	case sgorm.DBDriverPostgresql:
+		gdb = InitPostgresql()
+	case sgorm.DBDriverSqlite:
+		gdb = InitSqlite()
 	default:
 		panic("InitDB error, please modify the correct 'database' configuration at yaml file. " +
 			"Refer to https://fs/blob/main/configs/fs.yml#L85")
 	}
+
+	// record the changes of rooms, mobs and items
+	if err := gdb.Use(revision.Plugin{}); err != nil {
+		panic("init revision plugin error: " + err.Error())
+	}
+	gdb.Config.TranslateError = true // see ErrDuplicatedKey
 }
 
 // GetDB get db
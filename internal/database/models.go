@@ -0,0 +1,18 @@
+package database
+
+import (
+	"fs/internal/model"
+)
+
+// Models the models that have a table, the tables are created by the migrations of package migrate
+var Models = []interface{}{
+	&model.Room{},
+	&model.RoomExit{},
+	&model.Mob{},
+	&model.Item{},
+	&model.ItemInstance{},
+	&model.Spawn{},
+	&model.Account{},
+	&model.Character{},
+	&model.Revision{},
+}
@@ -0,0 +1,49 @@
+package database
+
+import (
+	"time"
+
+	"github.com/go-dev-frame/sponge/pkg/logger"
+	"github.com/go-dev-frame/sponge/pkg/sgorm"
+	"github.com/go-dev-frame/sponge/pkg/sgorm/postgresql"
+	"github.com/go-dev-frame/sponge/pkg/utils"
+
+	"fs/internal/config"
+)
+
+// InitPostgresql connect postgresql
+func InitPostgresql() *sgorm.DB {
+	pgCfg := config.Get().Database.Postgresql
+	var opts []postgresql.Option
+	if pgCfg.EnableLog {
+		opts = append(opts,
+			postgresql.WithLogging(logger.Get()),
+			postgresql.WithLogRequestIDKey("request_id"),
+		)
+	}
+
+	if config.Get().App.EnableTrace {
+		opts = append(opts, postgresql.WithEnableTrace())
+	}
+
+	dsn := utils.AdaptivePostgresqlDsn(pgCfg.Dsn)
+	db, err := OpenPostgresql(dsn, opts...)
+	if err != nil {
+		panic("init postgresql error: " + err.Error())
+	}
+
+	// the pool options are not applied by postgresql.Init
+	sqlDB, err := db.DB()
+	if err != nil {
+		panic("init postgresql error: " + err.Error())
+	}
+	sqlDB.SetMaxIdleConns(pgCfg.MaxIdleConns)
+	sqlDB.SetMaxOpenConns(pgCfg.MaxOpenConns)
+	sqlDB.SetConnMaxLifetime(time.Duration(pgCfg.ConnMaxLifetime) * time.Minute)
+	return db
+}
+
+// OpenPostgresql connect postgresql with a dsn of the form "host=... port=... user=... password=... dbname=..."
+func OpenPostgresql(dsn string, opts ...postgresql.Option) (*sgorm.DB, error) {
+	return postgresql.Init(dsn, opts...)
+}
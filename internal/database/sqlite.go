@@ -0,0 +1,73 @@
+package database
+
+import (
+	"time"
+
+	"github.com/go-dev-frame/sponge/pkg/logger"
+	"github.com/go-dev-frame/sponge/pkg/sgorm"
+	"github.com/go-dev-frame/sponge/pkg/sgorm/sqlite"
+	"github.com/go-dev-frame/sponge/pkg/utils"
+
+	"fs/internal/config"
+	"fs/internal/migrate"
+)
+
+// sqliteMemory the db file of a database that lives in memory until the service stops
+const sqliteMemory = ":memory:"
+
+// InitSqlite connect sqlite
+func InitSqlite() *sgorm.DB {
+	sqliteCfg := config.Get().Database.Sqlite
+	var opts []sqlite.Option
+	if sqliteCfg.EnableLog {
+		opts = append(opts,
+			sqlite.WithLogging(logger.Get()),
+			sqlite.WithLogRequestIDKey("request_id"),
+		)
+	}
+
+	if config.Get().App.EnableTrace {
+		opts = append(opts, sqlite.WithEnableTrace())
+	}
+
+	db, err := OpenSqlite(utils.AdaptiveSqlite(sqliteCfg.DBFile), opts...)
+	if err != nil {
+		panic("init sqlite error: " + err.Error())
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		panic("init sqlite error: " + err.Error())
+	}
+	if sqliteCfg.DBFile != sqliteMemory {
+		sqlDB.SetMaxIdleConns(sqliteCfg.MaxIdleConns)
+		sqlDB.SetMaxOpenConns(sqliteCfg.MaxOpenConns)
+		sqlDB.SetConnMaxLifetime(time.Duration(sqliteCfg.ConnMaxLifetime) * time.Minute)
+	}
+	return db
+}
+
+// OpenSqlite open a sqlite database file, or a database in memory for the file ":memory:".
+// A database in memory starts empty, the migrations are applied to it, and it keeps a single
+// connection as each connection would see another database.
+func OpenSqlite(dbFile string, opts ...sqlite.Option) (*sgorm.DB, error) {
+	db, err := sqlite.Init(dbFile, opts...)
+	if err != nil {
+		return nil, err
+	}
+	db.Config.TranslateError = true // see ErrDuplicatedKey
+	if dbFile != sqliteMemory {
+		return db, nil
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(1)
+	sqlDB.SetConnMaxLifetime(0)
+	if _, err = migrate.Up(db, 0); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
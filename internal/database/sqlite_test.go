@@ -0,0 +1,50 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-dev-frame/sponge/pkg/sgorm"
+
+	"fs/internal/migrate"
+	"fs/internal/model"
+)
+
+func TestOpenSqlite(t *testing.T) {
+	dbFile := filepath.Join(t.TempDir(), "fs.db")
+
+	// a file is migrated by fs migrate or at startup
+	db, err := OpenSqlite(dbFile)
+	require.NoError(t, err)
+	assert.False(t, db.Migrator().HasTable(&model.Mob{}))
+	_, err = migrate.Up(db, 0)
+	require.NoError(t, err)
+	mob := &model.Mob{MobID: "rat", MobName: "rat", MobCname: "老鼠"}
+	require.NoError(t, db.Create(mob).Error)
+	assert.Equal(t, uint64(1), mob.ID)
+	require.NoError(t, sgorm.CloseDB(db))
+
+	// the rows are kept when the file is opened again
+	db, err = OpenSqlite(dbFile)
+	require.NoError(t, err)
+	defer sgorm.CloseDB(db) //nolint
+	var count int64
+	require.NoError(t, db.Model(&model.Mob{}).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+
+	mob = &model.Mob{MobID: "cat", MobName: "cat", MobCname: "貓"}
+	require.NoError(t, db.Create(mob).Error)
+	assert.Equal(t, uint64(2), mob.ID)
+}
+
+func TestOpenSqlite_memory(t *testing.T) {
+	db, err := OpenSqlite(sqliteMemory)
+	require.NoError(t, err)
+	defer sgorm.CloseDB(db) //nolint
+	for _, m := range Models {
+		assert.True(t, db.Migrator().HasTable(m))
+	}
+}
@@ -0,0 +1,19 @@
+package ecode
+
+import (
+	"github.com/go-dev-frame/sponge/pkg/errcode"
+)
+
+// auth business-level http error codes.
+// the authNO value range is 1~999, if the same error code is used, it will cause panic.
+var (
+	authNO       = 6
+	authName     = "auth"
+	authBaseCode = errcode.HCode(authNO)
+
+	ErrLoginAuth          = errcode.NewError(authBaseCode+1, "wrong username or password")
+	ErrSetRoleAuth        = errcode.NewError(authBaseCode+2, "failed to set the account role")
+	ErrGenerateTokensAuth = errcode.NewError(authBaseCode+3, "failed to generate tokens")
+
+	// error codes are globally unique, adding 1 to the previous error code
+)
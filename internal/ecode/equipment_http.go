@@ -0,0 +1,19 @@
+package ecode
+
+import (
+	"github.com/go-dev-frame/sponge/pkg/errcode"
+)
+
+// equipment business-level http error codes.
+// the equipmentNO value range is 1~999, if the same error code is used, it will cause panic.
+var (
+	equipmentNO       = 5
+	equipmentName     = "equipment"
+	equipmentBaseCode = errcode.HCode(equipmentNO)
+
+	ErrPreviewEquipment = errcode.NewError(equipmentBaseCode+1, "failed to preview "+equipmentName)
+	ErrEquipmentItem    = errcode.NewError(equipmentBaseCode+2, equipmentName+" item does not exist")
+	ErrEquipmentSlot    = errcode.NewError(equipmentBaseCode+3, equipmentName+" item cannot be worn")
+
+	// error codes are globally unique, adding 1 to the previous error code
+)
@@ -16,6 +16,9 @@ var (
 	ErrUpdateByIDItem = errcode.NewError(itemBaseCode+3, "failed to update "+itemName)
 	ErrGetByIDItem    = errcode.NewError(itemBaseCode+4, "failed to get "+itemName+" details")
 	ErrListItem       = errcode.NewError(itemBaseCode+5, "failed to list of "+itemName)
+	ErrListByIDsItem  = errcode.NewError(itemBaseCode+6, "failed to list by ids of "+itemName)
+	ErrPatchByIDItem  = errcode.NewError(itemBaseCode+7, "failed to patch "+itemName)
+	ErrRulesItem      = errcode.NewError(itemBaseCode+8, itemName+" breaks the validation rules, see the errors of the fields")
 
 	// error codes are globally unique, adding 1 to the previous error code
 )
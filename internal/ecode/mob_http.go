@@ -16,6 +16,9 @@ var (
 	ErrUpdateByIDMob = errcode.NewError(mobBaseCode+3, "failed to update "+mobName)
 	ErrGetByIDMob    = errcode.NewError(mobBaseCode+4, "failed to get "+mobName+" details")
 	ErrListMob       = errcode.NewError(mobBaseCode+5, "failed to list of "+mobName)
+	ErrListByIDsMob  = errcode.NewError(mobBaseCode+6, "failed to list by ids of "+mobName)
+	ErrPatchByIDMob  = errcode.NewError(mobBaseCode+7, "failed to patch "+mobName)
+	ErrRulesMob      = errcode.NewError(mobBaseCode+8, mobName+" breaks the validation rules, see the errors of the fields")
 
 	// error codes are globally unique, adding 1 to the previous error code
 )
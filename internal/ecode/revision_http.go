@@ -0,0 +1,20 @@
+package ecode
+
+import (
+	"github.com/go-dev-frame/sponge/pkg/errcode"
+)
+
+// revision business-level http error codes.
+// the revisionNO value range is 1~999, if the same error code is used, it will cause panic.
+var (
+	revisionNO       = 8
+	revisionName     = "revision"
+	revisionBaseCode = errcode.HCode(revisionNO)
+
+	ErrListRevision    = errcode.NewError(revisionBaseCode+1, "failed to list "+revisionName+"s")
+	ErrDiffRevision    = errcode.NewError(revisionBaseCode+2, "failed to diff "+revisionName+"s")
+	ErrRestoreRevision = errcode.NewError(revisionBaseCode+3, "failed to restore "+revisionName)
+	ErrRevisionDeleted = errcode.NewError(revisionBaseCode+4, "the "+revisionName+" deleted the record, there is nothing to restore")
+
+	// error codes are globally unique, adding 1 to the previous error code
+)
@@ -0,0 +1,25 @@
+package ecode
+
+import (
+	"github.com/go-dev-frame/sponge/pkg/errcode"
+)
+
+// roomExit business-level http error codes.
+// the roomExitNO value range is 1~999, if the same error code is used, it will cause panic.
+var (
+	roomExitNO       = 2
+	roomExitName     = "room exit"
+	roomExitBaseCode = errcode.HCode(roomExitNO)
+
+	ErrCreateRoomExit     = errcode.NewError(roomExitBaseCode+1, "failed to create "+roomExitName)
+	ErrDeleteByIDRoomExit = errcode.NewError(roomExitBaseCode+2, "failed to delete "+roomExitName)
+	ErrUpdateByIDRoomExit = errcode.NewError(roomExitBaseCode+3, "failed to update "+roomExitName)
+	ErrGetByIDRoomExit    = errcode.NewError(roomExitBaseCode+4, "failed to get "+roomExitName+" details")
+	ErrListRoomExit       = errcode.NewError(roomExitBaseCode+5, "failed to list of "+roomExitName)
+	ErrRoomExitDirection  = errcode.NewError(roomExitBaseCode+6, "invalid "+roomExitName+" direction")
+	ErrRoomExitTarget     = errcode.NewError(roomExitBaseCode+7, roomExitName+" target room does not exist")
+	ErrRoomExitExists     = errcode.NewError(roomExitBaseCode+8, roomExitName+" already exists in this direction")
+	ErrMigrateRoomWay     = errcode.NewError(roomExitBaseCode+9, "failed to migrate room way to "+roomExitName)
+
+	// error codes are globally unique, adding 1 to the previous error code
+)
@@ -16,6 +16,8 @@ var (
 	ErrUpdateByIDRoom = errcode.NewError(roomBaseCode+3, "failed to update "+roomName)
 	ErrGetByIDRoom    = errcode.NewError(roomBaseCode+4, "failed to get "+roomName+" details")
 	ErrListRoom       = errcode.NewError(roomBaseCode+5, "failed to list of "+roomName)
+	ErrListByIDsRoom  = errcode.NewError(roomBaseCode+6, "failed to list by ids of "+roomName)
+	ErrPatchByIDRoom  = errcode.NewError(roomBaseCode+7, "failed to patch "+roomName)
 
 	// error codes are globally unique, adding 1 to the previous error code
 )
@@ -0,0 +1,20 @@
+package ecode
+
+import (
+	"github.com/go-dev-frame/sponge/pkg/errcode"
+)
+
+// sim business-level http error codes.
+// the simNO value range is 1~999, if the same error code is used, it will cause panic.
+var (
+	simNO       = 7
+	simName     = "sim"
+	simBaseCode = errcode.HCode(simNO)
+
+	ErrCombatSim    = errcode.NewError(simBaseCode+1, "failed to simulate combat")
+	ErrSimMob       = errcode.NewError(simBaseCode+2, simName+" mob does not exist")
+	ErrSimItem      = errcode.NewError(simBaseCode+3, simName+" item does not exist")
+	ErrSimEquipment = errcode.NewError(simBaseCode+4, simName+" item cannot be worn")
+
+	// error codes are globally unique, adding 1 to the previous error code
+)
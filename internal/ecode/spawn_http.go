@@ -0,0 +1,24 @@
+package ecode
+
+import (
+	"github.com/go-dev-frame/sponge/pkg/errcode"
+)
+
+// spawn business-level http error codes.
+// the spawnNO value range is 1~999, if the same error code is used, it will cause panic.
+var (
+	spawnNO       = 4
+	spawnName     = "spawn"
+	spawnBaseCode = errcode.HCode(spawnNO)
+
+	ErrCreateSpawn     = errcode.NewError(spawnBaseCode+1, "failed to create "+spawnName)
+	ErrDeleteByIDSpawn = errcode.NewError(spawnBaseCode+2, "failed to delete "+spawnName)
+	ErrUpdateByIDSpawn = errcode.NewError(spawnBaseCode+3, "failed to update "+spawnName)
+	ErrGetByIDSpawn    = errcode.NewError(spawnBaseCode+4, "failed to get "+spawnName+" details")
+	ErrListSpawn       = errcode.NewError(spawnBaseCode+5, "failed to list of "+spawnName)
+	ErrListByIDsSpawn  = errcode.NewError(spawnBaseCode+6, "failed to list by ids of "+spawnName)
+	ErrSpawnRoom       = errcode.NewError(spawnBaseCode+7, spawnName+" room does not exist")
+	ErrSpawnMob        = errcode.NewError(spawnBaseCode+8, spawnName+" mob prototype does not exist")
+
+	// error codes are globally unique, adding 1 to the previous error code
+)
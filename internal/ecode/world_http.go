@@ -0,0 +1,22 @@
+package ecode
+
+import (
+	"github.com/go-dev-frame/sponge/pkg/errcode"
+)
+
+// world business-level http error codes.
+// the worldNO value range is 1~999, if the same error code is used, it will cause panic.
+var (
+	worldNO       = 3
+	worldName     = "world"
+	worldBaseCode = errcode.HCode(worldNO)
+
+	ErrValidateWorld = errcode.NewError(worldBaseCode+1, "failed to validate "+worldName)
+	ErrNoRoomPath    = errcode.NewError(worldBaseCode+2, "no path between the rooms")
+	ErrExportWorld   = errcode.NewError(worldBaseCode+3, "failed to export "+worldName)
+	ErrImportWorld   = errcode.NewError(worldBaseCode+4, "failed to import "+worldName)
+	ErrWorldConflict = errcode.NewError(worldBaseCode+5, "records of the bundle already exist")
+	ErrWorldBundle   = errcode.NewError(worldBaseCode+6, "invalid world bundle")
+
+	// error codes are globally unique, adding 1 to the previous error code
+)
@@ -0,0 +1,75 @@
+// Package equip holds the rules of what items can be worn where and how the
+// bonuses of the worn items add up, it does not depend on storage.
+package equip
+
+import (
+	"strings"
+)
+
+// Classifier the kind of an item, the one letter value is stored in item.classifier
+type Classifier string
+
+// item classifiers
+const (
+	Weapon     Classifier = "w"
+	Shield     Classifier = "s"
+	Helmet     Classifier = "h"
+	Armour     Classifier = "a"
+	Gloves     Classifier = "g"
+	Leggings   Classifier = "l"
+	Boots      Classifier = "b"
+	Necklace   Classifier = "n"
+	Ring       Classifier = "r"
+	Consumable Classifier = "c"
+	Other      Classifier = "o" // also used for items without a classifier
+)
+
+// Classifiers all classifiers, keep in sync with the oneof rule of types.CreateItemRequest.Classifier
+var Classifiers = []Classifier{Weapon, Shield, Helmet, Armour, Gloves, Leggings, Boots, Necklace, Ring, Consumable, Other}
+
+var classifierNames = map[Classifier]string{
+	Weapon:     "weapon",
+	Shield:     "shield",
+	Helmet:     "helmet",
+	Armour:     "armour",
+	Gloves:     "gloves",
+	Leggings:   "leggings",
+	Boots:      "boots",
+	Necklace:   "necklace",
+	Ring:       "ring",
+	Consumable: "consumable",
+	Other:      "other",
+}
+
+// ParseClassifier parse the stored letter or the name of a classifier, e.g. "w", "weapon",
+// an empty value is Other
+func ParseClassifier(s string) (Classifier, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return Other, true
+	}
+	if _, ok := classifierNames[Classifier(s)]; ok {
+		return Classifier(s), true
+	}
+	for c, name := range classifierNames {
+		if name == s {
+			return c, true
+		}
+	}
+	return "", false
+}
+
+// Name the name of the classifier, e.g. "weapon"
+func (c Classifier) Name() string {
+	return classifierNames[c]
+}
+
+// Slots the slots an item of this kind can be worn in, empty if it cannot be worn
+func (c Classifier) Slots() []Slot {
+	return classifierSlots[c]
+}
+
+// Equippable check if an item of this kind can be worn
+func (c Classifier) Equippable() bool {
+	return len(classifierSlots[c]) > 0
+}
@@ -0,0 +1,73 @@
+package equip
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"fs/internal/model"
+)
+
+func TestParseClassifier(t *testing.T) {
+	c, ok := ParseClassifier("W")
+	assert.True(t, ok)
+	assert.Equal(t, Weapon, c)
+	c, ok = ParseClassifier("ring")
+	assert.True(t, ok)
+	assert.Equal(t, Ring, c)
+	c, ok = ParseClassifier("")
+	assert.True(t, ok)
+	assert.Equal(t, Other, c)
+	_, ok = ParseClassifier("x")
+	assert.False(t, ok)
+
+	assert.True(t, Ring.Equippable())
+	assert.False(t, Consumable.Equippable())
+	for _, c := range Classifiers {
+		assert.NotEmpty(t, c.Name())
+	}
+}
+
+func TestOutfit(t *testing.T) {
+	sword := &model.Item{ID: 1, Classifier: "w", Attack: 10, Str: 1}
+	axe := &model.Item{ID: 2, Classifier: "w", Attack: 12}
+	ring1 := &model.Item{ID: 3, Classifier: "r", Kar: 2}
+	ring2 := &model.Item{ID: 4, Classifier: "r", Kar: 3, Mp: 5}
+	ring3 := &model.Item{ID: 5, Classifier: "r"}
+	potion := &model.Item{ID: 6, Classifier: "c", Hp: 50}
+	plate := &model.Item{ID: 7, Classifier: "a", Defence: 8, Dodge: -2}
+
+	o, err := NewOutfit([]*model.Item{sword, ring1, ring2, plate})
+	require.NoError(t, err)
+	assert.Equal(t, sword, o[SlotWeapon])
+	assert.Equal(t, ring1, o[SlotRing1])
+	assert.Equal(t, ring2, o[SlotRing2])
+
+	base := Stats{Hp: 100, Attack: 5, Dodge: 3}
+	assert.Equal(t, Stats{Hp: 100, Mp: 5, Attack: 15, Defence: 8, Dodge: 1, Str: 1, Kar: 5}, Effective(base, o))
+
+	_, err = o.Wear(axe)
+	assert.True(t, errors.Is(err, ErrSlotTaken))
+	_, err = o.Wear(ring3)
+	assert.True(t, errors.Is(err, ErrSlotTaken))
+	_, err = o.Wear(potion)
+	assert.True(t, errors.Is(err, ErrNotEquippable))
+	_, err = o.Wear(&model.Item{ID: 8, Classifier: "?"})
+	assert.True(t, errors.Is(err, ErrUnknownClassifier))
+
+	_, err = NewOutfit([]*model.Item{sword, axe})
+	assert.Error(t, err)
+}
+
+func TestDress(t *testing.T) {
+	o := Dress([]*model.Item{
+		{ID: 1, Classifier: "w", Attack: 5},
+		{ID: 2, Classifier: "w", Attack: 9}, // second weapon, left out
+		{ID: 3, Classifier: "c", Hp: 50},    // consumable, left out
+		{ID: 4, Classifier: "b", Dodge: 2},
+	})
+	assert.Len(t, o, 2)
+	assert.Equal(t, Stats{Attack: 5, Dodge: 2}, o.Bonus())
+}
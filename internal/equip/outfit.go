@@ -0,0 +1,97 @@
+package equip
+
+import (
+	"errors"
+	"fmt"
+
+	"fs/internal/model"
+)
+
+// Slot where on the body an item is worn
+type Slot string
+
+// equipment slots
+const (
+	SlotWeapon  Slot = "weapon"
+	SlotOffhand Slot = "offhand"
+	SlotHead    Slot = "head"
+	SlotBody    Slot = "body"
+	SlotHands   Slot = "hands"
+	SlotLegs    Slot = "legs"
+	SlotFeet    Slot = "feet"
+	SlotNeck    Slot = "neck"
+	SlotRing1   Slot = "ring1"
+	SlotRing2   Slot = "ring2"
+)
+
+// Slots all slots in display order
+var Slots = []Slot{SlotWeapon, SlotOffhand, SlotHead, SlotBody, SlotHands, SlotLegs, SlotFeet, SlotNeck, SlotRing1, SlotRing2}
+
+var classifierSlots = map[Classifier][]Slot{
+	Weapon:   {SlotWeapon},
+	Shield:   {SlotOffhand},
+	Helmet:   {SlotHead},
+	Armour:   {SlotBody},
+	Gloves:   {SlotHands},
+	Leggings: {SlotLegs},
+	Boots:    {SlotFeet},
+	Necklace: {SlotNeck},
+	Ring:     {SlotRing1, SlotRing2},
+}
+
+// equip errors
+var (
+	ErrUnknownClassifier = errors.New("unknown item classifier")
+	ErrNotEquippable     = errors.New("item cannot be worn")
+	ErrSlotTaken         = errors.New("no free slot for item")
+)
+
+// Outfit the items worn in each slot
+type Outfit map[Slot]*model.Item
+
+// Wear put an item in the first free slot of its kind, the slot is returned
+func (o Outfit) Wear(item *model.Item) (Slot, error) {
+	c, ok := ParseClassifier(item.Classifier)
+	if !ok {
+		return "", fmt.Errorf("%w %q of item %d", ErrUnknownClassifier, item.Classifier, item.ID)
+	}
+	if !c.Equippable() {
+		return "", fmt.Errorf("%w: item %d is %s", ErrNotEquippable, item.ID, c.Name())
+	}
+	for _, slot := range c.Slots() {
+		if o[slot] == nil {
+			o[slot] = item
+			return slot, nil
+		}
+	}
+	return "", fmt.Errorf("%w %d, %s is already worn", ErrSlotTaken, item.ID, c.Name())
+}
+
+// NewOutfit wear the items in order, it fails at the first item that cannot be worn
+func NewOutfit(items []*model.Item) (Outfit, error) {
+	o := Outfit{}
+	for _, item := range items {
+		if _, err := o.Wear(item); err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
+
+// Bonus the sum of the stats of the worn items
+func (o Outfit) Bonus() Stats {
+	var s Stats
+	for _, item := range o {
+		s = s.Add(StatsOf(item))
+	}
+	return s
+}
+
+// Dress wear the items in order, items that cannot be worn or find no free slot are left out
+func Dress(items []*model.Item) Outfit {
+	o := Outfit{}
+	for _, item := range items {
+		_, _ = o.Wear(item)
+	}
+	return o
+}
@@ -0,0 +1,62 @@
+package equip
+
+import (
+	"fs/internal/model"
+)
+
+// Stats the stats an item adds to its wearer, and the stats of a character
+type Stats struct {
+	Hp      int `json:"hp"`
+	Mp      int `json:"mp"`
+	Attack  int `json:"attack"`
+	Defence int `json:"defence"`
+	Dodge   int `json:"dodge"`
+	Str     int `json:"str"`
+	Cor     int `json:"cor"`
+	Inte    int `json:"inte"`
+	Dex     int `json:"dex"`
+	Con     int `json:"con"`
+	Kar     int `json:"kar"`
+}
+
+// StartingStats the stats a new character starts with
+var StartingStats = Stats{Hp: 100, Mp: 100, Str: 10, Cor: 10, Inte: 10, Dex: 10, Con: 10, Kar: 10}
+
+// StatsOf the stat bonuses of an item
+func StatsOf(item *model.Item) Stats {
+	return Stats{
+		Hp:      item.Hp,
+		Mp:      item.Mp,
+		Attack:  item.Attack,
+		Defence: item.Defence,
+		Dodge:   item.Dodge,
+		Str:     item.Str,
+		Cor:     item.Cor,
+		Inte:    item.Inte,
+		Dex:     item.Dex,
+		Con:     item.Con,
+		Kar:     item.Kar,
+	}
+}
+
+// Add the sum of two stats
+func (s Stats) Add(o Stats) Stats {
+	return Stats{
+		Hp:      s.Hp + o.Hp,
+		Mp:      s.Mp + o.Mp,
+		Attack:  s.Attack + o.Attack,
+		Defence: s.Defence + o.Defence,
+		Dodge:   s.Dodge + o.Dodge,
+		Str:     s.Str + o.Str,
+		Cor:     s.Cor + o.Cor,
+		Inte:    s.Inte + o.Inte,
+		Dex:     s.Dex + o.Dex,
+		Con:     s.Con + o.Con,
+		Kar:     s.Kar + o.Kar,
+	}
+}
+
+// Effective the stats of a character wearing the outfit
+func Effective(base Stats, o Outfit) Stats {
+	return base.Add(o.Bonus())
+}
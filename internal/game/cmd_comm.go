@@ -0,0 +1,35 @@
+package game
+
+import (
+	"context"
+)
+
+func init() {
+	registerCommand(&Command{
+		Name:  "say",
+		Usage: "say <message>",
+		Help:  "Say something to everyone in the room, ' is a shortcut.",
+		Fn:    cmdSay,
+	})
+	registerCommand(&Command{
+		Name:  "quit",
+		Usage: "quit",
+		Help:  "Leave the game.",
+		Fn:    cmdQuit,
+	})
+}
+
+func cmdSay(_ context.Context, s *Session, args string) error {
+	if args == "" {
+		s.Println("Say what?")
+		return nil
+	}
+	s.Printf("You say, \"%s\"\n", args)
+	s.World().Broadcast(s.RoomID(), s.name+" says, \""+args+"\"", s)
+	return nil
+}
+
+func cmdQuit(_ context.Context, s *Session, _ string) error {
+	s.Println("Goodbye.")
+	return errQuit
+}
@@ -0,0 +1,101 @@
+package game
+
+import (
+	"context"
+	"errors"
+
+	"fs/internal/combat"
+	"fs/internal/database"
+	"fs/internal/model"
+	"fs/internal/world"
+)
+
+func init() {
+	registerCommand(&Command{
+		Name:    "kill",
+		Aliases: []string{"k"},
+		Usage:   "kill <target>",
+		Help:    "Attack a mob in the room, the fight goes on every heartbeat until one of you dies.",
+		Fn:      cmdKill,
+	})
+	registerCommand(&Command{
+		Name:  "flee",
+		Usage: "flee",
+		Help:  "Run out of a fight through a random exit.",
+		Fn:    cmdFlee,
+	})
+}
+
+func cmdKill(ctx context.Context, s *Session, args string) error {
+	if args == "" {
+		s.Println("Kill whom?")
+		return nil
+	}
+	roomID := s.RoomID()
+	mob := findMob(s.World().MobsIn(roomID), args)
+	if mob == nil {
+		s.Println("You do not see that here.")
+		return nil
+	}
+	title := mobTitle(mob.Proto)
+	if !combat.Attackable(mob.Proto) {
+		s.Printf("You cannot attack %s.\n", title)
+		return nil
+	}
+	if s.World().Fighting(s) == mob {
+		s.Printf("You are already fighting %s.\n", title)
+		return nil
+	}
+
+	stats, err := s.World().mobStats(ctx, mob.Proto)
+	if err != nil {
+		return err
+	}
+	if !s.World().attack(s, mob, stats) {
+		s.Println("You do not see that here.")
+		return nil
+	}
+	s.Printf("You attack %s!\n", title)
+	s.World().Broadcast(roomID, s.name+" attacks "+title+"!", s)
+	return nil
+}
+
+func cmdFlee(ctx context.Context, s *Session, _ string) error {
+	if s.World().Fighting(s) == nil {
+		s.Println("You are not fighting anyone.")
+		return nil
+	}
+
+	room, err := s.World().Room(ctx, s.RoomID())
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			s.Println("There is nowhere to run!")
+			return nil
+		}
+		return err
+	}
+	exits, err := s.World().RoomExits(ctx, room)
+	if err != nil {
+		return err
+	}
+	open := openExits(exits)
+	if len(open) == 0 {
+		s.Println("There is nowhere to run!")
+		return nil
+	}
+
+	d := world.Direction(open[s.World().random(len(open))].Direction)
+	s.Printf("You flee %s!\n", d)
+	return move(ctx, s, d)
+}
+
+// openExits the exits that are neither locked nor hidden
+func openExits(exits []*model.RoomExit) []*model.RoomExit {
+	var open []*model.RoomExit
+	for _, exit := range exits {
+		if !isSet(exit.Locked) && !isSet(exit.Hidden) {
+			open = append(open, exit)
+		}
+	}
+	return open
+}
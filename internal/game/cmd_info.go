@@ -0,0 +1,195 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+
+	"fs/internal/database"
+	"fs/internal/model"
+)
+
+func init() {
+	registerCommand(&Command{
+		Name:    "look",
+		Aliases: []string{"l"},
+		Usage:   "look [target]",
+		Help:    "Show the room you are in, or look at someone here.",
+		Fn:      cmdLook,
+	})
+	registerCommand(&Command{
+		Name:  "map",
+		Usage: "map [radius]",
+		Help:  "Draw the rooms around you, you are [*], rooms with stairs show ^ v or +.",
+		Fn:    cmdMap,
+	})
+	registerCommand(&Command{
+		Name:  "who",
+		Usage: "who",
+		Help:  "List the players online.",
+		Fn:    cmdWho,
+	})
+	registerCommand(&Command{
+		Name:    "help",
+		Aliases: []string{"?"},
+		Usage:   "help [command]",
+		Help:    "List the commands, or show help for one command.",
+		Fn:      cmdHelp,
+	})
+}
+
+func cmdLook(ctx context.Context, s *Session, args string) error {
+	if args == "" {
+		return lookRoom(ctx, s)
+	}
+
+	roomID := s.RoomID()
+	if mob := findMob(s.World().MobsIn(roomID), args); mob != nil {
+		s.Println(mobTitle(mob.Proto))
+		if mob.Proto.MobDesc != "" {
+			s.Println(mob.Proto.MobDesc)
+		}
+		return nil
+	}
+	for _, p := range s.World().PlayersIn(roomID) {
+		if strings.EqualFold(p.name, args) {
+			s.Printf("%s is a fellow adventurer.\n", p.name)
+			return nil
+		}
+	}
+
+	s.Println("You do not see that here.")
+	return nil
+}
+
+// lookRoom describe the room the player is in
+func lookRoom(ctx context.Context, s *Session) error {
+	roomID := s.RoomID()
+	room, err := s.World().Room(ctx, roomID)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			s.Println("You are floating in a formless void.")
+			return nil
+		}
+		return err
+	}
+
+	s.Println(room.Title)
+	if room.Desc != "" {
+		s.Println(room.Desc)
+	}
+	exits, err := s.World().RoomExits(ctx, room)
+	if err != nil {
+		return err
+	}
+	if names := visibleExits(exits); names != "" {
+		s.Printf("Exits: %s\n", names)
+	} else {
+		s.Println("There are no obvious exits.")
+	}
+
+	for _, mob := range s.World().MobsIn(roomID) {
+		s.Printf("%s is here.\n", mobTitle(mob.Proto))
+	}
+	for _, c := range s.World().CorpsesIn(roomID) {
+		s.Printf("The %s lies here.\n", c.Name)
+	}
+	for _, p := range s.World().PlayersIn(roomID) {
+		if p != s {
+			s.Printf("%s is here.\n", p.name)
+		}
+	}
+	return nil
+}
+
+// map radius limits
+const (
+	defaultMapRadius = 3
+	maxMapRadius     = 10
+)
+
+func cmdMap(ctx context.Context, s *Session, args string) error {
+	radius := defaultMapRadius
+	if args != "" {
+		n, err := strconv.Atoi(args)
+		if err != nil || n < 1 || n > maxMapRadius {
+			s.Printf("The radius is a number from 1 to %d.\n", maxMapRadius)
+			return nil
+		}
+		radius = n
+	}
+
+	out, err := s.World().Map(ctx, s.RoomID(), radius)
+	if err != nil {
+		return err
+	}
+	if out == "" {
+		s.Println("You have no idea where you are.")
+		return nil
+	}
+	s.Printf("%s", out)
+	return nil
+}
+
+func cmdWho(_ context.Context, s *Session, _ string) error {
+	players := s.World().Players()
+	names := make([]string, 0, len(players))
+	for _, p := range players {
+		names = append(names, p.name)
+	}
+	sort.Strings(names)
+
+	s.Printf("Players online (%d):\n", len(names))
+	for _, name := range names {
+		s.Printf("  %s\n", name)
+	}
+	return nil
+}
+
+func cmdHelp(_ context.Context, s *Session, args string) error {
+	if args != "" {
+		cmd, ok := lookupCommand(args)
+		if !ok {
+			s.Println("There is no such command.")
+			return nil
+		}
+		s.Printf("Usage: %s\n%s\n", cmd.Usage, cmd.Help)
+		if len(cmd.Aliases) > 0 {
+			s.Printf("Aliases: %s\n", strings.Join(cmd.Aliases, ", "))
+		}
+		return nil
+	}
+
+	s.Println("Commands:")
+	for _, name := range commandNames {
+		s.Printf("  %-12s %s\n", name, commands[name].Help)
+	}
+	return nil
+}
+
+// mobTitle e.g. "守衛(guard)"
+func mobTitle(mob *model.Mob) string {
+	if mob.MobCname == "" {
+		return mob.MobName
+	}
+	return mob.MobCname + "(" + mob.MobName + ")"
+}
+
+// findMob match a mob by name, chinese name or mob id, a prefix of the name is enough
+func findMob(mobs []*Mob, target string) *Mob {
+	target = strings.ToLower(target)
+	for _, mob := range mobs {
+		p := mob.Proto
+		if strings.EqualFold(p.MobName, target) || p.MobCname == target || strings.EqualFold(p.MobID, target) {
+			return mob
+		}
+	}
+	for _, mob := range mobs {
+		if strings.HasPrefix(strings.ToLower(mob.Proto.MobName), target) {
+			return mob
+		}
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/go-dev-frame/sponge/pkg/sgorm"
+
+	"fs/internal/database"
+	"fs/internal/model"
+	"fs/internal/world"
+)
+
+func init() {
+	for _, d := range world.Directions {
+		registerCommand(&Command{
+			Name:    string(d),
+			Aliases: []string{d.Short()},
+			Usage:   string(d),
+			Help:    "Walk " + string(d) + ".",
+			Fn:      moveCommand(d),
+		})
+	}
+	registerCommand(&Command{
+		Name:  "walk",
+		Usage: "walk <room id>",
+		Help:  "Walk the shortest way to a room, locked doors and hidden ways are avoided.",
+		Fn:    cmdWalk,
+	})
+}
+
+// maxWalkSteps the most rooms one walk command passes
+const maxWalkSteps = 50
+
+func moveCommand(d world.Direction) CommandFunc {
+	return func(ctx context.Context, s *Session, _ string) error {
+		return move(ctx, s, d)
+	}
+}
+
+// move walk the player through the exit in a direction
+func move(ctx context.Context, s *Session, d world.Direction) error {
+	toRoomID, err := passExit(ctx, s, d)
+	if err != nil || toRoomID == "" {
+		return err
+	}
+	s.enter(ctx, toRoomID)
+	return nil
+}
+
+func cmdWalk(ctx context.Context, s *Session, args string) error {
+	if args == "" {
+		s.Println("Walk where?")
+		return nil
+	}
+	path, ok, err := s.World().Path(ctx, s.RoomID(), args)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		s.Println("You do not know the way there.")
+		return nil
+	}
+	if len(path) == 0 {
+		s.Println("You are already there.")
+		return nil
+	}
+
+	for i, e := range path {
+		if i == maxWalkSteps {
+			s.Println("You stop to catch your breath.")
+			break
+		}
+		toRoomID, err := passExit(ctx, s, e.Direction)
+		if err != nil {
+			return err
+		}
+		if toRoomID == "" {
+			break
+		}
+		s.Printf("You walk %s.\n", e.Direction)
+		s.moveTo(toRoomID)
+	}
+	return lookRoom(ctx, s)
+}
+
+// passExit check that the player can take the exit in a direction, the room it
+// leads to is returned, or an empty id after telling the player why not.
+func passExit(ctx context.Context, s *Session, d world.Direction) (string, error) {
+	room, err := s.World().Room(ctx, s.RoomID())
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			s.Println("You cannot go that way.")
+			return "", nil
+		}
+		return "", err
+	}
+	exits, err := s.World().RoomExits(ctx, room)
+	if err != nil {
+		return "", err
+	}
+
+	exit := findExit(exits, d)
+	if exit == nil {
+		s.Println("You cannot go that way.")
+		return "", nil
+	}
+	if isSet(exit.Locked) {
+		s.Printf("The door to the %s is locked.\n", d)
+		return "", nil
+	}
+	if _, err = s.World().Room(ctx, exit.ToRoomID); err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			s.Println("That way leads nowhere.")
+			return "", nil
+		}
+		return "", err
+	}
+
+	return exit.ToRoomID, nil
+}
+
+func findExit(exits []*model.RoomExit, d world.Direction) *model.RoomExit {
+	for _, exit := range exits {
+		if exit.Direction == string(d) {
+			return exit
+		}
+	}
+	return nil
+}
+
+// visibleExits the directions of the exits that are not hidden, in display order
+func visibleExits(exits []*model.RoomExit) string {
+	var names []string
+	for _, d := range world.Directions {
+		if exit := findExit(exits, d); exit != nil && !isSet(exit.Hidden) {
+			names = append(names, string(d))
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+func isSet(b *sgorm.TinyBool) bool {
+	return b != nil && bool(*b)
+}
@@ -0,0 +1,66 @@
+package game
+
+import (
+	"context"
+
+	"fs/internal/model"
+)
+
+func init() {
+	registerCommand(&Command{
+		Name:    "inventory",
+		Aliases: []string{"i"},
+		Usage:   "inventory",
+		Help:    "List the items you carry.",
+		Fn:      cmdInventory,
+	})
+	registerCommand(&Command{
+		Name:  "score",
+		Usage: "score",
+		Help:  "Show your stats.",
+		Fn:    cmdScore,
+	})
+}
+
+func cmdInventory(ctx context.Context, s *Session, _ string) error {
+	if len(s.inventory) == 0 {
+		s.Println("You are not carrying anything.")
+		return nil
+	}
+
+	ids := make([]uint64, 0, len(s.inventory))
+	for _, inst := range s.inventory {
+		ids = append(ids, inst.ProtoID)
+	}
+	protos, err := s.World().itemDao.GetByIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	s.Println("You are carrying:")
+	for _, inst := range s.inventory {
+		if proto, ok := protos[inst.ProtoID]; ok {
+			s.Printf("  %s\n", itemTitle(proto))
+		}
+	}
+	return nil
+}
+
+func cmdScore(_ context.Context, s *Session, _ string) error {
+	ch := s.character
+	hp, stats := s.World().Vitals(s)
+	s.Printf("%s\n", s.name)
+	s.Printf("  Hp %d/%d Mp %d\n", hp, stats.Hp, ch.Mp)
+	s.Printf("  Attack %-4d Defence %-4d Dodge %-4d\n", stats.Attack, stats.Defence, stats.Dodge)
+	s.Printf("  Str %-4d Cor %-4d Inte %-4d\n", ch.Str, ch.Cor, ch.Inte)
+	s.Printf("  Dex %-4d Con %-4d Kar %-4d\n", ch.Dex, ch.Con, ch.Kar)
+	return nil
+}
+
+// itemTitle e.g. "長劍(sword)"
+func itemTitle(item *model.Item) string {
+	if item.ItemCname == "" {
+		return item.ItemName
+	}
+	return item.ItemCname + "(" + item.ItemName + ")"
+}
@@ -0,0 +1,333 @@
+package game
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+
+	"fs/internal/combat"
+	"fs/internal/equip"
+	"fs/internal/model"
+)
+
+// combat tuning
+const (
+	corpseDecay = 5 * time.Minute // how long a corpse lies in a room
+	regenShare  = 10              // out of a fight a tenth of the most hp comes back each heartbeat
+)
+
+// Corpse what is left in a room after something dies
+type Corpse struct {
+	Name   string // e.g. "corpse of 老鼠(rat)"
+	RoomID string
+
+	decayAt time.Time
+}
+
+// CorpsesIn list the corpses in a room, oldest first
+func (w *World) CorpsesIn(roomID string) []*Corpse {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	return append([]*Corpse(nil), w.corpses[roomID]...)
+}
+
+// items the prototypes of item instances with the instance overrides applied, in the order of the instances
+func (w *World) items(ctx context.Context, instances []*model.ItemInstance) ([]*model.Item, error) {
+	if len(instances) == 0 {
+		return nil, nil
+	}
+	ids := make([]uint64, 0, len(instances))
+	for _, inst := range instances {
+		ids = append(ids, inst.ProtoID)
+	}
+	protos, err := w.itemDao.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*model.Item, 0, len(instances))
+	for _, inst := range instances {
+		if proto, ok := protos[inst.ProtoID]; ok {
+			items = append(items, inst.Overrides.Apply(proto))
+		}
+	}
+	return items, nil
+}
+
+// playerStats the combat stats of a character, the items it carries are worn
+// in order, each in the first free slot of its kind
+func (w *World) playerStats(ctx context.Context, ch *model.Character, inventory []*model.ItemInstance) (combat.Stats, error) {
+	items, err := w.items(ctx, inventory)
+	if err != nil {
+		return combat.Stats{}, err
+	}
+	return combat.New(equip.Effective(combat.CharacterBase(ch), equip.Dress(items))), nil
+}
+
+// mobStats the combat stats of the mobs made from a prototype, wearing the items located at the prototype
+func (w *World) mobStats(ctx context.Context, proto *model.Mob) (combat.Stats, error) {
+	instances, err := w.instanceDao.GetByOwner(ctx, model.ItemLocationMob, strconv.FormatUint(proto.ID, 10))
+	if err != nil {
+		return combat.Stats{}, err
+	}
+	items, err := w.items(ctx, instances)
+	if err != nil {
+		return combat.Stats{}, err
+	}
+	return combat.New(equip.Effective(combat.MobBase(proto), equip.Dress(items))), nil
+}
+
+// Fighting the mob a player is fighting, nil if none
+func (w *World) Fighting(s *Session) *Mob {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return s.fighting
+}
+
+// Vitals the current hp and the combat stats of a player
+func (w *World) Vitals(s *Session) (int, combat.Stats) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return s.hp, s.stats
+}
+
+// attack start a fight of a player with a mob, stats are the stats of the mob with its
+// items, see mobStats. False if the mob is no longer in the room of the player.
+func (w *World) attack(s *Session, mob *Mob, stats combat.Stats) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if mob.RoomID != s.roomID || !w.hasMob(mob) {
+		return false
+	}
+	if !mob.armed {
+		mob.stats = stats
+		mob.hp = stats.Hp
+		mob.armed = true
+	}
+	s.fighting = mob
+	return true
+}
+
+// hasMob check if a mob is still in the world, the caller holds w.mu
+func (w *World) hasMob(mob *Mob) bool {
+	for _, m := range w.mobs[mob.RoomID] {
+		if m == mob {
+			return true
+		}
+	}
+	return false
+}
+
+// random a random number in [0, n)
+func (w *World) random(n int) int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.roll(n)
+}
+
+// message a line for one player, or for the players in a room when to is nil
+type message struct {
+	to     *Session
+	roomID string
+	except *Session
+	text   string
+}
+
+// round what a heartbeat has to tell, sent after the world lock is released
+type round struct {
+	messages []message
+	died     []*Session // players to show the room they woke up in
+}
+
+func (r *round) tell(s *Session, text string) {
+	r.messages = append(r.messages, message{to: s, text: text})
+}
+
+func (r *round) tellRoom(roomID string, except *Session, text string) {
+	r.messages = append(r.messages, message{roomID: roomID, except: except, text: text})
+}
+
+// Heartbeat run a round of every fight, players strike first, then each mob that is
+// attacked strikes back at one of its attackers. Out of a fight players and mobs
+// get hp back, and the corpses that have lain long enough decay.
+func (w *World) Heartbeat(ctx context.Context, now time.Time) {
+	r := &round{}
+
+	w.mu.Lock()
+	attackers := map[*Mob][]*Session{}
+	var fought []*Mob
+	for _, s := range w.sortedPlayers() {
+		mob := s.fighting
+		if mob == nil {
+			continue
+		}
+		if mob.RoomID != s.roomID || !w.hasMob(mob) {
+			s.fighting = nil
+			continue
+		}
+		if _, ok := attackers[mob]; !ok {
+			fought = append(fought, mob)
+		}
+		attackers[mob] = append(attackers[mob], s)
+		w.playerStrike(s, mob, now, r)
+	}
+	for _, mob := range fought {
+		if mob.hp > 0 {
+			w.mobStrike(mob, attackers[mob], now, r)
+		}
+	}
+	w.regen(attackers)
+	w.decayCorpses(now)
+	w.mu.Unlock()
+
+	for _, m := range r.messages {
+		if m.to != nil {
+			m.to.Println(m.text)
+		} else {
+			w.Broadcast(m.roomID, m.text, m.except)
+		}
+	}
+	for _, s := range r.died {
+		_ = lookRoom(ctx, s)
+	}
+}
+
+// sortedPlayers the online players by name, the caller holds w.mu
+func (w *World) sortedPlayers() []*Session {
+	players := make([]*Session, 0, len(w.players))
+	for _, s := range w.players {
+		players = append(players, s)
+	}
+	sort.Slice(players, func(i, j int) bool {
+		return players[i].name < players[j].name
+	})
+	return players
+}
+
+func (w *World) playerStrike(s *Session, mob *Mob, now time.Time, r *round) {
+	title := mobTitle(mob.Proto)
+	hit := combat.Strike(s.stats, mob.stats, w.roll)
+	if !hit.Landed {
+		r.tell(s, title+" dodges your attack.")
+		r.tellRoom(s.roomID, s, title+" dodges "+s.name+"'s attack.")
+		return
+	}
+
+	mob.hp -= hit.Damage
+	r.tell(s, "You hit "+title+" for "+strconv.Itoa(hit.Damage)+" damage.")
+	r.tellRoom(s.roomID, s, s.name+" hits "+title+".")
+	if mob.hp <= 0 {
+		w.mobDies(mob, now, r)
+	}
+}
+
+// mobStrike the mob strikes back at the player it is fighting, or the first of its attackers
+func (w *World) mobStrike(mob *Mob, attackers []*Session, now time.Time, r *round) {
+	if !containsSession(attackers, mob.target) {
+		mob.target = attackers[0]
+	}
+	s := mob.target
+	title := mobTitle(mob.Proto)
+	hit := combat.Strike(mob.stats, s.stats, w.roll)
+	if !hit.Landed {
+		r.tell(s, "You dodge "+title+"'s attack.")
+		r.tellRoom(s.roomID, s, s.name+" dodges "+title+"'s attack.")
+		return
+	}
+
+	s.hp -= hit.Damage
+	r.tell(s, title+" hits you for "+strconv.Itoa(hit.Damage)+" damage.")
+	r.tellRoom(s.roomID, s, title+" hits "+s.name+".")
+	if s.hp <= 0 {
+		w.playerDies(s, title, now, r)
+	}
+}
+
+// mobDies take a killed mob out of the world and leave its corpse, its spawn brings another one back
+func (w *World) mobDies(mob *Mob, now time.Time, r *round) {
+	title := mobTitle(mob.Proto)
+	w.removeMobLocked(mob)
+	w.addCorpse(mob.RoomID, title, now)
+	for _, s := range w.players {
+		if s.fighting == mob {
+			s.fighting = nil
+		}
+	}
+	r.tellRoom(mob.RoomID, nil, title+" is dead!")
+}
+
+// playerDies leave the corpse of a killed player, the player wakes up in the start
+// room with full hp and keeps what it carries
+func (w *World) playerDies(s *Session, killer string, now time.Time, r *round) {
+	roomID := s.roomID
+	w.addCorpse(roomID, s.name, now)
+	s.hp = s.stats.Hp
+	r.tell(s, "You have been killed by "+killer+"!")
+	r.tellRoom(roomID, s, s.name+" has been killed by "+killer+"!")
+
+	if start := s.server.startRoom; start != "" {
+		w.moveLocked(s, start)
+	} else {
+		s.fighting = nil
+	}
+	r.died = append(r.died, s)
+}
+
+// addCorpse the caller holds w.mu
+func (w *World) addCorpse(roomID string, of string, now time.Time) {
+	w.corpses[roomID] = append(w.corpses[roomID], &Corpse{
+		Name:    "corpse of " + of,
+		RoomID:  roomID,
+		decayAt: now.Add(corpseDecay),
+	})
+}
+
+// decayCorpses the caller holds w.mu
+func (w *World) decayCorpses(now time.Time) {
+	for roomID, list := range w.corpses {
+		kept := list[:0]
+		for _, c := range list {
+			if now.Before(c.decayAt) {
+				kept = append(kept, c)
+			}
+		}
+		if len(kept) == 0 {
+			delete(w.corpses, roomID)
+		} else {
+			w.corpses[roomID] = kept
+		}
+	}
+}
+
+// regen give hp back to the players and mobs out of a fight, the caller holds w.mu
+func (w *World) regen(fought map[*Mob][]*Session) {
+	for _, s := range w.players {
+		if s.fighting == nil {
+			s.hp = regenHp(s.hp, s.stats.Hp)
+		}
+	}
+	for _, list := range w.mobs {
+		for _, mob := range list {
+			if _, ok := fought[mob]; !ok {
+				mob.hp = regenHp(mob.hp, mob.stats.Hp)
+			}
+		}
+	}
+}
+
+func regenHp(hp int, most int) int {
+	if hp >= most {
+		return hp
+	}
+	gain := most / regenShare
+	if gain < 1 {
+		gain = 1
+	}
+	if hp+gain > most {
+		return most
+	}
+	return hp + gain
+}
@@ -0,0 +1,86 @@
+package game
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorld_Heartbeat(t *testing.T) {
+	_, addr, w := newTestServerWith(t, newMemCharacterDao(), &memItemInstanceDao{}, WithHeartbeat(time.Hour))
+	ctx := context.Background()
+	now := time.Now()
+
+	var rolls []int // the next rolls, 0 once they run out
+	w.mu.Lock()
+	w.roll = func(n int) int {
+		if len(rolls) == 0 {
+			return 0
+		}
+		r := rolls[0]
+		rolls = rolls[1:]
+		return r % n
+	}
+	w.mu.Unlock()
+
+	alice := dial(t, addr)
+	alice.expect(t, "name")
+	alice.send("alice")
+	alice.send("secret")
+	alice.send("secret")
+	alice.expect(t, "> ")
+
+	alice.send("kill guard")
+	alice.expect(t, "You cannot attack 守衛(guard).")
+	alice.send("flee")
+	alice.expect(t, "You are not fighting anyone.")
+
+	alice.send("n")
+	alice.expect(t, "The Inn")
+	alice.send("kill rat")
+	alice.expect(t, "You attack 老鼠(rat)!")
+	alice.expect(t, "> ")
+
+	// attack 10 against defence 1, the rat strikes back for the least damage
+	w.Heartbeat(ctx, now)
+	alice.expect(t, "You hit 老鼠(rat) for 10 damage.")
+	alice.expect(t, "老鼠(rat) hits you for 1 damage.")
+	alice.send("score")
+	alice.expect(t, "Hp 99/100")
+	alice.expect(t, "Attack 10")
+
+	w.Heartbeat(ctx, now)
+	alice.expect(t, "老鼠(rat) is dead!")
+	assert.Len(t, w.MobsIn("inn"), 1)
+	assert.Nil(t, w.Fighting(w.players["alice"]))
+	alice.send("look")
+	alice.expect(t, "The corpse of 老鼠(rat) lies here.")
+
+	// out of the fight hp comes back
+	alice.send("score")
+	alice.expect(t, "Hp 100/100")
+
+	// killed, the player wakes up in the start room
+	alice.send("kill rat")
+	alice.expect(t, "You attack 老鼠(rat)!")
+	alice.expect(t, "> ")
+	w.mu.Lock()
+	w.players["alice"].hp = 1
+	rolls = []int{99} // alice misses
+	w.mu.Unlock()
+	w.Heartbeat(ctx, now)
+	alice.expect(t, "老鼠(rat) dodges your attack.")
+	alice.expect(t, "You have been killed by 老鼠(rat)!")
+	alice.expect(t, "Town Square")
+	assert.Equal(t, "square", w.players["alice"].RoomID())
+	hp, stats := w.Vitals(w.players["alice"])
+	assert.Equal(t, stats.Hp, hp)
+
+	require.Len(t, w.CorpsesIn("inn"), 2)
+	assert.Equal(t, "corpse of Alice", w.CorpsesIn("inn")[1].Name)
+	w.Heartbeat(ctx, now.Add(corpseDecay))
+	assert.Empty(t, w.CorpsesIn("inn"))
+}
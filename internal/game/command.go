@@ -0,0 +1,66 @@
+package game
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+// CommandFunc handle a command, args is the rest of the line after the command word
+type CommandFunc func(ctx context.Context, s *Session, args string) error
+
+// Command a player command
+type Command struct {
+	Name    string
+	Aliases []string
+	Usage   string // shown by help, e.g. "say <message>"
+	Help    string
+	Fn      CommandFunc
+}
+
+var (
+	commands     = map[string]*Command{} // commands by name and alias
+	commandNames []string                // sorted names, aliases excluded
+)
+
+// registerCommand add a command to the command table, a duplicate name panics
+func registerCommand(cmd *Command) {
+	for _, name := range append([]string{cmd.Name}, cmd.Aliases...) {
+		if _, ok := commands[name]; ok {
+			panic("game command " + name + " already exists")
+		}
+		commands[name] = cmd
+	}
+	commandNames = append(commandNames, cmd.Name)
+	sort.Strings(commandNames)
+}
+
+// lookupCommand find a command by name or alias, an unambiguous prefix of a name also matches
+func lookupCommand(word string) (*Command, bool) {
+	word = strings.ToLower(word)
+	if cmd, ok := commands[word]; ok {
+		return cmd, true
+	}
+
+	var found *Command
+	for _, name := range commandNames {
+		if strings.HasPrefix(name, word) {
+			if found != nil {
+				return nil, false
+			}
+			found = commands[name]
+		}
+	}
+	return found, found != nil
+}
+
+// splitCommand split a line into the command word and its arguments,
+// a leading ' is short for say.
+func splitCommand(line string) (string, string) {
+	line = strings.TrimSpace(line)
+	if strings.HasPrefix(line, "'") {
+		return "say", strings.TrimSpace(line[1:])
+	}
+	word, args, _ := strings.Cut(line, " ")
+	return word, strings.TrimSpace(args)
+}
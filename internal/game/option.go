@@ -0,0 +1,72 @@
+package game
+
+import (
+	"time"
+)
+
+// Option set server options
+type Option func(*options)
+
+type options struct {
+	startRoom     string
+	idleTimeout   time.Duration
+	resetInterval time.Duration
+	saveInterval  time.Duration
+	heartbeat     time.Duration
+}
+
+func defaultOptions() *options {
+	return &options{
+		idleTimeout:   30 * time.Minute,
+		resetInterval: 10 * time.Second,
+		saveInterval:  5 * time.Minute,
+		heartbeat:     2 * time.Second,
+	}
+}
+
+func (o *options) apply(opts ...Option) {
+	for _, opt := range opts {
+		opt(o)
+	}
+}
+
+// WithStartRoom set the room new players enter
+func WithStartRoom(id string) Option {
+	return func(o *options) {
+		o.startRoom = id
+	}
+}
+
+// WithIdleTimeout set how long a player may be idle before being disconnected, 0 means never
+func WithIdleTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.idleTimeout = d
+	}
+}
+
+// WithResetInterval set how often the spawns are checked for mobs to bring back
+func WithResetInterval(d time.Duration) Option {
+	return func(o *options) {
+		if d > 0 {
+			o.resetInterval = d
+		}
+	}
+}
+
+// WithSaveInterval set how often the online players are saved, they are also saved when they leave
+func WithSaveInterval(d time.Duration) Option {
+	return func(o *options) {
+		if d > 0 {
+			o.saveInterval = d
+		}
+	}
+}
+
+// WithHeartbeat set how often a round of every fight is run
+func WithHeartbeat(d time.Duration) Option {
+	return func(o *options) {
+		if d > 0 {
+			o.heartbeat = d
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package game
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/go-dev-frame/sponge/pkg/gocrypto"
+	"github.com/go-dev-frame/sponge/pkg/logger"
+
+	"fs/internal/database"
+	"fs/internal/equip"
+	"fs/internal/model"
+)
+
+// errNoAccount the character has no account, e.g. it was deleted
+var errNoAccount = errors.New("character has no account")
+
+// Character get a character by name, database.ErrRecordNotFound if there is none
+func (w *World) Character(ctx context.Context, name string) (*model.Character, error) {
+	return w.characterDao.GetByName(ctx, name)
+}
+
+// CheckPassword check the password of the account a character belongs to
+func (w *World) CheckPassword(ctx context.Context, ch *model.Character, password string) (bool, error) {
+	account, err := w.accountDao.GetByID(ctx, ch.AccountID)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			return false, errNoAccount
+		}
+		return false, err
+	}
+	return gocrypto.VerifyPassword(password, account.PasswordHash), nil
+}
+
+// CreateCharacter create an account named after the character and the character in a room,
+// in one transaction so that no account is left without its character
+func (w *World) CreateCharacter(ctx context.Context, name string, password string, roomID string) (*model.Character, error) {
+	hash, err := gocrypto.HashAndSaltPassword(password)
+	if err != nil {
+		return nil, err
+	}
+	account := &model.Account{Username: name, PasswordHash: hash}
+	ch := newCharacter(0, name, roomID)
+	err = w.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		accountID, err := w.accountDao.CreateByTx(ctx, tx, account)
+		if err != nil {
+			return err
+		}
+		ch.AccountID = accountID
+		_, err = w.characterDao.CreateByTx(ctx, tx, ch)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ch, nil
+}
+
+// newCharacter a character with equip.StartingStats
+func newCharacter(accountID uint64, name string, roomID string) *model.Character {
+	s := equip.StartingStats
+	return &model.Character{
+		AccountID: accountID,
+		Name:      name,
+		RoomID:    roomID,
+		Hp:        s.Hp,
+		Mp:        s.Mp,
+		Str:       s.Str,
+		Cor:       s.Cor,
+		Inte:      s.Inte,
+		Dex:       s.Dex,
+		Con:       s.Con,
+		Kar:       s.Kar,
+	}
+}
+
+// Inventory get the item instances a player carries
+func (w *World) Inventory(ctx context.Context, name string) ([]*model.ItemInstance, error) {
+	return w.instanceDao.GetByOwner(ctx, model.ItemLocationPlayer, name)
+}
+
+// entryRoom the room a returning character is put in, the start room if its room no longer exists
+func (w *World) entryRoom(ctx context.Context, ch *model.Character, startRoom string) string {
+	if ch.RoomID == "" {
+		return startRoom
+	}
+	if _, err := w.Room(ctx, ch.RoomID); err != nil {
+		return startRoom
+	}
+	return ch.RoomID
+}
+
+// Save write the character of a player and the items it carries to the database
+func (w *World) Save(ctx context.Context, s *Session) error {
+	if s.character == nil {
+		return nil
+	}
+	w.mu.RLock()
+	ch := *s.character
+	if s.roomID != "" {
+		ch.RoomID = s.roomID
+	}
+	inventory := append([]*model.ItemInstance(nil), s.inventory...)
+	w.mu.RUnlock()
+
+	if err := w.characterDao.SaveByID(ctx, &ch); err != nil {
+		return err
+	}
+	for _, inst := range inventory {
+		if err := w.instanceDao.SaveByID(ctx, inst); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SaveAll save every online player, errors are logged and the others are still saved
+func (w *World) SaveAll(ctx context.Context) {
+	for _, s := range w.Players() {
+		if err := w.Save(ctx, s); err != nil {
+			logger.Warn("save player error", logger.Err(err), logger.String("name", s.name))
+		}
+	}
+}
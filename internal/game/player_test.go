@@ -0,0 +1,227 @@
+package game
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/go-dev-frame/sponge/pkg/sgorm"
+
+	"fs/internal/combat"
+	"fs/internal/dao"
+	"fs/internal/database"
+	"fs/internal/equip"
+	"fs/internal/model"
+)
+
+// memAccountDao in memory accounts for the game tests
+type memAccountDao struct {
+	dao.AccountDao
+	mu       sync.Mutex
+	accounts map[uint64]*model.Account
+}
+
+func (d *memAccountDao) Create(_ context.Context, table *model.Account) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	table.ID = uint64(len(d.accounts) + 1)
+	d.accounts[table.ID] = table
+	return nil
+}
+
+func (d *memAccountDao) CreateByTx(ctx context.Context, _ *gorm.DB, table *model.Account) (uint64, error) {
+	err := d.Create(ctx, table)
+	return table.ID, err
+}
+
+func (d *memAccountDao) GetByID(_ context.Context, id uint64) (*model.Account, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if a, ok := d.accounts[id]; ok {
+		return a, nil
+	}
+	return nil, database.ErrRecordNotFound
+}
+
+// memCharacterDao in memory characters for the game tests
+type memCharacterDao struct {
+	dao.CharacterDao
+	accounts *memAccountDao
+
+	mu         sync.Mutex
+	characters map[string]*model.Character
+}
+
+func newMemCharacterDao() *memCharacterDao {
+	return &memCharacterDao{
+		accounts:   &memAccountDao{accounts: map[uint64]*model.Account{}},
+		characters: map[string]*model.Character{},
+	}
+}
+
+func (d *memCharacterDao) Create(_ context.Context, table *model.Character) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	table.ID = uint64(len(d.characters) + 1)
+	c := *table
+	d.characters[table.Name] = &c
+	return nil
+}
+
+func (d *memCharacterDao) CreateByTx(ctx context.Context, _ *gorm.DB, table *model.Character) (uint64, error) {
+	err := d.Create(ctx, table)
+	return table.ID, err
+}
+
+func (d *memCharacterDao) GetByName(_ context.Context, name string) (*model.Character, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if c, ok := d.characters[name]; ok {
+		cc := *c
+		return &cc, nil
+	}
+	return nil, database.ErrRecordNotFound
+}
+
+func (d *memCharacterDao) SaveByID(_ context.Context, table *model.Character) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	c := *table
+	d.characters[table.Name] = &c
+	return nil
+}
+
+// memItemInstanceDao in memory item instances for the game tests
+type memItemInstanceDao struct {
+	dao.ItemInstanceDao
+	mu        sync.Mutex
+	instances []*model.ItemInstance
+	updated   int
+}
+
+func (d *memItemInstanceDao) GetByOwner(_ context.Context, locationType string, ownerID string) ([]*model.ItemInstance, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var list []*model.ItemInstance
+	for _, inst := range d.instances {
+		if inst.LocationType == locationType && inst.OwnerID == ownerID {
+			list = append(list, inst)
+		}
+	}
+	return list, nil
+}
+
+func (d *memItemInstanceDao) SaveByID(_ context.Context, _ *model.ItemInstance) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.updated++
+	return nil
+}
+
+// memItemDao in memory items for the game tests
+type memItemDao struct {
+	dao.ItemDao
+	items map[uint64]*model.Item
+}
+
+func (d *memItemDao) GetByIDs(_ context.Context, ids []uint64) (map[uint64]*model.Item, error) {
+	items := map[uint64]*model.Item{}
+	for _, id := range ids {
+		if item, ok := d.items[id]; ok {
+			items[id] = item
+		}
+	}
+	return items, nil
+}
+
+func TestServer_SaveAndLoad(t *testing.T) {
+	characters := newMemCharacterDao()
+	instances := &memItemInstanceDao{instances: []*model.ItemInstance{
+		{ID: 1, ProtoID: 1, LocationType: model.ItemLocationPlayer, OwnerID: "Carol"},
+	}}
+	s, addr, w := newTestServerWith(t, characters, instances)
+
+	carol := dial(t, addr)
+	carol.expect(t, "name")
+	carol.send("carol")
+	carol.expect(t, "Choose a password:")
+	carol.send("secret")
+	carol.send("secret")
+	carol.expect(t, "Town Square")
+
+	carol.send("i")
+	carol.expect(t, "長劍(sword)")
+	carol.send("score")
+	carol.expect(t, "Str 10")
+
+	carol.send("n")
+	carol.expect(t, "The Inn")
+
+	// saved while playing
+	w.SaveAll(context.Background())
+	ch, err := characters.GetByName(context.Background(), "Carol")
+	require.NoError(t, err)
+	assert.Equal(t, "inn", ch.RoomID)
+
+	// saved on logout, the next login starts in the saved room
+	carol.send("s")
+	carol.expect(t, "Town Square")
+	carol.send("quit")
+	carol.expect(t, "Goodbye.")
+	assert.Eventually(t, func() bool {
+		ch, _ := characters.GetByName(context.Background(), "Carol")
+		return ch.RoomID == "square" && len(w.Players()) == 0
+	}, time.Second, 10*time.Millisecond)
+
+	characters.mu.Lock()
+	characters.characters["Carol"].RoomID = "inn"
+	characters.mu.Unlock()
+	carol = dial(t, addr)
+	carol.expect(t, "name")
+	carol.send("Carol")
+	carol.expect(t, "Password:")
+	carol.send("secret")
+	carol.expect(t, "The Inn")
+
+	_ = s.Shutdown()
+	instances.mu.Lock()
+	assert.GreaterOrEqual(t, instances.updated, 2)
+	instances.mu.Unlock()
+}
+
+func TestNewCharacter(t *testing.T) {
+	// the simulator of the api takes the same stats for a new character
+	ch := newCharacter(1, "Ann", "square")
+	assert.Equal(t, equip.StartingStats, combat.CharacterBase(ch))
+}
+
+func TestWorld_CreateCharacter(t *testing.T) {
+	db, err := database.OpenSqlite(":memory:")
+	require.NoError(t, err)
+	defer sgorm.CloseDB(db) //nolint
+	w := newWorld(daos{
+		db:           db,
+		accountDao:   dao.NewAccountDao(db, nil),
+		characterDao: dao.NewCharacterDao(db, nil),
+	})
+	defer w.Close()
+	ctx := context.Background()
+
+	ch, err := w.CreateCharacter(ctx, "Ann", "secret", "square")
+	require.NoError(t, err)
+	account, err := w.accountDao.GetByUsername(ctx, "Ann")
+	require.NoError(t, err)
+	assert.Equal(t, account.ID, ch.AccountID)
+
+	// the account is rolled back with the character that cannot be created
+	require.NoError(t, w.characterDao.Create(ctx, &model.Character{AccountID: account.ID, Name: "Bob"}))
+	_, err = w.CreateCharacter(ctx, "Bob", "secret", "square")
+	assert.ErrorIs(t, err, database.ErrDuplicatedKey)
+	_, err = w.accountDao.GetByUsername(ctx, "Bob")
+	assert.ErrorIs(t, err, database.ErrRecordNotFound)
+}
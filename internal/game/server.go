@@ -0,0 +1,218 @@
+// Package game is the telnet front-end of the world, players connect with a MUD
+// client and each line they type is dispatched to the command table.
+package game
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/go-dev-frame/sponge/pkg/logger"
+)
+
+// Server telnet game server
+type Server struct {
+	world *World
+
+	startRoom     string
+	idleTimeout   time.Duration
+	resetInterval time.Duration
+	saveInterval  time.Duration
+	heartbeat     time.Duration
+
+	mu       sync.Mutex
+	ln       net.Listener
+	sessions map[*Session]bool
+	closed   bool
+	done     chan struct{} // closed by Shutdown, stops the reset, save and heartbeat loops
+}
+
+// NewServer creating a game server
+func NewServer(world *World, opts ...Option) *Server {
+	o := defaultOptions()
+	o.apply(opts...)
+
+	return &Server{
+		world:         world,
+		startRoom:     o.startRoom,
+		idleTimeout:   o.idleTimeout,
+		resetInterval: o.resetInterval,
+		saveInterval:  o.saveInterval,
+		heartbeat:     o.heartbeat,
+		sessions:      map[*Session]bool{},
+		done:          make(chan struct{}),
+	}
+}
+
+// ListenAndServe listen on the tcp address and serve players until Shutdown is called
+func (s *Server) ListenAndServe(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(ln)
+}
+
+// Serve accept connections on the listener
+func (s *Server) Serve(ln net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		_ = ln.Close()
+		return nil
+	}
+	s.ln = ln
+	s.mu.Unlock()
+
+	s.reset()
+	go s.resetLoop()
+	go s.saveLoop()
+	go s.heartbeatLoop()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if s.isClosed() {
+				return nil
+			}
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				time.Sleep(100 * time.Millisecond)
+				continue
+			}
+			return err
+		}
+
+		session := newSession(s, conn)
+		if !s.track(session, true) {
+			_ = conn.Close()
+			continue
+		}
+		go func() {
+			defer s.track(session, false)
+			session.serve()
+		}()
+	}
+}
+
+// Shutdown stop accepting connections, save and disconnect all players
+func (s *Server) Shutdown() error {
+	s.world.SaveAll(context.Background())
+	defer s.world.Close()
+
+	s.mu.Lock()
+	if !s.closed {
+		close(s.done)
+	}
+	s.closed = true
+	ln := s.ln
+	sessions := make([]*Session, 0, len(s.sessions))
+	for session := range s.sessions {
+		sessions = append(sessions, session)
+	}
+	s.mu.Unlock()
+
+	var err error
+	if ln != nil {
+		err = ln.Close()
+	}
+	for _, session := range sessions {
+		session.Println("\nThe game is shutting down, see you soon.")
+		_ = session.conn.Close()
+	}
+	return err
+}
+
+// reset bring back the mobs of the spawns, see World.Reset
+func (s *Server) reset() {
+	if err := s.world.Reset(context.Background(), time.Now()); err != nil {
+		logger.Warn("game reset error", logger.Err(err))
+	}
+}
+
+// resetLoop run a reset every reset interval until Shutdown is called
+func (s *Server) resetLoop() {
+	ticker := time.NewTicker(s.resetInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			s.reset()
+		}
+	}
+}
+
+// saveLoop save the online players every save interval until Shutdown is called
+func (s *Server) saveLoop() {
+	ticker := time.NewTicker(s.saveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			s.world.SaveAll(context.Background())
+		}
+	}
+}
+
+// heartbeatLoop run a combat round every heartbeat until Shutdown is called, see World.Heartbeat
+func (s *Server) heartbeatLoop() {
+	ticker := time.NewTicker(s.heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case now := <-ticker.C:
+			s.world.Heartbeat(context.Background(), now)
+		}
+	}
+}
+
+func (s *Server) track(session *Session, add bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !add {
+		delete(s.sessions, session)
+		return true
+	}
+	if s.closed {
+		return false
+	}
+	s.sessions[session] = true
+	return true
+}
+
+func (s *Server) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+// dispatch run the command typed by a player
+func (s *Server) dispatch(ctx context.Context, session *Session, line string) error {
+	word, args := splitCommand(line)
+	if word == "" {
+		return nil
+	}
+
+	cmd, ok := lookupCommand(word)
+	if !ok {
+		session.Println("What? Type 'help' for a list of commands.")
+		return nil
+	}
+
+	defer func() {
+		if e := recover(); e != nil {
+			logger.Error("game command panic", logger.Any("panic", e), logger.String("line", line))
+			session.Println("Something went wrong, please try again.")
+		}
+	}()
+	return cmd.Fn(ctx, session, args)
+}
@@ -0,0 +1,280 @@
+package game
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-dev-frame/sponge/pkg/sgorm"
+	"github.com/go-dev-frame/sponge/pkg/sgorm/query"
+
+	"fs/internal/dao"
+	"fs/internal/database"
+	"fs/internal/model"
+)
+
+// memRoomDao in memory rooms for the game tests
+type memRoomDao struct {
+	dao.RoomDao
+	rooms map[string]*model.Room
+}
+
+func (d *memRoomDao) GetByID(_ context.Context, id string) (*model.Room, error) {
+	if r, ok := d.rooms[id]; ok {
+		return r, nil
+	}
+	return nil, database.ErrRecordNotFound
+}
+
+func (d *memRoomDao) GetByColumns(_ context.Context, _ *query.Params) ([]*model.Room, int64, error) {
+	var rooms []*model.Room
+	for _, r := range d.rooms {
+		rooms = append(rooms, r)
+	}
+	return rooms, int64(len(rooms)), nil
+}
+
+// memRoomExitDao in memory exits for the game tests
+type memRoomExitDao struct {
+	dao.RoomExitDao
+	exits []*model.RoomExit
+}
+
+func (d *memRoomExitDao) GetByRoomID(_ context.Context, roomID string) ([]*model.RoomExit, error) {
+	var exits []*model.RoomExit
+	for _, e := range d.exits {
+		if e.RoomID == roomID {
+			exits = append(exits, e)
+		}
+	}
+	return exits, nil
+}
+
+func (d *memRoomExitDao) GetByColumns(_ context.Context, _ *query.Params) ([]*model.RoomExit, int64, error) {
+	return d.exits, int64(len(d.exits)), nil
+}
+
+// memMobDao in memory mobs for the game tests
+type memMobDao struct {
+	dao.MobDao
+	mobs map[uint64]*model.Mob
+}
+
+func (d *memMobDao) GetByID(_ context.Context, id uint64) (*model.Mob, error) {
+	if m, ok := d.mobs[id]; ok {
+		return m, nil
+	}
+	return nil, database.ErrRecordNotFound
+}
+
+func (d *memMobDao) GetByIDs(_ context.Context, ids []uint64) (map[uint64]*model.Mob, error) {
+	mobs := map[uint64]*model.Mob{}
+	for _, id := range ids {
+		if m, ok := d.mobs[id]; ok {
+			mobs[id] = m
+		}
+	}
+	return mobs, nil
+}
+
+// memSpawnDao in memory spawns for the game tests
+type memSpawnDao struct {
+	dao.SpawnDao
+	spawns []*model.Spawn
+	reads  int
+}
+
+func (d *memSpawnDao) GetByColumns(_ context.Context, _ *query.Params) ([]*model.Spawn, int64, error) {
+	d.reads++
+	return d.spawns, int64(len(d.spawns)), nil
+}
+
+func newTestServer(t *testing.T) (*Server, string) {
+	s, addr, _ := newTestServerWith(t, newMemCharacterDao(), &memItemInstanceDao{})
+	return s, addr
+}
+
+func newTestServerWith(t *testing.T, characters *memCharacterDao, instances *memItemInstanceDao, opts ...Option) (*Server, string, *World) {
+	yes, no := sgorm.TinyBool(true), sgorm.TinyBool(false)
+	db, err := database.OpenSqlite(":memory:") // for the transactions, the daos keep the data in memory
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = sgorm.CloseDB(db) })
+	world := newWorld(daos{
+		db: db,
+		roomDao: &memRoomDao{rooms: map[string]*model.Room{
+			"square": {ID: "square", Title: "Town Square", Desc: "A busy square.", Mobs: "1, 2"},
+			"inn":    {ID: "inn", Title: "The Inn", Way: "south:square"},
+		}},
+		exitDao: &memRoomExitDao{exits: []*model.RoomExit{
+			{RoomID: "square", Direction: "north", ToRoomID: "inn"},
+			{RoomID: "square", Direction: "east", ToRoomID: "inn", Door: &yes, Locked: &yes},
+			{RoomID: "square", Direction: "down", ToRoomID: "inn", Hidden: &yes},
+		}},
+		mobDao: &memMobDao{mobs: map[uint64]*model.Mob{
+			1: {ID: 1, MobID: "guard", MobName: "guard", MobCname: "守衛", MobDesc: "A stern guard.", Attackable: &no},
+			3: {ID: 3, MobID: "rat", MobName: "rat", MobCname: "老鼠", Hp: 20, Attack: 1, Defence: 1, Dodge: 1},
+		}},
+		spawnDao: &memSpawnDao{spawns: []*model.Spawn{
+			{ID: 1, RoomID: "inn", MobID: 3, MaxCount: 2},
+		}},
+		itemDao: &memItemDao{items: map[uint64]*model.Item{
+			1: {ID: 1, ItemName: "sword", ItemCname: "長劍", Classifier: "w"},
+		}},
+		instanceDao:  instances,
+		accountDao:   characters.accounts,
+		characterDao: characters,
+	})
+	s := NewServer(world, append([]Option{WithStartRoom("square")}, opts...)...)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() {
+		_ = s.Serve(ln)
+	}()
+	t.Cleanup(func() {
+		_ = s.Shutdown()
+	})
+
+	return s, ln.Addr().String(), world
+}
+
+type testClient struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func dial(t *testing.T, addr string) *testClient {
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return &testClient{conn: conn, r: bufio.NewReader(conn)}
+}
+
+func (c *testClient) send(line string) {
+	_, _ = c.conn.Write([]byte(line + "\r\n"))
+}
+
+// expect read until the text shows up, everything read is returned
+func (c *testClient) expect(t *testing.T, text string) string {
+	var sb strings.Builder
+	_ = c.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for !strings.Contains(sb.String(), text) {
+		b, err := c.r.ReadByte()
+		if err != nil {
+			t.Fatalf("waiting for %q: %v, got %q", text, err, sb.String())
+		}
+		sb.WriteByte(b)
+	}
+	return sb.String()
+}
+
+func TestServer_Session(t *testing.T) {
+	_, addr := newTestServer(t)
+
+	alice := dial(t, addr)
+	alice.expect(t, "name")
+	alice.send("x")
+	alice.expect(t, "Names are")
+	alice.send("alice")
+	alice.expect(t, "you are new here")
+	alice.send("secret")
+	alice.expect(t, "Confirm")
+	alice.send("secret")
+	out := alice.expect(t, "> ")
+	assert.Contains(t, out, "Town Square")
+	assert.Contains(t, out, "Exits: north, east\r\n")
+	assert.Contains(t, out, "守衛(guard) is here.")
+
+	bob := dial(t, addr)
+	bob.expect(t, "name")
+	bob.send("Alice")
+	bob.expect(t, "Password:")
+	bob.send("guess")
+	bob.expect(t, "Wrong password.")
+	bob.send("bob")
+	bob.expect(t, "Choose a password:")
+	bob.send("short")
+	bob.expect(t, "at least 6")
+	bob.send("bob")
+	bob.expect(t, "Choose a password:")
+	bob.send("password")
+	bob.expect(t, "Confirm")
+	bob.send("password")
+	out = bob.expect(t, "> ")
+	assert.Contains(t, out, "Alice is here.")
+	alice.expect(t, "Bob arrives.")
+
+	bob.send("'hello")
+	bob.expect(t, `You say, "hello"`)
+	alice.expect(t, `Bob says, "hello"`)
+
+	alice.send("l guard")
+	alice.expect(t, "A stern guard.")
+
+	alice.send("who")
+	out = alice.expect(t, "Bob")
+	assert.Contains(t, out, "Players online (2)")
+
+	alice.send("map")
+	out = alice.expect(t, "[ ]\r\n |\r\n[*]\r\n")
+	assert.NotContains(t, out, "[v]")
+
+	alice.send("dance")
+	alice.expect(t, "What?")
+
+	alice.send("east")
+	alice.expect(t, "The door to the east is locked.")
+	alice.send("w")
+	alice.expect(t, "You cannot go that way.")
+
+	alice.send("n")
+	out = alice.expect(t, "Exits: south")
+	assert.Contains(t, out, "The Inn")
+	out = alice.expect(t, "> ")
+	assert.Equal(t, 2, strings.Count(out, "老鼠(rat) is here."))
+	bob.expect(t, "Alice leaves.")
+	alice.send("south")
+	alice.expect(t, "Town Square")
+	bob.expect(t, "Alice arrives.")
+
+	alice.send("walk inn")
+	out = alice.expect(t, "Exits: south")
+	assert.Contains(t, out, "You walk north.")
+	assert.Contains(t, out, "The Inn")
+	alice.send("walk nowhere")
+	alice.expect(t, "You do not know the way there.")
+	alice.send("walk square")
+	alice.expect(t, "You walk south.")
+
+	bob.send("quit")
+	bob.expect(t, "Goodbye.")
+	alice.expect(t, "Bob leaves the game.")
+}
+
+func Test_lookupCommand(t *testing.T) {
+	cmd, ok := lookupCommand("l")
+	assert.True(t, ok)
+	assert.Equal(t, "look", cmd.Name)
+
+	cmd, ok = lookupCommand("QU")
+	assert.True(t, ok)
+	assert.Equal(t, "quit", cmd.Name)
+
+	_, ok = lookupCommand("xyz")
+	assert.False(t, ok)
+
+	word, args := splitCommand("  say   hello there ")
+	assert.Equal(t, "say", word)
+	assert.Equal(t, "hello there", args)
+	word, args = splitCommand("'hi")
+	assert.Equal(t, "say", word)
+	assert.Equal(t, "hi", args)
+}
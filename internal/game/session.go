@@ -0,0 +1,277 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-dev-frame/sponge/pkg/logger"
+
+	"fs/internal/auth"
+	"fs/internal/combat"
+	"fs/internal/database"
+	"fs/internal/model"
+	"fs/internal/telnet"
+)
+
+// errQuit returned by a command to end the session
+var errQuit = errors.New("quit")
+
+var validName = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]{2,19}$`)
+
+// Session a connected player
+type Session struct {
+	server *Server
+	conn   *telnet.Conn
+
+	name   string
+	roomID string
+
+	character *model.Character      // loaded at login, written back by World.Save
+	inventory []*model.ItemInstance // items carried, loaded at login
+
+	// combat state, guarded by the world lock as the heartbeat changes it
+	stats    combat.Stats // from the character and the items it wears, loaded at login
+	hp       int          // current hp, stats.Hp is the most
+	fighting *Mob
+}
+
+func newSession(server *Server, conn net.Conn) *Session {
+	return &Session{
+		server: server,
+		conn:   telnet.NewConn(conn),
+	}
+}
+
+// Name player name
+func (s *Session) Name() string {
+	return s.name
+}
+
+// RoomID the room the player is in, read under the world lock as a player who
+// dies in a fight is moved by the heartbeat
+func (s *Session) RoomID() string {
+	w := s.World()
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return s.roomID
+}
+
+// World the world the player is in
+func (s *Session) World() *World {
+	return s.server.world
+}
+
+// Println send a line of text to the player
+func (s *Session) Println(a ...interface{}) {
+	_, _ = s.conn.WriteString(fmt.Sprintln(a...))
+}
+
+// Printf send formatted text to the player
+func (s *Session) Printf(format string, a ...interface{}) {
+	_ = s.conn.Printf(format, a...)
+}
+
+// readLine read a line, the connection is dropped when the player is idle too long
+func (s *Session) readLine() (string, error) {
+	if s.server.idleTimeout > 0 {
+		_ = s.conn.SetReadDeadline(time.Now().Add(s.server.idleTimeout))
+	}
+	line, err := s.conn.ReadLine()
+	return strings.TrimSpace(line), err
+}
+
+func (s *Session) prompt(text string) (string, error) {
+	s.Printf("%s", text)
+	return s.readLine()
+}
+
+// promptPassword read a line without echo, the idle timeout applies as for readLine
+func (s *Session) promptPassword(text string) (string, error) {
+	if s.server.idleTimeout > 0 {
+		_ = s.conn.SetReadDeadline(time.Now().Add(s.server.idleTimeout))
+	}
+	line, err := s.conn.ReadPassword(text)
+	return strings.TrimSpace(line), err
+}
+
+// serve run the session until the player quits or the connection is closed
+func (s *Session) serve() {
+	defer func() {
+		_ = s.conn.Close()
+	}()
+
+	if err := s.conn.Negotiate(); err != nil {
+		return
+	}
+	s.Printf("%s", banner)
+
+	ctx := context.Background()
+	if err := s.login(ctx); err != nil {
+		s.logError("login", err)
+		return
+	}
+	defer s.logout(ctx)
+
+	s.enter(ctx, s.World().entryRoom(ctx, s.character, s.server.startRoom))
+
+	for {
+		line, err := s.prompt("> ")
+		if err != nil {
+			s.logError("read", err)
+			return
+		}
+		if err = s.server.dispatch(ctx, s, line); err != nil {
+			if errors.Is(err, errQuit) {
+				return
+			}
+			s.logError("command", err)
+			s.Println("Something went wrong, please try again.")
+		}
+	}
+}
+
+// login ask for a name and its password, an unknown name creates a new character
+func (s *Session) login(ctx context.Context) error {
+	tries := 0
+	for {
+		name, err := s.prompt("By what name do you wish to be known? ")
+		if err != nil {
+			return err
+		}
+		if !validName.MatchString(name) {
+			s.Println("Names are 3 to 20 letters or digits and start with a letter.")
+			continue
+		}
+		name = strings.ToUpper(name[:1]) + strings.ToLower(name[1:])
+
+		ch, err := s.World().Character(ctx, name)
+		switch {
+		case err == nil:
+			ok, err := s.checkPassword(ctx, ch)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				tries++
+				if tries >= maxPasswordTries {
+					s.Println("Wrong password, goodbye.")
+					return errQuit
+				}
+				s.Println("Wrong password.")
+				continue
+			}
+		case errors.Is(err, database.ErrRecordNotFound):
+			ch, err = s.createCharacter(ctx, name)
+			if err != nil {
+				return err
+			}
+			if ch == nil {
+				continue
+			}
+		default:
+			return err
+		}
+
+		inventory, err := s.World().Inventory(ctx, name)
+		if err != nil {
+			return err
+		}
+		stats, err := s.World().playerStats(ctx, ch, inventory)
+		if err != nil {
+			return err
+		}
+
+		s.name = name
+		s.character = ch
+		s.inventory = inventory
+		s.stats = stats
+		s.hp = stats.Hp
+		if !s.World().login(s) {
+			s.Println("That name is already playing.")
+			continue
+		}
+		logger.Info("player login", logger.String("name", s.name), logger.String("addr", s.conn.RemoteAddr().String()))
+		return nil
+	}
+}
+
+// maxPasswordTries wrong passwords before the connection is closed
+const maxPasswordTries = 3
+
+func (s *Session) checkPassword(ctx context.Context, ch *model.Character) (bool, error) {
+	password, err := s.promptPassword("Password: ")
+	if err != nil {
+		return false, err
+	}
+	return s.World().CheckPassword(ctx, ch, password)
+}
+
+// createCharacter ask for the password of a new character, nil if the player gave up
+func (s *Session) createCharacter(ctx context.Context, name string) (*model.Character, error) {
+	s.Printf("Welcome, %s, you are new here.\n", name)
+	password, err := s.promptPassword("Choose a password: ")
+	if err != nil {
+		return nil, err
+	}
+	if len(password) < auth.MinPasswordLen {
+		s.Printf("Passwords are at least %d characters.\n", auth.MinPasswordLen)
+		return nil, nil
+	}
+	confirm, err := s.promptPassword("Confirm the password: ")
+	if err != nil {
+		return nil, err
+	}
+	if confirm != password {
+		s.Println("The passwords do not match.")
+		return nil, nil
+	}
+	return s.World().CreateCharacter(ctx, name, password, s.server.startRoom)
+}
+
+func (s *Session) logout(ctx context.Context) {
+	if err := s.World().Save(ctx, s); err != nil {
+		logger.Warn("save player error", logger.Err(err), logger.String("name", s.name))
+	}
+	if roomID := s.RoomID(); roomID != "" {
+		s.World().Broadcast(roomID, s.name+" leaves the game.", s)
+	}
+	s.World().logout(s)
+	logger.Info("player logout", logger.String("name", s.name))
+}
+
+// enter move the player into a room and show it
+func (s *Session) enter(ctx context.Context, roomID string) {
+	s.moveTo(roomID)
+	_ = lookRoom(ctx, s)
+}
+
+// moveTo move the player into a room, the players in both rooms see it
+func (s *Session) moveTo(roomID string) {
+	if from := s.RoomID(); from != "" {
+		s.World().Broadcast(from, s.name+" leaves.", s)
+	}
+	s.World().move(s, roomID)
+	s.World().Broadcast(roomID, s.name+" arrives.", s)
+}
+
+func (s *Session) logError(action string, err error) {
+	if errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed) || errors.Is(err, errQuit) {
+		return
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		s.Println("\nYou have been idle too long, goodbye.")
+		return
+	}
+	logger.Warn("session "+action+" error", logger.Err(err), logger.String("name", s.name))
+}
+
+const banner = `
+Welcome to fs.
+`
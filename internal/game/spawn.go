@@ -0,0 +1,262 @@
+package game
+
+import (
+	"context"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"fs/internal/combat"
+	"fs/internal/dao"
+	"fs/internal/model"
+	"fs/internal/world"
+)
+
+// spawn defaults used when a field of the spawn table is 0
+const (
+	defaultSpawnMax     = 1
+	defaultSpawnRespawn = 5 * time.Minute
+	defaultSpawnChance  = 100
+)
+
+// Mob a live mob in the world, made from a mob prototype by a spawn
+type Mob struct {
+	ID     uint64     // unique while the server runs
+	Proto  *model.Mob // the prototype, shared by all mobs made from it
+	RoomID string
+
+	spawnKey string
+
+	// combat state, guarded by the world lock
+	stats  combat.Stats // from the prototype, and its items once it is attacked
+	armed  bool         // the items of the prototype are in stats
+	hp     int
+	target *Session // the player it strikes back at
+}
+
+// spawnDef a row of the spawn table, the mobs listed in Room.Mobs are kept
+// alive the same way, as a spawn per mob id that never fails its roll
+type spawnDef struct {
+	key     string
+	roomID  string
+	mobID   uint64
+	max     int
+	respawn time.Duration
+	chance  int // percent
+}
+
+func newSpawnDef(s *model.Spawn) *spawnDef {
+	def := &spawnDef{
+		key:     "spawn:" + strconv.FormatUint(s.ID, 10),
+		roomID:  s.RoomID,
+		mobID:   s.MobID,
+		max:     s.MaxCount,
+		respawn: time.Duration(s.RespawnSeconds) * time.Second,
+		chance:  s.Chance,
+	}
+	if def.max <= 0 {
+		def.max = defaultSpawnMax
+	}
+	if def.respawn <= 0 {
+		def.respawn = defaultSpawnRespawn
+	}
+	if def.chance <= 0 {
+		def.chance = defaultSpawnChance
+	}
+	return def
+}
+
+// roomSpawnDefs the resident mobs of a room, a mob listed twice is kept at two
+func roomSpawnDefs(room *model.Room) []*spawnDef {
+	var defs []*spawnDef
+	byID := map[uint64]*spawnDef{}
+	for _, id := range world.ParseMobIDs(room.Mobs) {
+		if def, ok := byID[id]; ok {
+			def.max++
+			continue
+		}
+		def := &spawnDef{
+			key:     "room:" + room.ID + ":" + strconv.FormatUint(id, 10),
+			roomID:  room.ID,
+			mobID:   id,
+			max:     1,
+			respawn: defaultSpawnRespawn,
+			chance:  defaultSpawnChance,
+		}
+		byID[id] = def
+		defs = append(defs, def)
+	}
+	return defs
+}
+
+// spawnState what the reset engine remembers about a spawn between resets
+type spawnState struct {
+	nextAt time.Time // when a missing mob comes back, zero if none is missing
+}
+
+// spawnDefsTTL how long the spawns are used before they are read again, like the graph
+// they are dropped on every write through the dao but other processes may change them
+const spawnDefsTTL = time.Minute
+
+// spawnDefs the spawns of the world, read again after a spawn or a room is written or once
+// they are older than spawnDefsTTL
+func (w *World) spawnDefs(ctx context.Context) ([]*spawnDef, error) {
+	w.defsMu.Lock()
+	defer w.defsMu.Unlock()
+
+	if w.defs != nil && time.Since(w.defsLoadedAt) < spawnDefsTTL {
+		return w.defs, nil
+	}
+	defs, err := w.loadSpawnDefs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	w.defs, w.defsLoadedAt = defs, time.Now()
+	return defs, nil
+}
+
+// dropSpawnDefs the next reset reads the spawns again
+func (w *World) dropSpawnDefs() {
+	w.defsMu.Lock()
+	defer w.defsMu.Unlock()
+	w.defs = nil
+}
+
+// loadSpawnDefs read the spawn table and the resident mobs of every room
+func (w *World) loadSpawnDefs(ctx context.Context) ([]*spawnDef, error) {
+	spawns, err := dao.GetAllSpawns(ctx, w.spawnDao)
+	if err != nil {
+		return nil, err
+	}
+	rooms, err := dao.GetAllRooms(ctx, w.roomDao)
+	if err != nil {
+		return nil, err
+	}
+
+	defs := make([]*spawnDef, 0, len(spawns)+len(rooms))
+	for _, s := range spawns {
+		defs = append(defs, newSpawnDef(s))
+	}
+	for _, room := range rooms {
+		defs = append(defs, roomSpawnDefs(room)...)
+	}
+	return defs, nil
+}
+
+// Reset bring the mobs of every spawn back. A spawn seen for the first time is
+// filled up at once, afterwards a missing mob comes back one at a time, each
+// respawn interval after it went missing, if the chance roll succeeds.
+func (w *World) Reset(ctx context.Context, now time.Time) error {
+	defs, err := w.spawnDefs(ctx)
+	if err != nil {
+		return err
+	}
+	ids := make([]uint64, 0, len(defs))
+	for _, def := range defs {
+		ids = append(ids, def.mobID)
+	}
+	protos, err := w.mobDao.GetByIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	var spawned []*Mob
+	w.mu.Lock()
+	for _, def := range defs {
+		proto, ok := protos[def.mobID]
+		if !ok {
+			continue // the prototype was deleted, world validate reports it
+		}
+		spawned = append(spawned, w.resetSpawn(def, proto, now)...)
+	}
+	w.mu.Unlock()
+
+	for _, mob := range spawned {
+		w.Broadcast(mob.RoomID, mobTitle(mob.Proto)+" appears.")
+	}
+	return nil
+}
+
+// resetSpawn the mobs a spawn makes at now, the caller holds w.mu
+func (w *World) resetSpawn(def *spawnDef, proto *model.Mob, now time.Time) []*Mob {
+	live := 0
+	for _, mob := range w.mobs[def.roomID] {
+		if mob.spawnKey == def.key {
+			live++
+		}
+	}
+
+	state, ok := w.spawns[def.key]
+	if !ok {
+		state = &spawnState{}
+		w.spawns[def.key] = state
+		var spawned []*Mob
+		for i := live; i < def.max; i++ {
+			if w.roll(100) < def.chance {
+				spawned = append(spawned, w.addMob(def, proto))
+			}
+		}
+		return spawned
+	}
+
+	if live >= def.max {
+		state.nextAt = time.Time{}
+		return nil
+	}
+	if state.nextAt.IsZero() {
+		state.nextAt = now.Add(def.respawn)
+		return nil
+	}
+	if now.Before(state.nextAt) {
+		return nil
+	}
+
+	state.nextAt = time.Time{}
+	if live+1 < def.max {
+		state.nextAt = now.Add(def.respawn)
+	}
+	if w.roll(100) >= def.chance {
+		state.nextAt = now.Add(def.respawn)
+		return nil
+	}
+	return []*Mob{w.addMob(def, proto)}
+}
+
+// addMob put a new mob of a spawn in its room, the caller holds w.mu
+func (w *World) addMob(def *spawnDef, proto *model.Mob) *Mob {
+	w.lastMobID++
+	stats := combat.New(combat.MobBase(proto))
+	mob := &Mob{ID: w.lastMobID, Proto: proto, RoomID: def.roomID, spawnKey: def.key, stats: stats, hp: stats.Hp}
+	w.mobs[def.roomID] = append(w.mobs[def.roomID], mob)
+	return mob
+}
+
+// RemoveMob take a mob out of the world, its spawn brings another one back later
+func (w *World) RemoveMob(mob *Mob) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.removeMobLocked(mob)
+}
+
+// removeMobLocked see RemoveMob, the caller holds w.mu
+func (w *World) removeMobLocked(mob *Mob) {
+	list := w.mobs[mob.RoomID]
+	for i, m := range list {
+		if m == mob {
+			w.mobs[mob.RoomID] = append(list[:i:i], list[i+1:]...)
+			return
+		}
+	}
+}
+
+// MobsIn list the live mobs in a room, in the order they appeared
+func (w *World) MobsIn(roomID string) []*Mob {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	return append([]*Mob(nil), w.mobs[roomID]...)
+}
+
+func defaultRoll(n int) int {
+	return rand.Intn(n)
+}
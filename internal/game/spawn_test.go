@@ -0,0 +1,110 @@
+package game
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-dev-frame/sponge/pkg/sgorm"
+
+	"fs/internal/dao"
+	"fs/internal/database"
+	"fs/internal/model"
+)
+
+func newSpawnTestWorld(t *testing.T, spawns ...*model.Spawn) *World {
+	w := newWorld(daos{
+		roomDao: &memRoomDao{rooms: map[string]*model.Room{
+			"square": {ID: "square", Title: "Town Square", Mobs: "1, 1, 9"},
+			"inn":    {ID: "inn", Title: "The Inn"},
+		}},
+		exitDao: &memRoomExitDao{},
+		mobDao: &memMobDao{mobs: map[uint64]*model.Mob{
+			1: {ID: 1, MobID: "guard", MobName: "guard"},
+			3: {ID: 3, MobID: "rat", MobName: "rat"},
+		}},
+		spawnDao: &memSpawnDao{spawns: spawns},
+	})
+	t.Cleanup(w.Close)
+	return w
+}
+
+func TestWorld_Reset(t *testing.T) {
+	w := newSpawnTestWorld(t, &model.Spawn{ID: 1, RoomID: "inn", MobID: 3, MaxCount: 3, RespawnSeconds: 60})
+	ctx := context.Background()
+	now := time.Now()
+
+	// filled up the first time, the unknown mob 9 is skipped
+	require.NoError(t, w.Reset(ctx, now))
+	assert.Len(t, w.MobsIn("square"), 2)
+	assert.Len(t, w.MobsIn("inn"), 3)
+	require.NoError(t, w.Reset(ctx, now))
+	assert.Len(t, w.MobsIn("inn"), 3)
+
+	rats := w.MobsIn("inn")
+	w.RemoveMob(rats[0])
+	w.RemoveMob(rats[1])
+	assert.Len(t, w.MobsIn("inn"), 1)
+
+	// the respawn interval starts when the reset sees them missing
+	require.NoError(t, w.Reset(ctx, now.Add(time.Second)))
+	assert.Len(t, w.MobsIn("inn"), 1)
+	require.NoError(t, w.Reset(ctx, now.Add(30*time.Second)))
+	assert.Len(t, w.MobsIn("inn"), 1)
+
+	// one comes back each interval
+	require.NoError(t, w.Reset(ctx, now.Add(61*time.Second)))
+	assert.Len(t, w.MobsIn("inn"), 2)
+	require.NoError(t, w.Reset(ctx, now.Add(90*time.Second)))
+	assert.Len(t, w.MobsIn("inn"), 2)
+	require.NoError(t, w.Reset(ctx, now.Add(121*time.Second)))
+	inn := w.MobsIn("inn")
+	assert.Len(t, inn, 3)
+	assert.Equal(t, uint64(7), inn[2].ID)
+	assert.Equal(t, "rat", inn[2].Proto.MobName)
+}
+
+func TestWorld_Reset_chance(t *testing.T) {
+	w := newSpawnTestWorld(t, &model.Spawn{ID: 1, RoomID: "inn", MobID: 3, MaxCount: 2, Chance: 50})
+	ctx := context.Background()
+	now := time.Now()
+
+	rolls := []int{10, 80}
+	w.roll = func(n int) int {
+		r := rolls[0]
+		rolls = append(rolls[1:], r)
+		return r
+	}
+	require.NoError(t, w.Reset(ctx, now))
+	assert.Len(t, w.MobsIn("inn"), 1)
+
+	// a failed roll waits for another interval
+	require.NoError(t, w.Reset(ctx, now))
+	require.NoError(t, w.Reset(ctx, now.Add(defaultSpawnRespawn)))
+	assert.Len(t, w.MobsIn("inn"), 2)
+}
+
+func TestWorld_Reset_reads(t *testing.T) {
+	w := newSpawnTestWorld(t, &model.Spawn{ID: 1, RoomID: "inn", MobID: 3, MaxCount: 1})
+	spawns := w.spawnDao.(*memSpawnDao)
+	ctx := context.Background()
+	now := time.Now()
+
+	// the spawns are read once, not on every reset
+	require.NoError(t, w.Reset(ctx, now))
+	require.NoError(t, w.Reset(ctx, now))
+	assert.Equal(t, 1, spawns.reads)
+
+	// a spawn written through the dao drops them
+	db, err := database.OpenSqlite(":memory:")
+	require.NoError(t, err)
+	defer sgorm.CloseDB(db) //nolint
+	spawns.spawns = append(spawns.spawns, &model.Spawn{ID: 2, RoomID: "square", MobID: 3, MaxCount: 1})
+	require.NoError(t, dao.NewSpawnDao(db, nil).Create(ctx, &model.Spawn{RoomID: "square", MobID: 3}))
+	require.NoError(t, w.Reset(ctx, now))
+	assert.Equal(t, 2, spawns.reads)
+	assert.Len(t, w.MobsIn("square"), 3)
+}
@@ -0,0 +1,245 @@
+package game
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"fs/internal/cache"
+	"fs/internal/dao"
+	"fs/internal/database"
+	"fs/internal/model"
+	"fs/internal/world"
+)
+
+// World the game view of the rooms and mobs managed by the http service,
+// static data is read through the dao layer, who is where is kept in memory.
+type World struct {
+	daos
+
+	graph   *world.GraphCache // for walk, other processes may change the rooms so it also expires
+	unhooks []func()          // remove the hooks dropping the graph and the spawns
+
+	defsMu       sync.Mutex
+	defs         []*spawnDef // for the resets, nil when they are read again
+	defsLoadedAt time.Time
+
+	mu      sync.RWMutex
+	players map[string]*Session          // online players by lower case name
+	rooms   map[string]map[*Session]bool // players by room id
+
+	mobs      map[string][]*Mob      // live mobs by room id
+	spawns    map[string]*spawnState // reset state by spawn key
+	corpses   map[string][]*Corpse   // by room id, removed by the heartbeat when they decay
+	lastMobID uint64
+	roll      func(n int) int // random number in [0, n), for the spawn chance and combat
+}
+
+// daos the tables the world reads and writes
+type daos struct {
+	db *gorm.DB // the transactions of the world are started on it
+
+	roomDao      dao.RoomDao
+	exitDao      dao.RoomExitDao
+	mobDao       dao.MobDao
+	spawnDao     dao.SpawnDao
+	itemDao      dao.ItemDao
+	instanceDao  dao.ItemInstanceDao
+	accountDao   dao.AccountDao
+	characterDao dao.CharacterDao
+}
+
+// NewWorld creating the world from the configured database and cache
+func NewWorld() *World {
+	return newWorld(daos{
+		db:           database.GetDB(),
+		roomDao:      dao.NewRoomDao(database.GetDB(), cache.NewRoomCache(database.GetCacheType())),
+		exitDao:      dao.NewRoomExitDao(database.GetDB(), cache.NewRoomExitCache(database.GetCacheType())),
+		mobDao:       dao.NewMobDao(database.GetDB(), cache.NewMobCache(database.GetCacheType())),
+		spawnDao:     dao.NewSpawnDao(database.GetDB(), cache.NewSpawnCache(database.GetCacheType())),
+		itemDao:      dao.NewItemDao(database.GetDB(), cache.NewItemCache(database.GetCacheType())),
+		instanceDao:  dao.NewItemInstanceDao(database.GetDB(), cache.NewItemInstanceCache(database.GetCacheType())),
+		accountDao:   dao.NewAccountDao(database.GetDB(), cache.NewAccountCache(database.GetCacheType())),
+		characterDao: dao.NewCharacterDao(database.GetDB(), cache.NewCharacterCache(database.GetCacheType())),
+	})
+}
+
+func newWorld(d daos) *World {
+	w := &World{
+		daos:    d,
+		players: map[string]*Session{},
+		rooms:   map[string]map[*Session]bool{},
+		mobs:    map[string][]*Mob{},
+		spawns:  map[string]*spawnState{},
+		corpses: map[string][]*Corpse{},
+		roll:    defaultRoll,
+	}
+	w.graph = world.NewGraphCache(w.loadGraph, graphTTL)
+	w.unhooks = append(w.unhooks,
+		dao.OnRoomChange(func(string) {
+			w.graph.Invalidate()
+			w.dropSpawnDefs() // Room.Mobs
+		}),
+		dao.OnSpawnChange(func(uint64) {
+			w.dropSpawnDefs()
+		}),
+	)
+	return w
+}
+
+// Close remove the hooks of the world on the dao, the world is not used after it
+func (w *World) Close() {
+	for _, unhook := range w.unhooks {
+		unhook()
+	}
+}
+
+// graphTTL how long the room graph is used before it is read again
+const graphTTL = time.Minute
+
+func (w *World) loadGraph(ctx context.Context) (*world.Graph, error) {
+	rooms, err := dao.GetAllRooms(ctx, w.roomDao)
+	if err != nil {
+		return nil, err
+	}
+	exits, err := dao.GetAllRoomExits(ctx, w.exitDao)
+	if err != nil {
+		return nil, err
+	}
+	return world.NewGraph(rooms, exits), nil
+}
+
+// Room get a room by id
+func (w *World) Room(ctx context.Context, id string) (*model.Room, error) {
+	return w.roomDao.GetByID(ctx, id)
+}
+
+// RoomExits get the exits of a room, rooms that have not been migrated to
+// structured exits fall back to the "direction:room" entries in Room.Way
+func (w *World) RoomExits(ctx context.Context, room *model.Room) ([]*model.RoomExit, error) {
+	exits, err := w.exitDao.GetByRoomID(ctx, room.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(exits) > 0 {
+		return exits, nil
+	}
+
+	wayExits, _ := world.ParseWay(room.Way)
+	for _, we := range wayExits {
+		exits = append(exits, &model.RoomExit{RoomID: room.ID, Direction: string(we.Direction), ToRoomID: we.ToRoomID})
+	}
+	return exits, nil
+}
+
+// Path find the way between two rooms that players can walk, locked exits are avoided
+func (w *World) Path(ctx context.Context, from, to string) ([]*world.Exit, bool, error) {
+	g, err := w.graph.Get(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	path, ok := world.FindPath(g, from, to, world.PathOptions{AvoidLocked: true})
+	return path, ok, nil
+}
+
+// Map draw the rooms around a room as ASCII, radius is the most exits away, hidden exits are left out
+func (w *World) Map(ctx context.Context, center string, radius int) (string, error) {
+	g, err := w.graph.Get(ctx)
+	if err != nil {
+		return "", err
+	}
+	g = g.WithoutHidden()
+	return world.RenderASCII(world.NewLayout(g, center, radius), center), nil
+}
+
+// login register an online player, false if the name is already in use
+func (w *World) login(s *Session) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	key := strings.ToLower(s.name)
+	if _, ok := w.players[key]; ok {
+		return false
+	}
+	w.players[key] = s
+	return true
+}
+
+// logout remove a player from the world
+func (w *World) logout(s *Session) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.players[strings.ToLower(s.name)] == s {
+		delete(w.players, strings.ToLower(s.name))
+	}
+	s.fighting = nil
+	if s.roomID != "" {
+		delete(w.rooms[s.roomID], s)
+	}
+}
+
+// move put a player in a room, leaving a room ends the fight in it
+func (w *World) move(s *Session, roomID string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.moveLocked(s, roomID)
+}
+
+// moveLocked see move, the caller holds w.mu
+func (w *World) moveLocked(s *Session, roomID string) {
+	s.fighting = nil
+	if s.roomID != "" {
+		delete(w.rooms[s.roomID], s)
+	}
+	if w.rooms[roomID] == nil {
+		w.rooms[roomID] = map[*Session]bool{}
+	}
+	w.rooms[roomID][s] = true
+	s.roomID = roomID
+}
+
+// Players list the online players
+func (w *World) Players() []*Session {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	players := make([]*Session, 0, len(w.players))
+	for _, s := range w.players {
+		players = append(players, s)
+	}
+	return players
+}
+
+// PlayersIn list the players in a room
+func (w *World) PlayersIn(roomID string) []*Session {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	players := make([]*Session, 0, len(w.rooms[roomID]))
+	for s := range w.rooms[roomID] {
+		players = append(players, s)
+	}
+	return players
+}
+
+// Broadcast send a message to everyone in a room except the given sessions
+func (w *World) Broadcast(roomID string, msg string, except ...*Session) {
+	for _, s := range w.PlayersIn(roomID) {
+		if !containsSession(except, s) {
+			s.Println(msg)
+		}
+	}
+}
+
+func containsSession(list []*Session, s *Session) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
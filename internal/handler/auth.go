@@ -0,0 +1,196 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/go-dev-frame/sponge/pkg/gin/middleware"
+	"github.com/go-dev-frame/sponge/pkg/gin/response"
+	"github.com/go-dev-frame/sponge/pkg/gocrypto"
+	"github.com/go-dev-frame/sponge/pkg/logger"
+	"github.com/go-dev-frame/sponge/pkg/utils"
+
+	"fs/internal/auth"
+	"fs/internal/cache"
+	"fs/internal/dao"
+	"fs/internal/database"
+	"fs/internal/ecode"
+	"fs/internal/model"
+	"fs/internal/types"
+)
+
+var _ AuthHandler = (*authHandler)(nil)
+
+// AuthHandler defining the handler interface
+type AuthHandler interface {
+	Login(c *gin.Context)
+	Refresh(c *gin.Context)
+	SetRole(c *gin.Context)
+}
+
+type authHandler struct {
+	accountDao dao.AccountDao
+}
+
+// NewAuthHandler creating the handler interface
+func NewAuthHandler() AuthHandler {
+	return &authHandler{
+		accountDao: dao.NewAccountDao(
+			database.GetDB(), // db driver is mysql
+			cache.NewAccountCache(database.GetCacheType()),
+		),
+	}
+}
+
+// Login get the tokens of an account
+// @Summary Log in
+// @Description Checks the username and password of an account and returns an access token carrying its role,
+// @Description and a refresh token to get new tokens with when the access token expires.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param data body types.LoginRequest true "username and password"
+// @Success 200 {object} types.LoginReply{}
+// @Router /api/v1/auth/login [post]
+func (h *authHandler) Login(c *gin.Context) {
+	form := &types.LoginRequest{}
+	err := c.ShouldBindJSON(form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	account, err := h.accountDao.GetByUsername(ctx, form.Username)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			response.Error(c, ecode.ErrLoginAuth)
+			return
+		}
+		logger.Error("GetByUsername error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+	if !gocrypto.VerifyPassword(form.Password, account.PasswordHash) {
+		response.Error(c, ecode.ErrLoginAuth)
+		return
+	}
+
+	h.sendTokens(c, account)
+}
+
+// Refresh exchange a refresh token for new tokens
+// @Summary Refresh the tokens
+// @Description Returns new tokens for a refresh token, the role is read again from the account.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param data body types.RefreshTokenRequest true "refresh token"
+// @Success 200 {object} types.RefreshTokenReply{}
+// @Router /api/v1/auth/refresh [post]
+func (h *authHandler) Refresh(c *gin.Context) {
+	form := &types.RefreshTokenRequest{}
+	err := c.ShouldBindJSON(form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	id, err := auth.ParseRefreshToken(form.RefreshToken)
+	if err != nil {
+		logger.Warn("ParseRefreshToken error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Out(c, ecode.Unauthorized)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	account, err := h.accountDao.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			response.Out(c, ecode.Unauthorized)
+			return
+		}
+		logger.Error("GetByID error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	h.sendTokens(c, account)
+}
+
+func (h *authHandler) sendTokens(c *gin.Context, account *model.Account) {
+	tokens, err := auth.GenerateTokens(account)
+	if err != nil {
+		logger.Error("GenerateTokens error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrGenerateTokensAuth)
+		return
+	}
+
+	response.Success(c, &types.AuthTokens{
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		Role:         auth.RoleOf(account),
+	})
+}
+
+// SetRole set the role of an account
+// @Summary Set the role of an account
+// @Description Sets the role of an account to player, builder or admin, the new role is in the tokens issued after it.
+// @Tags auth
+// @accept json
+// @Produce json
+// @Param id path string true "account id"
+// @Param data body types.SetAccountRoleRequest true "role"
+// @Success 200 {object} types.SetAccountRoleReply{}
+// @Router /api/v1/account/{id}/role [put]
+// @Security BearerAuth
+func (h *authHandler) SetRole(c *gin.Context) {
+	_, id, isAbort := getAccountIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	form := &types.SetAccountRoleRequest{}
+	err := c.ShouldBindJSON(form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	_, err = h.accountDao.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			response.Error(c, ecode.NotFound)
+			return
+		}
+		logger.Error("GetByID error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	err = h.accountDao.UpdateByID(ctx, &model.Account{ID: id, Role: form.Role})
+	if err != nil {
+		logger.Error("UpdateByID error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrSetRoleAuth)
+		return
+	}
+
+	response.Success(c)
+}
+
+func getAccountIDFromPath(c *gin.Context) (string, uint64, bool) {
+	idStr := c.Param("id")
+	id, err := utils.StrToUint64E(idStr)
+	if err != nil || id == 0 {
+		logger.Warn("StrToUint64E error: ", logger.String("idStr", idStr), middleware.GCtxRequestIDField(c))
+		return "", 0, true
+	}
+
+	return idStr, id, false
+}
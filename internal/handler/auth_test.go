@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-dev-frame/sponge/pkg/gocrypto"
+	"github.com/go-dev-frame/sponge/pkg/gotest"
+	"github.com/go-dev-frame/sponge/pkg/httpcli"
+
+	"fs/internal/auth"
+	"fs/internal/dao"
+	"fs/internal/ecode"
+	"fs/internal/model"
+	"fs/internal/types"
+)
+
+func newAuthHandler(t *testing.T) *gotest.Handler {
+	hash, err := gocrypto.HashAndSaltPassword("secret")
+	require.NoError(t, err)
+	testData := &model.Account{}
+	testData.ID = 1
+	testData.Username = "alice"
+	testData.PasswordHash = hash
+	testData.Role = auth.RoleBuilder
+
+	// init mock dao, the accounts are read without cache
+	c := gotest.NewCache(map[string]interface{}{"no cache": testData})
+	d := gotest.NewDao(c, testData)
+	d.IDao = dao.NewAccountDao(d.DB, nil)
+
+	// init mock handler
+	h := gotest.NewHandler(d, testData)
+	h.IHandler = &authHandler{accountDao: d.IDao.(dao.AccountDao)}
+	iHandler := h.IHandler.(AuthHandler)
+
+	testFns := []gotest.RouterInfo{
+		{
+			FuncName:    "Login",
+			Method:      http.MethodPost,
+			Path:        "/auth/login",
+			HandlerFunc: iHandler.Login,
+		},
+		{
+			FuncName:    "Refresh",
+			Method:      http.MethodPost,
+			Path:        "/auth/refresh",
+			HandlerFunc: iHandler.Refresh,
+		},
+		{
+			FuncName:    "SetRole",
+			Method:      http.MethodPut,
+			Path:        "/account/:id/role",
+			HandlerFunc: iHandler.SetRole,
+		},
+	}
+
+	h.GoRunHTTPServer(testFns)
+
+	time.Sleep(time.Millisecond * 200)
+	return h
+}
+
+func accountRows(a *model.Account) *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "username", "password_hash", "role"}).
+		AddRow(a.ID, a.Username, a.PasswordHash, a.Role)
+}
+
+func Test_authHandler_Login(t *testing.T) {
+	h := newAuthHandler(t)
+	defer h.Close()
+	testData := h.TestData.(*model.Account)
+
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(testData.Username, 1).
+		WillReturnRows(accountRows(testData))
+
+	result := &httpcli.StdResult{}
+	err := httpcli.Post(result, h.GetRequestURL("Login"), &types.LoginRequest{Username: "alice", Password: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Code != 0 {
+		t.Fatalf("%+v", result)
+	}
+	data := result.Data.(map[string]interface{})
+	assert.Equal(t, auth.RoleBuilder, data["role"])
+	assert.NotEmpty(t, data["accessToken"])
+	id, err := auth.ParseRefreshToken(data["refreshToken"].(string))
+	require.NoError(t, err)
+	assert.Equal(t, testData.ID, id)
+
+	// wrong password
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").
+		WillReturnRows(accountRows(testData))
+	err = httpcli.Post(result, h.GetRequestURL("Login"), &types.LoginRequest{Username: "alice", Password: "guess"})
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.ErrLoginAuth.Code(), result.Code)
+
+	// unknown username
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	err = httpcli.Post(result, h.GetRequestURL("Login"), &types.LoginRequest{Username: "bob", Password: "secret"})
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.ErrLoginAuth.Code(), result.Code)
+
+	// nil params error test
+	err = httpcli.Post(result, h.GetRequestURL("Login"), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.InvalidParams.Code(), result.Code)
+}
+
+func Test_authHandler_Refresh(t *testing.T) {
+	h := newAuthHandler(t)
+	defer h.Close()
+	testData := h.TestData.(*model.Account)
+
+	tokens, err := auth.GenerateTokens(&model.Account{ID: testData.ID, Username: testData.Username})
+	require.NoError(t, err)
+
+	// the role is read again from the account
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(testData.ID, 1).
+		WillReturnRows(accountRows(testData))
+
+	result := &httpcli.StdResult{}
+	err = httpcli.Post(result, h.GetRequestURL("Refresh"), &types.RefreshTokenRequest{RefreshToken: tokens.RefreshToken})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Code != 0 {
+		t.Fatalf("%+v", result)
+	}
+	assert.Equal(t, auth.RoleBuilder, result.Data.(map[string]interface{})["role"])
+
+	// an access token is not a refresh token
+	err = httpcli.Post(result, h.GetRequestURL("Refresh"), &types.RefreshTokenRequest{RefreshToken: tokens.AccessToken})
+	assert.Error(t, err)
+}
+
+func Test_authHandler_SetRole(t *testing.T) {
+	h := newAuthHandler(t)
+	defer h.Close()
+	testData := h.TestData.(*model.Account)
+
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(testData.ID, 1).
+		WillReturnRows(accountRows(testData))
+	h.MockDao.SQLMock.ExpectBegin()
+	h.MockDao.SQLMock.ExpectExec("UPDATE .*").
+		WithArgs(auth.RoleAdmin, testData.ID).
+		WillReturnResult(sqlmock.NewResult(int64(testData.ID), 1))
+	h.MockDao.SQLMock.ExpectCommit()
+
+	result := &httpcli.StdResult{}
+	err := httpcli.Put(result, h.GetRequestURL("SetRole", testData.ID), &types.SetAccountRoleRequest{Role: auth.RoleAdmin})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Code != 0 {
+		t.Fatalf("%+v", result)
+	}
+
+	// unknown role
+	err = httpcli.Put(result, h.GetRequestURL("SetRole", testData.ID), &types.SetAccountRoleRequest{Role: "god"})
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.InvalidParams.Code(), result.Code)
+
+	// zero id error test
+	err = httpcli.Put(result, h.GetRequestURL("SetRole", 0), &types.SetAccountRoleRequest{Role: auth.RoleAdmin})
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.InvalidParams.Code(), result.Code)
+}
@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/go-dev-frame/sponge/pkg/gin/middleware"
+	"github.com/go-dev-frame/sponge/pkg/gin/response"
+	"github.com/go-dev-frame/sponge/pkg/logger"
+
+	"fs/internal/cache"
+	"fs/internal/dao"
+	"fs/internal/database"
+	"fs/internal/ecode"
+	"fs/internal/equip"
+	"fs/internal/model"
+	"fs/internal/types"
+)
+
+var _ EquipmentHandler = (*equipmentHandler)(nil)
+
+// EquipmentHandler defining the handler interface
+type EquipmentHandler interface {
+	Preview(c *gin.Context)
+}
+
+type equipmentHandler struct {
+	itemDao     dao.ItemDao
+	instanceDao dao.ItemInstanceDao
+}
+
+// NewEquipmentHandler creating the handler interface
+func NewEquipmentHandler() EquipmentHandler {
+	return &equipmentHandler{
+		itemDao: dao.NewItemDao(
+			database.GetDB(), // db driver is mysql
+			cache.NewItemCache(database.GetCacheType()),
+		),
+		instanceDao: dao.NewItemInstanceDao(
+			database.GetDB(),
+			cache.NewItemInstanceCache(database.GetCacheType()),
+		),
+	}
+}
+
+// Preview the stats of a character wearing a set of items
+// @Summary Preview an outfit
+// @Description Wears the given items and item instances in order, each in the first free slot of its classifier,
+// @Description and returns the slots, the summed item bonuses and the base stats plus the bonuses.
+// @Tags equipment
+// @Accept json
+// @Produce json
+// @Param data body types.PreviewEquipmentRequest true "base stats and items to wear"
+// @Success 200 {object} types.PreviewEquipmentReply{}
+// @Router /api/v1/equipment/preview [post]
+// @Security BearerAuth
+func (h *equipmentHandler) Preview(c *gin.Context) {
+	form := &types.PreviewEquipmentRequest{}
+	err := c.ShouldBindJSON(form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	var instances map[uint64]*model.ItemInstance
+	if len(form.InstanceIDs) > 0 {
+		instances, err = h.instanceDao.GetByIDs(ctx, form.InstanceIDs)
+		if err != nil {
+			logger.Error("GetByIDs error", logger.Err(err), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+			return
+		}
+	}
+	protoIDs := append([]uint64{}, form.ItemIDs...)
+	for _, id := range form.InstanceIDs {
+		inst, ok := instances[id]
+		if !ok {
+			response.Error(c, ecode.ErrEquipmentItem.WithDetails("instance "+strconv.FormatUint(id, 10)))
+			return
+		}
+		protoIDs = append(protoIDs, inst.ProtoID)
+	}
+	var protos map[uint64]*model.Item
+	if len(protoIDs) > 0 {
+		protos, err = h.itemDao.GetByIDs(ctx, protoIDs)
+		if err != nil {
+			logger.Error("GetByIDs error", logger.Err(err), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+			return
+		}
+	}
+
+	outfit := equip.Outfit{}
+	instanceOf := map[*model.Item]uint64{}
+	wear := func(item *model.Item) bool {
+		if _, err := outfit.Wear(item); err != nil {
+			response.Error(c, ecode.ErrEquipmentSlot.WithDetails(err.Error()))
+			return false
+		}
+		return true
+	}
+	for _, id := range form.ItemIDs {
+		proto, ok := protos[id]
+		if !ok {
+			response.Error(c, ecode.ErrEquipmentItem.WithDetails("item "+strconv.FormatUint(id, 10)))
+			return
+		}
+		if !wear(proto) {
+			return
+		}
+	}
+	for _, id := range form.InstanceIDs {
+		inst := instances[id]
+		proto, ok := protos[inst.ProtoID]
+		if !ok {
+			response.Error(c, ecode.ErrEquipmentItem.WithDetails("item "+strconv.FormatUint(inst.ProtoID, 10)))
+			return
+		}
+		item := inst.Overrides.Apply(proto)
+		instanceOf[item] = inst.ID
+		if !wear(item) {
+			return
+		}
+	}
+
+	response.Success(c, convertEquipmentPreview(form.Base, outfit, instanceOf))
+}
+
+func convertEquipmentPreview(base equip.Stats, outfit equip.Outfit, instanceOf map[*model.Item]uint64) *types.EquipmentPreview {
+	preview := &types.EquipmentPreview{
+		Slots: []*types.EquippedItemDetail{},
+		Bonus: outfit.Bonus(),
+		Stats: equip.Effective(base, outfit),
+	}
+	for _, slot := range equip.Slots {
+		item := outfit[slot]
+		if item == nil {
+			continue
+		}
+		preview.Slots = append(preview.Slots, &types.EquippedItemDetail{
+			Slot:       string(slot),
+			ItemID:     item.ID,
+			InstanceID: instanceOf[item],
+			ItemName:   item.ItemName,
+			Classifier: item.Classifier,
+			Stats:      equip.StatsOf(item),
+		})
+	}
+	return preview
+}
@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-dev-frame/sponge/pkg/gotest"
+	"github.com/go-dev-frame/sponge/pkg/httpcli"
+
+	"fs/internal/dao"
+	"fs/internal/ecode"
+	"fs/internal/equip"
+	"fs/internal/model"
+	"fs/internal/types"
+)
+
+func newEquipmentHandler() *gotest.Handler {
+	testData := &model.Item{}
+	testData.ID = 1
+	testData.ItemName = "sword"
+	testData.Classifier = string(equip.Weapon)
+	testData.Attack = 10
+
+	// init mock dao, the items are read without cache
+	c := gotest.NewCache(map[string]interface{}{"no cache": testData})
+	d := gotest.NewDao(c, testData)
+	d.IDao = dao.NewItemDao(d.DB, nil)
+
+	// init mock handler
+	h := gotest.NewHandler(d, testData)
+	h.IHandler = &equipmentHandler{
+		itemDao:     d.IDao.(dao.ItemDao),
+		instanceDao: dao.NewItemInstanceDao(d.DB, nil),
+	}
+	iHandler := h.IHandler.(EquipmentHandler)
+
+	testFns := []gotest.RouterInfo{
+		{
+			FuncName:    "Preview",
+			Method:      http.MethodPost,
+			Path:        "/equipment/preview",
+			HandlerFunc: iHandler.Preview,
+		},
+	}
+
+	h.GoRunHTTPServer(testFns)
+
+	time.Sleep(time.Millisecond * 200)
+	return h
+}
+
+func Test_equipmentHandler_Preview(t *testing.T) {
+	h := newEquipmentHandler()
+	defer h.Close()
+	testData := h.TestData.(*model.Item)
+
+	itemColumns := []string{"id", "item_name", "classifier", "attack", "kar"}
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(5).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "proto_id", "overrides"}).AddRow(5, 2, `{"kar":4}`))
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(testData.ID, 2).
+		WillReturnRows(sqlmock.NewRows(itemColumns).
+			AddRow(testData.ID, testData.ItemName, testData.Classifier, testData.Attack, 0).
+			AddRow(2, "ring", "r", 0, 2))
+
+	result := &httpcli.StdResult{}
+	err := httpcli.Post(result, h.GetRequestURL("Preview"), &types.PreviewEquipmentRequest{
+		Base:        equip.Stats{Hp: 100, Attack: 5},
+		ItemIDs:     []uint64{testData.ID},
+		InstanceIDs: []uint64{5},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Code != 0 {
+		t.Fatalf("%+v", result)
+	}
+	data := result.Data.(map[string]interface{})
+	slots := data["slots"].([]interface{})
+	require.Len(t, slots, 2)
+	assert.Equal(t, "weapon", slots[0].(map[string]interface{})["slot"])
+	assert.Equal(t, "ring1", slots[1].(map[string]interface{})["slot"])
+	assert.Equal(t, float64(5), slots[1].(map[string]interface{})["instanceID"])
+	stats := data["stats"].(map[string]interface{})
+	assert.Equal(t, float64(100), stats["hp"])
+	assert.Equal(t, float64(15), stats["attack"])
+	assert.Equal(t, float64(4), stats["kar"])
+
+	// two weapons
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").
+		WillReturnRows(sqlmock.NewRows(itemColumns).
+			AddRow(testData.ID, testData.ItemName, testData.Classifier, testData.Attack, 0).
+			AddRow(3, "axe", "w", 12, 0))
+	err = httpcli.Post(result, h.GetRequestURL("Preview"), &types.PreviewEquipmentRequest{ItemIDs: []uint64{testData.ID, 3}})
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.ErrEquipmentSlot.Code(), result.Code)
+
+	// unknown item
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").
+		WillReturnRows(sqlmock.NewRows(itemColumns))
+	err = httpcli.Post(result, h.GetRequestURL("Preview"), &types.PreviewEquipmentRequest{ItemIDs: []uint64{9}})
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.ErrEquipmentItem.Code(), result.Code)
+
+	// nil params error test
+	err = httpcli.Post(result, h.GetRequestURL("Preview"), nil)
+	assert.NoError(t, err)
+	assert.NotEqual(t, 0, result.Code)
+}
+
+// the binding rules of the item requests list the equip classifiers
+func Test_itemClassifierBinding(t *testing.T) {
+	var letters []string
+	for _, c := range equip.Classifiers {
+		letters = append(letters, string(c))
+	}
+	want := "omitempty,oneof=" + strings.Join(letters, " ")
+
+	for _, v := range []interface{}{types.CreateItemRequest{}, types.UpdateItemByIDRequest{}} {
+		f, ok := reflect.TypeOf(v).FieldByName("Classifier")
+		require.True(t, ok)
+		assert.Equal(t, want, f.Tag.Get("binding"), reflect.TypeOf(v).Name())
+	}
+}
+
+func TestNewEquipmentHandler(t *testing.T) {
+	defer func() {
+		recover()
+	}()
+	_ = NewEquipmentHandler()
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"context"
 	"errors"
 
 	"github.com/gin-gonic/gin"
@@ -12,10 +13,12 @@ import (
 	"github.com/go-dev-frame/sponge/pkg/utils"
 
 	"fs/internal/cache"
+	"fs/internal/config"
 	"fs/internal/dao"
 	"fs/internal/database"
 	"fs/internal/ecode"
 	"fs/internal/model"
+	"fs/internal/rule"
 	"fs/internal/types"
 )
 
@@ -25,28 +28,40 @@ var _ ItemHandler = (*itemHandler)(nil)
 type ItemHandler interface {
 	Create(c *gin.Context)
 	DeleteByID(c *gin.Context)
+	Restore(c *gin.Context)
 	UpdateByID(c *gin.Context)
+	PatchByID(c *gin.Context)
 	GetByID(c *gin.Context)
 	List(c *gin.Context)
+	ListByIDs(c *gin.Context)
 }
 
 type itemHandler struct {
-	iDao dao.ItemDao
+	iDao  dao.ItemDao
+	rules *rule.Rules // checked before an item is written
 }
 
 // NewItemHandler creating the handler interface
 func NewItemHandler() ItemHandler {
+	itemDao := dao.NewItemDao(
+		database.GetDB(), // db driver is mysql
+		cache.NewItemCache(database.GetCacheType()),
+	)
+	rules, err := rule.NewItem(config.Get().Rules.Item, itemDao.ExistsItemID)
+	if err != nil {
+		panic("init item rules error: " + err.Error())
+	}
+
 	return &itemHandler{
-		iDao: dao.NewItemDao(
-			database.GetDB(), // db driver is mysql
-			cache.NewItemCache(database.GetCacheType()),
-		),
+		iDao:  itemDao,
+		rules: rules,
 	}
 }
 
 // Create a new item
 // @Summary Create a new item
 // @Description Creates a new item entity using the provided data in the request body.
+// @Description The fields breaking the validation rules set in fs.yml are returned in data.errors with the code of ErrRulesItem.
 // @Tags item
 // @Accept json
 // @Produce json
@@ -72,7 +87,15 @@ func (h *itemHandler) Create(c *gin.Context) {
 	// Note: if copier.Copy cannot assign a value to a field, add it here
 
 	ctx := middleware.WrapCtx(c)
+	violations, err := h.rules.CheckCreate(ctx, item)
+	if abortOnViolations(c, ecode.ErrRulesItem, violations, err) {
+		return
+	}
+
 	err = h.iDao.Create(ctx, item)
+	if abortOnDuplicated(c, ecode.ErrRulesItem, h.rules, item, err) {
+		return
+	}
 	if err != nil {
 		logger.Error("Create error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
 		response.Output(c, ecode.InternalServerError.ToHTTPCode())
@@ -84,7 +107,7 @@ func (h *itemHandler) Create(c *gin.Context) {
 
 // DeleteByID delete a item by id
 // @Summary Delete a item by id
-// @Description Deletes a existing item identified by the given id in the path.
+// @Description Moves a existing item identified by the given id in the path to the trash, see /api/v1/item/{id}/restore.
 // @Tags item
 // @Accept json
 // @Produce json
@@ -110,13 +133,50 @@ func (h *itemHandler) DeleteByID(c *gin.Context) {
 	response.Success(c)
 }
 
+// Restore take a item out of the trash by id
+// @Summary Take a item out of the trash by id
+// @Description Brings back a deleted item identified by the given id in the path, not found if it is not in the trash.
+// @Tags item
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} types.RestoreItemByIDReply{}
+// @Router /api/v1/item/{id}/restore [post]
+// @Security BearerAuth
+func (h *itemHandler) Restore(c *gin.Context) {
+	_, id, isAbort := getItemIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	err := h.iDao.RestoreByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			logger.Warn("RestoreByID not found", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("RestoreByID error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return
+	}
+
+	response.Success(c)
+}
+
 // UpdateByID update a item by id
 // @Summary Update a item by id
 // @Description Updates the specified item by given id in the path, support partial update.
+// @Description The version read with GetByID is required in the If-Match header or the version field,
+// @Description a conflict is returned if the item has been changed since then.
+// @Description The validation rules of the fields written are checked on the item as it is after the update, see Create.
 // @Tags item
 // @Accept json
 // @Produce json
 // @Param id path string true "id"
+// @Param If-Match header string false "ETag returned by GetByID"
 // @Param data body types.UpdateItemByIDRequest true "item information"
 // @Success 200 {object} types.UpdateItemByIDReply{}
 // @Router /api/v1/item/{id} [put]
@@ -136,6 +196,10 @@ func (h *itemHandler) UpdateByID(c *gin.Context) {
 		return
 	}
 	form.ID = id
+	form.Version, isAbort = getVersion(c, form.Version)
+	if isAbort {
+		return
+	}
 
 	item := &model.Item{}
 	err = copier.Copy(item, form)
@@ -146,19 +210,93 @@ func (h *itemHandler) UpdateByID(c *gin.Context) {
 	// Note: if copier.Copy cannot assign a value to a field, add it here
 
 	ctx := middleware.WrapCtx(c)
+	if h.checkUpdateRules(ctx, c, item, writtenFields(item)) {
+		return
+	}
+
 	err = h.iDao.UpdateByID(ctx, item)
 	if err != nil {
-		logger.Error("UpdateByID error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
-		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		if errors.Is(err, database.ErrVersionConflict) {
+			logger.Warn("UpdateByID conflict", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.Conflict)
+		} else if !abortOnDuplicated(c, ecode.ErrRulesItem, h.rules, item, err) {
+			logger.Error("UpdateByID error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
 		return
 	}
 
-	response.Success(c)
+	setETag(c, item.Version)
+	response.Success(c, gin.H{"version": item.Version})
+}
+
+// PatchByID update the fields of a item given in a JSON merge patch
+// @Summary Update the fields of a item given in a JSON merge patch
+// @Description Updates the fields of the item present in the body (RFC 7396), zero values and empty strings
+// @Description included, a null field is reset to its zero value and absent fields are untouched. The version
+// @Description read with GetByID is required in the If-Match header or the version field.
+// @Description The validation rules of the fields present are checked on the item as it is after the patch, see Create.
+// @Tags item
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param If-Match header string false "ETag returned by GetByID"
+// @Param data body types.PatchItemByIDRequest true "item fields"
+// @Success 200 {object} types.PatchItemByIDReply{}
+// @Router /api/v1/item/{id} [patch]
+// @Security BearerAuth
+func (h *itemHandler) PatchByID(c *gin.Context) {
+	_, id, isAbort := getMobIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	form := &types.PatchItemByIDRequest{}
+	fields, err := bindMergePatch(c, form)
+	if err != nil {
+		logger.Warn("bindMergePatch error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	form.Version, isAbort = getVersion(c, form.Version)
+	if isAbort {
+		return
+	}
+
+	item := &model.Item{}
+	err = copier.Copy(item, form)
+	if err != nil {
+		response.Error(c, ecode.ErrPatchByIDItem)
+		return
+	}
+	item.ID = id
+
+	ctx := middleware.WrapCtx(c)
+	if h.checkUpdateRules(ctx, c, item, fields) {
+		return
+	}
+
+	err = h.iDao.PatchByID(ctx, item, fields)
+	if err != nil {
+		if errors.Is(err, database.ErrVersionConflict) {
+			logger.Warn("PatchByID conflict", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.Conflict)
+		} else if !abortOnDuplicated(c, ecode.ErrRulesItem, h.rules, item, err) {
+			logger.Error("PatchByID error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return
+	}
+
+	setETag(c, item.Version)
+	response.Success(c, gin.H{"version": item.Version})
 }
 
 // GetByID get a item by id
 // @Summary Get a item by id
 // @Description Gets detailed information of a item specified by the given id in the path.
+// @Description The version of the item is also returned in the ETag header, for the If-Match header of UpdateByID.
 // @Tags item
 // @Param id path string true "id"
 // @Accept json
@@ -194,6 +332,7 @@ func (h *itemHandler) GetByID(c *gin.Context) {
 	}
 	// Note: if copier.Copy cannot assign a value to a field, add it here
 
+	setETag(c, item.Version)
 	response.Success(c, gin.H{"item": data})
 }
 
@@ -236,6 +375,71 @@ func (h *itemHandler) List(c *gin.Context) {
 	})
 }
 
+// ListByIDs get a list of items by ids
+// @Summary Get a list of items by ids
+// @Description Returns the items with the given ids in the order of the ids, ids that do not exist are left out.
+// @Tags item
+// @Accept json
+// @Produce json
+// @Param data body types.ListItemsByIDsRequest true "id list"
+// @Success 200 {object} types.ListItemsByIDsReply{}
+// @Router /api/v1/item/list/ids [post]
+// @Security BearerAuth
+func (h *itemHandler) ListByIDs(c *gin.Context) {
+	form := &types.ListItemsByIDsRequest{}
+	err := c.ShouldBindJSON(form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	itemMap, err := h.iDao.GetByIDs(ctx, form.IDs)
+	if err != nil {
+		logger.Error("GetByIDs error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	items := []*types.ItemObjDetail{}
+	for _, id := range form.IDs {
+		if v, ok := itemMap[id]; ok {
+			record, err := convertItem(v)
+			if err != nil {
+				response.Error(c, ecode.ErrListByIDsItem)
+				return
+			}
+			items = append(items, record)
+		}
+	}
+
+	response.Success(c, gin.H{
+		"items": items,
+	})
+}
+
+// checkUpdateRules check the validation rules on the stored item with the fields written by an update
+// or a patch, an error response has been written when isAbort is true
+func (h *itemHandler) checkUpdateRules(ctx context.Context, c *gin.Context, item *model.Item, fields []string) (isAbort bool) {
+	stored, err := h.iDao.GetByID(ctx, item.ID)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			logger.Warn("GetByID not found", logger.Err(err), logger.Any("id", item.ID), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("GetByID error", logger.Err(err), logger.Any("id", item.ID), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return true
+	}
+
+	after := *stored
+	mergeFields(&after, item, fields)
+	violations, err := h.rules.CheckUpdate(ctx, &after, fields)
+	return abortOnViolations(c, ecode.ErrRulesItem, violations, err)
+}
+
 func getItemIDFromPath(c *gin.Context) (string, uint64, bool) {
 	idStr := c.Param("id")
 	id, err := utils.StrToUint64E(idStr)
@@ -15,9 +15,12 @@ import (
 	"github.com/go-dev-frame/sponge/pkg/utils"
 
 	"fs/internal/cache"
+	"fs/internal/config"
 	"fs/internal/dao"
 	"fs/internal/database"
+	"fs/internal/ecode"
 	"fs/internal/model"
+	"fs/internal/rule"
 	"fs/internal/types"
 )
 
@@ -41,7 +44,8 @@ func newItemHandler() *gotest.Handler {
 
 	// init mock handler
 	h := gotest.NewHandler(d, testData)
-	h.IHandler = &itemHandler{iDao: d.IDao.(dao.ItemDao)}
+	rules, _ := rule.NewItem(config.RuleSet{}, d.IDao.(dao.ItemDao).ExistsItemID)
+	h.IHandler = &itemHandler{iDao: d.IDao.(dao.ItemDao), rules: rules}
 	iHandler := h.IHandler.(ItemHandler)
 
 	testFns := []gotest.RouterInfo{
@@ -57,12 +61,24 @@ func newItemHandler() *gotest.Handler {
 			Path:        "/item/:id",
 			HandlerFunc: iHandler.DeleteByID,
 		},
+		{
+			FuncName:    "Restore",
+			Method:      http.MethodPost,
+			Path:        "/item/:id/restore",
+			HandlerFunc: iHandler.Restore,
+		},
 		{
 			FuncName:    "UpdateByID",
 			Method:      http.MethodPut,
 			Path:        "/item/:id",
 			HandlerFunc: iHandler.UpdateByID,
 		},
+		{
+			FuncName:    "PatchByID",
+			Method:      http.MethodPatch,
+			Path:        "/item/:id",
+			HandlerFunc: iHandler.PatchByID,
+		},
 		{
 			FuncName:    "GetByID",
 			Method:      http.MethodGet,
@@ -75,6 +91,12 @@ func newItemHandler() *gotest.Handler {
 			Path:        "/item/list",
 			HandlerFunc: iHandler.List,
 		},
+		{
+			FuncName:    "ListByIDs",
+			Method:      http.MethodPost,
+			Path:        "/item/list/ids",
+			HandlerFunc: iHandler.ListByIDs,
+		},
 	}
 
 	h.GoRunHTTPServer(testFns)
@@ -88,7 +110,13 @@ func Test_itemHandler_Create(t *testing.T) {
 	defer h.Close()
 	testData := &types.CreateItemRequest{}
 	_ = copier.Copy(testData, h.TestData.(*model.Item))
+	testData.ItemID = "sword"
+	testData.Attack = 50
+	expectedSQLForItemID := "SELECT count\\(\\*\\) FROM `item` WHERE item_id = \\? AND id <> \\?" // the trash included
 
+	h.MockDao.SQLMock.ExpectQuery(expectedSQLForItemID).
+		WithArgs(testData.ItemID, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
 	h.MockDao.SQLMock.ExpectBegin()
 	args := h.MockDao.GetAnyArgs(h.TestData)
 	h.MockDao.SQLMock.ExpectExec("INSERT INTO .*").
@@ -101,20 +129,41 @@ func Test_itemHandler_Create(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
+	if result.Code != 0 {
+		t.Fatalf("%+v", result)
+	}
 
-	t.Logf("%+v", result)
-
+	// every field breaking a rule is returned, mp requires inte
+	testData.Attack = 10000
+	testData.Mp = 20
+	h.MockDao.SQLMock.ExpectQuery(expectedSQLForItemID).
+		WithArgs(testData.ItemID, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	err = httpcli.Post(result, h.GetRequestURL("Create"), testData)
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.ErrRulesItem.Code(), result.Code)
+	assert.Equal(t, map[string]interface{}{"errors": []interface{}{
+		map[string]interface{}{"field": "attack", "rule": "range", "message": "must be between -100 and 500"},
+		map[string]interface{}{"field": "mp", "rule": "requires", "message": "requires inte to be set"},
+		map[string]interface{}{"field": "itemID", "rule": "unique", "message": "sword is already used, the records in the trash included"},
+	}}, result.Data)
+
+	// unknown classifier
+	testData.Classifier = "x"
+	err = httpcli.Post(result, h.GetRequestURL("Create"), testData)
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.InvalidParams.Code(), result.Code)
 }
 
 func Test_itemHandler_DeleteByID(t *testing.T) {
 	h := newItemHandler()
 	defer h.Close()
 	testData := h.TestData.(*model.Item)
-	expectedSQLForDeletion := "DELETE .*"
+	expectedSQLForDeletion := "UPDATE .* SET `deleted_at`=.*" // moved to the trash
 
 	h.MockDao.SQLMock.ExpectBegin()
 	h.MockDao.SQLMock.ExpectExec(expectedSQLForDeletion).
-		WithArgs(testData.ID). // adjusted for the amount of test data
+		WithArgs(h.MockDao.AnyTime, testData.ID). // adjusted for the amount of test data
 		WillReturnResult(sqlmock.NewResult(int64(testData.ID), 1))
 	h.MockDao.SQLMock.ExpectCommit()
 
@@ -136,15 +185,59 @@ func Test_itemHandler_DeleteByID(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func Test_itemHandler_Restore(t *testing.T) {
+	h := newItemHandler()
+	defer h.Close()
+	testData := h.TestData.(*model.Item)
+
+	h.MockDao.SQLMock.ExpectBegin()
+	h.MockDao.SQLMock.ExpectExec("UPDATE .* SET `deleted_at`=.*").
+		WithArgs(nil, testData.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	h.MockDao.SQLMock.ExpectCommit()
+
+	result := &httpcli.StdResult{}
+	err := httpcli.Post(result, h.GetRequestURL("Restore", testData.ID), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Code != 0 {
+		t.Fatalf("%+v", result)
+	}
+
+	// not in the trash
+	h.MockDao.SQLMock.ExpectBegin()
+	h.MockDao.SQLMock.ExpectExec("UPDATE .*").
+		WithArgs(nil, 2).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	h.MockDao.SQLMock.ExpectCommit()
+	err = httpcli.Post(result, h.GetRequestURL("Restore", 2), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.NotFound.Code(), result.Code)
+
+	// zero id error test
+	err = httpcli.Post(result, h.GetRequestURL("Restore", 0), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.InvalidParams.Code(), result.Code)
+}
+
 func Test_itemHandler_UpdateByID(t *testing.T) {
 	h := newItemHandler()
 	defer h.Close()
 	testData := &types.UpdateItemByIDRequest{}
 	_ = copier.Copy(testData, h.TestData.(*model.Item))
+	testData.Version = 1
+	// the rules are checked on the stored item, it is cached until it is updated
+	expectGetByID := func(version int) {
+		h.MockDao.SQLMock.ExpectQuery("SELECT \\* FROM `item` WHERE id = \\?").
+			WithArgs(testData.ID, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "item_id", "inte", "version"}).AddRow(testData.ID, "sword", 0, version))
+	}
 
+	expectGetByID(1)
 	h.MockDao.SQLMock.ExpectBegin()
-	h.MockDao.SQLMock.ExpectExec("UPDATE .*").
-		WithArgs(h.MockDao.AnyTime, testData.ID). // adjusted for the amount of test data
+	h.MockDao.SQLMock.ExpectExec("UPDATE .* WHERE version = \\?").
+		WithArgs(1, testData.ID).
 		WillReturnResult(sqlmock.NewResult(int64(testData.ID), 1))
 	h.MockDao.SQLMock.ExpectCommit()
 
@@ -156,6 +249,34 @@ func Test_itemHandler_UpdateByID(t *testing.T) {
 	if result.Code != 0 {
 		t.Fatalf("%+v", result)
 	}
+	assert.Equal(t, map[string]interface{}{"version": float64(2)}, result.Data)
+
+	// the If-Match header comes before the version field, the item has been changed since version 2
+	expectGetByID(2)
+	h.MockDao.SQLMock.ExpectBegin()
+	h.MockDao.SQLMock.ExpectExec("UPDATE .* WHERE version = \\?").
+		WithArgs(2, testData.ID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	h.MockDao.SQLMock.ExpectCommit()
+	err = httpcli.Put(result, h.GetRequestURL("UpdateByID", testData.ID), testData,
+		httpcli.WithHeaders(map[string]string{"If-Match": `W/"2"`}))
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.Conflict.Code(), result.Code)
+
+	// missing or invalid version
+	err = httpcli.Put(result, h.GetRequestURL("UpdateByID", testData.ID), &types.UpdateItemByIDRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.InvalidParams.Code(), result.Code)
+	err = httpcli.Put(result, h.GetRequestURL("UpdateByID", testData.ID), testData,
+		httpcli.WithHeaders(map[string]string{"If-Match": "*"}))
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.InvalidParams.Code(), result.Code)
+
+	// the stored item has no inte
+	expectGetByID(2)
+	err = httpcli.Put(result, h.GetRequestURL("UpdateByID", testData.ID), &types.UpdateItemByIDRequest{Mp: 20, Version: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.ErrRulesItem.Code(), result.Code)
 
 	// zero id error test
 	err = httpcli.Put(result, h.GetRequestURL("UpdateByID", 0), testData)
@@ -166,6 +287,70 @@ func Test_itemHandler_UpdateByID(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func Test_itemHandler_PatchByID(t *testing.T) {
+	h := newItemHandler()
+	defer h.Close()
+	testData := h.TestData.(*model.Item)
+	// the rules are checked on the stored item, it is cached until it is patched
+	expectGetByID := func(version int) {
+		h.MockDao.SQLMock.ExpectQuery("SELECT \\* FROM `item` WHERE id = \\?").
+			WithArgs(testData.ID, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "item_id", "version"}).AddRow(testData.ID, "sword", version))
+	}
+
+	// a zero value and a null are written, the absent fields are not
+	expectGetByID(1)
+	h.MockDao.SQLMock.ExpectBegin()
+	h.MockDao.SQLMock.ExpectExec("UPDATE `item` SET `item_desc`=\\?,`kar`=\\?,`version`=\\? WHERE version = \\?").
+		WithArgs("", 0, 2, 1, testData.ID).
+		WillReturnResult(sqlmock.NewResult(int64(testData.ID), 1))
+	h.MockDao.SQLMock.ExpectCommit()
+
+	result := &httpcli.StdResult{}
+	patch := map[string]interface{}{"kar": 0, "itemDesc": nil, "version": 1}
+	err := httpcli.Patch(result, h.GetRequestURL("PatchByID", testData.ID), patch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Code != 0 {
+		t.Fatalf("%+v", result)
+	}
+	assert.Equal(t, map[string]interface{}{"version": float64(2)}, result.Data)
+
+	// changed since the version in If-Match
+	expectGetByID(2)
+	h.MockDao.SQLMock.ExpectBegin()
+	h.MockDao.SQLMock.ExpectExec("UPDATE `item` SET `kar`=\\?,`version`=\\? WHERE version = \\?").
+		WithArgs(0, 3, 2, testData.ID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	h.MockDao.SQLMock.ExpectCommit()
+	err = httpcli.Patch(result, h.GetRequestURL("PatchByID", testData.ID), map[string]interface{}{"kar": 0},
+		httpcli.WithHeaders(map[string]string{"If-Match": `"2"`}))
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.Conflict.Code(), result.Code)
+
+	// out of range
+	expectGetByID(3)
+	err = httpcli.Patch(result, h.GetRequestURL("PatchByID", testData.ID), map[string]interface{}{"kar": 11, "version": 3})
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.ErrRulesItem.Code(), result.Code)
+	assert.Equal(t, map[string]interface{}{"errors": []interface{}{
+		map[string]interface{}{"field": "kar", "rule": "range", "message": "must be between -10 and 10"},
+	}}, result.Data)
+
+	// not a merge patch, unknown field, missing version
+	for _, body := range []interface{}{[]int{1}, map[string]interface{}{"id": 2, "version": 1}, map[string]interface{}{"kar": 0}} {
+		err = httpcli.Patch(result, h.GetRequestURL("PatchByID", testData.ID), body)
+		assert.NoError(t, err)
+		assert.Equal(t, ecode.InvalidParams.Code(), result.Code)
+	}
+
+	// zero id error test
+	err = httpcli.Patch(result, h.GetRequestURL("PatchByID", 0), patch)
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.InvalidParams.Code(), result.Code)
+}
+
 func Test_itemHandler_GetByID(t *testing.T) {
 	h := newItemHandler()
 	defer h.Close()
@@ -234,6 +419,34 @@ func Test_itemHandler_List(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func Test_itemHandler_ListByIDs(t *testing.T) {
+	h := newItemHandler()
+	defer h.Close()
+	testData := h.TestData.(*model.Item)
+
+	// column names and corresponding data
+	rows := sqlmock.NewRows([]string{"id"}).
+		AddRow(testData.ID)
+
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").WillReturnRows(rows)
+
+	result := &httpcli.StdResult{}
+	err := httpcli.Post(result, h.GetRequestURL("ListByIDs"), &types.ListItemsByIDsRequest{IDs: []uint64{2, testData.ID}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Code != 0 {
+		t.Fatalf("%+v", result)
+	}
+	list := result.Data.(map[string]interface{})["items"].([]interface{})
+	assert.Len(t, list, 1)
+
+	// nil params error test
+	err = httpcli.Post(result, h.GetRequestURL("ListByIDs"), nil)
+	assert.NoError(t, err)
+	assert.NotEqual(t, 0, result.Code)
+}
+
 func TestNewItemHandler(t *testing.T) {
 	defer func() {
 		recover()
@@ -1,9 +1,12 @@
 package handler
 
 import (
+	"context"
 	"errors"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 
 	"github.com/go-dev-frame/sponge/pkg/copier"
 	"github.com/go-dev-frame/sponge/pkg/gin/middleware"
@@ -12,10 +15,13 @@ import (
 	"github.com/go-dev-frame/sponge/pkg/utils"
 
 	"fs/internal/cache"
+	"fs/internal/config"
 	"fs/internal/dao"
 	"fs/internal/database"
 	"fs/internal/ecode"
 	"fs/internal/model"
+	"fs/internal/reference"
+	"fs/internal/rule"
 	"fs/internal/types"
 )
 
@@ -25,28 +31,44 @@ var _ MobHandler = (*mobHandler)(nil)
 type MobHandler interface {
 	Create(c *gin.Context)
 	DeleteByID(c *gin.Context)
+	Restore(c *gin.Context)
 	UpdateByID(c *gin.Context)
+	PatchByID(c *gin.Context)
 	GetByID(c *gin.Context)
 	List(c *gin.Context)
+	ListByIDs(c *gin.Context)
 }
 
 type mobHandler struct {
-	iDao dao.MobDao
+	iDao    dao.MobDao
+	rules   *rule.Rules    // checked before a mob is written
+	refDaos reference.Daos // the tables checked by a delete
+	db      *gorm.DB       // the transaction of a delete is started on it
 }
 
 // NewMobHandler creating the handler interface
 func NewMobHandler() MobHandler {
+	mobDao := dao.NewMobDao(
+		database.GetDB(), // db driver is mysql
+		cache.NewMobCache(database.GetCacheType()),
+	)
+	rules, err := rule.NewMob(config.Get().Rules.Mob, mobDao.ExistsMobID)
+	if err != nil {
+		panic("init mob rules error: " + err.Error())
+	}
+
 	return &mobHandler{
-		iDao: dao.NewMobDao(
-			database.GetDB(), // db driver is mysql
-			cache.NewMobCache(database.GetCacheType()),
-		),
+		iDao:    mobDao,
+		rules:   rules,
+		refDaos: newReferenceDaos(),
+		db:      database.GetDB(),
 	}
 }
 
 // Create a new mob
 // @Summary Create a new mob
 // @Description Creates a new mob entity using the provided data in the request body.
+// @Description The fields breaking the validation rules set in fs.yml are returned in data.errors with the code of ErrRulesMob.
 // @Tags mob
 // @Accept json
 // @Produce json
@@ -72,7 +94,15 @@ func (h *mobHandler) Create(c *gin.Context) {
 	// Note: if copier.Copy cannot assign a value to a field, add it here
 
 	ctx := middleware.WrapCtx(c)
+	violations, err := h.rules.CheckCreate(ctx, mob)
+	if abortOnViolations(c, ecode.ErrRulesMob, violations, err) {
+		return
+	}
+
 	err = h.iDao.Create(ctx, mob)
+	if abortOnDuplicated(c, ecode.ErrRulesMob, h.rules, mob, err) {
+		return
+	}
 	if err != nil {
 		logger.Error("Create error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
 		response.Output(c, ecode.InternalServerError.ToHTTPCode())
@@ -84,15 +114,56 @@ func (h *mobHandler) Create(c *gin.Context) {
 
 // DeleteByID delete a mob by id
 // @Summary Delete a mob by id
-// @Description Deletes a existing mob identified by the given id in the path.
+// @Description Moves a existing mob identified by the given id in the path to the trash, see /api/v1/mob/{id}/restore.
+// @Description The delete is refused with the references to the mob, rooms listing it in mobs, spawns or the item instances
+// @Description it carries, unless cascade is true, then the references are removed in the same transaction and returned.
+// @Description A mob whose references were removed is purged instead of moved to the trash, it cannot be restored without them.
 // @Tags mob
 // @Accept json
 // @Produce json
 // @Param id path string true "id"
+// @Param cascade query bool false "remove the references to the mob"
 // @Success 200 {object} types.DeleteMobByIDReply{}
 // @Router /api/v1/mob/{id} [delete]
 // @Security BearerAuth
 func (h *mobHandler) DeleteByID(c *gin.Context) {
+	_, id, isAbort := getMobIDFromPath(c)
+	cascade, err := strconv.ParseBool(c.DefaultQuery("cascade", "false"))
+	if isAbort || err != nil {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	refs, err := reference.DeleteMob(ctx, h.db, h.refDaos, id, cascade)
+	if err != nil {
+		if errors.Is(err, reference.ErrReferenced) {
+			logger.Warn("DeleteByID referenced", logger.Err(err), logger.Any("id", id), logger.Any("references", refs), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.FailedPrecondition.WithDetails(referencedDetails(refs)))
+		} else {
+			logger.Error("DeleteByID error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return
+	}
+	if refs == nil {
+		refs = []reference.Reference{}
+	}
+
+	response.Success(c, gin.H{"references": refs})
+}
+
+// Restore take a mob out of the trash by id
+// @Summary Take a mob out of the trash by id
+// @Description Brings back a deleted mob identified by the given id in the path, not found if it is not in the trash.
+// @Tags mob
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} types.RestoreMobByIDReply{}
+// @Router /api/v1/mob/{id}/restore [post]
+// @Security BearerAuth
+func (h *mobHandler) Restore(c *gin.Context) {
 	_, id, isAbort := getMobIDFromPath(c)
 	if isAbort {
 		response.Error(c, ecode.InvalidParams)
@@ -100,10 +171,15 @@ func (h *mobHandler) DeleteByID(c *gin.Context) {
 	}
 
 	ctx := middleware.WrapCtx(c)
-	err := h.iDao.DeleteByID(ctx, id)
+	err := h.iDao.RestoreByID(ctx, id)
 	if err != nil {
-		logger.Error("DeleteByID error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
-		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		if errors.Is(err, database.ErrRecordNotFound) {
+			logger.Warn("RestoreByID not found", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("RestoreByID error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
 		return
 	}
 
@@ -113,10 +189,14 @@ func (h *mobHandler) DeleteByID(c *gin.Context) {
 // UpdateByID update a mob by id
 // @Summary Update a mob by id
 // @Description Updates the specified mob by given id in the path, support partial update.
+// @Description The version read with GetByID is required in the If-Match header or the version field,
+// @Description a conflict is returned if the mob has been changed since then.
+// @Description The validation rules of the fields written are checked on the mob as it is after the update, see Create.
 // @Tags mob
 // @Accept json
 // @Produce json
 // @Param id path string true "id"
+// @Param If-Match header string false "ETag returned by GetByID"
 // @Param data body types.UpdateMobByIDRequest true "mob information"
 // @Success 200 {object} types.UpdateMobByIDReply{}
 // @Router /api/v1/mob/{id} [put]
@@ -136,6 +216,10 @@ func (h *mobHandler) UpdateByID(c *gin.Context) {
 		return
 	}
 	form.ID = id
+	form.Version, isAbort = getVersion(c, form.Version)
+	if isAbort {
+		return
+	}
 
 	mob := &model.Mob{}
 	err = copier.Copy(mob, form)
@@ -146,19 +230,93 @@ func (h *mobHandler) UpdateByID(c *gin.Context) {
 	// Note: if copier.Copy cannot assign a value to a field, add it here
 
 	ctx := middleware.WrapCtx(c)
+	if h.checkUpdateRules(ctx, c, mob, writtenFields(mob)) {
+		return
+	}
+
 	err = h.iDao.UpdateByID(ctx, mob)
 	if err != nil {
-		logger.Error("UpdateByID error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
-		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		if errors.Is(err, database.ErrVersionConflict) {
+			logger.Warn("UpdateByID conflict", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.Conflict)
+		} else if !abortOnDuplicated(c, ecode.ErrRulesMob, h.rules, mob, err) {
+			logger.Error("UpdateByID error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
 		return
 	}
 
-	response.Success(c)
+	setETag(c, mob.Version)
+	response.Success(c, gin.H{"version": mob.Version})
+}
+
+// PatchByID update the fields of a mob given in a JSON merge patch
+// @Summary Update the fields of a mob given in a JSON merge patch
+// @Description Updates the fields of the mob present in the body (RFC 7396), zero values and empty strings
+// @Description included, a null field is reset to its zero value and absent fields are untouched. The version
+// @Description read with GetByID is required in the If-Match header or the version field.
+// @Description The validation rules of the fields present are checked on the mob as it is after the patch, see Create.
+// @Tags mob
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param If-Match header string false "ETag returned by GetByID"
+// @Param data body types.PatchMobByIDRequest true "mob fields"
+// @Success 200 {object} types.PatchMobByIDReply{}
+// @Router /api/v1/mob/{id} [patch]
+// @Security BearerAuth
+func (h *mobHandler) PatchByID(c *gin.Context) {
+	_, id, isAbort := getMobIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	form := &types.PatchMobByIDRequest{}
+	fields, err := bindMergePatch(c, form)
+	if err != nil {
+		logger.Warn("bindMergePatch error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	form.Version, isAbort = getVersion(c, form.Version)
+	if isAbort {
+		return
+	}
+
+	mob := &model.Mob{}
+	err = copier.Copy(mob, form)
+	if err != nil {
+		response.Error(c, ecode.ErrPatchByIDMob)
+		return
+	}
+	mob.ID = id
+
+	ctx := middleware.WrapCtx(c)
+	if h.checkUpdateRules(ctx, c, mob, fields) {
+		return
+	}
+
+	err = h.iDao.PatchByID(ctx, mob, fields)
+	if err != nil {
+		if errors.Is(err, database.ErrVersionConflict) {
+			logger.Warn("PatchByID conflict", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.Conflict)
+		} else if !abortOnDuplicated(c, ecode.ErrRulesMob, h.rules, mob, err) {
+			logger.Error("PatchByID error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return
+	}
+
+	setETag(c, mob.Version)
+	response.Success(c, gin.H{"version": mob.Version})
 }
 
 // GetByID get a mob by id
 // @Summary Get a mob by id
 // @Description Gets detailed information of a mob specified by the given id in the path.
+// @Description The version of the mob is also returned in the ETag header, for the If-Match header of UpdateByID.
 // @Tags mob
 // @Param id path string true "id"
 // @Accept json
@@ -194,6 +352,7 @@ func (h *mobHandler) GetByID(c *gin.Context) {
 	}
 	// Note: if copier.Copy cannot assign a value to a field, add it here
 
+	setETag(c, mob.Version)
 	response.Success(c, gin.H{"mob": data})
 }
 
@@ -236,6 +395,71 @@ func (h *mobHandler) List(c *gin.Context) {
 	})
 }
 
+// ListByIDs get a list of mobs by ids
+// @Summary Get a list of mobs by ids
+// @Description Returns the mobs with the given ids in the order of the ids, ids that do not exist are left out.
+// @Tags mob
+// @Accept json
+// @Produce json
+// @Param data body types.ListMobsByIDsRequest true "id list"
+// @Success 200 {object} types.ListMobsByIDsReply{}
+// @Router /api/v1/mob/list/ids [post]
+// @Security BearerAuth
+func (h *mobHandler) ListByIDs(c *gin.Context) {
+	form := &types.ListMobsByIDsRequest{}
+	err := c.ShouldBindJSON(form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	mobMap, err := h.iDao.GetByIDs(ctx, form.IDs)
+	if err != nil {
+		logger.Error("GetByIDs error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	mobs := []*types.MobObjDetail{}
+	for _, id := range form.IDs {
+		if v, ok := mobMap[id]; ok {
+			record, err := convertMob(v)
+			if err != nil {
+				response.Error(c, ecode.ErrListByIDsMob)
+				return
+			}
+			mobs = append(mobs, record)
+		}
+	}
+
+	response.Success(c, gin.H{
+		"mobs": mobs,
+	})
+}
+
+// checkUpdateRules check the validation rules on the stored mob with the fields written by an update
+// or a patch, an error response has been written when isAbort is true
+func (h *mobHandler) checkUpdateRules(ctx context.Context, c *gin.Context, mob *model.Mob, fields []string) (isAbort bool) {
+	stored, err := h.iDao.GetByID(ctx, mob.ID)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			logger.Warn("GetByID not found", logger.Err(err), logger.Any("id", mob.ID), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("GetByID error", logger.Err(err), logger.Any("id", mob.ID), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return true
+	}
+
+	after := *stored
+	mergeFields(&after, mob, fields)
+	violations, err := h.rules.CheckUpdate(ctx, &after, fields)
+	return abortOnViolations(c, ecode.ErrRulesMob, violations, err)
+}
+
 func getMobIDFromPath(c *gin.Context) (string, uint64, bool) {
 	idStr := c.Param("id")
 	id, err := utils.StrToUint64E(idStr)
@@ -15,9 +15,13 @@ import (
 	"github.com/go-dev-frame/sponge/pkg/utils"
 
 	"fs/internal/cache"
+	"fs/internal/config"
 	"fs/internal/dao"
 	"fs/internal/database"
+	"fs/internal/ecode"
 	"fs/internal/model"
+	"fs/internal/reference"
+	"fs/internal/rule"
 	"fs/internal/types"
 )
 
@@ -41,7 +45,20 @@ func newMobHandler() *gotest.Handler {
 
 	// init mock handler
 	h := gotest.NewHandler(d, testData)
-	h.IHandler = &mobHandler{iDao: d.IDao.(dao.MobDao)}
+	rules, _ := rule.NewMob(config.RuleSet{}, d.IDao.(dao.MobDao).ExistsMobID)
+	h.IHandler = &mobHandler{
+		iDao:  d.IDao.(dao.MobDao),
+		rules: rules,
+		refDaos: reference.Daos{
+			Rooms:         dao.NewRoomDao(d.DB, nil),
+			RoomExits:     dao.NewRoomExitDao(d.DB, nil),
+			Mobs:          d.IDao.(dao.MobDao),
+			Spawns:        dao.NewSpawnDao(d.DB, nil),
+			ItemInstances: dao.NewItemInstanceDao(d.DB, nil),
+			Characters:    dao.NewCharacterDao(d.DB, nil),
+		},
+		db: d.DB,
+	}
 	iHandler := h.IHandler.(MobHandler)
 
 	testFns := []gotest.RouterInfo{
@@ -57,12 +74,24 @@ func newMobHandler() *gotest.Handler {
 			Path:        "/mob/:id",
 			HandlerFunc: iHandler.DeleteByID,
 		},
+		{
+			FuncName:    "Restore",
+			Method:      http.MethodPost,
+			Path:        "/mob/:id/restore",
+			HandlerFunc: iHandler.Restore,
+		},
 		{
 			FuncName:    "UpdateByID",
 			Method:      http.MethodPut,
 			Path:        "/mob/:id",
 			HandlerFunc: iHandler.UpdateByID,
 		},
+		{
+			FuncName:    "PatchByID",
+			Method:      http.MethodPatch,
+			Path:        "/mob/:id",
+			HandlerFunc: iHandler.PatchByID,
+		},
 		{
 			FuncName:    "GetByID",
 			Method:      http.MethodGet,
@@ -75,6 +104,12 @@ func newMobHandler() *gotest.Handler {
 			Path:        "/mob/list",
 			HandlerFunc: iHandler.List,
 		},
+		{
+			FuncName:    "ListByIDs",
+			Method:      http.MethodPost,
+			Path:        "/mob/list/ids",
+			HandlerFunc: iHandler.ListByIDs,
+		},
 	}
 
 	h.GoRunHTTPServer(testFns)
@@ -88,7 +123,13 @@ func Test_mobHandler_Create(t *testing.T) {
 	defer h.Close()
 	testData := &types.CreateMobRequest{}
 	_ = copier.Copy(testData, h.TestData.(*model.Mob))
+	testData.MobID = "guard"
+	testData.Hp = 100
+	expectedSQLForMobID := "SELECT count\\(\\*\\) FROM `mob` WHERE mob_id = \\? AND id <> \\?" // the trash included
 
+	h.MockDao.SQLMock.ExpectQuery(expectedSQLForMobID).
+		WithArgs(testData.MobID, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
 	h.MockDao.SQLMock.ExpectBegin()
 	args := h.MockDao.GetAnyArgs(h.TestData)
 	h.MockDao.SQLMock.ExpectExec("INSERT INTO .*").
@@ -101,20 +142,68 @@ func Test_mobHandler_Create(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
+	if result.Code != 0 {
+		t.Fatalf("%+v", result)
+	}
 
-	t.Logf("%+v", result)
-
+	// every field breaking a rule is returned
+	testData.Hp = -1
+	testData.Attack = 10000
+	h.MockDao.SQLMock.ExpectQuery(expectedSQLForMobID).
+		WithArgs(testData.MobID, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	err = httpcli.Post(result, h.GetRequestURL("Create"), testData)
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.ErrRulesMob.Code(), result.Code)
+	assert.Equal(t, map[string]interface{}{"errors": []interface{}{
+		map[string]interface{}{"field": "hp", "rule": "range", "message": "must be between 1 and 100000"},
+		map[string]interface{}{"field": "attack", "rule": "range", "message": "must be between 0 and 1000"},
+		map[string]interface{}{"field": "mobID", "rule": "unique", "message": "guard is already used, the records in the trash included"},
+	}}, result.Data)
+
+	// another request took the mob id between the check and the insert, the unique index refuses it
+	testData.Hp = 100
+	testData.Attack = 1
+	h.MockDao.SQLMock.ExpectQuery(expectedSQLForMobID).
+		WithArgs(testData.MobID, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	h.MockDao.SQLMock.ExpectBegin()
+	h.MockDao.SQLMock.ExpectExec("INSERT INTO .*").
+		WillReturnError(database.ErrDuplicatedKey)
+	h.MockDao.SQLMock.ExpectRollback()
+	err = httpcli.Post(result, h.GetRequestURL("Create"), testData)
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.ErrRulesMob.Code(), result.Code)
+	assert.Equal(t, map[string]interface{}{"errors": []interface{}{
+		map[string]interface{}{"field": "mobID", "rule": "unique", "message": "guard is already used, the records in the trash included"},
+	}}, result.Data)
 }
 
 func Test_mobHandler_DeleteByID(t *testing.T) {
 	h := newMobHandler()
 	defer h.Close()
 	testData := h.TestData.(*model.Mob)
-	expectedSQLForDeletion := "DELETE .*"
+	expectedSQLForDeletion := "UPDATE .* SET `deleted_at`=.*" // moved to the trash
+	expectReferences := func(rooms *sqlmock.Rows, spawns *sqlmock.Rows) {
+		h.MockDao.SQLMock.ExpectBegin()
+		h.MockDao.SQLMock.ExpectQuery("SELECT \\* FROM `room` WHERE mobs LIKE \\?").
+			WithArgs("%1%").
+			WillReturnRows(rooms)
+		h.MockDao.SQLMock.ExpectQuery("SELECT \\* FROM `spawn` WHERE mob_id = \\?").
+			WithArgs(testData.ID).
+			WillReturnRows(spawns)
+		h.MockDao.SQLMock.ExpectQuery("SELECT \\* FROM `item_instance` WHERE location_type = \\? AND owner_id = \\?").
+			WithArgs(model.ItemLocationMob, "1").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	}
+	roomRows := func() *sqlmock.Rows {
+		// the hall lists mob 11, not mob 1
+		return sqlmock.NewRows([]string{"id", "mobs", "version"}).AddRow("square", "1, 2", 3).AddRow("hall", "11", 1)
+	}
 
-	h.MockDao.SQLMock.ExpectBegin()
+	expectReferences(sqlmock.NewRows([]string{"id"}), sqlmock.NewRows([]string{"id"}))
 	h.MockDao.SQLMock.ExpectExec(expectedSQLForDeletion).
-		WithArgs(testData.ID). // adjusted for the amount of test data
+		WithArgs(h.MockDao.AnyTime, testData.ID). // adjusted for the amount of test data
 		WillReturnResult(sqlmock.NewResult(int64(testData.ID), 1))
 	h.MockDao.SQLMock.ExpectCommit()
 
@@ -127,24 +216,99 @@ func Test_mobHandler_DeleteByID(t *testing.T) {
 		t.Fatalf("%+v", result)
 	}
 
-	// zero id error test
+	// refused while a room and a spawn refer to the mob
+	expectReferences(roomRows(), sqlmock.NewRows([]string{"id", "mob_id"}).AddRow(4, testData.ID))
+	h.MockDao.SQLMock.ExpectRollback()
+	err = httpcli.Delete(result, h.GetRequestURL("DeleteByID", testData.ID))
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.FailedPrecondition.Code(), result.Code)
+	assert.Contains(t, result.Msg, "room square mobs, spawn 4 mob_id")
+
+	// the references are removed in the transaction of the delete, and the mob is purged with them
+	expectReferences(roomRows(), sqlmock.NewRows([]string{"id", "mob_id"}).AddRow(4, testData.ID))
+	h.MockDao.SQLMock.ExpectExec("UPDATE `room` SET `mobs`=\\?,`version`=\\? WHERE version = \\?").
+		WithArgs("2", 4, 3, "square").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	h.MockDao.SQLMock.ExpectExec("DELETE FROM `spawn` WHERE id = \\?").
+		WithArgs(4).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	h.MockDao.SQLMock.ExpectExec("DELETE FROM `mob` WHERE id = \\?").
+		WithArgs(testData.ID).
+		WillReturnResult(sqlmock.NewResult(int64(testData.ID), 1))
+	h.MockDao.SQLMock.ExpectCommit()
+	err = httpcli.Delete(result, h.GetRequestURL("DeleteByID", testData.ID), httpcli.WithParams(map[string]interface{}{"cascade": true}))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.Code)
+	assert.Equal(t, map[string]interface{}{"references": []interface{}{
+		map[string]interface{}{"table": "room", "id": "square", "column": "mobs"},
+		map[string]interface{}{"table": "spawn", "id": "4", "column": "mob_id"},
+	}}, result.Data)
+
+	// zero id and invalid cascade error test
 	err = httpcli.Delete(result, h.GetRequestURL("DeleteByID", 0))
 	assert.NoError(t, err)
+	err = httpcli.Delete(result, h.GetRequestURL("DeleteByID", testData.ID), httpcli.WithParams(map[string]interface{}{"cascade": "maybe"}))
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.InvalidParams.Code(), result.Code)
 
 	// delete error test
 	err = httpcli.Delete(result, h.GetRequestURL("DeleteByID", 111))
 	assert.Error(t, err)
 }
 
+func Test_mobHandler_Restore(t *testing.T) {
+	h := newMobHandler()
+	defer h.Close()
+	testData := h.TestData.(*model.Mob)
+
+	h.MockDao.SQLMock.ExpectBegin()
+	h.MockDao.SQLMock.ExpectExec("UPDATE .* SET `deleted_at`=.*").
+		WithArgs(nil, testData.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	h.MockDao.SQLMock.ExpectCommit()
+
+	result := &httpcli.StdResult{}
+	err := httpcli.Post(result, h.GetRequestURL("Restore", testData.ID), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Code != 0 {
+		t.Fatalf("%+v", result)
+	}
+
+	// not in the trash
+	h.MockDao.SQLMock.ExpectBegin()
+	h.MockDao.SQLMock.ExpectExec("UPDATE .*").
+		WithArgs(nil, 2).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	h.MockDao.SQLMock.ExpectCommit()
+	err = httpcli.Post(result, h.GetRequestURL("Restore", 2), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.NotFound.Code(), result.Code)
+
+	// zero id error test
+	err = httpcli.Post(result, h.GetRequestURL("Restore", 0), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.InvalidParams.Code(), result.Code)
+}
+
 func Test_mobHandler_UpdateByID(t *testing.T) {
 	h := newMobHandler()
 	defer h.Close()
 	testData := &types.UpdateMobByIDRequest{}
 	_ = copier.Copy(testData, h.TestData.(*model.Mob))
+	testData.Version = 1
+	// the rules are checked on the stored mob, it is cached until it is updated
+	expectGetByID := func(version int) {
+		h.MockDao.SQLMock.ExpectQuery("SELECT \\* FROM `mob` WHERE id = \\?").
+			WithArgs(testData.ID, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "mob_id", "hp", "version"}).AddRow(testData.ID, "guard", 100, version))
+	}
 
+	expectGetByID(1)
 	h.MockDao.SQLMock.ExpectBegin()
-	h.MockDao.SQLMock.ExpectExec("UPDATE .*").
-		WithArgs(h.MockDao.AnyTime, testData.ID). // adjusted for the amount of test data
+	h.MockDao.SQLMock.ExpectExec("UPDATE .* WHERE version = \\?").
+		WithArgs(1, testData.ID).
 		WillReturnResult(sqlmock.NewResult(int64(testData.ID), 1))
 	h.MockDao.SQLMock.ExpectCommit()
 
@@ -156,6 +320,34 @@ func Test_mobHandler_UpdateByID(t *testing.T) {
 	if result.Code != 0 {
 		t.Fatalf("%+v", result)
 	}
+	assert.Equal(t, map[string]interface{}{"version": float64(2)}, result.Data)
+
+	// the If-Match header comes before the version field, the mob has been changed since version 2
+	expectGetByID(2)
+	h.MockDao.SQLMock.ExpectBegin()
+	h.MockDao.SQLMock.ExpectExec("UPDATE .* WHERE version = \\?").
+		WithArgs(2, testData.ID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	h.MockDao.SQLMock.ExpectCommit()
+	err = httpcli.Put(result, h.GetRequestURL("UpdateByID", testData.ID), testData,
+		httpcli.WithHeaders(map[string]string{"If-Match": `W/"2"`}))
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.Conflict.Code(), result.Code)
+
+	// missing or invalid version
+	err = httpcli.Put(result, h.GetRequestURL("UpdateByID", testData.ID), &types.UpdateMobByIDRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.InvalidParams.Code(), result.Code)
+	err = httpcli.Put(result, h.GetRequestURL("UpdateByID", testData.ID), testData,
+		httpcli.WithHeaders(map[string]string{"If-Match": "*"}))
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.InvalidParams.Code(), result.Code)
+
+	// out of range
+	expectGetByID(2)
+	err = httpcli.Put(result, h.GetRequestURL("UpdateByID", testData.ID), &types.UpdateMobByIDRequest{Hp: 200000, Version: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.ErrRulesMob.Code(), result.Code)
 
 	// zero id error test
 	err = httpcli.Put(result, h.GetRequestURL("UpdateByID", 0), testData)
@@ -166,27 +358,101 @@ func Test_mobHandler_UpdateByID(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func Test_mobHandler_PatchByID(t *testing.T) {
+	h := newMobHandler()
+	defer h.Close()
+	testData := h.TestData.(*model.Mob)
+	// the rules are checked on the stored mob, it is cached until it is patched
+	expectGetByID := func(version int) {
+		h.MockDao.SQLMock.ExpectQuery("SELECT \\* FROM `mob` WHERE id = \\?").
+			WithArgs(testData.ID, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "mob_id", "hp", "version"}).AddRow(testData.ID, "guard", 100, version))
+	}
+
+	// a zero value and a null are written, the absent fields are not
+	expectGetByID(1)
+	h.MockDao.SQLMock.ExpectBegin()
+	h.MockDao.SQLMock.ExpectExec("UPDATE `mob` SET `mob_desc`=\\?,`attack`=\\?,`version`=\\? WHERE version = \\?").
+		WithArgs("", 0, 2, 1, testData.ID).
+		WillReturnResult(sqlmock.NewResult(int64(testData.ID), 1))
+	h.MockDao.SQLMock.ExpectCommit()
+
+	result := &httpcli.StdResult{}
+	patch := map[string]interface{}{"attack": 0, "mobDesc": nil, "version": 1}
+	err := httpcli.Patch(result, h.GetRequestURL("PatchByID", testData.ID), patch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Code != 0 {
+		t.Fatalf("%+v", result)
+	}
+	assert.Equal(t, map[string]interface{}{"version": float64(2)}, result.Data)
+
+	// changed since the version in If-Match
+	expectGetByID(2)
+	h.MockDao.SQLMock.ExpectBegin()
+	h.MockDao.SQLMock.ExpectExec("UPDATE `mob` SET `attack`=\\?,`version`=\\? WHERE version = \\?").
+		WithArgs(0, 3, 2, testData.ID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	h.MockDao.SQLMock.ExpectCommit()
+	err = httpcli.Patch(result, h.GetRequestURL("PatchByID", testData.ID), map[string]interface{}{"attack": 0},
+		httpcli.WithHeaders(map[string]string{"If-Match": `"2"`}))
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.Conflict.Code(), result.Code)
+
+	// a null is checked as the zero value
+	expectGetByID(3)
+	err = httpcli.Patch(result, h.GetRequestURL("PatchByID", testData.ID), map[string]interface{}{"hp": 0, "mobID": nil, "version": 3})
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.ErrRulesMob.Code(), result.Code)
+	assert.Equal(t, map[string]interface{}{"errors": []interface{}{
+		map[string]interface{}{"field": "hp", "rule": "range", "message": "must be between 1 and 100000"},
+		map[string]interface{}{"field": "mobID", "rule": "required", "message": "is required"},
+	}}, result.Data)
+
+	// not a merge patch, unknown field, missing version
+	for _, body := range []interface{}{[]int{1}, map[string]interface{}{"id": 2, "version": 1}, map[string]interface{}{"attack": 0}} {
+		err = httpcli.Patch(result, h.GetRequestURL("PatchByID", testData.ID), body)
+		assert.NoError(t, err)
+		assert.Equal(t, ecode.InvalidParams.Code(), result.Code)
+	}
+
+	// zero id error test
+	err = httpcli.Patch(result, h.GetRequestURL("PatchByID", 0), patch)
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.InvalidParams.Code(), result.Code)
+}
+
 func Test_mobHandler_GetByID(t *testing.T) {
 	h := newMobHandler()
 	defer h.Close()
 	testData := h.TestData.(*model.Mob)
 
 	// column names and corresponding data
-	rows := sqlmock.NewRows([]string{"id"}).
-		AddRow(testData.ID)
+	rows := sqlmock.NewRows([]string{"id", "version"}).
+		AddRow(testData.ID, 3)
 
 	h.MockDao.SQLMock.ExpectQuery("SELECT .*").
 		WithArgs(testData.ID, 1).
 		WillReturnRows(rows)
 
+	resp, err := http.Get(h.GetRequestURL("GetByID", testData.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	assert.Equal(t, `"3"`, resp.Header.Get("ETag"))
+
+	// the version is kept in the cache
 	result := &httpcli.StdResult{}
-	err := httpcli.Get(result, h.GetRequestURL("GetByID", testData.ID))
+	err = httpcli.Get(result, h.GetRequestURL("GetByID", testData.ID))
 	if err != nil {
 		t.Fatal(err)
 	}
 	if result.Code != 0 {
 		t.Fatalf("%+v", result)
 	}
+	assert.Equal(t, float64(3), result.Data.(map[string]interface{})["mob"].(map[string]interface{})["version"])
 
 	// zero id error test
 	err = httpcli.Get(result, h.GetRequestURL("GetByID", 0))
@@ -234,6 +500,39 @@ func Test_mobHandler_List(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func Test_mobHandler_ListByIDs(t *testing.T) {
+	h := newMobHandler()
+	defer h.Close()
+	testData := h.TestData.(*model.Mob)
+
+	// column names and corresponding data
+	rows := sqlmock.NewRows([]string{"id"}).
+		AddRow(testData.ID)
+
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").WillReturnRows(rows)
+
+	result := &httpcli.StdResult{}
+	err := httpcli.Post(result, h.GetRequestURL("ListByIDs"), &types.ListMobsByIDsRequest{IDs: []uint64{2, testData.ID}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Code != 0 {
+		t.Fatalf("%+v", result)
+	}
+	list := result.Data.(map[string]interface{})["mobs"].([]interface{})
+	assert.Len(t, list, 1)
+
+	// more than 100 ids
+	err = httpcli.Post(result, h.GetRequestURL("ListByIDs"), &types.ListMobsByIDsRequest{IDs: make([]uint64, 101)})
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.InvalidParams.Code(), result.Code)
+
+	// nil params error test
+	err = httpcli.Post(result, h.GetRequestURL("ListByIDs"), nil)
+	assert.NoError(t, err)
+	assert.NotEqual(t, 0, result.Code)
+}
+
 func TestNewMobHandler(t *testing.T) {
 	defer func() {
 		recover()
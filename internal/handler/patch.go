@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// bindMergePatch bind a JSON merge patch (RFC 7396) to form, a pointer to a struct whose patchable fields
+// are pointers, and return the names of the patchable fields present in the body. A field set to null is
+// present with a nil pointer, so it is written as the zero value of the column.
+func bindMergePatch(c *gin.Context, form interface{}) ([]string, error) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var present map[string]json.RawMessage
+	if err = json.Unmarshal(body, &present); err != nil {
+		return nil, err
+	}
+	if present == nil {
+		return nil, errors.New("a merge patch must be a json object")
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.DisallowUnknownFields()
+	if err = dec.Decode(form); err != nil {
+		return nil, err
+	}
+	if err = binding.Validator.ValidateStruct(form); err != nil {
+		return nil, err
+	}
+
+	var fields []string
+	t := reflect.TypeOf(form).Elem()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Type.Kind() != reflect.Ptr {
+			continue // e.g. the version
+		}
+		key := strings.Split(field.Tag.Get("json"), ",")[0]
+		if _, ok := present[key]; ok {
+			fields = append(fields, field.Name)
+		}
+	}
+	return fields, nil
+}
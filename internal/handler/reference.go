@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"strings"
+
+	"fs/internal/cache"
+	"fs/internal/dao"
+	"fs/internal/database"
+	"fs/internal/reference"
+)
+
+// newReferenceDaos the tables checked when a room or a mob is deleted
+func newReferenceDaos() reference.Daos {
+	return reference.Daos{
+		Rooms: dao.NewRoomDao(
+			database.GetDB(),
+			cache.NewRoomCache(database.GetCacheType()),
+		),
+		RoomExits: dao.NewRoomExitDao(
+			database.GetDB(),
+			cache.NewRoomExitCache(database.GetCacheType()),
+		),
+		Mobs: dao.NewMobDao(
+			database.GetDB(),
+			cache.NewMobCache(database.GetCacheType()),
+		),
+		Spawns: dao.NewSpawnDao(
+			database.GetDB(),
+			cache.NewSpawnCache(database.GetCacheType()),
+		),
+		ItemInstances: dao.NewItemInstanceDao(
+			database.GetDB(),
+			cache.NewItemInstanceCache(database.GetCacheType()),
+		),
+		Characters: dao.NewCharacterDao(
+			database.GetDB(),
+			cache.NewCharacterCache(database.GetCacheType()),
+		),
+	}
+}
+
+// referencedDetails the details of the error returned when a delete is refused
+func referencedDetails(refs []reference.Reference) string {
+	list := make([]string, 0, len(refs))
+	for _, r := range refs {
+		list = append(list, r.String())
+	}
+	return "referenced by " + strings.Join(list, ", ") + ", delete with ?cascade=true to remove the references"
+}
@@ -0,0 +1,313 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/go-dev-frame/sponge/pkg/errcode"
+	"github.com/go-dev-frame/sponge/pkg/gin/middleware"
+	"github.com/go-dev-frame/sponge/pkg/gin/response"
+	"github.com/go-dev-frame/sponge/pkg/logger"
+	"github.com/go-dev-frame/sponge/pkg/utils"
+
+	"fs/internal/cache"
+	"fs/internal/config"
+	"fs/internal/dao"
+	"fs/internal/database"
+	"fs/internal/ecode"
+	"fs/internal/model"
+	"fs/internal/revision"
+	"fs/internal/rule"
+	"fs/internal/types"
+)
+
+var _ RevisionHandler = (*revisionHandler)(nil)
+
+// RevisionHandler defining the handler interface
+type RevisionHandler interface {
+	List(c *gin.Context)
+	Diff(c *gin.Context)
+	Restore(c *gin.Context)
+}
+
+// restoreFunc write the json of a record back to its table, the fields of a mob or an item
+// breaking the validation rules are returned instead and nothing is written
+type restoreFunc func(ctx context.Context, state string) ([]rule.Violation, error)
+
+type revisionHandler struct {
+	entity    string // revision.EntityRoom, EntityMob or EntityItem
+	iDao      dao.RevisionDao
+	restore   restoreFunc
+	rulesCode *errcode.Error // the code of the violations returned by restore
+}
+
+// NewRevisionHandler creating the handler interface of the revisions of an entity type
+func NewRevisionHandler(entity string) RevisionHandler {
+	h := &revisionHandler{
+		entity: entity,
+		iDao:   dao.NewRevisionDao(database.GetDB()),
+	}
+	switch entity {
+	case revision.EntityRoom:
+		h.restore = restoreRoom(dao.NewRoomDao(database.GetDB(), cache.NewRoomCache(database.GetCacheType())))
+	case revision.EntityMob:
+		mobDao := dao.NewMobDao(database.GetDB(), cache.NewMobCache(database.GetCacheType()))
+		rules, err := rule.NewMob(config.Get().Rules.Mob, mobDao.ExistsMobID)
+		if err != nil {
+			panic("init mob rules error: " + err.Error())
+		}
+		h.restore, h.rulesCode = restoreMob(mobDao, rules), ecode.ErrRulesMob
+	case revision.EntityItem:
+		itemDao := dao.NewItemDao(database.GetDB(), cache.NewItemCache(database.GetCacheType()))
+		rules, err := rule.NewItem(config.Get().Rules.Item, itemDao.ExistsItemID)
+		if err != nil {
+			panic("init item rules error: " + err.Error())
+		}
+		h.restore, h.rulesCode = restoreItem(itemDao, rules), ecode.ErrRulesItem
+	default:
+		panic("no revisions for " + entity)
+	}
+	return h
+}
+
+// List get a paginated list of the revisions of a record
+// @Summary Get a paginated list of the revisions of a record
+// @Description Returns the creates, updates and deletes of the room, mob or item in the path, newest first,
+// @Description with who made them and the record before and after each change.
+// @Tags revision
+// @Param entity path string true "room, mob or item"
+// @Param id path string true "id of the record"
+// @Param page query int false "page number, starting from 0"
+// @Param limit query int false "revisions per page, default is 20"
+// @Accept json
+// @Produce json
+// @Success 200 {object} types.ListRevisionsReply{}
+// @Router /api/v1/{entity}/{id}/revisions [get]
+// @Security BearerAuth
+func (h *revisionHandler) List(c *gin.Context) {
+	id, isAbort := h.getEntityIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	page, err1 := utils.StrToIntE(c.DefaultQuery("page", "0"))
+	limit, err2 := utils.StrToIntE(c.DefaultQuery("limit", "20"))
+	if err1 != nil || err2 != nil || page < 0 || limit < 1 {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	revisions, total, err := h.iDao.GetByEntity(ctx, h.entity, id, page, limit)
+	if err != nil {
+		logger.Error("GetByEntity error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	data := make([]*types.RevisionObjDetail, 0, len(revisions))
+	for _, v := range revisions {
+		data = append(data, convertRevision(v))
+	}
+
+	response.Success(c, gin.H{
+		"revisions": data,
+		"total":     total,
+	})
+}
+
+// Diff compare the record after two of its revisions
+// @Summary Compare the record after two of its revisions
+// @Description Returns the fields of the room, mob or item in the path that differ between the state after
+// @Description the revision from and the state after the revision to. A deleted record has no fields.
+// @Tags revision
+// @Param entity path string true "room, mob or item"
+// @Param id path string true "id of the record"
+// @Param from query int true "revision id"
+// @Param to query int true "revision id"
+// @Accept json
+// @Produce json
+// @Success 200 {object} types.DiffRevisionsReply{}
+// @Router /api/v1/{entity}/{id}/revisions/diff [get]
+// @Security BearerAuth
+func (h *revisionHandler) Diff(c *gin.Context) {
+	id, isAbort := h.getEntityIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	fromID, err1 := utils.StrToUint64E(c.Query("from"))
+	toID, err2 := utils.StrToUint64E(c.Query("to"))
+	if err1 != nil || err2 != nil || fromID == 0 || toID == 0 {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	from, ok := h.getRevision(c, ctx, id, fromID)
+	if !ok {
+		return
+	}
+	to, ok := h.getRevision(c, ctx, id, toID)
+	if !ok {
+		return
+	}
+
+	changes, err := revision.Diff(from.After, to.After)
+	if err != nil {
+		logger.Error("Diff error", logger.Err(err), logger.Any("from", fromID), logger.Any("to", toID), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrDiffRevision)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"from":    fromID,
+		"to":      toID,
+		"changes": changes,
+	})
+}
+
+// Restore write back the record as it was after one of its revisions
+// @Summary Write back the record as it was after one of its revisions
+// @Description Overwrites all the fields of the room, mob or item in the path with the state after the revision,
+// @Description the record is created again if it has been deleted. The restore is recorded as a new revision.
+// @Description A mob or an item is checked against the validation rules of fs.yml first, the fields breaking them
+// @Description are returned in data.errors with the code of ErrRulesMob or ErrRulesItem.
+// @Tags revision
+// @Param entity path string true "room, mob or item"
+// @Param id path string true "id of the record"
+// @Param revisionID path int true "revision id"
+// @Accept json
+// @Produce json
+// @Success 200 {object} types.RestoreRevisionReply{}
+// @Router /api/v1/{entity}/{id}/revisions/{revisionID}/restore [post]
+// @Security BearerAuth
+func (h *revisionHandler) Restore(c *gin.Context) {
+	id, isAbort := h.getEntityIDFromPath(c)
+	revisionID, err := utils.StrToUint64E(c.Param("revisionID"))
+	if isAbort || err != nil || revisionID == 0 {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	rev, ok := h.getRevision(c, ctx, id, revisionID)
+	if !ok {
+		return
+	}
+	if rev.After == "" {
+		response.Error(c, ecode.ErrRevisionDeleted)
+		return
+	}
+
+	violations, err := h.restore(ctx, rev.After)
+	if err != nil {
+		logger.Error("restore error", logger.Err(err), logger.Any("revision", rev), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrRestoreRevision)
+		return
+	}
+	if abortOnViolations(c, h.rulesCode, violations, nil) {
+		return
+	}
+
+	response.Success(c)
+}
+
+// getRevision get a revision of the record, an error response has been written when false is returned
+func (h *revisionHandler) getRevision(c *gin.Context, ctx context.Context, entityID string, id uint64) (*model.Revision, bool) { //nolint
+	rev, err := h.iDao.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			logger.Warn("GetByID not found", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("GetByID error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return nil, false
+	}
+	if rev.EntityType != h.entity || rev.EntityID != entityID {
+		response.Error(c, ecode.NotFound) // a revision of another record
+		return nil, false
+	}
+	return rev, true
+}
+
+// getEntityIDFromPath the id of the record as it is stored in the revisions
+func (h *revisionHandler) getEntityIDFromPath(c *gin.Context) (string, bool) {
+	if h.entity == revision.EntityRoom {
+		return getRoomIDFromPath(c)
+	}
+	_, id, isAbort := getMobIDFromPath(c) // mobs and items have uint64 ids
+	return utils.Uint64ToStr(id), isAbort
+}
+
+func restoreRoom(d dao.RoomDao) restoreFunc {
+	return func(ctx context.Context, state string) ([]rule.Violation, error) {
+		room := &model.Room{}
+		if err := json.Unmarshal([]byte(state), room); err != nil {
+			return nil, err
+		}
+		return nil, d.Save(ctx, room)
+	}
+}
+
+// restoreMob the rules are checked as for a new mob as every field is written, a revision may be
+// older than the rules or than the mob that took its mob id since
+func restoreMob(d dao.MobDao, rules *rule.Rules) restoreFunc {
+	return func(ctx context.Context, state string) ([]rule.Violation, error) {
+		mob := &model.Mob{}
+		if err := json.Unmarshal([]byte(state), mob); err != nil {
+			return nil, err
+		}
+		violations, err := rules.CheckCreate(ctx, mob)
+		if err != nil || len(violations) > 0 {
+			return violations, err
+		}
+		return saveChecked(rules, mob, d.Save(ctx, mob))
+	}
+}
+
+// restoreItem the rules are checked as with restoreMob
+func restoreItem(d dao.ItemDao, rules *rule.Rules) restoreFunc {
+	return func(ctx context.Context, state string) ([]rule.Violation, error) {
+		item := &model.Item{}
+		if err := json.Unmarshal([]byte(state), item); err != nil {
+			return nil, err
+		}
+		violations, err := rules.CheckCreate(ctx, item)
+		if err != nil || len(violations) > 0 {
+			return violations, err
+		}
+		return saveChecked(rules, item, d.Save(ctx, item))
+	}
+}
+
+// saveChecked the result of a restore, the unique index refusing the record is a violation of its unique field
+func saveChecked(rules *rule.Rules, table interface{}, err error) ([]rule.Violation, error) {
+	if errors.Is(err, database.ErrDuplicatedKey) {
+		return []rule.Violation{rules.UniqueViolation(table)}, nil
+	}
+	return nil, err
+}
+
+func convertRevision(rev *model.Revision) *types.RevisionObjDetail {
+	data := &types.RevisionObjDetail{
+		ID:         rev.ID,
+		EntityType: rev.EntityType,
+		EntityID:   rev.EntityID,
+		Action:     rev.Action,
+		Actor:      rev.Actor,
+		CreatedAt:  rev.CreatedAt,
+	}
+	if rev.Before != "" {
+		data.Before = json.RawMessage(rev.Before)
+	}
+	if rev.After != "" {
+		data.After = json.RawMessage(rev.After)
+	}
+	return data
+}
@@ -0,0 +1,234 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-dev-frame/sponge/pkg/gotest"
+	"github.com/go-dev-frame/sponge/pkg/httpcli"
+
+	"fs/internal/config"
+	"fs/internal/dao"
+	"fs/internal/database"
+	"fs/internal/ecode"
+	"fs/internal/model"
+	"fs/internal/revision"
+	"fs/internal/rule"
+)
+
+func newRevisionHandler() *gotest.Handler {
+	testData := &model.Revision{}
+	testData.ID = 2
+	testData.EntityType = revision.EntityMob
+	testData.EntityID = "1"
+	testData.Action = revision.ActionUpdate
+	testData.Actor = "bob"
+	testData.CreatedAt = time.Now()
+	testData.Before = `{"id":1,"mobID":"rat","mobName":"rat","hp":10}`
+	testData.After = `{"id":1,"mobID":"rat","mobName":"big rat","hp":20}`
+
+	// init mock dao, the revisions are not cached
+	d := gotest.NewDao(nil, testData)
+	d.IDao = dao.NewRevisionDao(d.DB)
+
+	// init mock handler
+	h := gotest.NewHandler(d, testData)
+	rules, _ := rule.NewMob(config.RuleSet{}, nil) // the unique mob id is left to the unique index
+	h.IHandler = &revisionHandler{
+		entity:    revision.EntityMob,
+		iDao:      d.IDao.(dao.RevisionDao),
+		restore:   restoreMob(dao.NewMobDao(d.DB, nil), rules),
+		rulesCode: ecode.ErrRulesMob,
+	}
+	iHandler := h.IHandler.(RevisionHandler)
+
+	testFns := []gotest.RouterInfo{
+		{
+			FuncName:    "List",
+			Method:      http.MethodGet,
+			Path:        "/mob/:id/revisions",
+			HandlerFunc: iHandler.List,
+		},
+		{
+			FuncName:    "Diff",
+			Method:      http.MethodGet,
+			Path:        "/mob/:id/revisions/diff",
+			HandlerFunc: iHandler.Diff,
+		},
+		{
+			FuncName:    "Restore",
+			Method:      http.MethodPost,
+			Path:        "/mob/:id/revisions/:revisionID/restore",
+			HandlerFunc: iHandler.Restore,
+		},
+	}
+
+	h.GoRunHTTPServer(testFns)
+
+	time.Sleep(time.Millisecond * 200)
+	return h
+}
+
+// revisionRows the columns of a revision
+func revisionRows(revisions ...*model.Revision) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{"id", "entity_type", "entity_id", "action", "actor", "created_at", "before_data", "after_data"})
+	for _, r := range revisions {
+		rows.AddRow(r.ID, r.EntityType, r.EntityID, r.Action, r.Actor, r.CreatedAt, r.Before, r.After)
+	}
+	return rows
+}
+
+func Test_revisionHandler_List(t *testing.T) {
+	h := newRevisionHandler()
+	defer h.Close()
+	testData := h.TestData.(*model.Revision)
+
+	h.MockDao.SQLMock.ExpectQuery("SELECT count(.*)").
+		WithArgs(revision.EntityMob, "1").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(revision.EntityMob, "1", 20).
+		WillReturnRows(revisionRows(testData))
+
+	result := &httpcli.StdResult{}
+	err := httpcli.Get(result, h.GetRequestURL("List", 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Code != 0 {
+		t.Fatalf("%+v", result)
+	}
+	data := result.Data.(map[string]interface{})
+	assert.Equal(t, float64(1), data["total"])
+	revisions := data["revisions"].([]interface{})
+	assert.Len(t, revisions, 1)
+	assert.Equal(t, "big rat", revisions[0].(map[string]interface{})["after"].(map[string]interface{})["mobName"])
+
+	// invalid id and page
+	err = httpcli.Get(result, h.GetRequestURL("List", "rat"))
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.InvalidParams.Code(), result.Code)
+	err = httpcli.Get(result, h.GetRequestURL("List", 1), httpcli.WithParams(map[string]interface{}{"limit": 0}))
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.InvalidParams.Code(), result.Code)
+}
+
+func Test_revisionHandler_Diff(t *testing.T) {
+	h := newRevisionHandler()
+	defer h.Close()
+	testData := h.TestData.(*model.Revision)
+	created := &model.Revision{ID: 1, EntityType: revision.EntityMob, EntityID: "1", Action: revision.ActionCreate,
+		CreatedAt: time.Now(), After: testData.Before}
+
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(created.ID, 1).
+		WillReturnRows(revisionRows(created))
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(testData.ID, 1).
+		WillReturnRows(revisionRows(testData))
+
+	result := &httpcli.StdResult{}
+	params := httpcli.WithParams(map[string]interface{}{"from": 1, "to": 2})
+	err := httpcli.Get(result, h.GetRequestURL("Diff", 1), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Code != 0 {
+		t.Fatalf("%+v", result)
+	}
+	changes := result.Data.(map[string]interface{})["changes"].([]interface{})
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"field": "hp", "from": float64(10), "to": float64(20)},
+		map[string]interface{}{"field": "mobName", "from": "rat", "to": "big rat"},
+	}, changes)
+
+	// revision of another mob
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(created.ID, 1).
+		WillReturnRows(revisionRows(created))
+	err = httpcli.Get(result, h.GetRequestURL("Diff", 2), params)
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.NotFound.Code(), result.Code)
+
+	// missing revision ids
+	err = httpcli.Get(result, h.GetRequestURL("Diff", 1))
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.InvalidParams.Code(), result.Code)
+}
+
+func Test_revisionHandler_Restore(t *testing.T) {
+	h := newRevisionHandler()
+	defer h.Close()
+	testData := h.TestData.(*model.Revision)
+
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(testData.ID, 1).
+		WillReturnRows(revisionRows(testData))
+	h.MockDao.SQLMock.ExpectBegin()
+	h.MockDao.SQLMock.ExpectQuery("SELECT `version` FROM `mob`").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(2))
+	h.MockDao.SQLMock.ExpectExec("UPDATE .*").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	h.MockDao.SQLMock.ExpectCommit()
+
+	result := &httpcli.StdResult{}
+	err := httpcli.Post(result, h.GetRequestURL("Restore", 1, testData.ID), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Code != 0 {
+		t.Fatalf("%+v", result)
+	}
+
+	// a revision breaking the rules set since it was made
+	invalid := &model.Revision{ID: 4, EntityType: revision.EntityMob, EntityID: "1", Action: revision.ActionUpdate,
+		CreatedAt: time.Now(), After: `{"id":1,"mobID":"rat","mobName":"rat","hp":0}`}
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(invalid.ID, 1).
+		WillReturnRows(revisionRows(invalid))
+	err = httpcli.Post(result, h.GetRequestURL("Restore", 1, invalid.ID), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.ErrRulesMob.Code(), result.Code)
+	assert.Equal(t, map[string]interface{}{"errors": []interface{}{
+		map[string]interface{}{"field": "hp", "rule": "range", "message": "must be between 1 and 100000"},
+	}}, result.Data)
+
+	// the mob id was taken by another mob since
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(testData.ID, 1).
+		WillReturnRows(revisionRows(testData))
+	h.MockDao.SQLMock.ExpectBegin()
+	h.MockDao.SQLMock.ExpectQuery("SELECT `version` FROM `mob`").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(3))
+	h.MockDao.SQLMock.ExpectExec("UPDATE .*").
+		WillReturnError(database.ErrDuplicatedKey)
+	h.MockDao.SQLMock.ExpectRollback()
+	err = httpcli.Post(result, h.GetRequestURL("Restore", 1, testData.ID), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.ErrRulesMob.Code(), result.Code)
+	assert.Contains(t, result.Data.(map[string]interface{})["errors"].([]interface{})[0].(map[string]interface{})["message"], "rat is already used")
+
+	// a delete has nothing to restore
+	deleted := &model.Revision{ID: 3, EntityType: revision.EntityMob, EntityID: "1", Action: revision.ActionDelete,
+		CreatedAt: time.Now(), Before: testData.After}
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(deleted.ID, 1).
+		WillReturnRows(revisionRows(deleted))
+	err = httpcli.Post(result, h.GetRequestURL("Restore", 1, deleted.ID), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.ErrRevisionDeleted.Code(), result.Code)
+
+	// revision not found
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(9, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	err = httpcli.Post(result, h.GetRequestURL("Restore", 1, 9), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.NotFound.Code(), result.Code)
+}
@@ -1,9 +1,13 @@
 package handler
 
 import (
+	"context"
 	"errors"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 
 	"github.com/go-dev-frame/sponge/pkg/copier"
 	"github.com/go-dev-frame/sponge/pkg/gin/middleware"
@@ -15,7 +19,9 @@ import (
 	"fs/internal/database"
 	"fs/internal/ecode"
 	"fs/internal/model"
+	"fs/internal/reference"
 	"fs/internal/types"
+	"fs/internal/world"
 )
 
 var _ RoomHandler = (*roomHandler)(nil)
@@ -24,13 +30,19 @@ var _ RoomHandler = (*roomHandler)(nil)
 type RoomHandler interface {
 	Create(c *gin.Context)
 	DeleteByID(c *gin.Context)
+	Restore(c *gin.Context)
 	UpdateByID(c *gin.Context)
+	PatchByID(c *gin.Context)
 	GetByID(c *gin.Context)
 	List(c *gin.Context)
+	ListByIDs(c *gin.Context)
 }
 
 type roomHandler struct {
-	iDao dao.RoomDao
+	iDao    dao.RoomDao
+	mobDao  dao.MobDao
+	refDaos reference.Daos // the tables checked by a delete
+	db      *gorm.DB       // the transaction of a delete is started on it
 }
 
 // NewRoomHandler creating the handler interface
@@ -40,6 +52,12 @@ func NewRoomHandler() RoomHandler {
 			database.GetDB(), // db driver is mysql
 			cache.NewRoomCache(database.GetCacheType()),
 		),
+		mobDao: dao.NewMobDao(
+			database.GetDB(),
+			cache.NewMobCache(database.GetCacheType()),
+		),
+		refDaos: newReferenceDaos(),
+		db:      database.GetDB(),
 	}
 }
 
@@ -83,15 +101,57 @@ func (h *roomHandler) Create(c *gin.Context) {
 
 // DeleteByID delete a room by id
 // @Summary Delete a room by id
-// @Description Deletes a existing room identified by the given id in the path.
+// @Description Moves a existing room identified by the given id in the path to the trash, see /api/v1/room/{id}/restore.
+// @Description The delete is refused with the references to the room, the exits of other rooms leading to it, the spawns or item instances
+// @Description in it or the characters saved in it, unless cascade is true, then the references are removed in the same transaction and
+// @Description returned, the characters enter the start room the next time. A room whose references were removed is purged instead of
+// @Description moved to the trash, it cannot be restored without them.
 // @Tags room
 // @Accept json
 // @Produce json
 // @Param id path string true "id"
+// @Param cascade query bool false "remove the references to the room"
 // @Success 200 {object} types.DeleteRoomByIDReply{}
 // @Router /api/v1/room/{id} [delete]
 // @Security BearerAuth
 func (h *roomHandler) DeleteByID(c *gin.Context) {
+	id, isAbort := getRoomIDFromPath(c)
+	cascade, err := strconv.ParseBool(c.DefaultQuery("cascade", "false"))
+	if isAbort || err != nil {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	refs, err := reference.DeleteRoom(ctx, h.db, h.refDaos, id, cascade)
+	if err != nil {
+		if errors.Is(err, reference.ErrReferenced) {
+			logger.Warn("DeleteByID referenced", logger.Err(err), logger.Any("id", id), logger.Any("references", refs), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.FailedPrecondition.WithDetails(referencedDetails(refs)))
+		} else {
+			logger.Error("DeleteByID error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return
+	}
+	if refs == nil {
+		refs = []reference.Reference{}
+	}
+
+	response.Success(c, gin.H{"references": refs})
+}
+
+// Restore take a room out of the trash by id
+// @Summary Take a room out of the trash by id
+// @Description Brings back a deleted room identified by the given id in the path, not found if it is not in the trash.
+// @Tags room
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} types.RestoreRoomByIDReply{}
+// @Router /api/v1/room/{id}/restore [post]
+// @Security BearerAuth
+func (h *roomHandler) Restore(c *gin.Context) {
 	id, isAbort := getRoomIDFromPath(c)
 	if isAbort {
 		response.Error(c, ecode.InvalidParams)
@@ -99,10 +159,15 @@ func (h *roomHandler) DeleteByID(c *gin.Context) {
 	}
 
 	ctx := middleware.WrapCtx(c)
-	err := h.iDao.DeleteByID(ctx, id)
+	err := h.iDao.RestoreByID(ctx, id)
 	if err != nil {
-		logger.Error("DeleteByID error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
-		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		if errors.Is(err, database.ErrRecordNotFound) {
+			logger.Warn("RestoreByID not found", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("RestoreByID error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
 		return
 	}
 
@@ -112,10 +177,13 @@ func (h *roomHandler) DeleteByID(c *gin.Context) {
 // UpdateByID update a room by id
 // @Summary Update a room by id
 // @Description Updates the specified room by given id in the path, support partial update.
+// @Description The version read with GetByID is required in the If-Match header or the version field,
+// @Description a conflict is returned if the room has been changed since then.
 // @Tags room
 // @Accept json
 // @Produce json
 // @Param id path string true "id"
+// @Param If-Match header string false "ETag returned by GetByID"
 // @Param data body types.UpdateRoomByIDRequest true "room information"
 // @Success 200 {object} types.UpdateRoomByIDReply{}
 // @Router /api/v1/room/{id} [put]
@@ -135,6 +203,10 @@ func (h *roomHandler) UpdateByID(c *gin.Context) {
 		return
 	}
 	form.ID = id
+	form.Version, isAbort = getVersion(c, form.Version)
+	if isAbort {
+		return
+	}
 
 	room := &model.Room{}
 	err = copier.Copy(room, form)
@@ -147,19 +219,85 @@ func (h *roomHandler) UpdateByID(c *gin.Context) {
 	ctx := middleware.WrapCtx(c)
 	err = h.iDao.UpdateByID(ctx, room)
 	if err != nil {
-		logger.Error("UpdateByID error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
-		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		if errors.Is(err, database.ErrVersionConflict) {
+			logger.Warn("UpdateByID conflict", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.Conflict)
+		} else {
+			logger.Error("UpdateByID error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
 		return
 	}
 
-	response.Success(c)
+	setETag(c, room.Version)
+	response.Success(c, gin.H{"version": room.Version})
+}
+
+// PatchByID update the fields of a room given in a JSON merge patch
+// @Summary Update the fields of a room given in a JSON merge patch
+// @Description Updates the fields of the room present in the body (RFC 7396), zero values and empty strings
+// @Description included, a null field is reset to its zero value and absent fields are untouched. The version
+// @Description read with GetByID is required in the If-Match header or the version field.
+// @Tags room
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param If-Match header string false "ETag returned by GetByID"
+// @Param data body types.PatchRoomByIDRequest true "room fields"
+// @Success 200 {object} types.PatchRoomByIDReply{}
+// @Router /api/v1/room/{id} [patch]
+// @Security BearerAuth
+func (h *roomHandler) PatchByID(c *gin.Context) {
+	id, isAbort := getRoomIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	form := &types.PatchRoomByIDRequest{}
+	fields, err := bindMergePatch(c, form)
+	if err != nil {
+		logger.Warn("bindMergePatch error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	form.Version, isAbort = getVersion(c, form.Version)
+	if isAbort {
+		return
+	}
+
+	room := &model.Room{}
+	err = copier.Copy(room, form)
+	if err != nil {
+		response.Error(c, ecode.ErrPatchByIDRoom)
+		return
+	}
+	room.ID = id
+
+	ctx := middleware.WrapCtx(c)
+	err = h.iDao.PatchByID(ctx, room, fields)
+	if err != nil {
+		if errors.Is(err, database.ErrVersionConflict) {
+			logger.Warn("PatchByID conflict", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.Conflict)
+		} else {
+			logger.Error("PatchByID error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return
+	}
+
+	setETag(c, room.Version)
+	response.Success(c, gin.H{"version": room.Version})
 }
 
 // GetByID get a room by id
 // @Summary Get a room by id
 // @Description Gets detailed information of a room specified by the given id in the path.
+// @Description The version of the room is also returned in the ETag header, for the If-Match header of UpdateByID.
 // @Tags room
 // @Param id path string true "id"
+// @Param expand query string false "mobs: also return the mobs listed in Room.Mobs"
 // @Accept json
 // @Produce json
 // @Success 200 {object} types.GetRoomByIDReply{}
@@ -193,6 +331,16 @@ func (h *roomHandler) GetByID(c *gin.Context) {
 	}
 	// Note: if copier.Copy cannot assign a value to a field, add it here
 
+	if expandMobs(c) {
+		err = h.expandMobs(ctx, []*types.RoomObjDetail{data})
+		if err != nil {
+			logger.Error("expandMobs error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+			return
+		}
+	}
+
+	setETag(c, room.Version)
 	response.Success(c, gin.H{"room": data})
 }
 
@@ -204,6 +352,7 @@ func (h *roomHandler) GetByID(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param data body types.Params true "query parameters"
+// @Param expand query string false "mobs: also return the mobs listed in Room.Mobs"
 // @Success 200 {object} types.ListRoomsReply{}
 // @Router /api/v1/room/list [post]
 // @Security BearerAuth
@@ -229,6 +378,14 @@ func (h *roomHandler) List(c *gin.Context) {
 		response.Error(c, ecode.ErrListRoom)
 		return
 	}
+	if expandMobs(c) {
+		err = h.expandMobs(ctx, data)
+		if err != nil {
+			logger.Error("expandMobs error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+			return
+		}
+	}
 
 	response.Success(c, gin.H{
 		"rooms": data,
@@ -236,6 +393,101 @@ func (h *roomHandler) List(c *gin.Context) {
 	})
 }
 
+// expandMobs check if ?expand= asks for the mobs, e.g. expand=mobs
+func expandMobs(c *gin.Context) bool {
+	for _, v := range strings.Split(c.Query("expand"), ",") {
+		if strings.TrimSpace(v) == "mobs" {
+			return true
+		}
+	}
+	return false
+}
+
+// expandMobs fill in the mobs listed in Room.Mobs of the rooms with one batch read,
+// in the order they are listed, ids that do not exist are left out
+func (h *roomHandler) expandMobs(ctx context.Context, rooms []*types.RoomObjDetail) error {
+	var ids []uint64
+	for _, room := range rooms {
+		ids = append(ids, world.ParseMobIDs(room.Mobs)...)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	mobs, err := h.mobDao.GetByIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, room := range rooms {
+		for _, id := range world.ParseMobIDs(room.Mobs) {
+			mob, ok := mobs[id]
+			if !ok {
+				continue
+			}
+			data, err := convertMob(mob)
+			if err != nil {
+				return err
+			}
+			room.MobDetails = append(room.MobDetails, data)
+		}
+	}
+	return nil
+}
+
+// ListByIDs get a list of rooms by ids
+// @Summary Get a list of rooms by ids
+// @Description Returns the rooms with the given ids in the order of the ids, ids that do not exist are left out.
+// @Tags room
+// @Accept json
+// @Produce json
+// @Param data body types.ListRoomsByIDsRequest true "id list"
+// @Param expand query string false "mobs: also return the mobs listed in Room.Mobs"
+// @Success 200 {object} types.ListRoomsByIDsReply{}
+// @Router /api/v1/room/list/ids [post]
+// @Security BearerAuth
+func (h *roomHandler) ListByIDs(c *gin.Context) {
+	form := &types.ListRoomsByIDsRequest{}
+	err := c.ShouldBindJSON(form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	roomMap, err := h.iDao.GetByIDs(ctx, form.IDs)
+	if err != nil {
+		logger.Error("GetByIDs error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	rooms := []*types.RoomObjDetail{}
+	for _, id := range form.IDs {
+		if v, ok := roomMap[id]; ok {
+			record, err := convertRoom(v)
+			if err != nil {
+				response.Error(c, ecode.ErrListByIDsRoom)
+				return
+			}
+			rooms = append(rooms, record)
+		}
+	}
+
+	if expandMobs(c) {
+		err = h.expandMobs(ctx, rooms)
+		if err != nil {
+			logger.Error("expandMobs error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+			return
+		}
+	}
+
+	response.Success(c, gin.H{
+		"rooms": rooms,
+	})
+}
+
 func getRoomIDFromPath(c *gin.Context) (string, bool) {
 	idStr := c.Param("id")
 
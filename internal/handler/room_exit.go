@@ -0,0 +1,453 @@
+package handler
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/go-dev-frame/sponge/pkg/copier"
+	"github.com/go-dev-frame/sponge/pkg/gin/middleware"
+	"github.com/go-dev-frame/sponge/pkg/gin/response"
+	"github.com/go-dev-frame/sponge/pkg/logger"
+
+	"fs/internal/cache"
+	"fs/internal/dao"
+	"fs/internal/database"
+	"fs/internal/ecode"
+	"fs/internal/model"
+	"fs/internal/types"
+	"fs/internal/world"
+)
+
+var _ RoomExitHandler = (*roomExitHandler)(nil)
+
+// RoomExitHandler defining the handler interface
+type RoomExitHandler interface {
+	Create(c *gin.Context)
+	DeleteByDirection(c *gin.Context)
+	UpdateByDirection(c *gin.Context)
+	GetByDirection(c *gin.Context)
+	List(c *gin.Context)
+	MigrateWays(c *gin.Context)
+}
+
+type roomExitHandler struct {
+	iDao    dao.RoomExitDao
+	roomDao dao.RoomDao
+	db      *gorm.DB // the transaction of MigrateWays is started on it
+}
+
+// NewRoomExitHandler creating the handler interface
+func NewRoomExitHandler() RoomExitHandler {
+	return &roomExitHandler{
+		iDao: dao.NewRoomExitDao(
+			database.GetDB(), // db driver is mysql
+			cache.NewRoomExitCache(database.GetCacheType()),
+		),
+		roomDao: dao.NewRoomDao(
+			database.GetDB(),
+			cache.NewRoomCache(database.GetCacheType()),
+		),
+		db: database.GetDB(),
+	}
+}
+
+// Create a new exit of a room
+// @Summary Create a new exit of a room
+// @Description Creates an exit leading from the room in the path to another room, one exit per direction.
+// @Tags room exit
+// @Accept json
+// @Produce json
+// @Param id path string true "room id"
+// @Param data body types.CreateRoomExitRequest true "exit information"
+// @Success 200 {object} types.CreateRoomExitReply{}
+// @Router /api/v1/room/{id}/exits [post]
+// @Security BearerAuth
+func (h *roomExitHandler) Create(c *gin.Context) {
+	roomID, isAbort := getRoomIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	form := &types.CreateRoomExitRequest{}
+	err := c.ShouldBindJSON(form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	direction, ok := world.ParseDirection(form.Direction)
+	if !ok {
+		response.Error(c, ecode.ErrRoomExitDirection)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	if !h.checkRooms(c, ctx, roomID, form.ToRoomID) {
+		return
+	}
+	exits, err := h.iDao.GetByRoomID(ctx, roomID)
+	if err != nil {
+		logger.Error("GetByRoomID error", logger.Err(err), logger.Any("roomID", roomID), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+	if findExit(exits, direction) != nil {
+		response.Error(c, ecode.ErrRoomExitExists)
+		return
+	}
+
+	exit := &model.RoomExit{}
+	err = copier.Copy(exit, form)
+	if err != nil {
+		response.Error(c, ecode.ErrCreateRoomExit)
+		return
+	}
+	exit.RoomID = roomID
+	exit.Direction = string(direction)
+
+	err = h.iDao.Create(ctx, exit)
+	if err != nil {
+		logger.Error("Create error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	response.Success(c, gin.H{"id": exit.ID})
+}
+
+// DeleteByDirection delete the exit of a room in a direction
+// @Summary Delete the exit of a room in a direction
+// @Description Deletes the exit leading out of the room in the given direction.
+// @Tags room exit
+// @Accept json
+// @Produce json
+// @Param id path string true "room id"
+// @Param direction path string true "direction"
+// @Success 200 {object} types.DeleteRoomExitReply{}
+// @Router /api/v1/room/{id}/exits/{direction} [delete]
+// @Security BearerAuth
+func (h *roomExitHandler) DeleteByDirection(c *gin.Context) {
+	exit, isAbort := h.getExitFromPath(c)
+	if isAbort {
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	err := h.iDao.DeleteByID(ctx, exit.ID)
+	if err != nil {
+		logger.Error("DeleteByID error", logger.Err(err), logger.Any("id", exit.ID), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	response.Success(c)
+}
+
+// UpdateByDirection update the exit of a room in a direction
+// @Summary Update the exit of a room in a direction
+// @Description Updates the target room or the door, lock and hidden flags of an exit, support partial update.
+// @Tags room exit
+// @Accept json
+// @Produce json
+// @Param id path string true "room id"
+// @Param direction path string true "direction"
+// @Param data body types.UpdateRoomExitRequest true "exit information"
+// @Success 200 {object} types.UpdateRoomExitReply{}
+// @Router /api/v1/room/{id}/exits/{direction} [put]
+// @Security BearerAuth
+func (h *roomExitHandler) UpdateByDirection(c *gin.Context) {
+	exit, isAbort := h.getExitFromPath(c)
+	if isAbort {
+		return
+	}
+
+	form := &types.UpdateRoomExitRequest{}
+	err := c.ShouldBindJSON(form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	if form.ToRoomID != "" && !h.checkRooms(c, ctx, form.ToRoomID) {
+		return
+	}
+
+	update := &model.RoomExit{}
+	err = copier.Copy(update, form)
+	if err != nil {
+		response.Error(c, ecode.ErrUpdateByIDRoomExit)
+		return
+	}
+	update.ID = exit.ID
+
+	err = h.iDao.UpdateByID(ctx, update)
+	if err != nil {
+		logger.Error("UpdateByID error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	response.Success(c)
+}
+
+// GetByDirection get the exit of a room in a direction
+// @Summary Get the exit of a room in a direction
+// @Description Gets detailed information of the exit leading out of the room in the given direction.
+// @Tags room exit
+// @Param id path string true "room id"
+// @Param direction path string true "direction"
+// @Accept json
+// @Produce json
+// @Success 200 {object} types.GetRoomExitReply{}
+// @Router /api/v1/room/{id}/exits/{direction} [get]
+// @Security BearerAuth
+func (h *roomExitHandler) GetByDirection(c *gin.Context) {
+	exit, isAbort := h.getExitFromPath(c)
+	if isAbort {
+		return
+	}
+
+	data, err := convertRoomExit(exit)
+	if err != nil {
+		response.Error(c, ecode.ErrGetByIDRoomExit)
+		return
+	}
+
+	response.Success(c, gin.H{"exit": data})
+}
+
+// List get the exits of a room
+// @Summary Get the exits of a room
+// @Description Returns all exits leading out of the room, hidden exits included.
+// @Tags room exit
+// @Param id path string true "room id"
+// @Accept json
+// @Produce json
+// @Success 200 {object} types.ListRoomExitsReply{}
+// @Router /api/v1/room/{id}/exits [get]
+// @Security BearerAuth
+func (h *roomExitHandler) List(c *gin.Context) {
+	roomID, isAbort := getRoomIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	exits, err := h.iDao.GetByRoomID(ctx, roomID)
+	if err != nil {
+		logger.Error("GetByRoomID error", logger.Err(err), logger.Any("roomID", roomID), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	data, err := convertRoomExits(exits)
+	if err != nil {
+		response.Error(c, ecode.ErrListRoomExit)
+		return
+	}
+
+	response.Success(c, gin.H{"exits": data})
+}
+
+// MigrateWays convert the free text Room.Way of every room into exits
+// @Summary Convert the free text Room.Way of every room into exits
+// @Description Parses "direction:room" entries in Room.Way, creates the missing exits and reports what could not be converted.
+// @Description Exits already present and targets that do not exist are skipped, Room.Way itself is left untouched.
+// @Description The exits are created in one transaction, none of them is created if one fails.
+// @Tags room exit
+// @Param dryRun query bool false "report only, do not create exits"
+// @Accept json
+// @Produce json
+// @Success 200 {object} types.MigrateRoomWaysReply{}
+// @Router /api/v1/room/exits/migrate [post]
+// @Security BearerAuth
+func (h *roomExitHandler) MigrateWays(c *gin.Context) {
+	dryRun := c.Query("dryRun") == "true"
+
+	ctx := middleware.WrapCtx(c)
+	rooms, err := dao.GetAllRooms(ctx, h.roomDao)
+	if err != nil {
+		logger.Error("GetAllRooms error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	report, err := h.migrateWays(ctx, rooms, dryRun)
+	if err != nil {
+		logger.Error("migrateWays error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrMigrateRoomWay)
+		return
+	}
+
+	response.Success(c, report)
+}
+
+func (h *roomExitHandler) migrateWays(ctx context.Context, rooms []*model.Room, dryRun bool) (*types.MigrateRoomWaysReport, error) {
+	report := &types.MigrateRoomWaysReport{
+		DryRun:   dryRun,
+		Rooms:    len(rooms),
+		Created:  []types.RoomExitObjDetail{},
+		Skipped:  []types.RoomWayMigrateIssue{},
+		Unparsed: []types.RoomWayMigrateIssue{},
+	}
+
+	var created []*model.RoomExit
+	roomIDs := make(map[string]bool, len(rooms))
+	for _, room := range rooms {
+		roomIDs[room.ID] = true
+	}
+
+	for _, room := range rooms {
+		wayExits, unparsed := world.ParseWay(room.Way)
+		for _, entry := range unparsed {
+			report.Unparsed = append(report.Unparsed, types.RoomWayMigrateIssue{
+				RoomID: room.ID, Entry: entry, Reason: "expected direction:room",
+			})
+		}
+		if len(wayExits) == 0 {
+			continue
+		}
+
+		exits, err := h.iDao.GetByRoomID(ctx, room.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, we := range wayExits {
+			entry := we.Direction.Short() + ":" + we.ToRoomID
+			if findExit(exits, we.Direction) != nil {
+				report.Skipped = append(report.Skipped, types.RoomWayMigrateIssue{
+					RoomID: room.ID, Entry: entry, Reason: "exit already exists",
+				})
+				continue
+			}
+			if !roomIDs[we.ToRoomID] {
+				report.Skipped = append(report.Skipped, types.RoomWayMigrateIssue{
+					RoomID: room.ID, Entry: entry, Reason: "target room does not exist",
+				})
+				continue
+			}
+
+			exit := &model.RoomExit{RoomID: room.ID, Direction: string(we.Direction), ToRoomID: we.ToRoomID}
+			created = append(created, exit)
+		}
+	}
+
+	if !dryRun && len(created) > 0 {
+		// all or none of the exits are created, so a failed migration can be run again
+		txCtx, notify := dao.CollectChanges(ctx)
+		err := h.db.WithContext(txCtx).Transaction(func(tx *gorm.DB) error {
+			for _, exit := range created {
+				if _, err := h.iDao.CreateByTx(txCtx, tx, exit); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		notify()
+	}
+
+	for _, exit := range created {
+		data, err := convertRoomExit(exit)
+		if err != nil {
+			return nil, err
+		}
+		report.Created = append(report.Created, *data)
+	}
+
+	return report, nil
+}
+
+// checkRooms check that the rooms exist, an error response has been written when false is returned
+func (h *roomExitHandler) checkRooms(c *gin.Context, ctx context.Context, roomIDs ...string) bool { //nolint
+	for i, id := range roomIDs {
+		_, err := h.roomDao.GetByID(ctx, id)
+		if err == nil {
+			continue
+		}
+		if errors.Is(err, database.ErrRecordNotFound) {
+			if i == 0 && len(roomIDs) > 1 {
+				response.Error(c, ecode.NotFound)
+			} else {
+				response.Error(c, ecode.ErrRoomExitTarget)
+			}
+			return false
+		}
+		logger.Error("GetByID error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return false
+	}
+	return true
+}
+
+// getExitFromPath find the exit identified by the room id and direction in the path,
+// an error response has been written when isAbort is true
+func (h *roomExitHandler) getExitFromPath(c *gin.Context) (*model.RoomExit, bool) {
+	roomID, isAbort := getRoomIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return nil, true
+	}
+	direction, ok := world.ParseDirection(c.Param("direction"))
+	if !ok {
+		response.Error(c, ecode.ErrRoomExitDirection)
+		return nil, true
+	}
+
+	ctx := middleware.WrapCtx(c)
+	exits, err := h.iDao.GetByRoomID(ctx, roomID)
+	if err != nil {
+		logger.Error("GetByRoomID error", logger.Err(err), logger.Any("roomID", roomID), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return nil, true
+	}
+	exit := findExit(exits, direction)
+	if exit == nil {
+		response.Error(c, ecode.NotFound)
+		return nil, true
+	}
+
+	return exit, false
+}
+
+func findExit(exits []*model.RoomExit, direction world.Direction) *model.RoomExit {
+	for _, exit := range exits {
+		if exit.Direction == string(direction) {
+			return exit
+		}
+	}
+	return nil
+}
+
+func convertRoomExit(exit *model.RoomExit) (*types.RoomExitObjDetail, error) {
+	data := &types.RoomExitObjDetail{}
+	err := copier.Copy(data, exit)
+	if err != nil {
+		return nil, err
+	}
+	// Note: if copier.Copy cannot assign a value to a field, add it here
+
+	return data, nil
+}
+
+func convertRoomExits(fromValues []*model.RoomExit) ([]*types.RoomExitObjDetail, error) {
+	toValues := []*types.RoomExitObjDetail{}
+	for _, v := range fromValues {
+		data, err := convertRoomExit(v)
+		if err != nil {
+			return nil, err
+		}
+		toValues = append(toValues, data)
+	}
+
+	return toValues, nil
+}
@@ -0,0 +1,317 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-dev-frame/sponge/pkg/gotest"
+	"github.com/go-dev-frame/sponge/pkg/httpcli"
+
+	"fs/internal/dao"
+	"fs/internal/ecode"
+	"fs/internal/model"
+	"fs/internal/types"
+)
+
+func newRoomExitHandler() *gotest.Handler {
+	testData := &model.RoomExit{ID: 1, RoomID: "hall", Direction: "north", ToRoomID: "yard", Cost: 1}
+
+	// init mock dao, the exits of a room are read without cache
+	d := gotest.NewDao(nil, testData)
+	d.IDao = dao.NewRoomExitDao(d.DB, nil)
+
+	// init mock handler
+	h := gotest.NewHandler(d, testData)
+	h.IHandler = &roomExitHandler{
+		iDao:    d.IDao.(dao.RoomExitDao),
+		roomDao: dao.NewRoomDao(d.DB, nil),
+		db:      d.DB,
+	}
+	iHandler := h.IHandler.(RoomExitHandler)
+
+	testFns := []gotest.RouterInfo{
+		{
+			FuncName:    "Create",
+			Method:      http.MethodPost,
+			Path:        "/room/:id/exits",
+			HandlerFunc: iHandler.Create,
+		},
+		{
+			FuncName:    "DeleteByDirection",
+			Method:      http.MethodDelete,
+			Path:        "/room/:id/exits/:direction",
+			HandlerFunc: iHandler.DeleteByDirection,
+		},
+		{
+			FuncName:    "UpdateByDirection",
+			Method:      http.MethodPut,
+			Path:        "/room/:id/exits/:direction",
+			HandlerFunc: iHandler.UpdateByDirection,
+		},
+		{
+			FuncName:    "GetByDirection",
+			Method:      http.MethodGet,
+			Path:        "/room/:id/exits/:direction",
+			HandlerFunc: iHandler.GetByDirection,
+		},
+		{
+			FuncName:    "List",
+			Method:      http.MethodGet,
+			Path:        "/room/:id/exits",
+			HandlerFunc: iHandler.List,
+		},
+		{
+			FuncName:    "MigrateWays",
+			Method:      http.MethodPost,
+			Path:        "/room/exits/migrate",
+			HandlerFunc: iHandler.MigrateWays,
+		},
+	}
+
+	h.GoRunHTTPServer(testFns)
+
+	time.Sleep(time.Millisecond * 200)
+	return h
+}
+
+// expectRoom expect the lookup of a room by id, found or not
+func expectRoom(h *gotest.Handler, id string, found bool) {
+	rows := sqlmock.NewRows([]string{"id"})
+	if found {
+		rows.AddRow(id)
+	}
+	h.MockDao.SQLMock.ExpectQuery("SELECT \\* FROM `room` WHERE id = \\?").
+		WithArgs(id, 1).
+		WillReturnRows(rows)
+}
+
+// expectExits expect the lookup of the exits of a room
+func expectExits(h *gotest.Handler, roomID string, exits ...*model.RoomExit) {
+	rows := sqlmock.NewRows([]string{"id", "room_id", "direction", "to_room_id", "cost"})
+	for _, e := range exits {
+		rows.AddRow(e.ID, e.RoomID, e.Direction, e.ToRoomID, e.Cost)
+	}
+	h.MockDao.SQLMock.ExpectQuery("SELECT \\* FROM `room_exit` WHERE room_id = \\?").
+		WithArgs(roomID).
+		WillReturnRows(rows)
+}
+
+func Test_roomExitHandler_Create(t *testing.T) {
+	h := newRoomExitHandler()
+	defer h.Close()
+	testData := h.TestData.(*model.RoomExit)
+	form := &types.CreateRoomExitRequest{Direction: "n", ToRoomID: testData.ToRoomID}
+
+	expectRoom(h, testData.RoomID, true)
+	expectRoom(h, testData.ToRoomID, true)
+	expectExits(h, testData.RoomID)
+	h.MockDao.SQLMock.ExpectBegin()
+	h.MockDao.SQLMock.ExpectExec("INSERT INTO `room_exit`").
+		WillReturnResult(sqlmock.NewResult(int64(testData.ID), 1))
+	h.MockDao.SQLMock.ExpectCommit()
+
+	result := &httpcli.StdResult{}
+	err := httpcli.Post(result, h.GetRequestURL("Create", testData.RoomID), form)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Code != 0 {
+		t.Fatalf("%+v", result)
+	}
+	assert.Equal(t, map[string]interface{}{"id": float64(testData.ID)}, result.Data)
+
+	// one exit per direction
+	expectRoom(h, testData.RoomID, true)
+	expectRoom(h, testData.ToRoomID, true)
+	expectExits(h, testData.RoomID, testData)
+	err = httpcli.Post(result, h.GetRequestURL("Create", testData.RoomID), form)
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.ErrRoomExitExists.Code(), result.Code)
+
+	// unknown target
+	expectRoom(h, testData.RoomID, true)
+	expectRoom(h, "void", false)
+	err = httpcli.Post(result, h.GetRequestURL("Create", testData.RoomID), &types.CreateRoomExitRequest{Direction: "n", ToRoomID: "void"})
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.ErrRoomExitTarget.Code(), result.Code)
+
+	// unknown direction
+	err = httpcli.Post(result, h.GetRequestURL("Create", testData.RoomID), &types.CreateRoomExitRequest{Direction: "sideways", ToRoomID: "yard"})
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.ErrRoomExitDirection.Code(), result.Code)
+}
+
+func Test_roomExitHandler_DeleteByDirection(t *testing.T) {
+	h := newRoomExitHandler()
+	defer h.Close()
+	testData := h.TestData.(*model.RoomExit)
+
+	expectExits(h, testData.RoomID, testData)
+	h.MockDao.SQLMock.ExpectQuery("SELECT `room_id` FROM `room_exit` WHERE id = \\?").
+		WithArgs(testData.ID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"room_id"}).AddRow(testData.RoomID))
+	h.MockDao.SQLMock.ExpectBegin()
+	h.MockDao.SQLMock.ExpectExec("DELETE FROM `room_exit` WHERE id = \\?").
+		WithArgs(testData.ID).
+		WillReturnResult(sqlmock.NewResult(int64(testData.ID), 1))
+	h.MockDao.SQLMock.ExpectCommit()
+
+	result := &httpcli.StdResult{}
+	err := httpcli.Delete(result, h.GetRequestURL("DeleteByDirection", testData.RoomID, "n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Code != 0 {
+		t.Fatalf("%+v", result)
+	}
+
+	// no exit in that direction
+	expectExits(h, testData.RoomID, testData)
+	err = httpcli.Delete(result, h.GetRequestURL("DeleteByDirection", testData.RoomID, "south"))
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.NotFound.Code(), result.Code)
+}
+
+func Test_roomExitHandler_UpdateByDirection(t *testing.T) {
+	h := newRoomExitHandler()
+	defer h.Close()
+	testData := h.TestData.(*model.RoomExit)
+
+	expectExits(h, testData.RoomID, testData)
+	expectRoom(h, "gate", true)
+	h.MockDao.SQLMock.ExpectBegin()
+	h.MockDao.SQLMock.ExpectExec("UPDATE `room_exit` SET `cost`=\\?,`to_room_id`=\\? WHERE `id` = \\?").
+		WithArgs(3, "gate", testData.ID).
+		WillReturnResult(sqlmock.NewResult(int64(testData.ID), 1))
+	h.MockDao.SQLMock.ExpectCommit()
+
+	result := &httpcli.StdResult{}
+	err := httpcli.Put(result, h.GetRequestURL("UpdateByDirection", testData.RoomID, "north"), &types.UpdateRoomExitRequest{ToRoomID: "gate", Cost: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Code != 0 {
+		t.Fatalf("%+v", result)
+	}
+
+	// unknown target
+	expectExits(h, testData.RoomID, testData)
+	expectRoom(h, "void", false)
+	err = httpcli.Put(result, h.GetRequestURL("UpdateByDirection", testData.RoomID, "north"), &types.UpdateRoomExitRequest{ToRoomID: "void"})
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.ErrRoomExitTarget.Code(), result.Code)
+}
+
+func Test_roomExitHandler_GetByDirection(t *testing.T) {
+	h := newRoomExitHandler()
+	defer h.Close()
+	testData := h.TestData.(*model.RoomExit)
+
+	expectExits(h, testData.RoomID, testData)
+
+	result := &httpcli.StdResult{}
+	err := httpcli.Get(result, h.GetRequestURL("GetByDirection", testData.RoomID, "n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Code != 0 {
+		t.Fatalf("%+v", result)
+	}
+	exit := result.Data.(map[string]interface{})["exit"].(map[string]interface{})
+	assert.Equal(t, "north", exit["direction"])
+	assert.Equal(t, testData.ToRoomID, exit["toRoomID"])
+
+	// unknown direction
+	err = httpcli.Get(result, h.GetRequestURL("GetByDirection", testData.RoomID, "sideways"))
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.ErrRoomExitDirection.Code(), result.Code)
+}
+
+func Test_roomExitHandler_List(t *testing.T) {
+	h := newRoomExitHandler()
+	defer h.Close()
+	testData := h.TestData.(*model.RoomExit)
+
+	expectExits(h, testData.RoomID, testData, &model.RoomExit{ID: 2, RoomID: "hall", Direction: "up", ToRoomID: "attic"})
+
+	result := &httpcli.StdResult{}
+	err := httpcli.Get(result, h.GetRequestURL("List", testData.RoomID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Code != 0 {
+		t.Fatalf("%+v", result)
+	}
+	assert.Len(t, result.Data.(map[string]interface{})["exits"], 2)
+
+	// get error test
+	err = httpcli.Get(result, h.GetRequestURL("List", "void"))
+	assert.Error(t, err)
+}
+
+func Test_roomExitHandler_MigrateWays(t *testing.T) {
+	h := newRoomExitHandler()
+	defer h.Close()
+	testData := h.TestData.(*model.RoomExit)
+	expectRooms := func() {
+		h.MockDao.SQLMock.ExpectQuery("SELECT \\* FROM `room`").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "way"}).
+				AddRow("hall", "n:yard,e:yard,s:void,x").
+				AddRow("yard", "s:hall"))
+	}
+
+	// the exits are created in one transaction
+	expectRooms()
+	expectExits(h, "hall")
+	expectExits(h, "yard", &model.RoomExit{ID: 9, RoomID: "yard", Direction: "south", ToRoomID: "hall"})
+	h.MockDao.SQLMock.ExpectBegin()
+	h.MockDao.SQLMock.ExpectExec("INSERT INTO `room_exit`").
+		WillReturnResult(sqlmock.NewResult(int64(testData.ID), 1))
+	h.MockDao.SQLMock.ExpectExec("INSERT INTO `room_exit`").
+		WillReturnResult(sqlmock.NewResult(2, 1))
+	h.MockDao.SQLMock.ExpectCommit()
+
+	result := &httpcli.StdResult{}
+	err := httpcli.Post(result, h.GetRequestURL("MigrateWays"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Code != 0 {
+		t.Fatalf("%+v", result)
+	}
+	report := result.Data.(map[string]interface{})
+	assert.Equal(t, float64(2), report["rooms"])
+	assert.Len(t, report["created"], 2)
+	assert.Equal(t, float64(testData.ID), report["created"].([]interface{})[0].(map[string]interface{})["id"])
+	assert.Len(t, report["skipped"], 2) // s:void and the exit of yard
+	assert.Len(t, report["unparsed"], 1)
+
+	// a failed insert rolls back the exits created before it
+	expectRooms()
+	expectExits(h, "hall")
+	expectExits(h, "yard")
+	h.MockDao.SQLMock.ExpectBegin()
+	h.MockDao.SQLMock.ExpectExec("INSERT INTO `room_exit`").
+		WillReturnResult(sqlmock.NewResult(int64(testData.ID), 1))
+	h.MockDao.SQLMock.ExpectExec("INSERT INTO `room_exit`").
+		WillReturnError(errors.New("duplicate entry"))
+	h.MockDao.SQLMock.ExpectRollback()
+	err = httpcli.Post(result, h.GetRequestURL("MigrateWays"), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.ErrMigrateRoomWay.Code(), result.Code)
+
+	// dry run, nothing is written
+	expectRooms()
+	expectExits(h, "hall")
+	expectExits(h, "yard")
+	err = httpcli.Post(result, h.GetRequestURL("MigrateWays")+"?dryRun=true", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.Code)
+	assert.Len(t, result.Data.(map[string]interface{})["created"], 3)
+	assert.NoError(t, h.MockDao.SQLMock.ExpectationsWereMet())
+}
@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-dev-frame/sponge/pkg/gotest"
+	"github.com/go-dev-frame/sponge/pkg/httpcli"
+
+	"fs/internal/dao"
+	"fs/internal/ecode"
+	"fs/internal/model"
+	"fs/internal/reference"
+)
+
+func newRoomHandler() *gotest.Handler {
+	testData := &model.Room{ID: "square", Title: "Town Square"}
+
+	// init mock dao
+	d := gotest.NewDao(nil, testData)
+	d.IDao = dao.NewRoomDao(d.DB, nil)
+
+	// init mock handler
+	h := gotest.NewHandler(d, testData)
+	h.IHandler = &roomHandler{
+		iDao:   d.IDao.(dao.RoomDao),
+		mobDao: dao.NewMobDao(d.DB, nil),
+		refDaos: reference.Daos{
+			Rooms:         d.IDao.(dao.RoomDao),
+			RoomExits:     dao.NewRoomExitDao(d.DB, nil),
+			Mobs:          dao.NewMobDao(d.DB, nil),
+			Spawns:        dao.NewSpawnDao(d.DB, nil),
+			ItemInstances: dao.NewItemInstanceDao(d.DB, nil),
+			Characters:    dao.NewCharacterDao(d.DB, nil),
+		},
+		db: d.DB,
+	}
+	iHandler := h.IHandler.(RoomHandler)
+
+	testFns := []gotest.RouterInfo{
+		{
+			FuncName:    "DeleteByID",
+			Method:      http.MethodDelete,
+			Path:        "/room/:id",
+			HandlerFunc: iHandler.DeleteByID,
+		},
+		{
+			FuncName:    "Restore",
+			Method:      http.MethodPost,
+			Path:        "/room/:id/restore",
+			HandlerFunc: iHandler.Restore,
+		},
+	}
+
+	h.GoRunHTTPServer(testFns)
+
+	time.Sleep(time.Millisecond * 200)
+	return h
+}
+
+func Test_roomHandler_DeleteByID(t *testing.T) {
+	h := newRoomHandler()
+	defer h.Close()
+	testData := h.TestData.(*model.Room)
+	expectReferences := func(exits, spawns, instances, characters *sqlmock.Rows) {
+		h.MockDao.SQLMock.ExpectBegin()
+		h.MockDao.SQLMock.ExpectQuery("SELECT \\* FROM `room_exit` WHERE to_room_id = \\? AND room_id <> \\?").
+			WithArgs(testData.ID, testData.ID).
+			WillReturnRows(exits)
+		h.MockDao.SQLMock.ExpectQuery("SELECT \\* FROM `spawn` WHERE room_id = \\?").
+			WithArgs(testData.ID).
+			WillReturnRows(spawns)
+		h.MockDao.SQLMock.ExpectQuery("SELECT \\* FROM `item_instance` WHERE location_type = \\? AND owner_id = \\?").
+			WithArgs(model.ItemLocationRoom, testData.ID).
+			WillReturnRows(instances)
+		h.MockDao.SQLMock.ExpectQuery("SELECT \\* FROM `item_instance` WHERE location_type = \\? AND owner_id = \\?").
+			WithArgs(model.ItemLocationContainer, "5"). // the contents of the instance in the room
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		h.MockDao.SQLMock.ExpectQuery("SELECT \\* FROM `character` WHERE room_id = \\?").
+			WithArgs(testData.ID).
+			WillReturnRows(characters)
+	}
+	referenceRows := func() (exits, spawns, instances, characters *sqlmock.Rows) {
+		return sqlmock.NewRows([]string{"id", "room_id", "direction", "to_room_id"}).AddRow(2, "gate", "south", testData.ID),
+			sqlmock.NewRows([]string{"id", "room_id"}).AddRow(3, testData.ID),
+			sqlmock.NewRows([]string{"id", "location_type", "owner_id"}).AddRow(5, model.ItemLocationRoom, testData.ID),
+			sqlmock.NewRows([]string{"id", "account_id", "name", "room_id", "hp"}).AddRow(7, 1, "ann", testData.ID, 40)
+	}
+
+	// a room without references is moved to the trash
+	h.MockDao.SQLMock.ExpectBegin()
+	h.MockDao.SQLMock.ExpectQuery("SELECT \\* FROM `room_exit`").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	h.MockDao.SQLMock.ExpectQuery("SELECT \\* FROM `spawn`").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	h.MockDao.SQLMock.ExpectQuery("SELECT \\* FROM `item_instance`").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	h.MockDao.SQLMock.ExpectQuery("SELECT \\* FROM `character`").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	h.MockDao.SQLMock.ExpectExec("UPDATE `room` SET `deleted_at`=\\? WHERE id = \\?").
+		WithArgs(h.MockDao.AnyTime, testData.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	h.MockDao.SQLMock.ExpectCommit()
+
+	result := &httpcli.StdResult{}
+	err := httpcli.Delete(result, h.GetRequestURL("DeleteByID", testData.ID))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.Code)
+	assert.Equal(t, map[string]interface{}{"references": []interface{}{}}, result.Data)
+
+	// refused while an exit, a spawn, an item instance and a character refer to the room
+	expectReferences(referenceRows())
+	h.MockDao.SQLMock.ExpectRollback()
+	err = httpcli.Delete(result, h.GetRequestURL("DeleteByID", testData.ID))
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.FailedPrecondition.Code(), result.Code)
+	assert.Contains(t, result.Msg, "room_exit 2 to_room_id, spawn 3 room_id, item_instance 5 owner_id, character 7 room_id")
+
+	// the references are removed in the transaction of the delete, and the room is purged with them
+	expectReferences(referenceRows())
+	h.MockDao.SQLMock.ExpectQuery("SELECT `room_id` FROM `room_exit` WHERE id = \\?").
+		WithArgs(2, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"room_id"}).AddRow("gate"))
+	h.MockDao.SQLMock.ExpectExec("DELETE FROM `room_exit` WHERE id = \\?").
+		WithArgs(2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	h.MockDao.SQLMock.ExpectExec("DELETE FROM `spawn` WHERE id = \\?").
+		WithArgs(3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	h.MockDao.SQLMock.ExpectExec("DELETE FROM `item_instance` WHERE id = \\?").
+		WithArgs(5).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// every column of the character is kept but the room, it enters the start room the next time
+	h.MockDao.SQLMock.ExpectExec("UPDATE `character` SET `account_id`=\\?,`name`=\\?,`room_id`=\\?,`hp`=\\?,.* WHERE `id` = \\?").
+		WithArgs(1, "ann", "", 40, 0, 0, 0, 0, 0, 0, 0, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	h.MockDao.SQLMock.ExpectExec("DELETE FROM `room` WHERE id = \\?").
+		WithArgs(testData.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	h.MockDao.SQLMock.ExpectCommit()
+	err = httpcli.Delete(result, h.GetRequestURL("DeleteByID", testData.ID), httpcli.WithParams(map[string]interface{}{"cascade": true}))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.Code)
+	assert.Equal(t, map[string]interface{}{"references": []interface{}{
+		map[string]interface{}{"table": "room_exit", "id": "2", "column": "to_room_id"},
+		map[string]interface{}{"table": "spawn", "id": "3", "column": "room_id"},
+		map[string]interface{}{"table": "item_instance", "id": "5", "column": "owner_id"},
+		map[string]interface{}{"table": "character", "id": "7", "column": "room_id"},
+	}}, result.Data)
+	assert.NoError(t, h.MockDao.SQLMock.ExpectationsWereMet())
+
+	// invalid cascade error test
+	err = httpcli.Delete(result, h.GetRequestURL("DeleteByID", testData.ID), httpcli.WithParams(map[string]interface{}{"cascade": "maybe"}))
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.InvalidParams.Code(), result.Code)
+
+	// delete error test
+	err = httpcli.Delete(result, h.GetRequestURL("DeleteByID", "gate"))
+	assert.Error(t, err)
+}
+
+func Test_roomHandler_Restore(t *testing.T) {
+	h := newRoomHandler()
+	defer h.Close()
+	testData := h.TestData.(*model.Room)
+
+	h.MockDao.SQLMock.ExpectBegin()
+	h.MockDao.SQLMock.ExpectExec("UPDATE `room` SET `deleted_at`=\\?.* WHERE id = \\? AND deleted_at IS NOT NULL").
+		WithArgs(nil, testData.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	h.MockDao.SQLMock.ExpectCommit()
+
+	result := &httpcli.StdResult{}
+	err := httpcli.Post(result, h.GetRequestURL("Restore", testData.ID), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.Code)
+
+	// a room purged by a cascading delete is not in the trash
+	h.MockDao.SQLMock.ExpectBegin()
+	h.MockDao.SQLMock.ExpectExec("UPDATE `room` SET `deleted_at`=\\?.* WHERE id = \\? AND deleted_at IS NOT NULL").
+		WithArgs(nil, testData.ID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	h.MockDao.SQLMock.ExpectCommit()
+	err = httpcli.Post(result, h.GetRequestURL("Restore", testData.ID), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.NotFound.Code(), result.Code)
+	assert.NoError(t, h.MockDao.SQLMock.ExpectationsWereMet())
+}
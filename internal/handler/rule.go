@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/go-dev-frame/sponge/pkg/errcode"
+	"github.com/go-dev-frame/sponge/pkg/gin/middleware"
+	"github.com/go-dev-frame/sponge/pkg/gin/response"
+	"github.com/go-dev-frame/sponge/pkg/logger"
+
+	"fs/internal/database"
+	"fs/internal/ecode"
+	"fs/internal/rule"
+)
+
+// writtenFields the names of the fields of table written by UpdateByID of the dao, which skips the zero ones
+func writtenFields(table interface{}) []string {
+	var fields []string
+	v := reflect.ValueOf(table).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if field.Anonymous || field.Name == "ID" || field.Name == "Version" || v.Field(i).IsZero() {
+			continue
+		}
+		fields = append(fields, field.Name)
+	}
+	return fields
+}
+
+// mergeFields copy the fields of src to dst, two pointers to the same model
+func mergeFields(dst interface{}, src interface{}, fields []string) {
+	d, s := reflect.ValueOf(dst).Elem(), reflect.ValueOf(src).Elem()
+	for _, name := range fields {
+		d.FieldByName(name).Set(s.FieldByName(name))
+	}
+}
+
+// abortOnViolations respond with the fields breaking the validation rules under the code e,
+// an error response has been written when isAbort is true
+func abortOnViolations(c *gin.Context, e *errcode.Error, violations []rule.Violation, err error) (isAbort bool) {
+	if err != nil {
+		logger.Error("check rules error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return true
+	}
+	if len(violations) > 0 {
+		logger.Warn("rules violated", logger.Any("errors", violations), middleware.GCtxRequestIDField(c))
+		response.Error(c, e, gin.H{"errors": violations})
+		return true
+	}
+	return false
+}
+
+// abortOnDuplicated respond as abortOnViolations with the unique field of table if the unique index of the
+// database refused the record, an error response has been written when isAbort is true
+func abortOnDuplicated(c *gin.Context, e *errcode.Error, rules *rule.Rules, table interface{}, err error) (isAbort bool) {
+	if !errors.Is(err, database.ErrDuplicatedKey) {
+		return false
+	}
+	return abortOnViolations(c, e, []rule.Violation{rules.UniqueViolation(table)}, nil)
+}
@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/go-dev-frame/sponge/pkg/gin/middleware"
+	"github.com/go-dev-frame/sponge/pkg/gin/response"
+	"github.com/go-dev-frame/sponge/pkg/logger"
+
+	"fs/internal/cache"
+	"fs/internal/combat"
+	"fs/internal/dao"
+	"fs/internal/database"
+	"fs/internal/ecode"
+	"fs/internal/equip"
+	"fs/internal/model"
+	"fs/internal/types"
+)
+
+// combat simulation defaults
+const (
+	defaultSimFights    = 1000
+	defaultSimMaxRounds = 200
+)
+
+var _ SimHandler = (*simHandler)(nil)
+
+// SimHandler defining the handler interface
+type SimHandler interface {
+	Combat(c *gin.Context)
+}
+
+type simHandler struct {
+	mobDao      dao.MobDao
+	itemDao     dao.ItemDao
+	instanceDao dao.ItemInstanceDao
+}
+
+// NewSimHandler creating the handler interface
+func NewSimHandler() SimHandler {
+	return &simHandler{
+		mobDao: dao.NewMobDao(
+			database.GetDB(), // db driver is mysql
+			cache.NewMobCache(database.GetCacheType()),
+		),
+		itemDao: dao.NewItemDao(
+			database.GetDB(),
+			cache.NewItemCache(database.GetCacheType()),
+		),
+		instanceDao: dao.NewItemInstanceDao(
+			database.GetDB(),
+			cache.NewItemInstanceCache(database.GetCacheType()),
+		),
+	}
+}
+
+// Combat simulate fights of a character build against a mob
+// @Summary Simulate combat
+// @Description Fights a character with the given base stats and items against a mob many times, with the formulas
+// @Description and the round order of the game server. Returns the win rate and how the rounds and the damage
+// @Description done and taken were distributed. The same seed gives the same result.
+// @Tags sim
+// @Accept json
+// @Produce json
+// @Param data body types.SimulateCombatRequest true "mob, character build and number of fights"
+// @Success 200 {object} types.SimulateCombatReply{}
+// @Router /api/v1/sim/combat [post]
+// @Security BearerAuth
+func (h *simHandler) Combat(c *gin.Context) {
+	form := &types.SimulateCombatRequest{}
+	err := c.ShouldBindJSON(form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	mob, err := h.mobDao.GetByID(ctx, form.MobID)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			response.Error(c, ecode.ErrSimMob.WithDetails(strconv.FormatUint(form.MobID, 10)))
+			return
+		}
+		logger.Error("GetByID error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+	instances, err := h.instanceDao.GetByOwner(ctx, model.ItemLocationMob, strconv.FormatUint(mob.ID, 10))
+	if err != nil {
+		logger.Error("GetByOwner error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	protoIDs := append([]uint64{}, form.ItemIDs...)
+	for _, inst := range instances {
+		protoIDs = append(protoIDs, inst.ProtoID)
+	}
+	var protos map[uint64]*model.Item
+	if len(protoIDs) > 0 {
+		protos, err = h.itemDao.GetByIDs(ctx, protoIDs)
+		if err != nil {
+			logger.Error("GetByIDs error", logger.Err(err), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+			return
+		}
+	}
+
+	// the build must be wearable, the items of the mob are worn as in the game
+	outfit := equip.Outfit{}
+	for _, id := range form.ItemIDs {
+		proto, ok := protos[id]
+		if !ok {
+			response.Error(c, ecode.ErrSimItem.WithDetails(strconv.FormatUint(id, 10)))
+			return
+		}
+		if _, err = outfit.Wear(proto); err != nil {
+			response.Error(c, ecode.ErrSimEquipment.WithDetails(err.Error()))
+			return
+		}
+	}
+	var mobItems []*model.Item
+	for _, inst := range instances {
+		if proto, ok := protos[inst.ProtoID]; ok {
+			mobItems = append(mobItems, inst.Overrides.Apply(proto))
+		}
+	}
+
+	base := form.Base
+	if base == (equip.Stats{}) {
+		base = equip.StartingStats
+	}
+	character := combat.New(equip.Effective(base, outfit))
+	opponent := combat.New(equip.Effective(combat.MobBase(mob), equip.Dress(mobItems)))
+	fights, maxRounds := form.Fights, form.MaxRounds
+	if fights == 0 {
+		fights = defaultSimFights
+	}
+	if maxRounds == 0 {
+		maxRounds = defaultSimMaxRounds
+	}
+
+	response.Success(c, &types.CombatSimulation{
+		Character:    character,
+		Mob:          opponent,
+		HitChance:    combat.HitChance(character, opponent),
+		MobHitChance: combat.HitChance(opponent, character),
+		Simulation:   *combat.Simulate(character, opponent, fights, maxRounds, form.Seed),
+	})
+}
@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-dev-frame/sponge/pkg/gotest"
+	"github.com/go-dev-frame/sponge/pkg/httpcli"
+
+	"fs/internal/dao"
+	"fs/internal/ecode"
+	"fs/internal/equip"
+	"fs/internal/model"
+	"fs/internal/types"
+)
+
+func newSimHandler() *gotest.Handler {
+	testData := &model.Mob{}
+	testData.ID = 1
+	testData.MobName = "rat"
+	testData.Hp = 30
+	testData.Attack = 4
+	testData.Defence = 2
+	testData.Dodge = 2
+
+	// init mock dao, the mobs and items are read without cache
+	c := gotest.NewCache(map[string]interface{}{"no cache": testData})
+	d := gotest.NewDao(c, testData)
+	d.IDao = dao.NewMobDao(d.DB, nil)
+
+	// init mock handler
+	h := gotest.NewHandler(d, testData)
+	h.IHandler = &simHandler{
+		mobDao:      d.IDao.(dao.MobDao),
+		itemDao:     dao.NewItemDao(d.DB, nil),
+		instanceDao: dao.NewItemInstanceDao(d.DB, nil),
+	}
+	iHandler := h.IHandler.(SimHandler)
+
+	testFns := []gotest.RouterInfo{
+		{
+			FuncName:    "Combat",
+			Method:      http.MethodPost,
+			Path:        "/sim/combat",
+			HandlerFunc: iHandler.Combat,
+		},
+	}
+
+	h.GoRunHTTPServer(testFns)
+
+	time.Sleep(time.Millisecond * 200)
+	return h
+}
+
+func Test_simHandler_Combat(t *testing.T) {
+	h := newSimHandler()
+	defer h.Close()
+	testData := h.TestData.(*model.Mob)
+
+	mobColumns := []string{"id", "mob_name", "hp", "attack", "defence", "dodge"}
+	itemColumns := []string{"id", "item_name", "classifier", "attack", "defence"}
+	expectMob := func() {
+		h.MockDao.SQLMock.ExpectQuery("SELECT .*").
+			WithArgs(testData.ID, 1).
+			WillReturnRows(sqlmock.NewRows(mobColumns).
+				AddRow(testData.ID, testData.MobName, testData.Hp, testData.Attack, testData.Defence, testData.Dodge))
+	}
+
+	// the rat wears a shield
+	expectMob()
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(model.ItemLocationMob, "1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "proto_id"}).AddRow(9, 3))
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(2, 3).
+		WillReturnRows(sqlmock.NewRows(itemColumns).
+			AddRow(2, "sword", "w", 5, 0).
+			AddRow(3, "shield", "s", 0, 4))
+
+	result := &httpcli.StdResult{}
+	err := httpcli.Post(result, h.GetRequestURL("Combat"), &types.SimulateCombatRequest{
+		MobID:   testData.ID,
+		ItemIDs: []uint64{2},
+		Fights:  200,
+		Seed:    1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Code != 0 {
+		t.Fatalf("%+v", result)
+	}
+	data := result.Data.(map[string]interface{})
+	assert.Equal(t, float64(200), data["fights"])
+	assert.Equal(t, float64(15), data["character"].(map[string]interface{})["attack"])
+	assert.Equal(t, float64(6), data["mob"].(map[string]interface{})["defence"])
+	assert.Equal(t, float64(76), data["hitChance"])
+	assert.Greater(t, data["winRate"], 0.9)
+	assert.Contains(t, data["taken"], "p90")
+
+	// two weapons
+	expectMob()
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "proto_id"}))
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").
+		WillReturnRows(sqlmock.NewRows(itemColumns).
+			AddRow(2, "sword", "w", 5, 0).
+			AddRow(4, "axe", "w", 7, 0))
+	err = httpcli.Post(result, h.GetRequestURL("Combat"), &types.SimulateCombatRequest{
+		MobID:   testData.ID,
+		Base:    equip.Stats{Hp: 50, Str: 5},
+		ItemIDs: []uint64{2, 4},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.ErrSimEquipment.Code(), result.Code)
+
+	// unknown mob
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").
+		WillReturnRows(sqlmock.NewRows(mobColumns))
+	err = httpcli.Post(result, h.GetRequestURL("Combat"), &types.SimulateCombatRequest{MobID: 5})
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.ErrSimMob.Code(), result.Code)
+
+	// too many fights
+	err = httpcli.Post(result, h.GetRequestURL("Combat"), &types.SimulateCombatRequest{MobID: testData.ID, Fights: 1000000})
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.InvalidParams.Code(), result.Code)
+}
@@ -0,0 +1,358 @@
+package handler
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/go-dev-frame/sponge/pkg/copier"
+	"github.com/go-dev-frame/sponge/pkg/gin/middleware"
+	"github.com/go-dev-frame/sponge/pkg/gin/response"
+	"github.com/go-dev-frame/sponge/pkg/logger"
+	"github.com/go-dev-frame/sponge/pkg/utils"
+
+	"fs/internal/cache"
+	"fs/internal/dao"
+	"fs/internal/database"
+	"fs/internal/ecode"
+	"fs/internal/model"
+	"fs/internal/types"
+)
+
+var _ SpawnHandler = (*spawnHandler)(nil)
+
+// SpawnHandler defining the handler interface
+type SpawnHandler interface {
+	Create(c *gin.Context)
+	DeleteByID(c *gin.Context)
+	UpdateByID(c *gin.Context)
+	GetByID(c *gin.Context)
+	List(c *gin.Context)
+	ListByIDs(c *gin.Context)
+}
+
+type spawnHandler struct {
+	iDao    dao.SpawnDao
+	roomDao dao.RoomDao
+	mobDao  dao.MobDao
+}
+
+// NewSpawnHandler creating the handler interface
+func NewSpawnHandler() SpawnHandler {
+	return &spawnHandler{
+		iDao: dao.NewSpawnDao(
+			database.GetDB(), // db driver is mysql
+			cache.NewSpawnCache(database.GetCacheType()),
+		),
+		roomDao: dao.NewRoomDao(
+			database.GetDB(),
+			cache.NewRoomCache(database.GetCacheType()),
+		),
+		mobDao: dao.NewMobDao(
+			database.GetDB(),
+			cache.NewMobCache(database.GetCacheType()),
+		),
+	}
+}
+
+// Create a new spawn
+// @Summary Create a new spawn
+// @Description Creates a new spawn entity using the provided data in the request body, the room and the mob prototype must exist.
+// @Tags spawn
+// @Accept json
+// @Produce json
+// @Param data body types.CreateSpawnRequest true "spawn information"
+// @Success 200 {object} types.CreateSpawnReply{}
+// @Router /api/v1/spawn [post]
+// @Security BearerAuth
+func (h *spawnHandler) Create(c *gin.Context) {
+	form := &types.CreateSpawnRequest{}
+	err := c.ShouldBindJSON(form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	spawn := &model.Spawn{}
+	err = copier.Copy(spawn, form)
+	if err != nil {
+		response.Error(c, ecode.ErrCreateSpawn)
+		return
+	}
+	// Note: if copier.Copy cannot assign a value to a field, add it here
+
+	ctx := middleware.WrapCtx(c)
+	if !h.checkRefs(c, ctx, spawn) {
+		return
+	}
+	err = h.iDao.Create(ctx, spawn)
+	if err != nil {
+		logger.Error("Create error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	response.Success(c, gin.H{"id": spawn.ID})
+}
+
+// DeleteByID delete a spawn by id
+// @Summary Delete a spawn by id
+// @Description Deletes a existing spawn identified by the given id in the path.
+// @Tags spawn
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} types.DeleteSpawnByIDReply{}
+// @Router /api/v1/spawn/{id} [delete]
+// @Security BearerAuth
+func (h *spawnHandler) DeleteByID(c *gin.Context) {
+	_, id, isAbort := getSpawnIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	err := h.iDao.DeleteByID(ctx, id)
+	if err != nil {
+		logger.Error("DeleteByID error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	response.Success(c)
+}
+
+// UpdateByID update a spawn by id
+// @Summary Update a spawn by id
+// @Description Updates the specified spawn by given id in the path, support partial update.
+// @Tags spawn
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param data body types.UpdateSpawnByIDRequest true "spawn information"
+// @Success 200 {object} types.UpdateSpawnByIDReply{}
+// @Router /api/v1/spawn/{id} [put]
+// @Security BearerAuth
+func (h *spawnHandler) UpdateByID(c *gin.Context) {
+	_, id, isAbort := getSpawnIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	form := &types.UpdateSpawnByIDRequest{}
+	err := c.ShouldBindJSON(form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	form.ID = id
+
+	spawn := &model.Spawn{}
+	err = copier.Copy(spawn, form)
+	if err != nil {
+		response.Error(c, ecode.ErrUpdateByIDSpawn)
+		return
+	}
+	// Note: if copier.Copy cannot assign a value to a field, add it here
+
+	ctx := middleware.WrapCtx(c)
+	if !h.checkRefs(c, ctx, spawn) {
+		return
+	}
+	err = h.iDao.UpdateByID(ctx, spawn)
+	if err != nil {
+		logger.Error("UpdateByID error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	response.Success(c)
+}
+
+// GetByID get a spawn by id
+// @Summary Get a spawn by id
+// @Description Gets detailed information of a spawn specified by the given id in the path.
+// @Tags spawn
+// @Param id path string true "id"
+// @Accept json
+// @Produce json
+// @Success 200 {object} types.GetSpawnByIDReply{}
+// @Router /api/v1/spawn/{id} [get]
+// @Security BearerAuth
+func (h *spawnHandler) GetByID(c *gin.Context) {
+	_, id, isAbort := getSpawnIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	spawn, err := h.iDao.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			logger.Warn("GetByID not found", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("GetByID error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return
+	}
+
+	data := &types.SpawnObjDetail{}
+	err = copier.Copy(data, spawn)
+	if err != nil {
+		response.Error(c, ecode.ErrGetByIDSpawn)
+		return
+	}
+	// Note: if copier.Copy cannot assign a value to a field, add it here
+
+	response.Success(c, gin.H{"spawn": data})
+}
+
+// List get a paginated list of spawns by custom conditions
+// @Summary Get a paginated list of spawns by custom conditions
+// @Description Returns a paginated list of spawn based on query filters, including page number and size.
+// @Tags spawn
+// @Accept json
+// @Produce json
+// @Param data body types.Params true "query parameters"
+// @Success 200 {object} types.ListSpawnsReply{}
+// @Router /api/v1/spawn/list [post]
+// @Security BearerAuth
+func (h *spawnHandler) List(c *gin.Context) {
+	form := &types.ListSpawnsRequest{}
+	err := c.ShouldBindJSON(form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	spawns, total, err := h.iDao.GetByColumns(ctx, &form.Params)
+	if err != nil {
+		logger.Error("GetByColumns error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	data, err := convertSpawns(spawns)
+	if err != nil {
+		response.Error(c, ecode.ErrListSpawn)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"spawns": data,
+		"total":  total,
+	})
+}
+
+// ListByIDs get a list of spawns by ids
+// @Summary Get a list of spawns by ids
+// @Description Returns the spawns with the given ids in the order of the ids, ids that do not exist are left out.
+// @Tags spawn
+// @Accept json
+// @Produce json
+// @Param data body types.ListSpawnsByIDsRequest true "id list"
+// @Success 200 {object} types.ListSpawnsByIDsReply{}
+// @Router /api/v1/spawn/list/ids [post]
+// @Security BearerAuth
+func (h *spawnHandler) ListByIDs(c *gin.Context) {
+	form := &types.ListSpawnsByIDsRequest{}
+	err := c.ShouldBindJSON(form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	spawnMap, err := h.iDao.GetByIDs(ctx, form.IDs)
+	if err != nil {
+		logger.Error("GetByIDs error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	spawns := []*types.SpawnObjDetail{}
+	for _, id := range form.IDs {
+		if v, ok := spawnMap[id]; ok {
+			record, err := convertSpawn(v)
+			if err != nil {
+				response.Error(c, ecode.ErrListByIDsSpawn)
+				return
+			}
+			spawns = append(spawns, record)
+		}
+	}
+
+	response.Success(c, gin.H{
+		"spawns": spawns,
+	})
+}
+
+// checkRefs check that the room and the mob prototype set in the spawn exist,
+// an error response has been written when false is returned
+func (h *spawnHandler) checkRefs(c *gin.Context, ctx context.Context, spawn *model.Spawn) bool { //nolint
+	var err error
+	if spawn.RoomID != "" {
+		if _, err = h.roomDao.GetByID(ctx, spawn.RoomID); errors.Is(err, database.ErrRecordNotFound) {
+			response.Error(c, ecode.ErrSpawnRoom)
+			return false
+		}
+	}
+	if err == nil && spawn.MobID != 0 {
+		if _, err = h.mobDao.GetByID(ctx, spawn.MobID); errors.Is(err, database.ErrRecordNotFound) {
+			response.Error(c, ecode.ErrSpawnMob)
+			return false
+		}
+	}
+	if err != nil {
+		logger.Error("GetByID error", logger.Err(err), logger.Any("spawn", spawn), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return false
+	}
+	return true
+}
+
+func getSpawnIDFromPath(c *gin.Context) (string, uint64, bool) {
+	idStr := c.Param("id")
+	id, err := utils.StrToUint64E(idStr)
+	if err != nil || id == 0 {
+		logger.Warn("StrToUint64E error: ", logger.String("idStr", idStr), middleware.GCtxRequestIDField(c))
+		return "", 0, true
+	}
+
+	return idStr, id, false
+}
+
+func convertSpawn(spawn *model.Spawn) (*types.SpawnObjDetail, error) {
+	data := &types.SpawnObjDetail{}
+	err := copier.Copy(data, spawn)
+	if err != nil {
+		return nil, err
+	}
+	// Note: if copier.Copy cannot assign a value to a field, add it here
+
+	return data, nil
+}
+
+func convertSpawns(fromValues []*model.Spawn) ([]*types.SpawnObjDetail, error) {
+	toValues := []*types.SpawnObjDetail{}
+	for _, v := range fromValues {
+		data, err := convertSpawn(v)
+		if err != nil {
+			return nil, err
+		}
+		toValues = append(toValues, data)
+	}
+
+	return toValues, nil
+}
@@ -0,0 +1,308 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-dev-frame/sponge/pkg/copier"
+	"github.com/go-dev-frame/sponge/pkg/gotest"
+	"github.com/go-dev-frame/sponge/pkg/httpcli"
+	"github.com/go-dev-frame/sponge/pkg/sgorm/query"
+	"github.com/go-dev-frame/sponge/pkg/utils"
+
+	"fs/internal/cache"
+	"fs/internal/dao"
+	"fs/internal/database"
+	"fs/internal/ecode"
+	"fs/internal/model"
+	"fs/internal/types"
+)
+
+func newSpawnHandler() *gotest.Handler {
+	testData := &model.Spawn{}
+	testData.ID = 1
+	testData.RoomID = "hall"
+	testData.MobID = 1
+	testData.MaxCount = 2
+	testData.RespawnSeconds = 60
+	testData.Chance = 50
+
+	// init mock cache
+	c := gotest.NewCache(map[string]interface{}{utils.Uint64ToStr(testData.ID): testData})
+	c.ICache = cache.NewSpawnCache(&database.CacheType{
+		CType: "redis",
+		Rdb:   c.RedisClient,
+	})
+
+	// init mock dao
+	d := gotest.NewDao(c, testData)
+	d.IDao = dao.NewSpawnDao(d.DB, c.ICache.(cache.SpawnCache))
+
+	// init mock handler
+	h := gotest.NewHandler(d, testData)
+	h.IHandler = &spawnHandler{
+		iDao:    d.IDao.(dao.SpawnDao),
+		roomDao: dao.NewRoomDao(d.DB, nil),
+		mobDao:  dao.NewMobDao(d.DB, nil),
+	}
+	iHandler := h.IHandler.(SpawnHandler)
+
+	testFns := []gotest.RouterInfo{
+		{
+			FuncName:    "Create",
+			Method:      http.MethodPost,
+			Path:        "/spawn",
+			HandlerFunc: iHandler.Create,
+		},
+		{
+			FuncName:    "DeleteByID",
+			Method:      http.MethodDelete,
+			Path:        "/spawn/:id",
+			HandlerFunc: iHandler.DeleteByID,
+		},
+		{
+			FuncName:    "UpdateByID",
+			Method:      http.MethodPut,
+			Path:        "/spawn/:id",
+			HandlerFunc: iHandler.UpdateByID,
+		},
+		{
+			FuncName:    "GetByID",
+			Method:      http.MethodGet,
+			Path:        "/spawn/:id",
+			HandlerFunc: iHandler.GetByID,
+		},
+		{
+			FuncName:    "List",
+			Method:      http.MethodPost,
+			Path:        "/spawn/list",
+			HandlerFunc: iHandler.List,
+		},
+		{
+			FuncName:    "ListByIDs",
+			Method:      http.MethodPost,
+			Path:        "/spawn/list/ids",
+			HandlerFunc: iHandler.ListByIDs,
+		},
+	}
+
+	h.GoRunHTTPServer(testFns)
+
+	time.Sleep(time.Millisecond * 200)
+	return h
+}
+
+func Test_spawnHandler_Create(t *testing.T) {
+	h := newSpawnHandler()
+	defer h.Close()
+	testData := &types.CreateSpawnRequest{}
+	_ = copier.Copy(testData, h.TestData.(*model.Spawn))
+
+	expectRefs(h, testData.RoomID, testData.MobID)
+	h.MockDao.SQLMock.ExpectBegin()
+	h.MockDao.SQLMock.ExpectExec("INSERT INTO .*").
+		WithArgs(testData.RoomID, testData.MobID, testData.MaxCount, testData.RespawnSeconds, testData.Chance).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	h.MockDao.SQLMock.ExpectCommit()
+
+	result := &httpcli.StdResult{}
+	err := httpcli.Post(result, h.GetRequestURL("Create"), testData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Code != 0 {
+		t.Fatalf("%+v", result)
+	}
+
+	// unknown room
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs("void", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	testData.RoomID = "void"
+	err = httpcli.Post(result, h.GetRequestURL("Create"), testData)
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.ErrSpawnRoom.Code(), result.Code)
+
+	// missing mob
+	err = httpcli.Post(result, h.GetRequestURL("Create"), &types.CreateSpawnRequest{RoomID: "hall"})
+	assert.NoError(t, err)
+	assert.NotEqual(t, 0, result.Code)
+}
+
+// expectRefs expect the room and mob lookups of checkRefs
+func expectRefs(h *gotest.Handler, roomID string, mobID uint64) {
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(roomID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(roomID))
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(mobID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(mobID))
+}
+
+func Test_spawnHandler_DeleteByID(t *testing.T) {
+	h := newSpawnHandler()
+	defer h.Close()
+	testData := h.TestData.(*model.Spawn)
+	expectedSQLForDeletion := "DELETE .*"
+
+	h.MockDao.SQLMock.ExpectBegin()
+	h.MockDao.SQLMock.ExpectExec(expectedSQLForDeletion).
+		WithArgs(testData.ID). // adjusted for the amount of test data
+		WillReturnResult(sqlmock.NewResult(int64(testData.ID), 1))
+	h.MockDao.SQLMock.ExpectCommit()
+
+	result := &httpcli.StdResult{}
+	err := httpcli.Delete(result, h.GetRequestURL("DeleteByID", testData.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Code != 0 {
+		t.Fatalf("%+v", result)
+	}
+
+	// zero id error test
+	err = httpcli.Delete(result, h.GetRequestURL("DeleteByID", 0))
+	assert.NoError(t, err)
+
+	// delete error test
+	err = httpcli.Delete(result, h.GetRequestURL("DeleteByID", 111))
+	assert.Error(t, err)
+}
+
+func Test_spawnHandler_UpdateByID(t *testing.T) {
+	h := newSpawnHandler()
+	defer h.Close()
+	testData := &types.UpdateSpawnByIDRequest{}
+	_ = copier.Copy(testData, h.TestData.(*model.Spawn))
+
+	expectRefs(h, testData.RoomID, testData.MobID)
+	h.MockDao.SQLMock.ExpectBegin()
+	h.MockDao.SQLMock.ExpectExec("UPDATE .*").
+		WithArgs(testData.Chance, testData.MaxCount, testData.MobID, testData.RespawnSeconds, testData.RoomID, testData.ID).
+		WillReturnResult(sqlmock.NewResult(int64(testData.ID), 1))
+	h.MockDao.SQLMock.ExpectCommit()
+
+	result := &httpcli.StdResult{}
+	err := httpcli.Put(result, h.GetRequestURL("UpdateByID", testData.ID), testData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Code != 0 {
+		t.Fatalf("%+v", result)
+	}
+
+	// zero id error test
+	err = httpcli.Put(result, h.GetRequestURL("UpdateByID", 0), testData)
+	assert.NoError(t, err)
+
+	// update error test
+	err = httpcli.Put(result, h.GetRequestURL("UpdateByID", 111), testData)
+	assert.Error(t, err)
+}
+
+func Test_spawnHandler_GetByID(t *testing.T) {
+	h := newSpawnHandler()
+	defer h.Close()
+	testData := h.TestData.(*model.Spawn)
+
+	// column names and corresponding data
+	rows := sqlmock.NewRows([]string{"id"}).
+		AddRow(testData.ID)
+
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(testData.ID, 1).
+		WillReturnRows(rows)
+
+	result := &httpcli.StdResult{}
+	err := httpcli.Get(result, h.GetRequestURL("GetByID", testData.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Code != 0 {
+		t.Fatalf("%+v", result)
+	}
+
+	// zero id error test
+	err = httpcli.Get(result, h.GetRequestURL("GetByID", 0))
+	assert.NoError(t, err)
+
+	// get error test
+	err = httpcli.Get(result, h.GetRequestURL("GetByID", 111))
+	assert.Error(t, err)
+}
+
+func Test_spawnHandler_List(t *testing.T) {
+	h := newSpawnHandler()
+	defer h.Close()
+	testData := h.TestData.(*model.Spawn)
+
+	// column names and corresponding data
+	rows := sqlmock.NewRows([]string{"id"}).
+		AddRow(testData.ID)
+
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").WillReturnRows(rows)
+
+	result := &httpcli.StdResult{}
+	err := httpcli.Post(result, h.GetRequestURL("List"), &types.ListSpawnsRequest{Params: query.Params{
+		Page:  0,
+		Limit: 10,
+		Sort:  "ignore count", // ignore test count
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Code != 0 {
+		t.Fatalf("%+v", result)
+	}
+
+	// nil params error test
+	err = httpcli.Post(result, h.GetRequestURL("List"), nil)
+	assert.NoError(t, err)
+
+	// get error test
+	err = httpcli.Post(result, h.GetRequestURL("List"), &types.ListSpawnsRequest{Params: query.Params{
+		Page:  0,
+		Limit: 10,
+		Sort:  "unknown-column",
+	}})
+	assert.Error(t, err)
+}
+
+func Test_spawnHandler_ListByIDs(t *testing.T) {
+	h := newSpawnHandler()
+	defer h.Close()
+	testData := h.TestData.(*model.Spawn)
+
+	// column names and corresponding data
+	rows := sqlmock.NewRows([]string{"id"}).
+		AddRow(testData.ID)
+
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").WillReturnRows(rows)
+
+	result := &httpcli.StdResult{}
+	err := httpcli.Post(result, h.GetRequestURL("ListByIDs"), &types.ListSpawnsByIDsRequest{IDs: []uint64{2, testData.ID}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Code != 0 {
+		t.Fatalf("%+v", result)
+	}
+	list := result.Data.(map[string]interface{})["spawns"].([]interface{})
+	assert.Len(t, list, 1)
+
+	// nil params error test
+	err = httpcli.Post(result, h.GetRequestURL("ListByIDs"), nil)
+	assert.NoError(t, err)
+	assert.NotEqual(t, 0, result.Code)
+}
+
+func TestNewSpawnHandler(t *testing.T) {
+	defer func() {
+		recover()
+	}()
+	_ = NewSpawnHandler()
+}
@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/go-dev-frame/sponge/pkg/gin/middleware"
+	"github.com/go-dev-frame/sponge/pkg/gin/response"
+	"github.com/go-dev-frame/sponge/pkg/logger"
+	"github.com/go-dev-frame/sponge/pkg/utils"
+
+	"fs/internal/cache"
+	"fs/internal/config"
+	"fs/internal/dao"
+	"fs/internal/database"
+	"fs/internal/ecode"
+	"fs/internal/revision"
+	"fs/internal/types"
+)
+
+var _ TrashHandler = (*trashHandler)(nil)
+
+// TrashHandler defining the handler interface
+type TrashHandler interface {
+	List(c *gin.Context)
+	Purge(c *gin.Context)
+}
+
+type trashHandler struct {
+	roomDao dao.RoomDao
+	mobDao  dao.MobDao
+	itemDao dao.ItemDao
+	days    int // days a record stays in the trash
+}
+
+// NewTrashHandler creating the handler interface
+func NewTrashHandler() TrashHandler {
+	return &trashHandler{
+		roomDao: dao.NewRoomDao(
+			database.GetDB(),
+			cache.NewRoomCache(database.GetCacheType()),
+		),
+		mobDao: dao.NewMobDao(
+			database.GetDB(),
+			cache.NewMobCache(database.GetCacheType()),
+		),
+		itemDao: dao.NewItemDao(
+			database.GetDB(),
+			cache.NewItemCache(database.GetCacheType()),
+		),
+		days: config.Get().Database.TrashDays,
+	}
+}
+
+// List get a paginated list of the rooms, mobs or items in the trash
+// @Summary Get a paginated list of the rooms, mobs or items in the trash
+// @Description Returns the deleted records of the entity type in the path, the latest deleted first, with when
+// @Description they can be purged. A record is taken out of the trash with /api/v1/{entity}/{id}/restore.
+// @Tags trash
+// @Param entity path string true "room, mob or item"
+// @Param page query int false "page number, starting from 0"
+// @Param limit query int false "records per page, default is 20"
+// @Accept json
+// @Produce json
+// @Success 200 {object} types.ListTrashReply{}
+// @Router /api/v1/trash/{entity} [get]
+// @Security BearerAuth
+func (h *trashHandler) List(c *gin.Context) {
+	entity := c.Param("entity")
+	page, err1 := utils.StrToIntE(c.DefaultQuery("page", "0"))
+	limit, err2 := utils.StrToIntE(c.DefaultQuery("limit", "20"))
+	if err1 != nil || err2 != nil || page < 0 || limit < 1 {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	var records []*types.TrashObjDetail
+	var total int64
+	var err error
+	switch entity {
+	case revision.EntityRoom:
+		rooms, n, e := h.roomDao.GetDeleted(ctx, page, limit)
+		for _, v := range rooms {
+			records = append(records, h.convertTrash(v.ID, v.Title, v.DeletedAt.Time))
+		}
+		total, err = n, e
+	case revision.EntityMob:
+		mobs, n, e := h.mobDao.GetDeleted(ctx, page, limit)
+		for _, v := range mobs {
+			records = append(records, h.convertTrash(utils.Uint64ToStr(v.ID), v.MobName, v.DeletedAt.Time))
+		}
+		total, err = n, e
+	case revision.EntityItem:
+		items, n, e := h.itemDao.GetDeleted(ctx, page, limit)
+		for _, v := range items {
+			records = append(records, h.convertTrash(utils.Uint64ToStr(v.ID), v.ItemName, v.DeletedAt.Time))
+		}
+		total, err = n, e
+	default:
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	if err != nil {
+		logger.Error("GetDeleted error", logger.Err(err), logger.String("entity", entity), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+	if records == nil {
+		records = []*types.TrashObjDetail{}
+	}
+
+	response.Success(c, gin.H{
+		"records": records,
+		"total":   total,
+	})
+}
+
+// Purge remove for good the rooms, mobs and items that have been in the trash for some days
+// @Summary Remove for good the rooms, mobs and items that have been in the trash for some days
+// @Description Purges the records deleted more than the given days ago, by default database.trashDays in
+// @Description the config, 0 empties the trash, the exits of a purged room go with it. Purged records can still be
+// @Description written back from their revisions.
+// @Tags trash
+// @Param days query int false "days in the trash"
+// @Accept json
+// @Produce json
+// @Success 200 {object} types.PurgeTrashReply{}
+// @Router /api/v1/trash [delete]
+// @Security BearerAuth
+func (h *trashHandler) Purge(c *gin.Context) {
+	days, err := utils.StrToIntE(c.DefaultQuery("days", utils.IntToStr(h.days)))
+	if err != nil || days < 0 {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	before := time.Now().AddDate(0, 0, -days)
+	counts := types.PurgeTrashCounts{}
+	if counts.Rooms, err = h.roomDao.PurgeDeleted(ctx, before); err == nil {
+		if counts.Mobs, err = h.mobDao.PurgeDeleted(ctx, before); err == nil {
+			counts.Items, err = h.itemDao.PurgeDeleted(ctx, before)
+		}
+	}
+	if err != nil {
+		logger.Error("PurgeDeleted error", logger.Err(err), logger.Int("days", days), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	response.Success(c, counts)
+}
+
+func (h *trashHandler) convertTrash(id string, name string, deletedAt time.Time) *types.TrashObjDetail {
+	return &types.TrashObjDetail{
+		ID:        id,
+		Name:      name,
+		DeletedAt: deletedAt,
+		PurgeAt:   deletedAt.AddDate(0, 0, h.days),
+	}
+}
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-dev-frame/sponge/pkg/gotest"
+	"github.com/go-dev-frame/sponge/pkg/httpcli"
+
+	"fs/internal/dao"
+	"fs/internal/ecode"
+	"fs/internal/model"
+)
+
+func newTrashHandler() *gotest.Handler {
+	testData := &model.Room{ID: "yard", Title: "Yard"}
+
+	// init mock dao, the trash is read without cache
+	d := gotest.NewDao(nil, testData)
+	d.IDao = dao.NewRoomDao(d.DB, nil)
+
+	// init mock handler
+	h := gotest.NewHandler(d, testData)
+	h.IHandler = &trashHandler{
+		roomDao: d.IDao.(dao.RoomDao),
+		mobDao:  dao.NewMobDao(d.DB, nil),
+		itemDao: dao.NewItemDao(d.DB, nil),
+		days:    30,
+	}
+	iHandler := h.IHandler.(TrashHandler)
+
+	testFns := []gotest.RouterInfo{
+		{
+			FuncName:    "List",
+			Method:      http.MethodGet,
+			Path:        "/trash/:entity",
+			HandlerFunc: iHandler.List,
+		},
+		{
+			FuncName:    "Purge",
+			Method:      http.MethodDelete,
+			Path:        "/trash",
+			HandlerFunc: iHandler.Purge,
+		},
+	}
+
+	h.GoRunHTTPServer(testFns)
+
+	time.Sleep(time.Millisecond * 200)
+	return h
+}
+
+func Test_trashHandler_List(t *testing.T) {
+	h := newTrashHandler()
+	defer h.Close()
+	testData := h.TestData.(*model.Room)
+	deletedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	h.MockDao.SQLMock.ExpectQuery("SELECT count(.*) FROM `room` WHERE deleted_at IS NOT NULL").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	h.MockDao.SQLMock.ExpectQuery("SELECT \\* FROM `room` WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC").
+		WithArgs(20).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "deleted_at"}).AddRow(testData.ID, testData.Title, deletedAt))
+
+	result := &httpcli.StdResult{}
+	err := httpcli.Get(result, h.GetRequestURL("List", "room"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Code != 0 {
+		t.Fatalf("%+v", result)
+	}
+	data := result.Data.(map[string]interface{})
+	assert.Equal(t, float64(1), data["total"])
+	records := data["records"].([]interface{})
+	assert.Len(t, records, 1)
+	record := records[0].(map[string]interface{})
+	assert.Equal(t, "yard", record["id"])
+	assert.Equal(t, "Yard", record["name"])
+	assert.Equal(t, "2024-05-31T12:00:00Z", record["purgeAt"])
+
+	// empty trash
+	h.MockDao.SQLMock.ExpectQuery("SELECT count(.*) FROM `mob` WHERE deleted_at IS NOT NULL").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	err = httpcli.Get(result, h.GetRequestURL("List", "mob"))
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{}, result.Data.(map[string]interface{})["records"])
+
+	// unknown entity
+	err = httpcli.Get(result, h.GetRequestURL("List", "spawn"))
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.InvalidParams.Code(), result.Code)
+}
+
+func Test_trashHandler_Purge(t *testing.T) {
+	h := newTrashHandler()
+	defer h.Close()
+
+	for i, table := range []string{"room", "mob", "item"} {
+		h.MockDao.SQLMock.ExpectBegin()
+		if table == "room" {
+			// the exits of the purged rooms go in the same transaction
+			h.MockDao.SQLMock.ExpectExec("DELETE FROM `room_exit` WHERE room_id IN \\(SELECT `id` FROM `room` WHERE deleted_at < \\?\\)").
+				WithArgs(h.MockDao.AnyTime).
+				WillReturnResult(sqlmock.NewResult(0, 2))
+		}
+		h.MockDao.SQLMock.ExpectExec("DELETE FROM `" + table + "` WHERE deleted_at < \\?").
+			WithArgs(h.MockDao.AnyTime).
+			WillReturnResult(sqlmock.NewResult(0, int64(i)))
+		h.MockDao.SQLMock.ExpectCommit()
+	}
+
+	result := &httpcli.StdResult{}
+	err := httpcli.Delete(result, h.GetRequestURL("Purge"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Code != 0 {
+		t.Fatalf("%+v", result)
+	}
+	assert.Equal(t, map[string]interface{}{"rooms": float64(0), "mobs": float64(1), "items": float64(2)}, result.Data)
+	assert.NoError(t, h.MockDao.SQLMock.ExpectationsWereMet())
+
+	err = httpcli.Delete(result, h.GetRequestURL("Purge"), httpcli.WithParams(map[string]interface{}{"days": -1}))
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.InvalidParams.Code(), result.Code)
+}
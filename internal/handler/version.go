@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/go-dev-frame/sponge/pkg/gin/response"
+
+	"fs/internal/ecode"
+)
+
+// setETag set the version of a room, mob or item as the ETag header, it is sent back in If-Match to update the record
+func setETag(c *gin.Context, version int) {
+	c.Header("ETag", `"`+strconv.Itoa(version)+`"`)
+}
+
+// getVersion the version an update is based on, from the If-Match header or else from the version field
+// of the body, an error response has been written when isAbort is true
+func getVersion(c *gin.Context, bodyVersion int) (version int, isAbort bool) {
+	ifMatch := strings.TrimSpace(c.GetHeader("If-Match"))
+	if ifMatch == "" {
+		if bodyVersion < 1 {
+			response.Error(c, ecode.InvalidParams.WithDetails("version is required, send the If-Match header or the version field"))
+			return 0, true
+		}
+		return bodyVersion, false
+	}
+
+	// the ETag of GetByID, weak or not, a list of tags or * is not supported
+	version, err := strconv.Atoi(strings.Trim(strings.TrimPrefix(ifMatch, "W/"), `"`))
+	if err != nil || version < 1 {
+		response.Error(c, ecode.InvalidParams.WithDetails("invalid If-Match header "+ifMatch))
+		return 0, true
+	}
+	return version, false
+}
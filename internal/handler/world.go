@@ -0,0 +1,469 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/go-dev-frame/sponge/pkg/gin/middleware"
+	"github.com/go-dev-frame/sponge/pkg/gin/response"
+	"github.com/go-dev-frame/sponge/pkg/logger"
+
+	"fs/internal/bundle"
+	"fs/internal/cache"
+	"fs/internal/config"
+	"fs/internal/dao"
+	"fs/internal/database"
+	"fs/internal/ecode"
+	"fs/internal/model"
+	"fs/internal/rule"
+	"fs/internal/types"
+	"fs/internal/world"
+)
+
+var _ WorldHandler = (*worldHandler)(nil)
+
+// WorldHandler defining the handler interface
+type WorldHandler interface {
+	Validate(c *gin.Context)
+	Path(c *gin.Context)
+	Map(c *gin.Context)
+	Export(c *gin.Context)
+	Import(c *gin.Context)
+}
+
+type worldHandler struct {
+	roomDao dao.RoomDao
+	exitDao dao.RoomExitDao
+	mobDao  dao.MobDao
+	itemDao dao.ItemDao
+	db      *gorm.DB // the transaction of an import is started on it
+
+	mobRules  *rule.Rules // checked by an import, the unique ids are left to the unique indexes
+	itemRules *rule.Rules
+
+	graph *world.GraphCache // dropped whenever a room or exit is written through the dao, and after graphTTL
+}
+
+// graphTTL how long the room graph is used before it is read again, the dao hooks only see the writes
+// of this process, not the ones of fs world import, fs trash purge or the other replicas
+const graphTTL = time.Minute
+
+// NewWorldHandler creating the handler interface
+func NewWorldHandler() WorldHandler {
+	h := &worldHandler{
+		roomDao: dao.NewRoomDao(
+			database.GetDB(), // db driver is mysql
+			cache.NewRoomCache(database.GetCacheType()),
+		),
+		exitDao: dao.NewRoomExitDao(
+			database.GetDB(),
+			cache.NewRoomExitCache(database.GetCacheType()),
+		),
+		mobDao: dao.NewMobDao(
+			database.GetDB(),
+			cache.NewMobCache(database.GetCacheType()),
+		),
+		itemDao: dao.NewItemDao(
+			database.GetDB(),
+			cache.NewItemCache(database.GetCacheType()),
+		),
+		db: database.GetDB(),
+	}
+	var err error
+	if h.mobRules, err = rule.NewMob(config.Get().Rules.Mob, nil); err != nil {
+		panic("init mob rules error: " + err.Error())
+	}
+	if h.itemRules, err = rule.NewItem(config.Get().Rules.Item, nil); err != nil {
+		panic("init item rules error: " + err.Error())
+	}
+	h.graph = world.NewGraphCache(func(ctx context.Context) (*world.Graph, error) {
+		_, g, err := h.loadGraph(ctx)
+		return g, err
+	}, graphTTL)
+	watchRoomChanges(h.graph)
+
+	return h
+}
+
+var (
+	graphHookMu sync.Mutex
+	unhookGraph func()
+)
+
+// watchRoomChanges drop the graph on every room change, the hook of the graph of a previous
+// handler is removed, the routers build one world handler
+func watchRoomChanges(graph *world.GraphCache) {
+	graphHookMu.Lock()
+	defer graphHookMu.Unlock()
+	if unhookGraph != nil {
+		unhookGraph()
+	}
+	unhookGraph = dao.OnRoomChange(func(string) {
+		graph.Invalidate()
+	})
+}
+
+// Validate check the rooms, exits and mobs of the world
+// @Summary Check the rooms, exits and mobs of the world
+// @Description Reports exits leading to rooms that do not exist, exits without a way back, rooms that cannot be
+// @Description reached from the start room and mob ids in Room.Mobs that do not exist.
+// @Tags world
+// @Param start query string false "start room, default is game.startRoom in the config"
+// @Accept json
+// @Produce json
+// @Success 200 {object} types.ValidateWorldReply{}
+// @Router /api/v1/world/validate [get]
+// @Security BearerAuth
+func (h *worldHandler) Validate(c *gin.Context) {
+	startRoom := c.Query("start")
+	if startRoom == "" {
+		startRoom = config.Get().Game.StartRoom
+	}
+
+	ctx := middleware.WrapCtx(c)
+	rooms, g, err := h.loadGraph(ctx)
+	if err != nil {
+		logger.Error("loadGraph error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	report := convertWorldReport(world.Validate(g, startRoom), g)
+	report.UnknownMobs, err = h.unknownMobs(ctx, rooms)
+	if err != nil {
+		logger.Error("unknownMobs error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrValidateWorld)
+		return
+	}
+	report.Valid = report.StartRoomFound && len(report.DanglingExits) == 0 && len(report.OneWayExits) == 0 &&
+		len(report.UnreachableRooms) == 0 && len(report.UnknownMobs) == 0
+
+	response.Success(c, report)
+}
+
+// Path find the shortest path between two rooms
+// @Summary Find the shortest path between two rooms
+// @Description Finds the exits to take from the room in the path to another room, by number of steps or by exit cost.
+// @Description Locked exits, exits with doors, hidden exits and given rooms can be avoided.
+// @Tags world
+// @Param id path string true "room id to start from"
+// @Param to query string true "room id to go to"
+// @Param weighted query bool false "use the exit costs instead of the number of steps"
+// @Param avoid query string false "comma separated exit kinds to avoid: locked, doors, hidden"
+// @Param avoidRooms query string false "comma separated room ids to avoid"
+// @Accept json
+// @Produce json
+// @Success 200 {object} types.GetRoomPathReply{}
+// @Router /api/v1/room/{id}/path [get]
+// @Security BearerAuth
+func (h *worldHandler) Path(c *gin.Context) {
+	from, isAbort := getRoomIDFromPath(c)
+	to := c.Query("to")
+	if isAbort || to == "" {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	opts, err := parsePathOptions(c.Query("weighted"), c.Query("avoid"), c.Query("avoidRooms"))
+	if err != nil {
+		logger.Warn("parsePathOptions error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	g, err := h.graph.Get(ctx)
+	if err != nil {
+		logger.Error("graph.Get error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+	if !g.HasRoom(from) || !g.HasRoom(to) {
+		response.Error(c, ecode.NotFound)
+		return
+	}
+
+	path, ok := world.FindPath(g, from, to, opts)
+	if !ok {
+		response.Error(c, ecode.ErrNoRoomPath)
+		return
+	}
+
+	response.Success(c, convertRoomPath(from, to, path))
+}
+
+// Map draw the rooms of the world
+// @Summary Draw the rooms of the world
+// @Description Lays the rooms out on a grid following the exit directions and returns the map as ASCII text,
+// @Description SVG or Graphviz DOT. With a centre room only the rooms reachable from it are drawn.
+// @Tags world
+// @Param format query string false "ascii (default), svg or dot"
+// @Param center query string false "room id at the centre of the map"
+// @Param radius query int false "most exits away from the centre room, 0 means no limit"
+// @Produce plain
+// @Produce image/svg+xml
+// @Success 200 {string} string "the map"
+// @Router /api/v1/world/map [get]
+// @Security BearerAuth
+func (h *worldHandler) Map(c *gin.Context) {
+	format := c.DefaultQuery("format", world.FormatASCII)
+	contentType, ok := mapContentTypes[format]
+	if !ok {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	radius, err := strconv.Atoi(c.DefaultQuery("radius", "0"))
+	if err != nil || radius < 0 {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	center := c.Query("center")
+
+	ctx := middleware.WrapCtx(c)
+	g, err := h.graph.Get(ctx)
+	if err != nil {
+		logger.Error("graph.Get error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+	if center != "" && !g.HasRoom(center) {
+		response.Error(c, ecode.NotFound)
+		return
+	}
+
+	out, _ := world.Render(world.NewLayout(g, center, radius), format)
+	c.Data(http.StatusOK, contentType, []byte(out))
+}
+
+var mapContentTypes = map[string]string{
+	world.FormatASCII: "text/plain; charset=utf-8",
+	world.FormatSVG:   "image/svg+xml",
+	world.FormatDOT:   "text/vnd.graphviz; charset=utf-8",
+}
+
+// Export download the whole world as a bundle
+// @Summary Download the whole world as a bundle
+// @Description Writes the rooms, room exits, mobs and items to a versioned bundle that can be imported
+// @Description into another database with /api/v1/world/import or the fs world import command.
+// @Tags world
+// @Param format query string false "json (default) or yaml"
+// @Produce json
+// @Produce application/yaml
+// @Success 200 {object} bundle.Bundle{}
+// @Router /api/v1/world/export [get]
+// @Security BearerAuth
+func (h *worldHandler) Export(c *gin.Context) {
+	format, ok := bundle.ParseFormat(c.DefaultQuery("format", string(bundle.FormatJSON)))
+	if !ok {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	b, err := bundle.Export(ctx, h.daos())
+	if err != nil {
+		logger.Error("bundle.Export error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrExportWorld)
+		return
+	}
+
+	buf := &bytes.Buffer{}
+	if err = bundle.Encode(buf, b, format); err != nil {
+		logger.Error("bundle.Encode error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrExportWorld)
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="world.`+string(format)+`"`)
+	c.Data(http.StatusOK, bundleContentTypes[format], buf.Bytes())
+}
+
+// Import load a bundle into the world
+// @Summary Load a bundle into the world
+// @Description Writes the rooms, room exits, mobs and items of a bundle from /api/v1/world/export in one transaction.
+// @Description Rooms, mobs and items keep their ids, room exits are matched by room and direction. A record that
+// @Description already exists, in the trash or not, is skipped, overwritten with every field of the bundle or fails
+// @Description the whole import, an overwritten record in the trash is restored. A dry run reports what would be
+// @Description written and rolls it back. The mobs and items written are checked against the validation rules of fs.yml,
+// @Description the fields of the first one breaking them are returned in data.errors with the code of ErrRulesMob or ErrRulesItem.
+// @Tags world
+// @Param format query string false "json or yaml, by default from the Content-Type"
+// @Param conflict query string false "skip, overwrite or fail (default)"
+// @Param dryRun query bool false "report what would be written without writing it"
+// @Param data body bundle.Bundle true "bundle"
+// @Accept json
+// @Accept application/yaml
+// @Produce json
+// @Success 200 {object} types.ImportWorldReply{}
+// @Router /api/v1/world/import [post]
+// @Security BearerAuth
+func (h *worldHandler) Import(c *gin.Context) {
+	format, ok := bundleFormat(c.Query("format"), c.ContentType())
+	conflict, ok2 := bundle.ParseConflict(c.Query("conflict"))
+	if !ok || !ok2 {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	b, err := bundle.Decode(c.Request.Body, format)
+	if err != nil {
+		logger.Warn("bundle.Decode error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrWorldBundle.WithDetails(err.Error()))
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	report, err := bundle.Import(ctx, h.db, h.daos(), b, bundle.Options{
+		DryRun:    c.Query("dryRun") == "true",
+		Conflict:  conflict,
+		MobRules:  h.mobRules,
+		ItemRules: h.itemRules,
+	})
+	if err != nil {
+		if errors.Is(err, bundle.ErrConflict) {
+			response.Error(c, ecode.ErrWorldConflict.WithDetails(err.Error()))
+			return
+		}
+		var rulesErr *bundle.RulesError
+		if errors.As(err, &rulesErr) {
+			e := ecode.ErrRulesItem
+			if rulesErr.Table == "mob" {
+				e = ecode.ErrRulesMob
+			}
+			logger.Warn("rules violated", logger.Err(err), middleware.GCtxRequestIDField(c))
+			response.Error(c, e.WithDetails(rulesErr.Table+" "+rulesErr.Key), gin.H{"errors": rulesErr.Violations})
+			return
+		}
+		logger.Error("bundle.Import error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrImportWorld)
+		return
+	}
+
+	response.Success(c, report)
+}
+
+var bundleContentTypes = map[bundle.Format]string{
+	bundle.FormatJSON: "application/json; charset=utf-8",
+	bundle.FormatYAML: "application/yaml; charset=utf-8",
+}
+
+// bundleFormat the format given in the query, or else the one of the content type
+func bundleFormat(query string, contentType string) (bundle.Format, bool) {
+	if query != "" {
+		return bundle.ParseFormat(query)
+	}
+	if strings.Contains(contentType, "yaml") {
+		return bundle.FormatYAML, true
+	}
+	return bundle.FormatJSON, true
+}
+
+func (h *worldHandler) daos() bundle.Daos {
+	return bundle.Daos{Rooms: h.roomDao, RoomExits: h.exitDao, Mobs: h.mobDao, Items: h.itemDao}
+}
+
+// loadGraph read all rooms and exits
+func (h *worldHandler) loadGraph(ctx context.Context) ([]*model.Room, *world.Graph, error) {
+	rooms, err := dao.GetAllRooms(ctx, h.roomDao)
+	if err != nil {
+		return nil, nil, err
+	}
+	exits, err := dao.GetAllRoomExits(ctx, h.exitDao)
+	if err != nil {
+		return nil, nil, err
+	}
+	return rooms, world.NewGraph(rooms, exits), nil
+}
+
+// unknownMobs find the mob ids in Room.Mobs that are not in the mob table, with one batch read
+func (h *worldHandler) unknownMobs(ctx context.Context, rooms []*model.Room) ([]types.WorldMobIssue, error) {
+	issues := []types.WorldMobIssue{}
+	var ids []uint64
+	for _, room := range rooms {
+		ids = append(ids, world.ParseMobIDs(room.Mobs)...)
+	}
+	if len(ids) == 0 {
+		return issues, nil
+	}
+
+	mobs, err := h.mobDao.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, room := range rooms {
+		for _, id := range world.ParseMobIDs(room.Mobs) {
+			if _, ok := mobs[id]; !ok {
+				issues = append(issues, types.WorldMobIssue{RoomID: room.ID, MobID: id})
+			}
+		}
+	}
+	return issues, nil
+}
+
+func parsePathOptions(weighted string, avoid string, avoidRooms string) (world.PathOptions, error) {
+	opts := world.PathOptions{Weighted: weighted == "true", AvoidRooms: map[string]bool{}}
+	for _, kind := range strings.Split(avoid, ",") {
+		switch strings.TrimSpace(kind) {
+		case "":
+		case "locked":
+			opts.AvoidLocked = true
+		case "doors":
+			opts.AvoidDoors = true
+		case "hidden":
+			opts.AvoidHidden = true
+		default:
+			return opts, errors.New("unknown exit kind to avoid: " + kind)
+		}
+	}
+	for _, id := range strings.Split(avoidRooms, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			opts.AvoidRooms[id] = true
+		}
+	}
+	return opts, nil
+}
+
+func convertRoomPath(from string, to string, path []*world.Exit) *types.RoomPath {
+	data := &types.RoomPath{
+		From:       from,
+		To:         to,
+		Steps:      make([]types.RoomPathStep, 0, len(path)),
+		Directions: make([]string, 0, len(path)),
+		Cost:       world.PathCost(path),
+	}
+	for _, e := range path {
+		data.Steps = append(data.Steps, types.RoomPathStep{RoomID: e.From, Direction: string(e.Direction), ToRoomID: e.To, Cost: e.Cost})
+		data.Directions = append(data.Directions, e.Direction.Short())
+	}
+	return data
+}
+
+func convertWorldReport(r *world.Report, g *world.Graph) *types.ValidateWorldReport {
+	report := &types.ValidateWorldReport{
+		StartRoom:        r.StartRoom,
+		StartRoomFound:   r.StartRoomFound,
+		Rooms:            len(g.Rooms()),
+		Exits:            g.NumExits(),
+		DanglingExits:    convertWorldExits(r.DanglingExits),
+		OneWayExits:      convertWorldExits(r.OneWayExits),
+		UnreachableRooms: r.UnreachableRooms,
+	}
+	if report.UnreachableRooms == nil {
+		report.UnreachableRooms = []string{}
+	}
+	return report
+}
+
+func convertWorldExits(exits []*world.Exit) []types.WorldExitIssue {
+	issues := make([]types.WorldExitIssue, 0, len(exits))
+	for _, e := range exits {
+		issues = append(issues, types.WorldExitIssue{RoomID: e.From, Direction: string(e.Direction), ToRoomID: e.To})
+	}
+	return issues
+}
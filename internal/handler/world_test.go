@@ -0,0 +1,334 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-dev-frame/sponge/pkg/gotest"
+	"github.com/go-dev-frame/sponge/pkg/httpcli"
+
+	"fs/internal/bundle"
+	"fs/internal/config"
+	"fs/internal/dao"
+	"fs/internal/database"
+	"fs/internal/ecode"
+	"fs/internal/model"
+	"fs/internal/rule"
+	"fs/internal/types"
+	"fs/internal/world"
+)
+
+func newWorldHandler() *gotest.Handler {
+	testData := &model.Room{ID: "square", Title: "Town Square"}
+
+	// init mock dao, the world is read without cache
+	d := gotest.NewDao(nil, testData)
+	d.IDao = dao.NewRoomDao(d.DB, nil)
+
+	// init mock handler
+	h := gotest.NewHandler(d, testData)
+	mobRules, _ := rule.NewMob(config.RuleSet{}, nil)
+	itemRules, _ := rule.NewItem(config.RuleSet{}, nil)
+	wh := &worldHandler{
+		roomDao:   d.IDao.(dao.RoomDao),
+		exitDao:   dao.NewRoomExitDao(d.DB, nil),
+		mobDao:    dao.NewMobDao(d.DB, nil),
+		itemDao:   dao.NewItemDao(d.DB, nil),
+		db:        d.DB,
+		mobRules:  mobRules,
+		itemRules: itemRules,
+	}
+	wh.graph = world.NewGraphCache(func(ctx context.Context) (*world.Graph, error) {
+		_, g, err := wh.loadGraph(ctx)
+		return g, err
+	}, 0)
+	h.IHandler = wh
+	iHandler := h.IHandler.(WorldHandler)
+
+	testFns := []gotest.RouterInfo{
+		{
+			FuncName:    "Validate",
+			Method:      http.MethodGet,
+			Path:        "/world/validate",
+			HandlerFunc: iHandler.Validate,
+		},
+		{
+			FuncName:    "Path",
+			Method:      http.MethodGet,
+			Path:        "/room/:id/path",
+			HandlerFunc: iHandler.Path,
+		},
+		{
+			FuncName:    "Map",
+			Method:      http.MethodGet,
+			Path:        "/world/map",
+			HandlerFunc: iHandler.Map,
+		},
+		{
+			FuncName:    "Export",
+			Method:      http.MethodGet,
+			Path:        "/world/export",
+			HandlerFunc: iHandler.Export,
+		},
+		{
+			FuncName:    "Import",
+			Method:      http.MethodPost,
+			Path:        "/world/import",
+			HandlerFunc: iHandler.Import,
+		},
+	}
+
+	h.GoRunHTTPServer(testFns)
+
+	time.Sleep(time.Millisecond * 200)
+	return h
+}
+
+// expectGraph expect the rooms and exits of a small world to be read:
+// square <-> inn by north and south, inn down to a hidden cellar without a way back,
+// square east to a room that does not exist, and an attic no exit leads to
+func expectGraph(h *gotest.Handler) {
+	h.MockDao.SQLMock.ExpectQuery("SELECT \\* FROM `room`").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "mobs"}).
+			AddRow("square", "Town Square", "1, 7").
+			AddRow("inn", "The Inn", "").
+			AddRow("cellar", "Cellar", "").
+			AddRow("attic", "Attic", ""))
+	h.MockDao.SQLMock.ExpectQuery("SELECT \\* FROM `room_exit`").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "room_id", "direction", "to_room_id", "hidden", "cost"}).
+			AddRow(1, "square", "north", "inn", 0, 1).
+			AddRow(2, "inn", "south", "square", 0, 1).
+			AddRow(3, "inn", "down", "cellar", 1, 5).
+			AddRow(4, "square", "east", "void", 0, 1))
+}
+
+func Test_worldHandler_Validate(t *testing.T) {
+	h := newWorldHandler()
+	defer h.Close()
+
+	expectGraph(h)
+	// the mobs of every room are read at once
+	h.MockDao.SQLMock.ExpectQuery("SELECT \\* FROM `mob` WHERE id IN \\(\\?,\\?\\)").
+		WithArgs(1, 7).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	result := &httpcli.StdResult{}
+	err := httpcli.Get(result, h.GetRequestURL("Validate"), httpcli.WithParams(map[string]interface{}{"start": "square"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Code != 0 {
+		t.Fatalf("%+v", result)
+	}
+	data, _ := json.Marshal(result.Data)
+	report := &types.ValidateWorldReport{}
+	require.NoError(t, json.Unmarshal(data, report))
+	assert.False(t, report.Valid)
+	assert.True(t, report.StartRoomFound)
+	assert.Equal(t, 4, report.Rooms)
+	assert.Equal(t, 4, report.Exits)
+	assert.Equal(t, []types.WorldExitIssue{{RoomID: "square", Direction: "east", ToRoomID: "void"}}, report.DanglingExits)
+	assert.Equal(t, []types.WorldExitIssue{{RoomID: "inn", Direction: "down", ToRoomID: "cellar"}}, report.OneWayExits)
+	assert.Equal(t, []string{"attic"}, report.UnreachableRooms)
+	assert.Equal(t, []types.WorldMobIssue{{RoomID: "square", MobID: 7}}, report.UnknownMobs)
+
+	// get error test
+	h.MockDao.SQLMock.ExpectQuery("SELECT \\* FROM `room`").WillReturnError(errors.New("db error"))
+	err = httpcli.Get(result, h.GetRequestURL("Validate"), httpcli.WithParams(map[string]interface{}{"start": "square"}))
+	assert.Error(t, err)
+	assert.NoError(t, h.MockDao.SQLMock.ExpectationsWereMet())
+}
+
+func Test_worldHandler_Path(t *testing.T) {
+	h := newWorldHandler()
+	defer h.Close()
+
+	// the graph is read once and kept, there is no room change hook in the test
+	expectGraph(h)
+
+	result := &httpcli.StdResult{}
+	err := httpcli.Get(result, h.GetRequestURL("Path", "square"), httpcli.WithParams(map[string]interface{}{"to": "cellar"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Code != 0 {
+		t.Fatalf("%+v", result)
+	}
+	data, _ := json.Marshal(result.Data)
+	path := &types.RoomPath{}
+	require.NoError(t, json.Unmarshal(data, path))
+	assert.Equal(t, []string{"n", "d"}, path.Directions)
+	assert.Equal(t, 6, path.Cost)
+
+	// the cellar is only reached by a hidden exit
+	err = httpcli.Get(result, h.GetRequestURL("Path", "square"), httpcli.WithParams(map[string]interface{}{"to": "cellar", "avoid": "hidden"}))
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.ErrNoRoomPath.Code(), result.Code)
+
+	// unknown room, missing target and unknown exit kind
+	err = httpcli.Get(result, h.GetRequestURL("Path", "square"), httpcli.WithParams(map[string]interface{}{"to": "void"}))
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.NotFound.Code(), result.Code)
+	err = httpcli.Get(result, h.GetRequestURL("Path", "square"))
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.InvalidParams.Code(), result.Code)
+	err = httpcli.Get(result, h.GetRequestURL("Path", "square"), httpcli.WithParams(map[string]interface{}{"to": "inn", "avoid": "walls"}))
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.InvalidParams.Code(), result.Code)
+	assert.NoError(t, h.MockDao.SQLMock.ExpectationsWereMet())
+}
+
+func Test_worldHandler_Map(t *testing.T) {
+	h := newWorldHandler()
+	defer h.Close()
+
+	expectGraph(h)
+
+	for format, contentType := range mapContentTypes {
+		resp, err := http.Get(h.GetRequestURL("Map") + "?center=square&radius=1&format=" + format)
+		require.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode, format)
+		assert.Equal(t, contentType, resp.Header.Get("Content-Type"), format)
+		assert.NotEmpty(t, body, format)
+	}
+
+	// unknown centre room, format and radius
+	result := &httpcli.StdResult{}
+	err := httpcli.Get(result, h.GetRequestURL("Map"), httpcli.WithParams(map[string]interface{}{"center": "void"}))
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.NotFound.Code(), result.Code)
+	err = httpcli.Get(result, h.GetRequestURL("Map"), httpcli.WithParams(map[string]interface{}{"format": "png"}))
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.InvalidParams.Code(), result.Code)
+	err = httpcli.Get(result, h.GetRequestURL("Map"), httpcli.WithParams(map[string]interface{}{"radius": -1}))
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.InvalidParams.Code(), result.Code)
+	assert.NoError(t, h.MockDao.SQLMock.ExpectationsWereMet())
+}
+
+func Test_worldHandler_Export(t *testing.T) {
+	h := newWorldHandler()
+	defer h.Close()
+	testData := h.TestData.(*model.Room)
+
+	h.MockDao.SQLMock.ExpectQuery("SELECT .* FROM `room`").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(testData.ID, testData.Title))
+	for _, table := range []string{"room_exit", "mob", "item"} {
+		h.MockDao.SQLMock.ExpectQuery("SELECT .* FROM `" + table + "`").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	}
+
+	resp, err := http.Get(h.GetRequestURL("Export") + "?format=yaml")
+	require.NoError(t, err)
+	defer resp.Body.Close() //nolint
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("Content-Disposition"), "world.yaml")
+
+	b, err := bundle.Decode(resp.Body, bundle.FormatYAML)
+	require.NoError(t, err)
+	assert.Equal(t, []*model.Room{testData}, b.Rooms)
+
+	// unknown format
+	result := &httpcli.StdResult{}
+	err = httpcli.Get(result, h.GetRequestURL("Export"), httpcli.WithParams(map[string]interface{}{"format": "xml"}))
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.InvalidParams.Code(), result.Code)
+}
+
+func Test_worldHandler_Import(t *testing.T) {
+	h := newWorldHandler()
+	defer h.Close()
+	testData := h.TestData.(*model.Room)
+
+	body := []byte("version: 1\nrooms:\n  - id: " + testData.ID + "\n    title: " + testData.Title + "\n")
+	post := func(query string, body []byte) *httpcli.StdResult {
+		resp, err := http.Post(h.GetRequestURL("Import")+query, "application/yaml", bytes.NewReader(body))
+		require.NoError(t, err)
+		defer resp.Body.Close() //nolint
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		result := &httpcli.StdResult{}
+		require.NoError(t, json.Unmarshal(data, result), string(data))
+		return result
+	}
+
+	// dry run of a new room, the rooms in the trash are looked up as well
+	h.MockDao.SQLMock.ExpectBegin()
+	h.MockDao.SQLMock.ExpectQuery("SELECT .* FROM `room` WHERE id IN \\(\\?\\)$").WithArgs(testData.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	h.MockDao.SQLMock.ExpectExec("INSERT INTO `room`").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	h.MockDao.SQLMock.ExpectRollback()
+
+	result := post("?dryRun=true", body)
+	assert.Equal(t, 0, result.Code)
+	assert.Equal(t, true, result.Data.(map[string]interface{})["dryRun"])
+
+	// the room exists and the strategy is fail
+	h.MockDao.SQLMock.ExpectBegin()
+	h.MockDao.SQLMock.ExpectQuery("SELECT .* FROM `room`").WithArgs(testData.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(testData.ID))
+	h.MockDao.SQLMock.ExpectRollback()
+
+	result = post("", body)
+	assert.Equal(t, ecode.ErrWorldConflict.Code(), result.Code)
+
+	// the room is in the trash, it is a conflict too
+	h.MockDao.SQLMock.ExpectBegin()
+	h.MockDao.SQLMock.ExpectQuery("SELECT .* FROM `room`").WithArgs(testData.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "deleted_at"}).AddRow(testData.ID, time.Now()))
+	h.MockDao.SQLMock.ExpectRollback()
+
+	result = post("", body)
+	assert.Equal(t, ecode.ErrWorldConflict.Code(), result.Code)
+	assert.Contains(t, result.Msg, "in the trash")
+
+	// a mob breaking the rules rolls back the import
+	mob := []byte("version: 1\nmobs:\n  - id: 3\n    mobID: rat\n    hp: 0\n")
+	h.MockDao.SQLMock.ExpectBegin()
+	h.MockDao.SQLMock.ExpectQuery("SELECT .* FROM `mob`").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	h.MockDao.SQLMock.ExpectRollback()
+
+	result = post("", mob)
+	assert.Equal(t, ecode.ErrRulesMob.Code(), result.Code)
+	assert.Contains(t, result.Msg, "mob 3")
+	assert.Equal(t, map[string]interface{}{"errors": []interface{}{
+		map[string]interface{}{"field": "hp", "rule": "range", "message": "must be between 1 and 100000"},
+	}}, result.Data)
+
+	// the mob id is taken by another mob, the unique index refuses it
+	mob = []byte("version: 1\nmobs:\n  - id: 3\n    mobID: rat\n    hp: 5\n")
+	h.MockDao.SQLMock.ExpectBegin()
+	h.MockDao.SQLMock.ExpectQuery("SELECT .* FROM `mob`").WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	h.MockDao.SQLMock.ExpectExec("INSERT INTO `mob`").
+		WillReturnError(database.ErrDuplicatedKey)
+	h.MockDao.SQLMock.ExpectRollback()
+
+	result = post("", mob)
+	assert.Equal(t, ecode.ErrRulesMob.Code(), result.Code)
+	assert.Equal(t, map[string]interface{}{"errors": []interface{}{
+		map[string]interface{}{"field": "mobID", "rule": "unique", "message": "rat is already used, the records in the trash included"},
+	}}, result.Data)
+
+	// unknown version and unknown conflict strategy
+	result = post("", []byte("version: 9\n"))
+	assert.Equal(t, ecode.ErrWorldBundle.Code(), result.Code)
+	result = post("?conflict=merge", body)
+	assert.Equal(t, ecode.InvalidParams.Code(), result.Code)
+	assert.NoError(t, h.MockDao.SQLMock.ExpectationsWereMet())
+}
@@ -0,0 +1,507 @@
+package lpc
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"fs/internal/bundle"
+	"fs/internal/equip"
+	"fs/internal/model"
+	"fs/internal/world"
+)
+
+// Options how the objects are converted
+type Options struct {
+	FirstMobID  uint64 // id of the first mob, the next mobs count up from it, 0 is 1
+	FirstItemID uint64 // id of the first item, 0 is 1
+}
+
+// Issue something in a file that was not imported
+type Issue struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"` // 0 if it is about the whole file
+	Message string `json:"message"`
+}
+
+func (i Issue) String() string {
+	if i.Line == 0 {
+		return i.File + ": " + i.Message
+	}
+	return i.File + ":" + strconv.Itoa(i.Line) + ": " + i.Message
+}
+
+// Report what was converted and everything that could not be mapped
+type Report struct {
+	Files     int     `json:"files"`
+	Rooms     int     `json:"rooms"`
+	RoomExits int     `json:"roomExits"`
+	Mobs      int     `json:"mobs"`
+	Items     int     `json:"items"`
+	Issues    []Issue `json:"issues"`
+}
+
+// kinds of objects
+type kind int
+
+const (
+	kindNone kind = iota
+	kindRoom
+	kindMob
+	kindItem
+)
+
+// column sizes of the models, longer values are cut
+const (
+	maxIDLen    = 50
+	maxTitleLen = 30
+	maxNameLen  = 50
+	maxMobsLen  = 256 // Room.Mobs
+)
+
+// calls that only matter to the driver, they are not reported
+var ignoredCalls = map[string]bool{
+	"setup": true, "replace_program": true, "set_default_object": true,
+	"clonep": true, "seteuid": true, "getuid": true,
+}
+
+// Convert turn parsed objects into the records of a bundle that can be imported with
+// bundle.Import. Rooms inherit ROOM, mobs inherit NPC and the other objects with a
+// set_name are items. Ids are the mudlib paths without the leading / and .c, mobs and
+// items also get numeric ids in the order of their paths as Room.Mobs lists mobs by id.
+func Convert(objs []*Object, o Options) (*bundle.Bundle, *Report) {
+	if o.FirstMobID == 0 {
+		o.FirstMobID = 1
+	}
+	if o.FirstItemID == 0 {
+		o.FirstItemID = 1
+	}
+	objs = append([]*Object(nil), objs...)
+	sort.Slice(objs, func(i, j int) bool { return objs[i].Path < objs[j].Path })
+
+	c := &converter{
+		kinds:   map[string]kind{},
+		mobIDs:  map[string]uint64{},
+		bundle:  &bundle.Bundle{Version: bundle.Version, ExportedAt: time.Now().UTC()},
+		report:  &Report{Files: len(objs), Issues: []Issue{}},
+		options: o,
+	}
+	for _, obj := range objs {
+		id := ID(obj.Path)
+		c.kinds[id] = kindOf(obj)
+		if c.kinds[id] == kindMob && len(id) <= maxIDLen {
+			c.mobIDs[id] = o.FirstMobID + uint64(len(c.mobIDs))
+		}
+	}
+	for _, obj := range objs {
+		c.convert(obj)
+	}
+
+	c.report.Rooms = len(c.bundle.Rooms)
+	c.report.RoomExits = len(c.bundle.RoomExits)
+	c.report.Mobs = len(c.bundle.Mobs)
+	c.report.Items = len(c.bundle.Items)
+	return c.bundle, c.report
+}
+
+// ID the id of a mudlib path, e.g. d/snow/inn for /d/snow/inn.c or /d/snow/inn
+func ID(mudlibPath string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(path.Clean("/"+mudlibPath), "/"), ".c")
+}
+
+func kindOf(obj *Object) kind {
+	for _, name := range obj.Inherits {
+		switch name = strings.ToUpper(name); {
+		case strings.Contains(name, "ROOM"):
+			return kindRoom
+		case strings.Contains(name, "NPC"):
+			return kindMob
+		}
+	}
+	for _, call := range obj.Calls {
+		if call.Name == "set_name" {
+			return kindItem
+		}
+	}
+	for _, call := range obj.Calls {
+		if key := call.Arg(0); call.Name == "set" && (key.Text == "short" || key.Text == "exits") {
+			return kindRoom
+		}
+	}
+	return kindNone
+}
+
+type converter struct {
+	kinds   map[string]kind
+	mobIDs  map[string]uint64 // numeric ids of the mobs by path id, in the order of the paths
+	bundle  *bundle.Bundle
+	report  *Report
+	options Options
+
+	file string // the file being converted
+}
+
+func (c *converter) issue(line int, format string, args ...interface{}) {
+	c.report.Issues = append(c.report.Issues, Issue{File: c.file, Line: line, Message: fmt.Sprintf(format, args...)})
+}
+
+func (c *converter) convert(obj *Object) {
+	c.file = obj.Path
+	id := ID(obj.Path)
+	if len(id) > maxIDLen {
+		c.issue(0, "the id %s is longer than %d characters, the file is skipped", id, maxIDLen)
+		return
+	}
+	for _, f := range obj.Functions {
+		c.issue(0, "function %s() is not imported", f)
+	}
+
+	switch c.kinds[id] {
+	case kindRoom:
+		c.room(id, obj)
+	case kindMob:
+		c.mob(id, obj)
+	case kindItem:
+		c.item(id, obj)
+	default:
+		c.issue(0, "not a room, npc or object, the file is skipped")
+	}
+}
+
+func (c *converter) room(id string, obj *Object) {
+	room := &model.Room{ID: id}
+	var mobs []string
+	for _, call := range obj.Calls {
+		if call.Name != "set" {
+			c.unmappedCall(call)
+			continue
+		}
+		key, value := call.Arg(0).Text, call.Arg(1)
+		switch key {
+		case "short":
+			room.Title = c.text(call, value, maxTitleLen)
+		case "long":
+			room.Desc = c.text(call, value, 0)
+		case "exits":
+			c.exits(id, call, value)
+		case "objects":
+			mobs = append(mobs, c.objects(call, value)...)
+		default:
+			c.unmappedSet(call)
+		}
+	}
+	room.Mobs = strings.Join(mobs, ",")
+	if len(room.Mobs) > maxMobsLen {
+		room.Mobs = room.Mobs[:strings.LastIndex(room.Mobs[:maxMobsLen+1], ",")]
+		c.issue(0, "the mobs of the room do not fit in %d characters, the last ones are left out", maxMobsLen)
+	}
+	c.bundle.Rooms = append(c.bundle.Rooms, room)
+}
+
+// exits set("exits", ([ "north": __DIR__"inn" ]))
+func (c *converter) exits(roomID string, call Call, value Value) {
+	if value.Kind != Mapping {
+		c.issue(call.Line, "set(\"exits\") is not a mapping: %s", value)
+		return
+	}
+	for _, p := range value.Pairs {
+		d, ok := world.ParseDirection(p.Key.Text)
+		if p.Key.Kind != String || !ok {
+			c.issue(call.Line, "exit %s is not a direction, it is not imported", p.Key)
+			continue
+		}
+		if p.Value.Kind != String {
+			c.issue(call.Line, "exit %s leads to %s which is not a path, it is not imported", d, p.Value)
+			continue
+		}
+		to := ID(p.Value.Text)
+		if c.kinds[to] != kindRoom {
+			c.issue(call.Line, "exit %s leads to %s which is not one of the imported rooms", d, to)
+		}
+		c.bundle.RoomExits = append(c.bundle.RoomExits, &model.RoomExit{
+			RoomID:    roomID,
+			Direction: string(d),
+			ToRoomID:  to,
+			Cost:      1,
+		})
+	}
+}
+
+// objects set("objects", ([ __DIR__"npc/waiter": 2 ])), the mobs are listed in Room.Mobs
+// once for each copy
+func (c *converter) objects(call Call, value Value) []string {
+	if value.Kind != Mapping {
+		c.issue(call.Line, "set(\"objects\") is not a mapping: %s", value)
+		return nil
+	}
+	var mobs []string
+	for _, p := range value.Pairs {
+		if p.Key.Kind != String {
+			c.issue(call.Line, "object %s is not a path, it is not imported", p.Key)
+			continue
+		}
+		id := ID(p.Key.Text)
+		count := 1
+		if p.Value.Kind == Int {
+			count = p.Value.Int
+		} else {
+			c.issue(call.Line, "the number of %s is not a number: %s, one is placed", id, p.Value)
+		}
+
+		mobID, isMob := c.mobIDs[id]
+		switch {
+		case isMob:
+			for i := 0; i < count; i++ {
+				mobs = append(mobs, strconv.FormatUint(mobID, 10))
+			}
+		case c.kinds[id] == kindItem:
+			c.issue(call.Line, "object %s is an item, items lying in rooms are not imported", id)
+		default:
+			c.issue(call.Line, "object %s is not one of the imported npcs", id)
+		}
+	}
+	return mobs
+}
+
+// mob stats set by set(key, n), the first key found of each list is used
+var (
+	mobHpKeys = []string{"max_kee", "max_qi", "kee", "qi"}
+	mobMpKeys = []string{"max_force", "max_neili", "max_mana", "force", "neili", "mana"}
+)
+
+func (c *converter) mob(id string, obj *Object) {
+	mob := &model.Mob{ID: c.mobIDs[id], MobID: id}
+	sets := map[string]Value{}
+	for _, call := range obj.Calls {
+		switch call.Name {
+		case "set_name":
+			mob.MobCname, mob.MobName = c.names(call)
+		case "set_skill":
+			c.mobSkill(mob, call)
+		case "set":
+			switch key := call.Arg(0).Text; {
+			case key == "long":
+				mob.MobDesc = c.text(call, call.Arg(1), 0)
+			case contains(mobHpKeys, key) || contains(mobMpKeys, key):
+				if call.Arg(1).Kind != Int {
+					c.issue(call.Line, "set(%q) is not a number: %s", key, call.Arg(1))
+					continue
+				}
+				sets[key] = call.Arg(1)
+			default:
+				c.unmappedSet(call)
+			}
+		case "carry_object":
+			c.issue(call.Line, "carry_object(%s) is not imported, give the mob the item as an item instance", call.Arg(0))
+		default:
+			c.unmappedCall(call)
+		}
+	}
+	mob.Hp = firstInt(sets, mobHpKeys)
+	mob.Mp = firstInt(sets, mobMpKeys)
+	if mob.MobName == "" {
+		mob.MobName = path.Base(id)
+		c.issue(0, "no set_name, the name is %s", mob.MobName)
+	}
+	c.bundle.Mobs = append(c.bundle.Mobs, mob)
+}
+
+// mobSkill set_skill("dodge", 50), dodge, parry and unarmed are the dodge, defence and attack of the mob
+func (c *converter) mobSkill(mob *model.Mob, call Call) {
+	level := call.Arg(1)
+	if level.Kind != Int {
+		c.issue(call.Line, "set_skill(%s) is not a number: %s", call.Arg(0), level)
+		return
+	}
+	switch call.Arg(0).Text {
+	case "dodge":
+		mob.Dodge = level.Int
+	case "parry":
+		mob.Defence = level.Int
+	case "unarmed":
+		mob.Attack = level.Int
+	default:
+		c.issue(call.Line, "set_skill(%s) is not mapped", call.Arg(0))
+	}
+}
+
+// item stats set by set("apply/<name>"), set("armor_prop/<name>") or set("weapon_prop/<name>")
+var itemStatPrefixes = []string{"apply/", "armor_prop/", "weapon_prop/"}
+
+func (c *converter) item(id string, obj *Object) {
+	item := &model.Item{ID: c.options.FirstItemID + uint64(len(c.bundle.Items)), ItemID: id}
+	for _, name := range obj.Inherits {
+		if cl, ok := itemClassifier(strings.ToLower(name)); ok {
+			item.Classifier = string(cl)
+		}
+	}
+
+	initDamage := 0
+	for _, call := range obj.Calls {
+		switch {
+		case call.Name == "set_name":
+			item.ItemCname, item.ItemName = c.names(call)
+		case call.Name == "set":
+			c.itemSet(item, call)
+		case strings.HasPrefix(call.Name, "init_"):
+			cl, ok := itemClassifier(strings.TrimPrefix(call.Name, "init_"))
+			if !ok {
+				c.unmappedCall(call)
+				continue
+			}
+			item.Classifier = string(cl)
+			if cl == equip.Weapon && call.Arg(0).Kind == Int {
+				initDamage = call.Arg(0).Int
+			}
+		default:
+			c.unmappedCall(call)
+		}
+	}
+	if item.Attack == 0 {
+		item.Attack = initDamage
+	}
+	if item.ItemName == "" {
+		item.ItemName = path.Base(id)
+		c.issue(0, "no set_name, the name is %s", item.ItemName)
+	}
+	c.bundle.Items = append(c.bundle.Items, item)
+}
+
+func (c *converter) itemSet(item *model.Item, call Call) {
+	key, value := call.Arg(0).Text, call.Arg(1)
+	switch key {
+	case "long":
+		item.ItemDesc = c.text(call, value, 0)
+		return
+	case "armor_type", "skill_type":
+		if cl, ok := itemClassifier(value.Text); ok && value.Kind == String {
+			item.Classifier = string(cl)
+			return
+		}
+	case "food_remaining", "liquid":
+		item.Classifier = string(equip.Consumable)
+		return
+	}
+
+	for _, prefix := range itemStatPrefixes {
+		if stat, ok := strings.CutPrefix(key, prefix); ok {
+			if field := itemStat(item, stat); field != nil && value.Kind == Int {
+				*field = value.Int
+				return
+			}
+		}
+	}
+	c.unmappedSet(call)
+}
+
+// itemStat the field of a stat of the legacy mudlib
+func itemStat(item *model.Item, stat string) *int {
+	switch stat {
+	case "damage", "attack":
+		return &item.Attack
+	case "armor", "defense":
+		return &item.Defence
+	case "dodge":
+		return &item.Dodge
+	case "str":
+		return &item.Str
+	case "cor":
+		return &item.Cor
+	case "int":
+		return &item.Inte
+	case "dex":
+		return &item.Dex
+	case "con":
+		return &item.Con
+	case "kar":
+		return &item.Kar
+	}
+	return nil
+}
+
+// classifiers of the weapon and armour kinds of the legacy mudlib, as used in inherit,
+// init_<kind>() and set("armor_type")
+var legacyClassifiers = map[string]equip.Classifier{
+	"sword": equip.Weapon, "blade": equip.Weapon, "dagger": equip.Weapon, "staff": equip.Weapon,
+	"stick": equip.Weapon, "club": equip.Weapon, "whip": equip.Weapon, "hammer": equip.Weapon,
+	"axe": equip.Weapon, "spear": equip.Weapon, "fork": equip.Weapon, "pike": equip.Weapon,
+	"throwing": equip.Weapon,
+	"cloth":    equip.Armour, "armor": equip.Armour, "surcoat": equip.Armour,
+	"head": equip.Helmet, "hands": equip.Gloves, "feet": equip.Boots, "boots": equip.Boots,
+	"legs": equip.Leggings, "shield": equip.Shield, "neck": equip.Necklace, "finger": equip.Ring,
+	"f_food": equip.Consumable, "f_liquid": equip.Consumable, "food": equip.Consumable,
+}
+
+func itemClassifier(kind string) (equip.Classifier, bool) {
+	cl, ok := legacyClassifiers[kind]
+	return cl, ok
+}
+
+// names set_name("店小二", ({ "waiter", "xiao er" })), the chinese name and the first english id
+func (c *converter) names(call Call) (string, string) {
+	cname := c.text(call, call.Arg(0), maxNameLen)
+	ids := call.Arg(1)
+	if ids.Kind != Array || len(ids.Elems) == 0 || ids.Elems[0].Kind != String {
+		c.issue(call.Line, "the ids of set_name are not an array of strings: %s", ids)
+		return cname, ""
+	}
+	return cname, cut(ids.Elems[0].Text, maxNameLen)
+}
+
+// text a string value, cut to size runes if size is not 0
+func (c *converter) text(call Call, value Value, size int) string {
+	if value.Kind != String {
+		c.issue(call.Line, "%s(%s) is not a string: %s", call.Name, call.Arg(0), value)
+		return ""
+	}
+	text := strings.TrimRight(value.Text, "\n")
+	if size > 0 && utf8.RuneCountInString(text) > size {
+		c.issue(call.Line, "%s(%s) is longer than %d characters, it is cut", call.Name, call.Arg(0), size)
+		text = cut(text, size)
+	}
+	return text
+}
+
+func (c *converter) unmappedSet(call Call) {
+	c.issue(call.Line, "set(%s, %s) is not mapped", call.Arg(0), call.Arg(1))
+}
+
+func (c *converter) unmappedCall(call Call) {
+	if ignoredCalls[call.Name] {
+		return
+	}
+	args := make([]string, 0, len(call.Args))
+	for _, a := range call.Args {
+		args = append(args, a.String())
+	}
+	c.issue(call.Line, "%s(%s) is not mapped", call.Name, strings.Join(args, ", "))
+}
+
+func cut(s string, size int) string {
+	if utf8.RuneCountInString(s) <= size {
+		return s
+	}
+	return string([]rune(s)[:size])
+}
+
+func firstInt(values map[string]Value, keys []string) int {
+	for _, key := range keys {
+		if v, ok := values[key]; ok {
+			return v.Int
+		}
+	}
+	return 0
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package lpc
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"fs/internal/bundle"
+	"fs/internal/model"
+)
+
+func TestConvert(t *testing.T) {
+	objs, err := Load("testdata", "d/snow")
+	require.NoError(t, err)
+	require.Len(t, objs, 4)
+
+	b, report := Convert(objs, Options{FirstMobID: 10})
+	assert.Equal(t, bundle.Version, b.Version)
+	assert.Equal(t, &Report{Files: 4, Rooms: 2, RoomExits: 3, Mobs: 1, Items: 1, Issues: report.Issues}, report)
+
+	require.Len(t, b.Rooms, 2)
+	assert.Equal(t, &model.Room{
+		ID:    "d/snow/inn",
+		Title: "飲風客棧",
+		Desc:  "這裡是雪亭鎮的飲風客棧，一進門就聞到陣陣酒香。\n西邊是廣場。",
+		Mobs:  "10,10",
+	}, b.Rooms[0])
+	assert.Equal(t, "雪亭鎮的廣場，人來人往。", b.Rooms[1].Desc)
+
+	exits := []string{}
+	for _, e := range b.RoomExits {
+		exits = append(exits, e.RoomID+" "+e.Direction+" "+e.ToRoomID)
+	}
+	assert.Equal(t, []string{"d/snow/inn west d/snow/square", "d/snow/inn up d/snow/inn_2f", "d/snow/square east d/snow/inn"}, exits)
+
+	assert.Equal(t, []*model.Mob{{
+		ID: 10, MobID: "d/snow/npc/waiter", MobName: "waiter", MobCname: "店小二", MobDesc: "一個手腳勤快的店小二。",
+		Hp: 300, Mp: 50, Attack: 15, Defence: 10, Dodge: 20,
+	}}, b.Mobs)
+	assert.Equal(t, []*model.Item{{
+		ID: 1, ItemID: "d/snow/obj/sword", ItemName: "long sword", ItemCname: "長劍", ItemDesc: "一把普通的長劍。",
+		Attack: 12, Dodge: -1, Str: 2, Classifier: "w",
+	}}, b.Items)
+
+	issues := []string{}
+	for _, i := range report.Issues {
+		issues = append(issues, i.String())
+	}
+	for _, want := range []string{
+		"/d/snow/inn.c:15: exit up leads to d/snow/inn_2f which is not one of the imported rooms",
+		`/d/snow/inn.c:15: exit "enter" is not a direction, it is not imported`,
+		"/d/snow/inn.c:20: object d/snow/obj/sword is an item, items lying in rooms are not imported",
+		`/d/snow/inn.c:24: set("no_fight", 1) is not mapped`,
+		`/d/snow/npc/waiter.c:17: set_skill("force") is not mapped`,
+		`/d/snow/npc/waiter.c:19: carry_object("/obj/cloth") is not imported, give the mob the item as an item instance`,
+		`/d/snow/obj/sword.c:16: set("value", 300) is not mapped`,
+		"/d/snow/square.c: function init() is not imported",
+	} {
+		assert.Contains(t, issues, want)
+	}
+	assert.Len(t, issues, 12, strings.Join(issues, "\n"))
+}
+
+func TestConvert_limits(t *testing.T) {
+	long := strings.Repeat("x", 60)
+	objs := []*Object{
+		Parse("/d/"+long+".c", []byte(`inherit ROOM; void create() { set("short", "a"); }`)),
+		Parse("/d/hall.c", []byte(`inherit ROOM; void create() {
+			set("short", "`+strings.Repeat("長", 40)+`");
+			set("objects", ([ "/d/npc/guard": 200, "/d/npc/ghost": 1 ]));
+		}`)),
+		Parse("/d/npc/guard.c", []byte(`inherit NPC; void create() { set("long", "a guard"); }`)),
+		Parse("/d/readme.c", []byte(`int x;`)),
+	}
+	b, report := Convert(objs, Options{})
+
+	require.Len(t, b.Rooms, 1)
+	assert.Equal(t, strings.Repeat("長", 30), b.Rooms[0].Title)
+	assert.LessOrEqual(t, len(b.Rooms[0].Mobs), 256)
+	assert.True(t, strings.HasSuffix(b.Rooms[0].Mobs, ",1"))
+	require.Len(t, b.Mobs, 1)
+	assert.Equal(t, "guard", b.Mobs[0].MobName)
+
+	messages := []string{}
+	for _, i := range report.Issues {
+		messages = append(messages, i.Message)
+	}
+	assert.Equal(t, []string{
+		`set("short") is longer than 30 characters, it is cut`,
+		"object d/npc/ghost is not one of the imported npcs",
+		"the mobs of the room do not fit in 256 characters, the last ones are left out",
+		"no set_name, the name is guard",
+		"not a room, npc or object, the file is skipped",
+		"the id d/" + long + " is longer than 50 characters, the file is skipped",
+	}, messages)
+}
@@ -0,0 +1,40 @@
+package lpc
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Load parse the .c files below the directories of a mudlib, dirs are relative to the
+// root of the mudlib and no dirs is the whole mudlib. The mudlib path of a file is its
+// path relative to root, e.g. /d/snow/inn.c.
+func Load(root string, dirs ...string) ([]*Object, error) {
+	if len(dirs) == 0 {
+		dirs = []string{"."}
+	}
+	var objs []*Object
+	for _, dir := range dirs {
+		err := filepath.WalkDir(filepath.Join(root, dir), func(file string, e fs.DirEntry, err error) error {
+			if err != nil || e.IsDir() || !strings.HasSuffix(file, ".c") {
+				return err
+			}
+			rel, err := filepath.Rel(root, file)
+			if err != nil {
+				return err
+			}
+			src, err := os.ReadFile(file)
+			if err != nil {
+				return err
+			}
+			objs = append(objs, Parse(path.Join("/", filepath.ToSlash(rel)), src))
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return objs, nil
+}
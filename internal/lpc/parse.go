@@ -0,0 +1,374 @@
+package lpc
+
+import (
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Kind the kind of a value
+type Kind int
+
+// value kinds
+const (
+	Unknown Kind = iota // an expression the parser does not evaluate, Value.Text is its source
+	String
+	Int
+	Mapping
+	Array
+)
+
+// Value an argument of a call
+type Value struct {
+	Kind  Kind
+	Text  string  // String, or the source of Unknown
+	Int   int     // Int
+	Pairs []Pair  // Mapping, in source order
+	Elems []Value // Array
+}
+
+// Pair an entry of a mapping
+type Pair struct {
+	Key   Value
+	Value Value
+}
+
+// String the value as it would be written in LPC, used in reports
+func (v Value) String() string {
+	switch v.Kind {
+	case String:
+		return strconv.Quote(v.Text)
+	case Int:
+		return strconv.Itoa(v.Int)
+	case Mapping:
+		parts := make([]string, 0, len(v.Pairs))
+		for _, p := range v.Pairs {
+			parts = append(parts, p.Key.String()+": "+p.Value.String())
+		}
+		return "([ " + strings.Join(parts, ", ") + " ])"
+	case Array:
+		parts := make([]string, 0, len(v.Elems))
+		for _, e := range v.Elems {
+			parts = append(parts, e.String())
+		}
+		return "({ " + strings.Join(parts, ", ") + " })"
+	}
+	return v.Text
+}
+
+// Call a function call in create(), e.g. set("short", "Inn")
+type Call struct {
+	Name string
+	Args []Value
+	Line int
+}
+
+// Object what was parsed from a .c file
+type Object struct {
+	Path      string   // mudlib path of the file, e.g. /d/snow/inn.c
+	Inherits  []string // e.g. ROOM, NPC
+	Calls     []Call   // the calls in create() in source order, calls on objects (x->f()) are left out
+	Functions []string // the functions other than create, they are not imported
+}
+
+// Arg the argument i of a call, Unknown if there is none
+func (c Call) Arg(i int) Value {
+	if i < len(c.Args) {
+		return c.Args[i]
+	}
+	return Value{Kind: Unknown}
+}
+
+// Parse parse the source of a .c file, path is the mudlib path of the file,
+// __DIR__ and __FILE__ are evaluated from it
+func Parse(filePath string, src []byte) *Object {
+	s := newScanner(string(src))
+	p := &parser{
+		toks:    s.tokens(),
+		defines: s.defines,
+		file:    filePath,
+		obj:     &Object{Path: filePath},
+	}
+	p.parseFile()
+	return p.obj
+}
+
+type parser struct {
+	toks    []token
+	pos     int
+	defines map[string][]token
+	expands int // number of defines expanded, a define that uses itself stops at maxExpands
+	file    string
+	obj     *Object
+}
+
+const maxExpands = 10000
+
+func (p *parser) peek(n int) token {
+	if p.pos+n < len(p.toks) {
+		return p.toks[p.pos+n]
+	}
+	return p.toks[len(p.toks)-1]
+}
+
+func (p *parser) advance() token {
+	t := p.peek(0)
+	if p.pos < len(p.toks)-1 {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) is(text string) bool {
+	t := p.peek(0)
+	return t.kind == tokPunct && t.text == text
+}
+
+// parseFile read the inherits and the function bodies of the top level
+func (p *parser) parseFile() {
+	for p.peek(0).kind != tokEOF {
+		t := p.advance()
+		switch {
+		case t.kind == tokIdent && t.text == "inherit":
+			if name := p.advance(); name.kind == tokIdent || name.kind == tokString {
+				p.obj.Inherits = append(p.obj.Inherits, name.text)
+			}
+		case t.kind == tokIdent && p.is("("):
+			// a function definition, the return type and modifiers were skipped
+			p.skipBalanced()
+			if !p.is("{") {
+				continue // a prototype
+			}
+			if t.text == "create" {
+				p.parseBody()
+			} else {
+				p.obj.Functions = append(p.obj.Functions, t.text)
+				p.skipBalanced()
+			}
+		case t.kind == tokPunct && t.text == "{":
+			p.pos--
+			p.skipBalanced()
+		}
+	}
+}
+
+// parseBody collect the calls of a { } block
+func (p *parser) parseBody() {
+	p.advance() // {
+	depth := 1
+	for depth > 0 && p.peek(0).kind != tokEOF {
+		prev := p.peek(-1)
+		t := p.advance()
+		switch {
+		case t.kind == tokPunct && t.text == "{":
+			depth++
+		case t.kind == tokPunct && t.text == "}":
+			depth--
+		case t.kind == tokIdent && p.is("(") && !keywords[t.text] && !(prev.kind == tokPunct && (prev.text == "->" || prev.text == "::")):
+			call := Call{Name: t.text, Line: t.line}
+			p.advance() // (
+			args := p.pos
+			call.Args = p.parseList(")")
+			p.obj.Calls = append(p.obj.Calls, call)
+			// the arguments are scanned too, calls nested in them are not lost
+			p.pos = args
+		}
+	}
+}
+
+// keywords followed by ( that are not calls
+var keywords = map[string]bool{"if": true, "while": true, "for": true, "foreach": true, "switch": true, "return": true}
+
+// parseList read values separated by commas up to the closing punctuation, which is consumed
+func (p *parser) parseList(closing string) []Value {
+	var list []Value
+	for !p.is(closing) && p.peek(0).kind != tokEOF {
+		list = append(list, p.parseExpr(","+closing))
+		if p.is(",") {
+			p.advance()
+		}
+	}
+	p.advance()
+	return list
+}
+
+// parseExpr read a value, strings joined with + or written next to each other are one string.
+// Anything else is Unknown and skipped up to one of the stop punctuations.
+func (p *parser) parseExpr(stops string) Value {
+	start := p.pos
+	v := p.parsePrimary()
+	for v.Kind == String {
+		if p.is("+") {
+			p.advance()
+		}
+		next := p.peek(0)
+		if next.kind != tokString && next.kind != tokIdent {
+			break
+		}
+		w := p.parsePrimary()
+		if w.Kind != String {
+			v = Value{Kind: Unknown}
+			break
+		}
+		v.Text += w.Text
+	}
+	if v.Kind == Int && p.is("*") { // e.g. set("value", 10 * 100)
+		p.advance()
+		if w := p.parsePrimary(); w.Kind == Int {
+			v.Int *= w.Int
+		} else {
+			v = Value{Kind: Unknown}
+		}
+	}
+
+	if v.Kind != Unknown && p.atStop(stops) {
+		return v
+	}
+	p.skipTo(stops)
+	return Value{Kind: Unknown, Text: p.source(start, p.pos)}
+}
+
+func (p *parser) atStop(stops string) bool {
+	t := p.peek(0)
+	return t.kind == tokEOF || t.kind == tokPunct && strings.Contains(stops, t.text)
+}
+
+func (p *parser) parsePrimary() Value {
+	t := p.advance()
+	switch t.kind {
+	case tokString:
+		return Value{Kind: String, Text: t.text}
+	case tokInt:
+		n, err := strconv.ParseInt(t.text, 0, 64)
+		if err != nil {
+			return Value{Kind: Unknown}
+		}
+		return Value{Kind: Int, Int: int(n)}
+	case tokIdent:
+		switch t.text {
+		case "__DIR__":
+			return Value{Kind: String, Text: path.Dir(p.file) + "/"}
+		case "__FILE__":
+			return Value{Kind: String, Text: p.file}
+		}
+		if d, ok := p.defines[t.text]; ok && p.expands < maxExpands {
+			p.expand(d)
+			return p.parsePrimary()
+		}
+	case tokPunct:
+		switch {
+		case t.text == "-":
+			if v := p.parsePrimary(); v.Kind == Int {
+				v.Int = -v.Int
+				return v
+			}
+		case t.text == "(" && p.is("["):
+			p.advance()
+			return p.parseMapping()
+		case t.text == "(" && p.is("{"):
+			p.advance()
+			v := Value{Kind: Array, Elems: p.parseList("}")}
+			if p.is(")") {
+				p.advance()
+			}
+			return v
+		case t.text == "(":
+			v := p.parseExpr(")")
+			p.advance()
+			return v
+		}
+	}
+	return Value{Kind: Unknown}
+}
+
+// expand replace the define name just read by its tokens
+func (p *parser) expand(d []token) {
+	p.expands++
+	p.pos--
+	toks := make([]token, 0, len(p.toks)+len(d)-1)
+	toks = append(toks, p.toks[:p.pos]...)
+	toks = append(toks, d...)
+	p.toks = append(toks, p.toks[p.pos+1:]...)
+}
+
+func (p *parser) parseMapping() Value {
+	v := Value{Kind: Mapping}
+	for !p.is("]") && p.peek(0).kind != tokEOF {
+		key := p.parseExpr(":,]")
+		if !p.is(":") {
+			p.skipTo("]")
+			v = Value{Kind: Unknown}
+			break
+		}
+		p.advance()
+		v.Pairs = append(v.Pairs, Pair{Key: key, Value: p.parseExpr(",]")})
+		if p.is(",") {
+			p.advance()
+		}
+	}
+	p.advance() // ]
+	if p.is(")") {
+		p.advance()
+	}
+	return v
+}
+
+// skipTo skip tokens up to one of the stop punctuations outside of brackets, the stop is not consumed
+func (p *parser) skipTo(stops string) {
+	depth := 0
+	for {
+		t := p.peek(0)
+		if t.kind == tokEOF {
+			return
+		}
+		if t.kind == tokPunct {
+			switch t.text {
+			case "(", "[", "{":
+				depth++
+			case ")", "]", "}":
+				if depth == 0 {
+					return // ours or a closing bracket of the caller
+				}
+				depth--
+			default:
+				if depth == 0 && strings.Contains(stops, t.text) {
+					return
+				}
+			}
+		}
+		p.advance()
+	}
+}
+
+// skipBalanced skip a bracketed group starting at the current token
+func (p *parser) skipBalanced() {
+	depth := 0
+	for p.peek(0).kind != tokEOF {
+		t := p.advance()
+		if t.kind != tokPunct {
+			continue
+		}
+		switch t.text {
+		case "(", "[", "{":
+			depth++
+		case ")", "]", "}":
+			depth--
+		}
+		if depth <= 0 {
+			return
+		}
+	}
+}
+
+// source the tokens from start to end joined by spaces, used for Unknown values
+func (p *parser) source(start int, end int) string {
+	parts := make([]string, 0, end-start)
+	for _, t := range p.toks[start:end] {
+		if t.kind == tokString {
+			parts = append(parts, strconv.Quote(t.text))
+		} else {
+			parts = append(parts, t.text)
+		}
+	}
+	return strings.Join(parts, " ")
+}
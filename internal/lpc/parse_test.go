@@ -0,0 +1,99 @@
+package lpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	src := `
+#include <ansi.h>
+#define HALL "/d/snow/hall"
+#define LEVEL 3
+
+inherit ROOM;
+inherit F_SAVE;
+
+int query_light() { return 1; }
+
+void create()
+{
+	::create();
+	set("short", "客棧");
+	set("long", @LONG
+第一行
+第二行
+LONG
+	);
+	set("exits", ([ "north": HALL, "south" : __DIR__ + "yard" ]));
+	set("names", ({ "a", "b" }) );
+	set("level", -LEVEL);
+	set("chance", random(10) + 1);
+	if( clonep() ) set_default_object(__FILE__);
+	carry_object("/obj/cloth")->wear();
+	set("c", 'x');
+}
+`
+	obj := Parse("/d/snow/inn.c", []byte(src))
+	assert.Equal(t, []string{"ROOM", "F_SAVE"}, obj.Inherits)
+	assert.Equal(t, []string{"query_light"}, obj.Functions)
+
+	names := []string{}
+	for _, c := range obj.Calls {
+		names = append(names, c.Name)
+	}
+	assert.Equal(t, []string{"set", "set", "set", "set", "set", "set", "random", "clonep", "set_default_object",
+		"carry_object", "set"}, names)
+
+	calls := obj.Calls
+	assert.Equal(t, Value{Kind: String, Text: "客棧"}, calls[0].Arg(1))
+	assert.Equal(t, 14, calls[0].Line)
+	assert.Equal(t, "第一行\n第二行\n", calls[1].Arg(1).Text)
+
+	exits := calls[2].Arg(1)
+	require.Equal(t, Mapping, exits.Kind)
+	assert.Equal(t, []Pair{
+		{Key: Value{Kind: String, Text: "north"}, Value: Value{Kind: String, Text: "/d/snow/hall"}},
+		{Key: Value{Kind: String, Text: "south"}, Value: Value{Kind: String, Text: "/d/snow/yard"}},
+	}, exits.Pairs)
+	assert.Equal(t, `({ "a", "b" })`, calls[3].Arg(1).String())
+	assert.Equal(t, Value{Kind: Int, Int: -3}, calls[4].Arg(1))
+
+	chance := calls[5].Arg(1)
+	assert.Equal(t, Unknown, chance.Kind)
+	assert.Equal(t, "random ( 10 ) + 1", chance.Text)
+
+	assert.Equal(t, "/d/snow/inn.c", calls[8].Arg(0).Text)
+	assert.Equal(t, "/obj/cloth", calls[9].Arg(0).Text)
+	assert.Equal(t, Value{Kind: Int, Int: 'x'}, calls[10].Arg(1))
+	assert.Equal(t, Unknown, calls[10].Arg(2).Kind)
+}
+
+func TestParse_broken(t *testing.T) {
+	// unbalanced sources must not hang or panic
+	for _, src := range []string{
+		`void create() { set("exits", ([ "north" "x" ])); set("short", "a"); }`,
+		`void create() { set("long", @TEXT
+never ends`,
+		`void create() { set("a", ({ 1, 2 ); }`,
+		`void create( { set(`,
+		`/* never closed`,
+		"int c = '\\\n';",
+		`int c = '\`,
+	} {
+		assert.NotPanics(t, func() { Parse("/x.c", []byte(src)) }, src)
+	}
+
+	obj := Parse("/x.c", []byte(`void create() { set("exits", ([ "north" "x" ])); set("short", "a"); }`))
+	require.Len(t, obj.Calls, 2)
+	assert.Equal(t, Unknown, obj.Calls[0].Arg(1).Kind)
+	assert.Equal(t, "a", obj.Calls[1].Arg(1).Text)
+
+	// a line continuation in a character constant
+	obj = Parse("/x.c", []byte("void create() { set(\"c\", '\\\nx');\n set(\"d\", 1); }"))
+	require.Len(t, obj.Calls, 2)
+	assert.Equal(t, Value{Kind: Int, Int: 'x'}, obj.Calls[0].Arg(1))
+	assert.Equal(t, 3, obj.Calls[1].Line)
+}
@@ -0,0 +1,264 @@
+// Package lpc reads the room, npc and object files of a legacy LPC (ES2 style) mudlib,
+// the common set("short"), set("long"), set("exits") and set("objects") patterns become
+// rooms, room exits, mobs and items, and everything else is reported.
+package lpc
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// token kinds
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokInt
+	tokPunct
+)
+
+type token struct {
+	kind tokenKind
+	text string // the unquoted text of strings, the punctuation of punct
+	line int
+}
+
+// scanner splits LPC source into tokens, comments are dropped, the tokens of #define
+// lines without parameters are remembered and the other preprocessor lines are dropped
+type scanner struct {
+	src     string
+	pos     int
+	line    int
+	defines map[string][]token
+}
+
+func newScanner(src string) *scanner {
+	return &scanner{src: src, line: 1, defines: map[string][]token{}}
+}
+
+// tokens scan the whole source
+func (s *scanner) tokens() []token {
+	var list []token
+	for {
+		t := s.next()
+		list = append(list, t)
+		if t.kind == tokEOF {
+			return list
+		}
+	}
+}
+
+func (s *scanner) next() token {
+	for {
+		s.skipSpace()
+		if s.pos >= len(s.src) {
+			return token{kind: tokEOF, line: s.line}
+		}
+		if s.atLineStart() && s.src[s.pos] == '#' {
+			s.directive()
+			continue
+		}
+		break
+	}
+
+	c := s.src[s.pos]
+	line := s.line
+	switch {
+	case c == '"':
+		return token{kind: tokString, text: s.quoted(), line: line}
+	case c == '@':
+		return token{kind: tokString, text: s.textBlock(), line: line}
+	case c == '\'':
+		return token{kind: tokInt, text: strconv.Itoa(s.char()), line: line}
+	case c >= '0' && c <= '9':
+		start := s.pos
+		for s.pos < len(s.src) && isIdentByte(s.src[s.pos]) {
+			s.pos++
+		}
+		return token{kind: tokInt, text: s.src[start:s.pos], line: line}
+	case isIdentByte(c):
+		start := s.pos
+		for s.pos < len(s.src) && isIdentByte(s.src[s.pos]) {
+			s.pos++
+		}
+		return token{kind: tokIdent, text: s.src[start:s.pos], line: line}
+	}
+
+	for _, p := range []string{"->", "::"} {
+		if strings.HasPrefix(s.src[s.pos:], p) {
+			s.pos += len(p)
+			return token{kind: tokPunct, text: p, line: line}
+		}
+	}
+	_, size := utf8.DecodeRuneInString(s.src[s.pos:])
+	text := s.src[s.pos : s.pos+size]
+	s.pos += size
+	return token{kind: tokPunct, text: text, line: line}
+}
+
+func (s *scanner) skipSpace() {
+	for s.pos < len(s.src) {
+		switch {
+		case s.src[s.pos] == '\n':
+			s.line++
+			s.pos++
+		case s.src[s.pos] == ' ' || s.src[s.pos] == '\t' || s.src[s.pos] == '\r':
+			s.pos++
+		case strings.HasPrefix(s.src[s.pos:], "//"):
+			for s.pos < len(s.src) && s.src[s.pos] != '\n' {
+				s.pos++
+			}
+		case strings.HasPrefix(s.src[s.pos:], "/*"):
+			end := strings.Index(s.src[s.pos+2:], "*/")
+			if end < 0 {
+				end = len(s.src) - s.pos - 2
+			} else {
+				end += 2
+			}
+			s.line += strings.Count(s.src[s.pos:s.pos+2+end], "\n")
+			s.pos = min(s.pos+2+end, len(s.src))
+		default:
+			return
+		}
+	}
+}
+
+// atLineStart check if only spaces are before pos on its line
+func (s *scanner) atLineStart() bool {
+	for i := s.pos - 1; i >= 0; i-- {
+		switch s.src[i] {
+		case '\n':
+			return true
+		case ' ', '\t', '\r':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// directive read a preprocessor line, the value of #define NAME value is kept
+func (s *scanner) directive() {
+	end := strings.IndexByte(s.src[s.pos:], '\n')
+	if end < 0 {
+		end = len(s.src) - s.pos
+	}
+	text := s.src[s.pos : s.pos+end]
+	s.pos += end
+
+	fields := strings.Fields(strings.TrimPrefix(text, "#"))
+	if len(fields) < 3 || fields[0] != "define" || strings.Contains(fields[1], "(") {
+		return
+	}
+	list := newScanner(strings.Join(fields[2:], " ")).tokens()
+	s.defines[fields[1]] = list[:len(list)-1] // without EOF
+}
+
+// quoted read a "string", the escapes are resolved
+func (s *scanner) quoted() string {
+	var b strings.Builder
+	s.pos++ // opening quote
+	for s.pos < len(s.src) {
+		c := s.src[s.pos]
+		switch c {
+		case '"':
+			s.pos++
+			return b.String()
+		case '\n':
+			s.line++
+		case '\\':
+			if s.pos+1 < len(s.src) {
+				s.pos++
+				b.WriteString(unescape(s.src[s.pos]))
+				if s.src[s.pos] == '\n' {
+					s.line++
+				}
+				s.pos++
+				continue
+			}
+		}
+		b.WriteByte(c)
+		s.pos++
+	}
+	return b.String()
+}
+
+func unescape(c byte) string {
+	switch c {
+	case 'n':
+		return "\n"
+	case 't':
+		return "\t"
+	case '\n':
+		return "" // line continuation
+	}
+	return string(c)
+}
+
+// char read a 'c' character constant
+func (s *scanner) char() int {
+	s.pos++
+	for strings.HasPrefix(s.src[s.pos:], "\\\n") { // line continuation, unescape has no rune for it
+		s.pos += 2
+		s.line++
+	}
+	r, size := utf8.DecodeRuneInString(s.src[s.pos:])
+	if r == '\\' && s.pos+1 < len(s.src) {
+		r, size = rune(unescape(s.src[s.pos+1])[0]), 2
+	}
+	s.pos += size
+	if s.pos < len(s.src) && s.src[s.pos] == '\'' {
+		s.pos++
+	}
+	return int(r)
+}
+
+// textBlock read a @MARK ... MARK text block, the lines between the marks are the text
+func (s *scanner) textBlock() string {
+	s.pos++ // @
+	if s.pos < len(s.src) && s.src[s.pos] == '@' {
+		s.pos++ // @@MARK is an array of the lines, read as the text
+	}
+	start := s.pos
+	for s.pos < len(s.src) && isIdentByte(s.src[s.pos]) {
+		s.pos++
+	}
+	mark := s.src[start:s.pos]
+	if nl := strings.IndexByte(s.src[s.pos:], '\n'); nl >= 0 {
+		s.pos += nl + 1
+		s.line++
+	} else {
+		s.pos = len(s.src)
+	}
+
+	var b strings.Builder
+	for s.pos < len(s.src) {
+		end := strings.IndexByte(s.src[s.pos:], '\n')
+		if end < 0 {
+			end = len(s.src) - s.pos
+		}
+		line := s.src[s.pos : s.pos+end]
+		if trimmed := strings.TrimLeftFunc(line, unicode.IsSpace); mark != "" && strings.HasPrefix(trimmed, mark) &&
+			(len(trimmed) == len(mark) || !isIdentByte(trimmed[len(mark)])) {
+			// the end mark, what follows it on the line is scanned as code
+			s.pos += strings.Index(line, mark) + len(mark)
+			return b.String()
+		}
+		b.WriteString(strings.TrimRight(line, "\r"))
+		b.WriteByte('\n')
+		s.pos += end
+		if s.pos < len(s.src) {
+			s.pos++
+			s.line++
+		}
+	}
+	return b.String()
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
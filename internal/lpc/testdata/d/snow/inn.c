@@ -0,0 +1,28 @@
+// inn.c
+
+#include <room.h>
+
+inherit ROOM;
+
+void create()
+{
+	set("short", "飲風客棧");
+	set("long", @LONG
+這裡是雪亭鎮的飲風客棧，一進門就聞到陣陣酒香。
+西邊是廣場。
+LONG
+	);
+	set("exits", ([ /* sizeof() == 3 */
+		"west" : __DIR__"square",
+		"up" : "/d/snow/inn_2f",
+		"enter" : __DIR__"kitchen",
+	]));
+	set("objects", ([
+		__DIR__"npc/waiter" : 2,
+		__DIR__"obj/sword" : 1,
+	]));
+	set("no_fight", 1);
+
+	setup();
+	replace_program(ROOM);
+}
@@ -0,0 +1,20 @@
+// waiter.c
+
+inherit NPC;
+
+void create()
+{
+	set_name("店小二", ({ "waiter", "xiao er" }) );
+	set("gender", "男性");
+	set("age", 22);
+	set("long", "一個手腳勤快的店小二。\n");
+	set("max_kee", 300);
+	set("kee", 250);
+	set("max_force", 50);
+	set_skill("dodge", 20);
+	set_skill("parry", 10);
+	set_skill("unarmed", 15);
+	set_skill("force", 5);
+	setup();
+	carry_object("/obj/cloth")->wear();
+}
@@ -0,0 +1,22 @@
+// sword.c
+
+#include <weapon.h>
+
+inherit SWORD;
+
+void create()
+{
+	set_name("長劍", ({ "long sword", "sword" }) );
+	set_weight(5000);
+	if( clonep() )
+		set_default_object(__FILE__);
+	else {
+		set("unit", "把");
+		set("long", "一把普通的長劍。\n");
+		set("value", 3 * 100);
+		set("weapon_prop/str", 2);
+		set("weapon_prop/dodge", -1);
+	}
+	init_sword(12);
+	setup();
+}
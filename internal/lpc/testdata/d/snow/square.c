@@ -0,0 +1,18 @@
+#define INN __DIR__"inn"
+
+inherit ROOM;
+
+void create()
+{
+	set("short", "廣場");
+	set("long", "雪亭鎮的廣場，" + "人來人往。\n");
+	set("exits", ([
+		"east" : INN,
+	]));
+	setup();
+}
+
+void init()
+{
+	add_action("do_dig", "dig");
+}
@@ -0,0 +1,62 @@
+package migrate
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ErrDuplicatedID records share an id that a migration makes unique
+var ErrDuplicatedID = errors.New("duplicated ids")
+
+// checks run in the transaction of the up of their version before its sql, so that data the
+// version cannot take stops it with an error telling what to fix instead of the one of the driver
+var checks = map[int]func(tx *gorm.DB) error{
+	9: uniqueIDs,
+}
+
+// uniqueIDs check the mob and item ids before 0009_unique_id adds their unique indexes,
+// the records in the trash included
+func uniqueIDs(tx *gorm.DB) error {
+	var duplicates []string
+	for _, t := range []struct{ table, column string }{{"mob", "mob_id"}, {"item", "item_id"}} {
+		shared := tx.Table(t.table).Select(t.column).Group(t.column).Having("COUNT(*) > 1")
+		rows, err := tx.Table(t.table).Select("id", t.column).Where(t.column+" IN (?)", shared).
+			Order(t.column).Order("id").Rows()
+		if err != nil {
+			return err
+		}
+
+		ids := map[string][]string{}
+		var keys []string
+		for rows.Next() {
+			var id uint64
+			var key string
+			if err = rows.Scan(&id, &key); err != nil {
+				_ = rows.Close()
+				return err
+			}
+			if _, ok := ids[key]; !ok {
+				keys = append(keys, key)
+			}
+			ids[key] = append(ids[key], strconv.FormatUint(id, 10))
+		}
+		_ = rows.Close()
+		if err = rows.Err(); err != nil {
+			return err
+		}
+		for _, key := range keys {
+			duplicates = append(duplicates, fmt.Sprintf("%s %q of the %ss %s", t.column, key, t.table, strings.Join(ids[key], ", ")))
+		}
+	}
+
+	if len(duplicates) > 0 {
+		return fmt.Errorf("%w: %s, the records in the trash included. Give each record its own id through the api, "+
+			"or purge the ones in the trash with fs trash purge -days 0, then migrate again",
+			ErrDuplicatedID, strings.Join(duplicates, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+// Package migrate applies the versioned schema migrations embedded in the binary.
+// The migrations of a database are the files sql/<dialect>/<version>_<name>.up.sql and
+// .down.sql, the versions that were applied are recorded in the schema_version table.
+// Statements of a file end with a ; at the end of a line. A version may have a check of the
+// data that runs before its up, see checks.
+package migrate
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:embed sql
+var files embed.FS
+
+// ErrUnknownVersion a version was applied to the database that the binary has no migration of
+var ErrUnknownVersion = errors.New("unknown schema version")
+
+// Migration a version of the schema
+type Migration struct {
+	Version int
+	Name    string
+	Up      string // sql that applies the version
+	Down    string // sql that reverts it
+}
+
+// State of a version in a database
+type State struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	AppliedAt *time.Time `json:"appliedAt"` // nil if pending
+	Unknown   bool       `json:"unknown"`   // applied but not in the binary
+}
+
+// schemaVersion a row of the schema_version table
+type schemaVersion struct {
+	Version   int       `gorm:"column:version;primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"column:name;type:varchar(100);not null"`
+	AppliedAt time.Time `gorm:"column:applied_at;not null"`
+}
+
+// TableName table name
+func (m *schemaVersion) TableName() string {
+	return "schema_version"
+}
+
+// Migrations the migrations of a dialect (mysql, postgres or sqlite) in version order
+func Migrations(dialect string) ([]Migration, error) {
+	dir := path.Join("sql", dialect)
+	entries, err := fs.ReadDir(files, dir)
+	if err != nil {
+		return nil, fmt.Errorf("no migrations of %s", dialect)
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		base, direction, ok := cutDirection(entry.Name())
+		versionText, name, found := strings.Cut(base, "_")
+		version, err := strconv.Atoi(versionText)
+		if !ok || !found || err != nil {
+			return nil, fmt.Errorf("migration file name %s is not <version>_<name>.up.sql or .down.sql", entry.Name())
+		}
+		data, err := files.ReadFile(path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		m := byVersion[version]
+		if m == nil {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		}
+		if direction == "up" {
+			m.Up = string(data)
+		} else {
+			m.Down = string(data)
+		}
+	}
+
+	list := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %04d_%s of %s misses the up or the down file", m.Version, m.Name, dialect)
+		}
+		list = append(list, *m)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+	return list, nil
+}
+
+func cutDirection(fileName string) (base string, direction string, ok bool) {
+	for _, direction = range []string{"up", "down"} {
+		if base, ok = strings.CutSuffix(fileName, "."+direction+".sql"); ok {
+			return base, direction, true
+		}
+	}
+	return "", "", false
+}
+
+// Up apply the pending migrations in version order, at most steps of them if steps > 0,
+// the migrations that were applied are returned
+func Up(db *gorm.DB, steps int) ([]Migration, error) {
+	migrations, applied, err := load(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, m := range migrations {
+		if steps > 0 && len(done) == steps {
+			break
+		}
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if check, ok := checks[m.Version]; ok {
+				if err := check(tx); err != nil {
+					return err
+				}
+			}
+			if err := exec(tx, m.Up); err != nil {
+				return err
+			}
+			return tx.Create(&schemaVersion{Version: m.Version, Name: m.Name, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("migration %04d_%s up: %w", m.Version, m.Name, err)
+		}
+		done = append(done, m)
+	}
+	return done, nil
+}
+
+// Down revert the applied migrations from the latest version, steps of them or all
+// of them if steps <= 0, the migrations that were reverted are returned
+func Down(db *gorm.DB, steps int) ([]Migration, error) {
+	migrations, applied, err := load(db)
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[int]Migration, len(migrations))
+	for _, m := range migrations {
+		byVersion[m.Version] = m
+	}
+	versions := make([]int, 0, len(applied))
+	for version := range applied {
+		versions = append(versions, version)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(versions)))
+
+	var done []Migration
+	for _, version := range versions {
+		if steps > 0 && len(done) == steps {
+			break
+		}
+		m, ok := byVersion[version]
+		if !ok {
+			return done, fmt.Errorf("%w %d (%s)", ErrUnknownVersion, version, applied[version].Name)
+		}
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := exec(tx, m.Down); err != nil {
+				return err
+			}
+			return tx.Where("version = ?", m.Version).Delete(&schemaVersion{}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("migration %04d_%s down: %v", m.Version, m.Name, err)
+		}
+		done = append(done, m)
+	}
+	return done, nil
+}
+
+// Status the versions of the binary and of the database in version order
+func Status(db *gorm.DB) ([]State, error) {
+	migrations, applied, err := load(db)
+	if err != nil {
+		return nil, err
+	}
+
+	states := make([]State, 0, len(migrations))
+	for _, m := range migrations {
+		state := State{Version: m.Version, Name: m.Name}
+		if v, ok := applied[m.Version]; ok {
+			state.AppliedAt = &v.AppliedAt
+			delete(applied, m.Version)
+		}
+		states = append(states, state)
+	}
+	for _, v := range applied {
+		states = append(states, State{Version: v.Version, Name: v.Name, AppliedAt: &v.AppliedAt, Unknown: true})
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Version < states[j].Version })
+	return states, nil
+}
+
+// load the migrations of the database of db and the versions applied to it,
+// the schema_version table is created if it does not exist
+func load(db *gorm.DB) ([]Migration, map[int]schemaVersion, error) {
+	migrations, err := Migrations(db.Dialector.Name())
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = db.AutoMigrate(&schemaVersion{}); err != nil {
+		return nil, nil, err
+	}
+	var rows []schemaVersion
+	if err = db.Find(&rows).Error; err != nil {
+		return nil, nil, err
+	}
+	applied := make(map[int]schemaVersion, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+	return migrations, applied, nil
+}
+
+// exec run the statements of a migration file one by one, as not every driver
+// accepts several statements in one call
+func exec(tx *gorm.DB, sql string) error {
+	for _, stmt := range strings.SplitAfter(sql, ";\n") {
+		if stmt = strings.TrimSpace(stmt); stmt == "" {
+			continue
+		}
+		if err := tx.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
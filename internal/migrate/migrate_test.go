@@ -0,0 +1,155 @@
+package migrate_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/go-dev-frame/sponge/pkg/sgorm"
+	"github.com/go-dev-frame/sponge/pkg/sgorm/sqlite"
+
+	"fs/internal/database"
+	"fs/internal/migrate"
+)
+
+func newSqliteDB(t *testing.T) *sgorm.DB {
+	db, err := sqlite.Init(filepath.Join(t.TempDir(), "fs.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = sgorm.CloseDB(db) })
+	return db
+}
+
+func TestMigrations(t *testing.T) {
+	sqliteMigrations, err := migrate.Migrations("sqlite")
+	require.NoError(t, err)
+	require.NotEmpty(t, sqliteMigrations)
+	assert.Equal(t, 1, sqliteMigrations[0].Version)
+	assert.Equal(t, "baseline", sqliteMigrations[0].Name)
+
+	// every database has the same versions
+	for _, dialect := range []string{"mysql", "postgres"} {
+		migrations, err := migrate.Migrations(dialect)
+		require.NoError(t, err)
+		require.Len(t, migrations, len(sqliteMigrations), dialect)
+		for i, m := range migrations {
+			assert.Equal(t, sqliteMigrations[i].Version, m.Version, dialect)
+			assert.Equal(t, sqliteMigrations[i].Name, m.Name, dialect)
+		}
+	}
+
+	_, err = migrate.Migrations("mongodb")
+	assert.Error(t, err)
+}
+
+func TestUpDown(t *testing.T) {
+	db := newSqliteDB(t)
+	migrations, err := migrate.Migrations("sqlite")
+	require.NoError(t, err)
+
+	states, err := migrate.Status(db)
+	require.NoError(t, err)
+	require.Len(t, states, len(migrations))
+	for _, s := range states {
+		assert.Nil(t, s.AppliedAt)
+	}
+
+	// the baseline has the room, mob and item tables
+	done, err := migrate.Up(db, 1)
+	require.NoError(t, err)
+	require.Len(t, done, 1)
+	assert.Equal(t, "baseline", done[0].Name)
+	for _, table := range []string{"room", "mob", "item"} {
+		assert.True(t, db.Migrator().HasTable(table), table)
+	}
+	assert.False(t, db.Migrator().HasTable("room_exit"))
+
+	done, err = migrate.Up(db, 0)
+	require.NoError(t, err)
+	assert.Len(t, done, len(migrations)-1)
+	done, err = migrate.Up(db, 0)
+	require.NoError(t, err)
+	assert.Empty(t, done)
+
+	// the tables have the columns and indexes of the models
+	for _, m := range database.Models {
+		stmt := &gorm.Statement{DB: db}
+		require.NoError(t, stmt.Parse(m))
+		require.True(t, db.Migrator().HasTable(m), stmt.Table)
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName != "" {
+				assert.True(t, db.Migrator().HasColumn(m, field.DBName), stmt.Table+"."+field.DBName)
+			}
+		}
+		for _, index := range stmt.Schema.ParseIndexes() {
+			assert.True(t, db.Migrator().HasIndex(m, index.Name), stmt.Table+" "+index.Name)
+		}
+	}
+
+	states, err = migrate.Status(db)
+	require.NoError(t, err)
+	for _, s := range states {
+		assert.NotNil(t, s.AppliedAt, s.Name)
+	}
+
+	done, err = migrate.Down(db, 1)
+	require.NoError(t, err)
+	require.Len(t, done, 1)
+	assert.Equal(t, migrations[len(migrations)-1].Version, done[0].Version)
+	states, err = migrate.Status(db)
+	require.NoError(t, err)
+	assert.Nil(t, states[len(states)-1].AppliedAt)
+
+	done, err = migrate.Down(db, 0)
+	require.NoError(t, err)
+	assert.Len(t, done, len(migrations)-1)
+	for _, m := range database.Models {
+		assert.False(t, db.Migrator().HasTable(m))
+	}
+}
+
+func TestDown_unknownVersion(t *testing.T) {
+	db := newSqliteDB(t)
+	_, err := migrate.Up(db, 0)
+	require.NoError(t, err)
+	require.NoError(t, db.Exec("INSERT INTO schema_version (version, name, applied_at) VALUES (?, ?, ?)",
+		9999, "from_a_newer_binary", time.Now()).Error)
+
+	states, err := migrate.Status(db)
+	require.NoError(t, err)
+	last := states[len(states)-1]
+	assert.Equal(t, 9999, last.Version)
+	assert.True(t, last.Unknown)
+
+	_, err = migrate.Down(db, 1)
+	assert.ErrorIs(t, err, migrate.ErrUnknownVersion)
+}
+
+func TestUp_duplicatedIDs(t *testing.T) {
+	db := newSqliteDB(t)
+	_, err := migrate.Up(db, 8)
+	require.NoError(t, err)
+	for _, stmt := range []string{
+		`INSERT INTO mob (id, mob_id, mob_name, mob_cname) VALUES (1, 'rat', 'rat', 'rat'), (2, 'cat', 'cat', 'cat')`,
+		`INSERT INTO mob (id, mob_id, mob_name, mob_cname, deleted_at) VALUES (3, 'rat', 'rat', 'rat', CURRENT_TIMESTAMP)`,
+		`INSERT INTO item (id, item_id, item_name) VALUES (1, 'ring', 'ring'), (2, 'ring', 'ring'), (3, 'ring', 'ring')`,
+	} {
+		require.NoError(t, db.Exec(stmt).Error)
+	}
+
+	// the ids to fix are reported instead of the error of the unique index, the trash included
+	done, err := migrate.Up(db, 0)
+	assert.ErrorIs(t, err, migrate.ErrDuplicatedID)
+	assert.Empty(t, done)
+	assert.Contains(t, err.Error(), `mob_id "rat" of the mobs 1, 3; item_id "ring" of the items 1, 2, 3`)
+
+	require.NoError(t, db.Exec(`UPDATE mob SET mob_id = 'old_rat' WHERE id = 3`).Error)
+	require.NoError(t, db.Exec(`DELETE FROM item WHERE id > 1`).Error)
+	done, err = migrate.Up(db, 0)
+	require.NoError(t, err)
+	require.NotEmpty(t, done)
+	assert.Equal(t, "unique_id", done[0].Name)
+}
@@ -0,0 +1,44 @@
+CREATE TABLE IF NOT EXISTS `room` (
+  `id` varchar(50) NOT NULL,
+  `title` varchar(30) NOT NULL,
+  `desc` text,
+  `way` varchar(30),
+  `mobs` varchar(256),
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `mob` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `mob_id` varchar(50) NOT NULL,
+  `mob_name` varchar(50) NOT NULL,
+  `mob_cname` varchar(50) NOT NULL,
+  `mob_desc` text,
+  `attackable` tinyint(1),
+  `hp` int(11) NOT NULL DEFAULT 100,
+  `mp` int(11) NOT NULL DEFAULT 100,
+  `attack` int(11) NOT NULL DEFAULT 1,
+  `defence` int(11) NOT NULL DEFAULT 1,
+  `dodge` int(11) NOT NULL DEFAULT 1,
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `item` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `item_id` varchar(50) NOT NULL,
+  `item_name` varchar(50) NOT NULL,
+  `item_cname` varchar(50),
+  `item_desc` text,
+  `hp` int(11),
+  `mp` int(11),
+  `attack` int(11),
+  `defence` int(11),
+  `dodge` int(11),
+  `str` int(11),
+  `cor` int(11),
+  `inte` int(11),
+  `dex` int(11),
+  `con` int(11),
+  `kar` int(11),
+  `classifier` varchar(1),
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS `room_exit` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `room_id` varchar(50) NOT NULL,
+  `direction` varchar(10) NOT NULL,
+  `to_room_id` varchar(50) NOT NULL,
+  `door` tinyint(1),
+  `locked` tinyint(1),
+  `hidden` tinyint(1),
+  `cost` int(11) NOT NULL DEFAULT 1,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_room_direction` (`room_id`, `direction`),
+  KEY `idx_room_exit_to_room_id` (`to_room_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
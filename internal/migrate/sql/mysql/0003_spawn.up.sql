@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS `spawn` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `room_id` varchar(50) NOT NULL,
+  `mob_id` bigint(20) NOT NULL,
+  `max_count` int(11) NOT NULL DEFAULT 1,
+  `respawn_seconds` int(11) NOT NULL DEFAULT 300,
+  `chance` int(11) NOT NULL DEFAULT 100,
+  PRIMARY KEY (`id`),
+  KEY `idx_spawn_room_id` (`room_id`),
+  KEY `idx_spawn_mob_id` (`mob_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS `item_instance` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `proto_id` bigint(20) NOT NULL,
+  `location_type` varchar(10) NOT NULL,
+  `owner_id` varchar(50) NOT NULL,
+  `durability` int(11) NOT NULL DEFAULT 100,
+  `overrides` json,
+  PRIMARY KEY (`id`),
+  KEY `idx_item_instance_proto_id` (`proto_id`),
+  KEY `idx_item_instance_location` (`location_type`, `owner_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
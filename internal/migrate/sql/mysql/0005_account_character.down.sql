@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS `character`;
+DROP TABLE IF EXISTS `account`;
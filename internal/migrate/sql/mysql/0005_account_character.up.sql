@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS `account` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `username` varchar(50) NOT NULL,
+  `password_hash` varchar(100) NOT NULL,
+  `role` varchar(10) NOT NULL DEFAULT 'player',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_account_username` (`username`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS `character` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `account_id` bigint(20) NOT NULL,
+  `name` varchar(20) NOT NULL,
+  `room_id` varchar(50),
+  `hp` int(11) NOT NULL DEFAULT 100,
+  `mp` int(11) NOT NULL DEFAULT 100,
+  `str` int(11) NOT NULL DEFAULT 10,
+  `cor` int(11) NOT NULL DEFAULT 10,
+  `inte` int(11) NOT NULL DEFAULT 10,
+  `dex` int(11) NOT NULL DEFAULT 10,
+  `con` int(11) NOT NULL DEFAULT 10,
+  `kar` int(11) NOT NULL DEFAULT 10,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_character_name` (`name`),
+  KEY `idx_character_account_id` (`account_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS `revision` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `entity_type` varchar(10) NOT NULL,
+  `entity_id` varchar(50) NOT NULL,
+  `action` varchar(10) NOT NULL,
+  `actor` varchar(50),
+  `created_at` datetime(3) NOT NULL,
+  `before_data` text,
+  `after_data` text,
+  PRIMARY KEY (`id`),
+  KEY `idx_revision_entity` (`entity_type`, `entity_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,6 @@
+DROP INDEX `idx_room_deleted_at` ON `room`;
+ALTER TABLE `room` DROP COLUMN `deleted_at`;
+DROP INDEX `idx_mob_deleted_at` ON `mob`;
+ALTER TABLE `mob` DROP COLUMN `deleted_at`;
+DROP INDEX `idx_item_deleted_at` ON `item`;
+ALTER TABLE `item` DROP COLUMN `deleted_at`;
@@ -0,0 +1,3 @@
+ALTER TABLE `room` ADD COLUMN `version` int(11) NOT NULL DEFAULT 1;
+ALTER TABLE `mob` ADD COLUMN `version` int(11) NOT NULL DEFAULT 1;
+ALTER TABLE `item` ADD COLUMN `version` int(11) NOT NULL DEFAULT 1;
@@ -0,0 +1,2 @@
+ALTER TABLE `mob` DROP INDEX `idx_mob_mob_id`;
+ALTER TABLE `item` DROP INDEX `idx_item_item_id`;
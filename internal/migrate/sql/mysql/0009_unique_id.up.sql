@@ -0,0 +1,2 @@
+ALTER TABLE `mob` ADD UNIQUE KEY `idx_mob_mob_id` (`mob_id`);
+ALTER TABLE `item` ADD UNIQUE KEY `idx_item_item_id` (`item_id`);
@@ -0,0 +1,44 @@
+CREATE TABLE IF NOT EXISTS "room" (
+  "id" varchar(50) NOT NULL,
+  "title" varchar(30) NOT NULL,
+  "desc" text,
+  "way" varchar(30),
+  "mobs" varchar(256),
+  PRIMARY KEY ("id")
+);
+
+CREATE TABLE IF NOT EXISTS "mob" (
+  "id" bigserial,
+  "mob_id" varchar(50) NOT NULL,
+  "mob_name" varchar(50) NOT NULL,
+  "mob_cname" varchar(50) NOT NULL,
+  "mob_desc" text,
+  "attackable" smallint,
+  "hp" integer NOT NULL DEFAULT 100,
+  "mp" integer NOT NULL DEFAULT 100,
+  "attack" integer NOT NULL DEFAULT 1,
+  "defence" integer NOT NULL DEFAULT 1,
+  "dodge" integer NOT NULL DEFAULT 1,
+  PRIMARY KEY ("id")
+);
+
+CREATE TABLE IF NOT EXISTS "item" (
+  "id" bigserial,
+  "item_id" varchar(50) NOT NULL,
+  "item_name" varchar(50) NOT NULL,
+  "item_cname" varchar(50),
+  "item_desc" text,
+  "hp" integer,
+  "mp" integer,
+  "attack" integer,
+  "defence" integer,
+  "dodge" integer,
+  "str" integer,
+  "cor" integer,
+  "inte" integer,
+  "dex" integer,
+  "con" integer,
+  "kar" integer,
+  "classifier" varchar(1),
+  PRIMARY KEY ("id")
+);
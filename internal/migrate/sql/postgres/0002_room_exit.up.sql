@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS "room_exit" (
+  "id" bigserial,
+  "room_id" varchar(50) NOT NULL,
+  "direction" varchar(10) NOT NULL,
+  "to_room_id" varchar(50) NOT NULL,
+  "door" smallint,
+  "locked" smallint,
+  "hidden" smallint,
+  "cost" integer NOT NULL DEFAULT 1,
+  PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_room_direction" ON "room_exit" ("room_id", "direction");
+
+CREATE INDEX IF NOT EXISTS "idx_room_exit_to_room_id" ON "room_exit" ("to_room_id");
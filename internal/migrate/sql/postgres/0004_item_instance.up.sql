@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS "item_instance" (
+  "id" bigserial,
+  "proto_id" bigint NOT NULL,
+  "location_type" varchar(10) NOT NULL,
+  "owner_id" varchar(50) NOT NULL,
+  "durability" integer NOT NULL DEFAULT 100,
+  "overrides" json,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "idx_item_instance_proto_id" ON "item_instance" ("proto_id");
+
+CREATE INDEX IF NOT EXISTS "idx_item_instance_location" ON "item_instance" ("location_type", "owner_id");
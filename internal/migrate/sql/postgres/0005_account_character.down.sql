@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "character";
+
+DROP TABLE IF EXISTS "account";
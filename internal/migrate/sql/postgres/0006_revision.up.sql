@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS "revision" (
+  "id" bigserial,
+  "entity_type" varchar(10) NOT NULL,
+  "entity_id" varchar(50) NOT NULL,
+  "action" varchar(10) NOT NULL,
+  "actor" varchar(50),
+  "created_at" timestamptz NOT NULL,
+  "before_data" text,
+  "after_data" text,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "idx_revision_entity" ON "revision" ("entity_type", "entity_id");
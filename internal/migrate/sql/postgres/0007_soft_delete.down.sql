@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS "idx_room_deleted_at";
+ALTER TABLE "room" DROP COLUMN IF EXISTS "deleted_at";
+DROP INDEX IF EXISTS "idx_mob_deleted_at";
+ALTER TABLE "mob" DROP COLUMN IF EXISTS "deleted_at";
+DROP INDEX IF EXISTS "idx_item_deleted_at";
+ALTER TABLE "item" DROP COLUMN IF EXISTS "deleted_at";
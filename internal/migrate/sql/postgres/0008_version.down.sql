@@ -0,0 +1,3 @@
+ALTER TABLE "room" DROP COLUMN IF EXISTS "version";
+ALTER TABLE "mob" DROP COLUMN IF EXISTS "version";
+ALTER TABLE "item" DROP COLUMN IF EXISTS "version";
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS "idx_mob_mob_id";
+DROP INDEX IF EXISTS "idx_item_item_id";
@@ -0,0 +1,3 @@
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_mob_mob_id" ON "mob" ("mob_id");
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_item_item_id" ON "item" ("item_id");
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS "spawn" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "room_id" varchar(50) NOT NULL,
+  "mob_id" bigint NOT NULL,
+  "max_count" integer NOT NULL DEFAULT 1,
+  "respawn_seconds" integer NOT NULL DEFAULT 300,
+  "chance" integer NOT NULL DEFAULT 100
+);
+
+CREATE INDEX IF NOT EXISTS "idx_spawn_room_id" ON "spawn" ("room_id");
+
+CREATE INDEX IF NOT EXISTS "idx_spawn_mob_id" ON "spawn" ("mob_id");
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS "account" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "username" varchar(50) NOT NULL,
+  "password_hash" varchar(100) NOT NULL,
+  "role" varchar(10) NOT NULL DEFAULT 'player'
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_account_username" ON "account" ("username");
+
+CREATE TABLE IF NOT EXISTS "character" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "account_id" bigint NOT NULL,
+  "name" varchar(20) NOT NULL,
+  "room_id" varchar(50),
+  "hp" integer NOT NULL DEFAULT 100,
+  "mp" integer NOT NULL DEFAULT 100,
+  "str" integer NOT NULL DEFAULT 10,
+  "cor" integer NOT NULL DEFAULT 10,
+  "inte" integer NOT NULL DEFAULT 10,
+  "dex" integer NOT NULL DEFAULT 10,
+  "con" integer NOT NULL DEFAULT 10,
+  "kar" integer NOT NULL DEFAULT 10
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_character_name" ON "character" ("name");
+
+CREATE INDEX IF NOT EXISTS "idx_character_account_id" ON "character" ("account_id");
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS "revision" (
+  "id" integer PRIMARY KEY AUTOINCREMENT,
+  "entity_type" varchar(10) NOT NULL,
+  "entity_id" varchar(50) NOT NULL,
+  "action" varchar(10) NOT NULL,
+  "actor" varchar(50),
+  "created_at" datetime NOT NULL,
+  "before_data" text,
+  "after_data" text
+);
+
+CREATE INDEX IF NOT EXISTS "idx_revision_entity" ON "revision" ("entity_type", "entity_id");
@@ -0,0 +1,6 @@
+ALTER TABLE "room" ADD COLUMN "deleted_at" datetime;
+CREATE INDEX IF NOT EXISTS "idx_room_deleted_at" ON "room" ("deleted_at");
+ALTER TABLE "mob" ADD COLUMN "deleted_at" datetime;
+CREATE INDEX IF NOT EXISTS "idx_mob_deleted_at" ON "mob" ("deleted_at");
+ALTER TABLE "item" ADD COLUMN "deleted_at" datetime;
+CREATE INDEX IF NOT EXISTS "idx_item_deleted_at" ON "item" ("deleted_at");
@@ -0,0 +1,3 @@
+ALTER TABLE "room" DROP COLUMN "version";
+ALTER TABLE "mob" DROP COLUMN "version";
+ALTER TABLE "item" DROP COLUMN "version";
@@ -0,0 +1,21 @@
+package model
+
+// Account a login of a player, the password is stored as a bcrypt hash
+type Account struct {
+	ID           uint64 `gorm:"column:id;type:bigint(20);primary_key;AUTO_INCREMENT" json:"id"`
+	Username     string `gorm:"column:username;type:varchar(50);not null;uniqueIndex" json:"username"`
+	PasswordHash string `gorm:"column:password_hash;type:varchar(100);not null" json:"passwordHash"`
+	Role         string `gorm:"column:role;type:varchar(10);default:player;not null" json:"role"` // player, builder or admin, see auth.Roles
+}
+
+// TableName table name
+func (m *Account) TableName() string {
+	return "account"
+}
+
+// AccountColumnNames Whitelist for custom query fields to prevent sql injection attacks
+var AccountColumnNames = map[string]bool{
+	"id":       true,
+	"username": true,
+	"role":     true,
+}
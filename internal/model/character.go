@@ -0,0 +1,39 @@
+package model
+
+// Character a player character, the items it carries are item instances located at
+// ItemLocationPlayer with the character name as owner
+type Character struct {
+	ID        uint64 `gorm:"column:id;type:bigint(20);primary_key;AUTO_INCREMENT" json:"id"`
+	AccountID uint64 `gorm:"column:account_id;type:bigint(20);not null;index" json:"accountID"`
+	Name      string `gorm:"column:name;type:varchar(20);not null;uniqueIndex" json:"name"`
+	RoomID    string `gorm:"column:room_id;type:varchar(50)" json:"roomID"` // room the character was in when last saved
+	Hp        int    `gorm:"column:hp;type:int(11);default:100;not null" json:"hp"`
+	Mp        int    `gorm:"column:mp;type:int(11);default:100;not null" json:"mp"`
+	Str       int    `gorm:"column:str;type:int(11);default:10;not null" json:"str"`
+	Cor       int    `gorm:"column:cor;type:int(11);default:10;not null" json:"cor"`
+	Inte      int    `gorm:"column:inte;type:int(11);default:10;not null" json:"inte"`
+	Dex       int    `gorm:"column:dex;type:int(11);default:10;not null" json:"dex"`
+	Con       int    `gorm:"column:con;type:int(11);default:10;not null" json:"con"`
+	Kar       int    `gorm:"column:kar;type:int(11);default:10;not null" json:"kar"`
+}
+
+// TableName table name
+func (m *Character) TableName() string {
+	return "character"
+}
+
+// CharacterColumnNames Whitelist for custom query fields to prevent sql injection attacks
+var CharacterColumnNames = map[string]bool{
+	"id":         true,
+	"account_id": true,
+	"name":       true,
+	"room_id":    true,
+	"hp":         true,
+	"mp":         true,
+	"str":        true,
+	"cor":        true,
+	"inte":       true,
+	"dex":        true,
+	"con":        true,
+	"kar":        true,
+}
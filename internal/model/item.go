@@ -2,7 +2,7 @@ package model
 
 type Item struct {
 	ID         uint64 `gorm:"column:id;type:bigint(20);primary_key;AUTO_INCREMENT" json:"id"`
-	ItemID     string `gorm:"column:item_id;type:varchar(50);not null" json:"itemID"`
+	ItemID     string `gorm:"column:item_id;type:varchar(50);not null;uniqueIndex:idx_item_item_id" json:"itemID"`
 	ItemName   string `gorm:"column:item_name;type:varchar(50);not null" json:"itemName"`
 	ItemCname  string `gorm:"column:item_cname;type:varchar(50)" json:"itemCname"`
 	ItemDesc   string `gorm:"column:item_desc;type:text" json:"itemDesc"`
@@ -18,6 +18,9 @@ type Item struct {
 	Con        int    `gorm:"column:con;type:int(11)" json:"con"`
 	Kar        int    `gorm:"column:kar;type:int(11)" json:"kar"`
 	Classifier string `gorm:"column:classifier;type:varchar(1)" json:"classifier"`
+	Version    int    `gorm:"column:version;type:int(11);default:1;not null" json:"version,omitempty"` // bumped by every update, see UpdateByID
+
+	Trash
 }
 
 // TableName table name
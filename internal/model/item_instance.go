@@ -0,0 +1,72 @@
+package model
+
+// item instance location types, what OwnerID refers to
+const (
+	ItemLocationRoom      = "room"      // room.id
+	ItemLocationMob       = "mob"       // mob.id, carried by every mob made from the prototype
+	ItemLocationPlayer    = "player"    // player name
+	ItemLocationContainer = "container" // item_instance.id of the container
+)
+
+// ItemLocationTypes valid values of ItemInstance.LocationType
+var ItemLocationTypes = []string{ItemLocationRoom, ItemLocationMob, ItemLocationPlayer, ItemLocationContainer}
+
+// ItemInstance a single object made from an item prototype, e.g. the sword lying in a room
+type ItemInstance struct {
+	ID           uint64             `gorm:"column:id;type:bigint(20);primary_key;AUTO_INCREMENT" json:"id"`
+	ProtoID      uint64             `gorm:"column:proto_id;type:bigint(20);not null;index" json:"protoID"` // item.id of the prototype
+	LocationType string             `gorm:"column:location_type;type:varchar(10);not null;index:idx_item_instance_location" json:"locationType"`
+	OwnerID      string             `gorm:"column:owner_id;type:varchar(50);not null;index:idx_item_instance_location" json:"ownerID"`
+	Durability   *int               `gorm:"column:durability;type:int(11);default:100;not null" json:"durability"` // nil is 100 on create, 0 is broken
+	Overrides    *ItemStatOverrides `gorm:"column:overrides;type:json;serializer:json" json:"overrides"`           // stats that differ from the prototype
+}
+
+// ItemStatOverrides stats of an instance that replace the prototype stats, nil fields are not overridden
+type ItemStatOverrides struct {
+	Hp      *int `json:"hp,omitempty"`
+	Mp      *int `json:"mp,omitempty"`
+	Attack  *int `json:"attack,omitempty"`
+	Defence *int `json:"defence,omitempty"`
+	Dodge   *int `json:"dodge,omitempty"`
+	Str     *int `json:"str,omitempty"`
+	Cor     *int `json:"cor,omitempty"`
+	Inte    *int `json:"inte,omitempty"`
+	Dex     *int `json:"dex,omitempty"`
+	Con     *int `json:"con,omitempty"`
+	Kar     *int `json:"kar,omitempty"`
+}
+
+// Apply a copy of the prototype with the overrides written over its stats
+func (o *ItemStatOverrides) Apply(proto *Item) *Item {
+	item := *proto
+	if o == nil {
+		return &item
+	}
+	for _, f := range []struct {
+		v   *int
+		dst *int
+	}{
+		{o.Hp, &item.Hp}, {o.Mp, &item.Mp}, {o.Attack, &item.Attack}, {o.Defence, &item.Defence},
+		{o.Dodge, &item.Dodge}, {o.Str, &item.Str}, {o.Cor, &item.Cor}, {o.Inte, &item.Inte},
+		{o.Dex, &item.Dex}, {o.Con, &item.Con}, {o.Kar, &item.Kar},
+	} {
+		if f.v != nil {
+			*f.dst = *f.v
+		}
+	}
+	return &item
+}
+
+// TableName table name
+func (m *ItemInstance) TableName() string {
+	return "item_instance"
+}
+
+// ItemInstanceColumnNames Whitelist for custom query fields to prevent sql injection attacks
+var ItemInstanceColumnNames = map[string]bool{
+	"id":            true,
+	"proto_id":      true,
+	"location_type": true,
+	"owner_id":      true,
+	"durability":    true,
+}
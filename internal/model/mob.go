@@ -6,7 +6,7 @@ import (
 
 type Mob struct {
 	ID         uint64          `gorm:"column:id;type:bigint(20);primary_key;AUTO_INCREMENT" json:"id"`
-	MobID      string          `gorm:"column:mob_id;type:varchar(50);not null" json:"mobID"`
+	MobID      string          `gorm:"column:mob_id;type:varchar(50);not null;uniqueIndex:idx_mob_mob_id" json:"mobID"`
 	MobName    string          `gorm:"column:mob_name;type:varchar(50);not null" json:"mobName"`
 	MobCname   string          `gorm:"column:mob_cname;type:varchar(50);not null" json:"mobCname"`
 	MobDesc    string          `gorm:"column:mob_desc;type:text" json:"mobDesc"`
@@ -16,6 +16,9 @@ type Mob struct {
 	Attack     int             `gorm:"column:attack;type:int(11);default:1;not null" json:"attack"`
 	Defence    int             `gorm:"column:defence;type:int(11);default:1;not null" json:"defence"`
 	Dodge      int             `gorm:"column:dodge;type:int(11);default:1;not null" json:"dodge"`
+	Version    int             `gorm:"column:version;type:int(11);default:1;not null" json:"version,omitempty"` // bumped by every update, see UpdateByID
+
+	Trash
 }
 
 // TableName table name
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"time"
+)
+
+// Revision a create, update or delete of a room, mob or item, Before and After are
+// the json of the record, empty when the record does not exist
+type Revision struct {
+	ID         uint64    `gorm:"column:id;type:bigint(20);primary_key;AUTO_INCREMENT" json:"id"`
+	EntityType string    `gorm:"column:entity_type;type:varchar(10);not null;index:idx_revision_entity" json:"entityType"` // room, mob or item
+	EntityID   string    `gorm:"column:entity_id;type:varchar(50);not null;index:idx_revision_entity" json:"entityID"`
+	Action     string    `gorm:"column:action;type:varchar(10);not null" json:"action"` // create, update or delete
+	Actor      string    `gorm:"column:actor;type:varchar(50)" json:"actor"`            // account name, empty if not known
+	CreatedAt  time.Time `gorm:"column:created_at;not null" json:"createdAt"`
+	Before     string    `gorm:"column:before_data;type:text" json:"before"`
+	After      string    `gorm:"column:after_data;type:text" json:"after"`
+}
+
+// TableName table name
+func (m *Revision) TableName() string {
+	return "revision"
+}
+
+// RevisionColumnNames Whitelist for custom query fields to prevent sql injection attacks
+var RevisionColumnNames = map[string]bool{
+	"id":          true,
+	"entity_type": true,
+	"entity_id":   true,
+	"action":      true,
+	"actor":       true,
+	"created_at":  true,
+}
@@ -1,11 +1,14 @@
 package model
 
 type Room struct {
-	ID    string `gorm:"column:id;type:varchar(50);primary_key" json:"id"`
-	Title string `gorm:"column:title;type:varchar(30);not null" json:"title"`
-	Desc  string `gorm:"column:desc;type:text" json:"desc"`
-	Way   string `gorm:"column:way;type:varchar(30)" json:"way"`
-	Mobs  string `gorm:"column:mobs;type:varchar(256)" json:"mobs"`
+	ID      string `gorm:"column:id;type:varchar(50);primary_key" json:"id"`
+	Title   string `gorm:"column:title;type:varchar(30);not null" json:"title"`
+	Desc    string `gorm:"column:desc;type:text" json:"desc"`
+	Way     string `gorm:"column:way;type:varchar(30)" json:"way"`
+	Mobs    string `gorm:"column:mobs;type:varchar(256)" json:"mobs"`
+	Version int    `gorm:"column:version;type:int(11);default:1;not null" json:"version,omitempty"` // bumped by every update, see UpdateByID
+
+	Trash
 }
 
 // TableName table name
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"github.com/go-dev-frame/sponge/pkg/sgorm"
+)
+
+// RoomExit an exit leading from a room to another room in a direction
+type RoomExit struct {
+	ID        uint64          `gorm:"column:id;type:bigint(20);primary_key;AUTO_INCREMENT" json:"id"`
+	RoomID    string          `gorm:"column:room_id;type:varchar(50);not null;uniqueIndex:idx_room_direction" json:"roomID"`
+	Direction string          `gorm:"column:direction;type:varchar(10);not null;uniqueIndex:idx_room_direction" json:"direction"`
+	ToRoomID  string          `gorm:"column:to_room_id;type:varchar(50);not null;index" json:"toRoomID"`
+	Door      *sgorm.TinyBool `gorm:"column:door;type:tinyint(1)" json:"door"`
+	Locked    *sgorm.TinyBool `gorm:"column:locked;type:tinyint(1)" json:"locked"`
+	Hidden    *sgorm.TinyBool `gorm:"column:hidden;type:tinyint(1)" json:"hidden"`
+	Cost      int             `gorm:"column:cost;type:int(11);not null;default:1" json:"cost"` // travel cost for weighted path finding
+}
+
+// TableName table name
+func (m *RoomExit) TableName() string {
+	return "room_exit"
+}
+
+// RoomExitColumnNames Whitelist for custom query fields to prevent sql injection attacks
+var RoomExitColumnNames = map[string]bool{
+	"id":         true,
+	"room_id":    true,
+	"direction":  true,
+	"to_room_id": true,
+	"door":       true,
+	"locked":     true,
+	"hidden":     true,
+	"cost":       true,
+}
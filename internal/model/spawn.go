@@ -0,0 +1,26 @@
+package model
+
+// Spawn how many of a mob prototype live in a room and how they come back after being killed
+type Spawn struct {
+	ID             uint64 `gorm:"column:id;type:bigint(20);primary_key;AUTO_INCREMENT" json:"id"`
+	RoomID         string `gorm:"column:room_id;type:varchar(50);not null;index" json:"roomID"`
+	MobID          uint64 `gorm:"column:mob_id;type:bigint(20);not null;index" json:"mobID"` // mob.id of the prototype
+	MaxCount       int    `gorm:"column:max_count;type:int(11);default:1;not null" json:"maxCount"`
+	RespawnSeconds int    `gorm:"column:respawn_seconds;type:int(11);default:300;not null" json:"respawnSeconds"`
+	Chance         int    `gorm:"column:chance;type:int(11);default:100;not null" json:"chance"` // percent chance a respawn succeeds
+}
+
+// TableName table name
+func (m *Spawn) TableName() string {
+	return "spawn"
+}
+
+// SpawnColumnNames Whitelist for custom query fields to prevent sql injection attacks
+var SpawnColumnNames = map[string]bool{
+	"id":              true,
+	"room_id":         true,
+	"mob_id":          true,
+	"max_count":       true,
+	"respawn_seconds": true,
+	"chance":          true,
+}
@@ -0,0 +1,11 @@
+package model
+
+import (
+	"gorm.io/gorm"
+)
+
+// Trash embedded in the rooms, mobs and items, deleting one of them sets the deleted_at and
+// moves it to the trash, where it is left out of the queries until it is restored or purged
+type Trash struct {
+	DeletedAt gorm.DeletedAt `gorm:"column:deleted_at;index" json:"-"`
+}
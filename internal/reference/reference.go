@@ -0,0 +1,230 @@
+// Package reference finds the rows that refer to a room or a mob by id, so that deleting it does
+// not leave dangling ids behind. A delete is refused while the record is referenced, or cascades
+// and removes the references in its transaction through the ByTx methods of the daos.
+//
+// A record without references is moved to the trash and can be restored. The references removed
+// by a cascade are not kept anywhere, so a record whose delete removed some is purged with them
+// instead: restoring it would bring it back without its spawns, exits and items.
+package reference
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+
+	"gorm.io/gorm"
+
+	"fs/internal/dao"
+	"fs/internal/model"
+	"fs/internal/world"
+)
+
+// ErrReferenced the record is still referred to by other rows and the delete does not cascade
+var ErrReferenced = errors.New("record is referenced")
+
+// Reference a row that refers to the record being deleted
+type Reference struct {
+	Table  string `json:"table"`  // room, room_exit, spawn, item_instance or character
+	ID     string `json:"id"`     // id of the row
+	Column string `json:"column"` // column holding the reference
+}
+
+// String e.g. "spawn 3 mob_id"
+func (r Reference) String() string {
+	return r.Table + " " + r.ID + " " + r.Column
+}
+
+// Daos the tables a delete checks and cascades to
+type Daos struct {
+	Rooms         dao.RoomDao
+	RoomExits     dao.RoomExitDao
+	Mobs          dao.MobDao
+	Spawns        dao.SpawnDao
+	ItemInstances dao.ItemInstanceDao
+	Characters    dao.CharacterDao
+}
+
+// DeleteMob delete a mob in one transaction with its references, the rooms that list it in
+// Room.Mobs, its spawns and the item instances it carries. The references are returned, with
+// ErrReferenced if there are some and cascade is false, then nothing is written. The mob is moved
+// to the trash if it has no references, else it is purged with them.
+func DeleteMob(ctx context.Context, db *gorm.DB, d Daos, id uint64, cascade bool) ([]Reference, error) {
+	var refs []Reference
+	ctx, notify := dao.CollectChanges(ctx)
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		rooms, err := roomsListingMob(tx, id)
+		if err != nil {
+			return err
+		}
+		var spawns []*model.Spawn
+		if err = tx.Where("mob_id = ?", id).Order("id").Find(&spawns).Error; err != nil {
+			return err
+		}
+		instances, err := instancesAt(tx, model.ItemLocationMob, strconv.FormatUint(id, 10))
+		if err != nil {
+			return err
+		}
+
+		for _, r := range rooms {
+			refs = append(refs, Reference{Table: "room", ID: r.ID, Column: "mobs"})
+		}
+		for _, s := range spawns {
+			refs = append(refs, Reference{Table: "spawn", ID: strconv.FormatUint(s.ID, 10), Column: "mob_id"})
+		}
+		refs = append(refs, instanceReferences(instances)...)
+		if len(refs) > 0 && !cascade {
+			return ErrReferenced
+		}
+
+		for _, r := range rooms {
+			room := &model.Room{ID: r.ID, Mobs: world.RemoveMobID(r.Mobs, id), Version: r.Version}
+			if err = d.Rooms.PatchByTx(ctx, tx, room, []string{"Mobs"}); err != nil { // may clear Room.Mobs
+				return err
+			}
+		}
+		for _, s := range spawns {
+			if err = d.Spawns.DeleteByTx(ctx, tx, s.ID); err != nil {
+				return err
+			}
+		}
+		if err = deleteInstances(ctx, tx, d, instances); err != nil {
+			return err
+		}
+		return d.Mobs.DeleteByTx(ctx, trashOrPurge(tx, refs), id)
+	})
+	if err == nil {
+		notify()
+	}
+	return refs, err
+}
+
+// DeleteRoom delete a room in one transaction with its references, the exits of other rooms
+// leading to it, the spawns and the item instances in it and the characters that were last saved
+// in it. The characters are kept, they enter the start room the next time. The exits of the room
+// itself are kept, they are back with the room if it is restored from the trash. The references
+// are returned and the room is moved to the trash or purged as with DeleteMob.
+func DeleteRoom(ctx context.Context, db *gorm.DB, d Daos, id string, cascade bool) ([]Reference, error) {
+	var refs []Reference
+	ctx, notify := dao.CollectChanges(ctx)
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var exits []*model.RoomExit
+		if err := tx.Where("to_room_id = ? AND room_id <> ?", id, id).Order("id").Find(&exits).Error; err != nil {
+			return err
+		}
+		var spawns []*model.Spawn
+		if err := tx.Where("room_id = ?", id).Order("id").Find(&spawns).Error; err != nil {
+			return err
+		}
+		instances, err := instancesAt(tx, model.ItemLocationRoom, id)
+		if err != nil {
+			return err
+		}
+		var characters []*model.Character
+		if err = tx.Where("room_id = ?", id).Order("id").Find(&characters).Error; err != nil {
+			return err
+		}
+
+		for _, e := range exits {
+			refs = append(refs, Reference{Table: "room_exit", ID: strconv.FormatUint(e.ID, 10), Column: "to_room_id"})
+		}
+		for _, s := range spawns {
+			refs = append(refs, Reference{Table: "spawn", ID: strconv.FormatUint(s.ID, 10), Column: "room_id"})
+		}
+		refs = append(refs, instanceReferences(instances)...)
+		for _, ch := range characters {
+			refs = append(refs, Reference{Table: "character", ID: strconv.FormatUint(ch.ID, 10), Column: "room_id"})
+		}
+		if len(refs) > 0 && !cascade {
+			return ErrReferenced
+		}
+
+		for _, e := range exits {
+			if err = d.RoomExits.DeleteByTx(ctx, tx, e.ID); err != nil {
+				return err
+			}
+		}
+		for _, s := range spawns {
+			if err = d.Spawns.DeleteByTx(ctx, tx, s.ID); err != nil {
+				return err
+			}
+		}
+		if err = deleteInstances(ctx, tx, d, instances); err != nil {
+			return err
+		}
+		for _, ch := range characters {
+			ch.RoomID = "" // the start room
+			if err = d.Characters.SaveByTx(ctx, tx, ch); err != nil {
+				return err
+			}
+		}
+		return d.Rooms.DeleteByTx(ctx, trashOrPurge(tx, refs), id)
+	})
+	if err == nil {
+		notify()
+	}
+	return refs, err
+}
+
+// roomsListingMob the rooms whose Room.Mobs lists the mob
+func roomsListingMob(tx *gorm.DB, id uint64) ([]*model.Room, error) {
+	var candidates []*model.Room
+	// Room.Mobs is free text, the sql only narrows it down
+	err := tx.Where("mobs LIKE ?", "%"+strconv.FormatUint(id, 10)+"%").Order("id").Find(&candidates).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var rooms []*model.Room
+	for _, r := range candidates {
+		if slices.Contains(world.ParseMobIDs(r.Mobs), id) {
+			rooms = append(rooms, r)
+		}
+	}
+	return rooms, nil
+}
+
+// trashOrPurge the transaction deleting the record, a record whose references were removed is purged
+// instead of moved to the trash, see the package documentation
+func trashOrPurge(tx *gorm.DB, refs []Reference) *gorm.DB {
+	if len(refs) > 0 {
+		return tx.Unscoped()
+	}
+	return tx
+}
+
+// instancesAt the item instances at a location, with the ones in the containers among them
+func instancesAt(tx *gorm.DB, locationType string, ownerID string) ([]*model.ItemInstance, error) {
+	var instances []*model.ItemInstance
+	err := tx.Where("location_type = ? AND owner_id = ?", locationType, ownerID).Order("id").Find(&instances).Error
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < len(instances); i++ { // grows with the contents of the containers
+		var contents []*model.ItemInstance
+		err = tx.Where("location_type = ? AND owner_id = ?", model.ItemLocationContainer, strconv.FormatUint(instances[i].ID, 10)).
+			Order("id").Find(&contents).Error
+		if err != nil {
+			return nil, err
+		}
+		instances = append(instances, contents...)
+	}
+	return instances, nil
+}
+
+func instanceReferences(instances []*model.ItemInstance) []Reference {
+	refs := make([]Reference, 0, len(instances))
+	for _, inst := range instances {
+		refs = append(refs, Reference{Table: "item_instance", ID: strconv.FormatUint(inst.ID, 10), Column: "owner_id"})
+	}
+	return refs
+}
+
+func deleteInstances(ctx context.Context, tx *gorm.DB, d Daos, instances []*model.ItemInstance) error {
+	for _, inst := range instances {
+		if err := d.ItemInstances.DeleteByTx(ctx, tx, inst.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
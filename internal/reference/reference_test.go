@@ -0,0 +1,157 @@
+package reference_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-dev-frame/sponge/pkg/sgorm"
+
+	"fs/internal/dao"
+	"fs/internal/database"
+	"fs/internal/model"
+	"fs/internal/reference"
+)
+
+func newDB(t *testing.T) (*sgorm.DB, reference.Daos) {
+	db, err := database.OpenSqlite(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = sgorm.CloseDB(db) })
+	return db, reference.Daos{
+		Rooms:         dao.NewRoomDao(db, nil),
+		RoomExits:     dao.NewRoomExitDao(db, nil),
+		Mobs:          dao.NewMobDao(db, nil),
+		Spawns:        dao.NewSpawnDao(db, nil),
+		ItemInstances: dao.NewItemInstanceDao(db, nil),
+		Characters:    dao.NewCharacterDao(db, nil),
+	}
+}
+
+// countRows the rows of a table, the ones in the trash included
+func countRows(t *testing.T, db *sgorm.DB, table interface{}) int64 {
+	var n int64
+	require.NoError(t, db.Unscoped().Model(table).Count(&n).Error)
+	return n
+}
+
+func TestDeleteMob(t *testing.T) {
+	db, d := newDB(t)
+	ctx := context.Background()
+	require.NoError(t, d.Mobs.Create(ctx, &model.Mob{ID: 1, MobID: "guard", MobName: "guard"}))
+	require.NoError(t, d.Mobs.Create(ctx, &model.Mob{ID: 11, MobID: "cat", MobName: "cat"}))
+	require.NoError(t, d.Rooms.Create(ctx, &model.Room{ID: "gate", Mobs: "1"}))
+	require.NoError(t, d.Rooms.Create(ctx, &model.Room{ID: "square", Mobs: "11, 1"}))
+	require.NoError(t, d.Rooms.Create(ctx, &model.Room{ID: "yard", Mobs: "11"}))
+	require.NoError(t, d.Spawns.Create(ctx, &model.Spawn{RoomID: "gate", MobID: 1}))
+	// a bag carried by the guard with a coin in it, and the sword of the cat
+	require.NoError(t, d.ItemInstances.Create(ctx, &model.ItemInstance{ProtoID: 1, LocationType: model.ItemLocationMob, OwnerID: "1"}))
+	require.NoError(t, d.ItemInstances.Create(ctx, &model.ItemInstance{ProtoID: 2, LocationType: model.ItemLocationContainer, OwnerID: "1"}))
+	require.NoError(t, d.ItemInstances.Create(ctx, &model.ItemInstance{ProtoID: 3, LocationType: model.ItemLocationMob, OwnerID: "11"}))
+
+	want := []reference.Reference{
+		{Table: "room", ID: "gate", Column: "mobs"},
+		{Table: "room", ID: "square", Column: "mobs"},
+		{Table: "spawn", ID: "1", Column: "mob_id"},
+		{Table: "item_instance", ID: "1", Column: "owner_id"},
+		{Table: "item_instance", ID: "2", Column: "owner_id"},
+	}
+	refs, err := reference.DeleteMob(ctx, db, d, 1, false)
+	assert.ErrorIs(t, err, reference.ErrReferenced)
+	assert.Equal(t, want, refs)
+	_, err = d.Mobs.GetByID(ctx, 1)
+	assert.NoError(t, err) // nothing is written
+
+	refs, err = reference.DeleteMob(ctx, db, d, 1, true)
+	require.NoError(t, err)
+	assert.Equal(t, want, refs)
+	_, err = d.Mobs.GetByID(ctx, 1)
+	assert.ErrorIs(t, err, database.ErrRecordNotFound)
+	// purged with its references, it cannot be restored without them
+	assert.Equal(t, int64(1), countRows(t, db, &model.Mob{}))
+	assert.ErrorIs(t, d.Mobs.RestoreByID(ctx, 1), database.ErrRecordNotFound)
+
+	for id, mobs := range map[string]string{"gate": "", "square": "11", "yard": "11"} {
+		room, err := d.Rooms.GetByID(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, mobs, room.Mobs, id)
+	}
+	gate, _ := d.Rooms.GetByID(ctx, "gate")
+	assert.Equal(t, 2, gate.Version)
+	assert.Zero(t, countRows(t, db, &model.Spawn{}))
+	assert.Equal(t, int64(1), countRows(t, db, &model.ItemInstance{})) // the sword of the cat
+
+	// a mob without references
+	refs, err = reference.DeleteMob(ctx, db, d, 11, false)
+	assert.ErrorIs(t, err, reference.ErrReferenced)
+	assert.Len(t, refs, 3)
+	require.NoError(t, d.Rooms.DeleteByID(ctx, "square"))
+	require.NoError(t, d.Rooms.DeleteByID(ctx, "yard"))
+	require.NoError(t, d.ItemInstances.DeleteByID(ctx, 3))
+	refs, err = reference.DeleteMob(ctx, db, d, 11, false)
+	require.NoError(t, err) // the rooms in the trash do not count
+	assert.Empty(t, refs)
+	require.NoError(t, d.Mobs.RestoreByID(ctx, 11)) // it was moved to the trash
+}
+
+func TestDeleteRoom(t *testing.T) {
+	db, d := newDB(t)
+	ctx := context.Background()
+	for _, id := range []string{"gate", "square", "yard"} {
+		require.NoError(t, d.Rooms.Create(ctx, &model.Room{ID: id}))
+	}
+	require.NoError(t, d.RoomExits.Create(ctx, &model.RoomExit{RoomID: "square", Direction: "north", ToRoomID: "gate"}))
+	require.NoError(t, d.RoomExits.Create(ctx, &model.RoomExit{RoomID: "gate", Direction: "south", ToRoomID: "square"}))
+	require.NoError(t, d.RoomExits.Create(ctx, &model.RoomExit{RoomID: "gate", Direction: "up", ToRoomID: "gate"}))
+	require.NoError(t, d.RoomExits.Create(ctx, &model.RoomExit{RoomID: "yard", Direction: "east", ToRoomID: "square"}))
+	require.NoError(t, d.Spawns.Create(ctx, &model.Spawn{RoomID: "gate", MobID: 1}))
+	require.NoError(t, d.ItemInstances.Create(ctx, &model.ItemInstance{ProtoID: 1, LocationType: model.ItemLocationRoom, OwnerID: "gate"}))
+	require.NoError(t, d.ItemInstances.Create(ctx, &model.ItemInstance{ProtoID: 1, LocationType: model.ItemLocationRoom, OwnerID: "yard"}))
+	require.NoError(t, d.Characters.Create(ctx, &model.Character{AccountID: 1, Name: "ann", RoomID: "gate", Hp: 40}))
+	require.NoError(t, d.Characters.Create(ctx, &model.Character{AccountID: 1, Name: "bob", RoomID: "yard"}))
+
+	want := []reference.Reference{
+		{Table: "room_exit", ID: "1", Column: "to_room_id"},
+		{Table: "spawn", ID: "1", Column: "room_id"},
+		{Table: "item_instance", ID: "1", Column: "owner_id"},
+		{Table: "character", ID: "1", Column: "room_id"},
+	}
+	var changed []string
+	unregister := dao.OnRoomChange(func(roomID string) {
+		changed = append(changed, roomID)
+	})
+	defer unregister()
+
+	refs, err := reference.DeleteRoom(ctx, db, d, "gate", false)
+	assert.ErrorIs(t, err, reference.ErrReferenced)
+	assert.Equal(t, want, refs)
+	assert.Empty(t, changed) // rolled back
+
+	refs, err = reference.DeleteRoom(ctx, db, d, "gate", true)
+	require.NoError(t, err)
+	assert.Equal(t, want, refs)
+	assert.Equal(t, []string{"square", "gate"}, changed) // the room of the exit leading to gate, then gate
+	_, err = d.Rooms.GetByID(ctx, "gate")
+	assert.ErrorIs(t, err, database.ErrRecordNotFound)
+	assert.ErrorIs(t, d.Rooms.RestoreByID(ctx, "gate"), database.ErrRecordNotFound) // purged with its references
+
+	// the exits of the room are kept, the one leading to it from square is gone
+	var exits []*model.RoomExit
+	require.NoError(t, db.Order("id").Find(&exits).Error)
+	require.Len(t, exits, 3)
+	for i, id := range []uint64{2, 3, 4} {
+		assert.Equal(t, id, exits[i].ID)
+	}
+	assert.Zero(t, countRows(t, db, &model.Spawn{}))
+	assert.Equal(t, int64(1), countRows(t, db, &model.ItemInstance{})) // the one in the yard
+
+	// the character enters the start room the next time, the rest of it is kept
+	ann, err := d.Characters.GetByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "", ann.RoomID)
+	assert.Equal(t, 40, ann.Hp)
+	bob, err := d.Characters.GetByID(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "yard", bob.RoomID)
+}
@@ -0,0 +1,358 @@
+// Package revision records the creates, updates and deletes of rooms, mobs and items as
+// rows of the revision table, with who made the change and the json of the record before
+// and after it. The rows are written by a gorm plugin in the transaction of the change,
+// so every write through the daos is recorded.
+package revision
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"fs/internal/auth"
+	"fs/internal/model"
+)
+
+// entity types, the table names of the models
+const (
+	EntityRoom = "room"
+	EntityMob  = "mob"
+	EntityItem = "item"
+)
+
+// Entities the entity types that have revisions
+var Entities = []string{EntityRoom, EntityMob, EntityItem}
+
+// actions
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionDelete  = "delete"  // also moving the record to the trash
+	ActionRestore = "restore" // taking the record out of the trash
+)
+
+// key of the records loaded before an update or delete in the settings of the statement
+const beforeKey = "revision:before"
+
+var _ gorm.Plugin = Plugin{}
+
+// Plugin the gorm plugin that records the revisions, e.g. db.Use(revision.Plugin{})
+type Plugin struct{}
+
+// Name of the plugin
+func (Plugin) Name() string {
+	return "revision"
+}
+
+// Initialize register the callbacks, the records are loaded before an update or
+// delete and the revisions are written after it
+func (Plugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	if err := cb.Create().After("gorm:create").Register("revision:create", afterCreate); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("revision:before_update", loadBefore); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:update").Register("revision:update", afterUpdate); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before("gorm:delete").Register("revision:before_delete", loadBefore); err != nil {
+		return err
+	}
+	return cb.Delete().After("gorm:delete").Register("revision:delete", afterDelete)
+}
+
+// tracked check if the statement writes a table that has revisions
+func tracked(db *gorm.DB) bool {
+	if db.Error != nil || db.Statement.Schema == nil || db.Statement.Schema.PrioritizedPrimaryField == nil {
+		return false
+	}
+	for _, entity := range Entities {
+		if db.Statement.Table == entity {
+			return true
+		}
+	}
+	return false
+}
+
+func afterCreate(db *gorm.DB) {
+	if !tracked(db) {
+		return
+	}
+	var revisions []*model.Revision
+	err := eachRecord(db.Statement.ReflectValue, func(record reflect.Value) error {
+		id, data, err := snapshot(db, record)
+		if err != nil {
+			return err
+		}
+		revisions = append(revisions, newRevision(db, ActionCreate, id, "", data))
+		return nil
+	})
+	if err == nil {
+		err = save(db, revisions)
+	}
+	_ = db.AddError(err)
+}
+
+// loadBefore load the records an update or a delete is about to change
+func loadBefore(db *gorm.DB) {
+	if !tracked(db) {
+		return
+	}
+	conds := conditions(db)
+	if len(conds) == 0 {
+		return // refused by gorm without a where clause
+	}
+	records, err := find(db, clause.Where{Exprs: conds})
+	if err != nil {
+		_ = db.AddError(err)
+		return
+	}
+	db.Statement.Settings.Store(beforeKey, records)
+}
+
+func afterUpdate(db *gorm.DB) {
+	before, ok := loaded(db)
+	if !ok || len(before) == 0 {
+		return
+	}
+
+	pk := db.Statement.Schema.PrioritizedPrimaryField
+	ids := make([]interface{}, 0, len(before))
+	for _, r := range before {
+		ids = append(ids, r.key)
+	}
+	after, err := find(db, clause.Where{Exprs: []clause.Expression{
+		clause.IN{Column: clause.Column{Name: pk.DBName}, Values: ids},
+	}})
+	if err != nil {
+		_ = db.AddError(err)
+		return
+	}
+	afterByID := make(map[string]record, len(after))
+	for _, r := range after {
+		afterByID[r.id] = r
+	}
+
+	// a record in the trash does not exist for the revisions
+	var revisions []*model.Revision
+	for _, b := range before {
+		a, ok := afterByID[b.id]
+		switch {
+		case !ok || b.deleted && a.deleted:
+		case b.deleted:
+			revisions = append(revisions, newRevision(db, ActionRestore, b.id, "", a.data))
+		case a.deleted:
+			revisions = append(revisions, newRevision(db, ActionDelete, b.id, b.data, ""))
+		case a.data != b.data:
+			revisions = append(revisions, newRevision(db, ActionUpdate, b.id, b.data, a.data))
+		}
+	}
+	_ = db.AddError(save(db, revisions))
+}
+
+func afterDelete(db *gorm.DB) {
+	before, ok := loaded(db)
+	if !ok {
+		return
+	}
+	revisions := make([]*model.Revision, 0, len(before))
+	for _, r := range before {
+		if !r.deleted { // purging the trash is not a change
+			revisions = append(revisions, newRevision(db, ActionDelete, r.id, r.data, ""))
+		}
+	}
+	_ = db.AddError(save(db, revisions))
+}
+
+func loaded(db *gorm.DB) ([]record, bool) {
+	if db.Error != nil {
+		return nil, false
+	}
+	v, ok := db.Statement.Settings.Load(beforeKey)
+	if !ok {
+		return nil, false
+	}
+	records, ok := v.([]record)
+	return records, ok
+}
+
+// record a row of a tracked table
+type record struct {
+	key     interface{} // value of the primary key
+	id      string      // the primary key as text, the entity id of the revision
+	data    string      // json
+	deleted bool        // in the trash, the deleted_at of a soft deleted record is set
+}
+
+// conditions the where clause of the statement and the primary key of its model,
+// e.g. db.Model(&model.Room{ID: "inn"}).Updates(...) has only the primary key
+func conditions(db *gorm.DB) []clause.Expression {
+	stmt := db.Statement
+	var conds []clause.Expression
+	if c, ok := stmt.Clauses["WHERE"]; ok {
+		if where, ok := c.Expression.(clause.Where); ok {
+			conds = append(conds, where.Exprs...)
+		}
+	}
+
+	if stmt.Model == nil {
+		return conds
+	}
+	model := reflect.Indirect(reflect.ValueOf(stmt.Model))
+	if model.Kind() != reflect.Struct || model.Type() != stmt.Schema.ModelType {
+		return conds
+	}
+	for _, field := range stmt.Schema.PrimaryFields {
+		if value, zero := field.ValueOf(stmt.Context, model); !zero {
+			conds = append(conds, clause.Eq{Column: clause.Column{Name: field.DBName}, Value: value})
+		}
+	}
+	return conds
+}
+
+// find load the records of the table of the statement in its transaction, the records
+// in the trash are only loaded if the statement is unscoped
+func find(db *gorm.DB, where clause.Where) ([]record, error) {
+	stmt := db.Statement
+	rows := reflect.New(reflect.SliceOf(reflect.PointerTo(stmt.Schema.ModelType)))
+	tx := db.Session(&gorm.Session{NewDB: true}).Table(stmt.Table)
+	if stmt.Unscoped {
+		tx = tx.Unscoped()
+	}
+	err := tx.Clauses(where).Find(rows.Interface()).Error
+	if err != nil {
+		return nil, err
+	}
+	deletedAt := stmt.Schema.LookUpField("deleted_at")
+
+	var records []record
+	err = eachRecord(rows.Elem(), func(row reflect.Value) error {
+		id, data, err := snapshot(db, row)
+		if err != nil {
+			return err
+		}
+		r := record{id: id, data: data}
+		r.key, _ = stmt.Schema.PrioritizedPrimaryField.ValueOf(stmt.Context, row)
+		if deletedAt != nil {
+			_, zero := deletedAt.ValueOf(stmt.Context, row)
+			r.deleted = !zero
+		}
+		records = append(records, r)
+		return nil
+	})
+	return records, err
+}
+
+// eachRecord call fn with each struct of a struct, a pointer or a slice of them
+func eachRecord(v reflect.Value, fn func(record reflect.Value) error) error {
+	v = reflect.Indirect(v)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := eachRecord(v.Index(i), fn); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		return fn(v)
+	}
+	return nil
+}
+
+// snapshot the entity id and the json of a record
+func snapshot(db *gorm.DB, record reflect.Value) (string, string, error) {
+	key, _ := db.Statement.Schema.PrioritizedPrimaryField.ValueOf(db.Statement.Context, record)
+
+	// the version only counts the writes, it is left out so that it is not a change of its own
+	if field := db.Statement.Schema.LookUpField("version"); field != nil {
+		copied := reflect.New(record.Type()).Elem()
+		copied.Set(record)
+		if err := field.Set(db.Statement.Context, copied, 0); err != nil {
+			return "", "", err
+		}
+		record = copied
+	}
+
+	data, err := json.Marshal(record.Interface())
+	if err != nil {
+		return "", "", err
+	}
+	return fmt.Sprint(key), string(data), nil
+}
+
+func newRevision(db *gorm.DB, action string, id string, before string, after string) *model.Revision {
+	return &model.Revision{
+		EntityType: db.Statement.Table,
+		EntityID:   id,
+		Action:     action,
+		Actor:      auth.NameOf(db.Statement.Context),
+		CreatedAt:  time.Now(),
+		Before:     before,
+		After:      after,
+	}
+}
+
+// save write the revisions in the transaction of the statement
+func save(db *gorm.DB, revisions []*model.Revision) error {
+	if len(revisions) == 0 {
+		return nil
+	}
+	return db.Session(&gorm.Session{NewDB: true}).Create(revisions).Error
+}
+
+// Change a field that differs between two states of an entity
+type Change struct {
+	Field string      `json:"field"` // json name of the field
+	From  interface{} `json:"from"`  // nil if the field is not in the first state
+	To    interface{} `json:"to"`    // nil if the field is not in the second state
+}
+
+// Diff compare two states of an entity, the json of the record after two revisions,
+// an empty state is an entity that does not exist. The changes are ordered by field.
+func Diff(from string, to string) ([]Change, error) {
+	a, err := fields(from)
+	if err != nil {
+		return nil, err
+	}
+	b, err := fields(to)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(a)+len(b))
+	for name := range a {
+		names = append(names, name)
+	}
+	for name := range b {
+		if _, ok := a[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	changes := []Change{}
+	for _, name := range names {
+		if !reflect.DeepEqual(a[name], b[name]) {
+			changes = append(changes, Change{Field: name, From: a[name], To: b[name]})
+		}
+	}
+	return changes, nil
+}
+
+func fields(state string) (map[string]interface{}, error) {
+	m := map[string]interface{}{}
+	if state == "" {
+		return m, nil
+	}
+	if err := json.Unmarshal([]byte(state), &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
@@ -0,0 +1,148 @@
+package revision_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-dev-frame/sponge/pkg/sgorm"
+
+	"fs/internal/auth"
+	"fs/internal/dao"
+	"fs/internal/database"
+	"fs/internal/model"
+	"fs/internal/revision"
+)
+
+func newDB(t *testing.T) *sgorm.DB {
+	db, err := database.OpenSqlite(":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = sgorm.CloseDB(db) })
+	require.NoError(t, db.Use(revision.Plugin{}))
+	return db
+}
+
+func revisionsOf(t *testing.T, db *sgorm.DB, entityType string, entityID string) []*model.Revision {
+	var revisions []*model.Revision
+	require.NoError(t, db.Where("entity_type = ? AND entity_id = ?", entityType, entityID).Order("id").Find(&revisions).Error)
+	return revisions
+}
+
+func TestPlugin(t *testing.T) {
+	db := newDB(t)
+	ctx := auth.WithName(context.Background(), "bob")
+	rooms := dao.NewRoomDao(db, nil)
+
+	require.NoError(t, rooms.Create(ctx, &model.Room{ID: "inn", Title: "Inn", Desc: "A warm inn."}))
+	require.NoError(t, rooms.UpdateByID(ctx, &model.Room{ID: "inn", Desc: "A cold inn.", Version: 1}))
+	require.NoError(t, rooms.UpdateByID(ctx, &model.Room{ID: "inn", Desc: "A cold inn.", Version: 2})) // only the version changes
+	require.NoError(t, rooms.DeleteByID(context.Background(), "inn"))
+
+	revisions := revisionsOf(t, db, revision.EntityRoom, "inn")
+	require.Len(t, revisions, 3)
+	for i, action := range []string{revision.ActionCreate, revision.ActionUpdate, revision.ActionDelete} {
+		assert.Equal(t, action, revisions[i].Action)
+		assert.False(t, revisions[i].CreatedAt.IsZero())
+	}
+	assert.Equal(t, "bob", revisions[0].Actor)
+	assert.Equal(t, "bob", revisions[1].Actor)
+	assert.Empty(t, revisions[2].Actor)
+
+	assert.Empty(t, revisions[0].Before)
+	assert.JSONEq(t, `{"id":"inn","title":"Inn","desc":"A warm inn.","way":"","mobs":""}`, revisions[0].After)
+	assert.Equal(t, revisions[0].After, revisions[1].Before)
+	assert.JSONEq(t, `{"id":"inn","title":"Inn","desc":"A cold inn.","way":"","mobs":""}`, revisions[1].After)
+	assert.Equal(t, revisions[1].After, revisions[2].Before)
+	assert.Empty(t, revisions[2].After)
+
+	// writing back a revision takes the room out of the trash
+	require.NoError(t, rooms.Save(ctx, &model.Room{ID: "inn", Title: "Inn"}))
+	require.NoError(t, rooms.Save(ctx, &model.Room{ID: "inn", Title: "Inn", Desc: "A warm inn."}))
+	revisions = revisionsOf(t, db, revision.EntityRoom, "inn")
+	require.Len(t, revisions, 5)
+	assert.Equal(t, revision.ActionRestore, revisions[3].Action)
+	assert.Empty(t, revisions[3].Before)
+	assert.Equal(t, revision.ActionUpdate, revisions[4].Action)
+	assert.Equal(t, revisions[0].After, revisions[4].After)
+}
+
+func TestPlugin_trash(t *testing.T) {
+	db := newDB(t)
+	ctx := context.Background()
+	mobs := dao.NewMobDao(db, nil)
+
+	mob := &model.Mob{MobID: "rat", MobName: "rat", MobCname: "老鼠"}
+	require.NoError(t, mobs.Create(ctx, mob))
+	require.NoError(t, mobs.DeleteByID(ctx, mob.ID))
+	require.NoError(t, mobs.DeleteByID(ctx, mob.ID)) // already in the trash
+	require.NoError(t, mobs.RestoreByID(ctx, mob.ID))
+	require.NoError(t, mobs.DeleteByID(ctx, mob.ID))
+	n, err := mobs.PurgeDeleted(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	// purging the trash is not recorded
+	revisions := revisionsOf(t, db, revision.EntityMob, "1")
+	var actions []string
+	for _, r := range revisions {
+		actions = append(actions, r.Action)
+	}
+	assert.Equal(t, []string{revision.ActionCreate, revision.ActionDelete, revision.ActionRestore, revision.ActionDelete}, actions)
+	assert.Equal(t, revisions[0].After, revisions[2].After)
+}
+
+func TestPlugin_transaction(t *testing.T) {
+	db := newDB(t)
+	ctx := context.Background()
+	mobs := dao.NewMobDao(db, nil)
+	items := dao.NewItemDao(db, nil)
+
+	// the revisions are rolled back with the change
+	tx := db.Begin()
+	_, err := mobs.CreateByTx(ctx, tx, &model.Mob{MobID: "rat", MobName: "rat", MobCname: "老鼠"})
+	require.NoError(t, err)
+	require.NoError(t, tx.Rollback().Error)
+	var count int64
+	require.NoError(t, db.Model(&model.Revision{}).Count(&count).Error)
+	assert.Zero(t, count)
+
+	tx = db.Begin()
+	id, err := items.CreateByTx(ctx, tx, &model.Item{ItemID: "sword", ItemName: "sword", Attack: 5})
+	require.NoError(t, err)
+	require.NoError(t, items.UpdateByTx(ctx, tx, &model.Item{ID: id, Attack: 7, Version: 1}))
+	require.NoError(t, tx.Commit().Error)
+
+	revisions := revisionsOf(t, db, revision.EntityItem, "1")
+	require.Len(t, revisions, 2)
+	var before, after model.Item
+	require.NoError(t, json.Unmarshal([]byte(revisions[1].Before), &before))
+	require.NoError(t, json.Unmarshal([]byte(revisions[1].After), &after))
+	assert.Equal(t, 5, before.Attack)
+	assert.Equal(t, 7, after.Attack)
+
+	// other tables have no revisions
+	require.NoError(t, db.Create(&model.Spawn{RoomID: "inn", MobID: 1}).Error)
+	require.NoError(t, db.Model(&model.Revision{}).Count(&count).Error)
+	assert.Equal(t, int64(2), count)
+}
+
+func TestDiff(t *testing.T) {
+	changes, err := revision.Diff(`{"id":"inn","title":"Inn","desc":"A warm inn."}`, `{"id":"inn","title":"Inn","desc":"A cold inn.","way":"n"}`)
+	require.NoError(t, err)
+	assert.Equal(t, []revision.Change{
+		{Field: "desc", From: "A warm inn.", To: "A cold inn."},
+		{Field: "way", From: nil, To: "n"},
+	}, changes)
+
+	// a deleted entity has no fields
+	changes, err = revision.Diff(`{"id":"inn"}`, "")
+	require.NoError(t, err)
+	assert.Equal(t, []revision.Change{{Field: "id", From: "inn", To: nil}}, changes)
+
+	_, err = revision.Diff("{", "")
+	assert.Error(t, err)
+}
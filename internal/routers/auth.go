@@ -0,0 +1,25 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"fs/internal/auth"
+	"fs/internal/handler"
+)
+
+func init() {
+	apiV1RouterFns = append(apiV1RouterFns, func(group *gin.RouterGroup) {
+		authRouter(group, handler.NewAuthHandler())
+	})
+}
+
+func authRouter(group *gin.RouterGroup, h handler.AuthHandler) {
+	g := group.Group("/auth")
+	g.POST("/login", h.Login)     // [post] /api/v1/auth/login
+	g.POST("/refresh", h.Refresh) // [post] /api/v1/auth/refresh
+
+	// only admins can manage accounts
+	a := group.Group("/account")
+	a.Use(auth.Middleware(), auth.Require(auth.RoleAdmin))
+	a.PUT("/:id/role", h.SetRole) // [put] /api/v1/account/:id/role
+}
@@ -0,0 +1,23 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"fs/internal/auth"
+	"fs/internal/handler"
+)
+
+func init() {
+	apiV1RouterFns = append(apiV1RouterFns, func(group *gin.RouterGroup) {
+		equipmentRouter(group, handler.NewEquipmentHandler())
+	})
+}
+
+func equipmentRouter(group *gin.RouterGroup, h handler.EquipmentHandler) {
+	g := group.Group("/equipment")
+
+	// All the following routes require an access token from /api/v1/auth/login, every role can read
+	g.Use(auth.Middleware())
+
+	g.POST("/preview", h.Preview) // [post] /api/v1/equipment/preview
+}
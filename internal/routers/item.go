@@ -3,6 +3,7 @@ package routers
 import (
 	"github.com/gin-gonic/gin"
 
+	"fs/internal/auth"
 	"fs/internal/handler"
 )
 
@@ -17,15 +18,17 @@ func itemRouter(group *gin.RouterGroup, h handler.ItemHandler) {
 
 	// JWT authentication reference: https://go-sponge.com/component/transport/gin.html#jwt-authorization-middleware
 
-	// All the following routes use jwt authentication, you also can use middleware.Auth(middleware.WithExtraVerify(fn))
-	//g.Use(middleware.Auth())
-
-	// If jwt authentication is not required for all routes, authentication middleware can be added
-	// separately for only certain routes. In this case, g.Use(middleware.Auth()) above should not be used.
-
-	g.POST("/", h.Create)          // [post] /api/v1/item
-	g.DELETE("/:id", h.DeleteByID) // [delete] /api/v1/item/:id
-	g.PUT("/:id", h.UpdateByID)    // [put] /api/v1/item/:id
-	g.GET("/:id", h.GetByID)       // [get] /api/v1/item/:id
-	g.POST("/list", h.List)        // [post] /api/v1/item/list
+	// All the following routes require an access token from /api/v1/auth/login, every role can read,
+	// only builders and admins can change the world
+	g.Use(auth.Middleware())
+	builder := auth.Require(auth.RoleBuilder)
+
+	g.POST("/", builder, h.Create)             // [post] /api/v1/item
+	g.DELETE("/:id", builder, h.DeleteByID)    // [delete] /api/v1/item/:id
+	g.POST("/:id/restore", builder, h.Restore) // [post] /api/v1/item/:id/restore
+	g.PUT("/:id", builder, h.UpdateByID)       // [put] /api/v1/item/:id
+	g.PATCH("/:id", builder, h.PatchByID)      // [patch] /api/v1/item/:id
+	g.GET("/:id", h.GetByID)                   // [get] /api/v1/item/:id
+	g.POST("/list", h.List)                    // [post] /api/v1/item/list
+	g.POST("/list/ids", h.ListByIDs)           // [post] /api/v1/item/list/ids
 }
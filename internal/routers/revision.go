@@ -0,0 +1,29 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"fs/internal/auth"
+	"fs/internal/handler"
+	"fs/internal/revision"
+)
+
+func init() {
+	apiV1RouterFns = append(apiV1RouterFns, func(group *gin.RouterGroup) {
+		for _, entity := range revision.Entities {
+			revisionRouter(group, entity, handler.NewRevisionHandler(entity))
+		}
+	})
+}
+
+func revisionRouter(group *gin.RouterGroup, entity string, h handler.RevisionHandler) {
+	g := group.Group("/" + entity + "/:id/revisions")
+
+	// All the following routes require an access token from /api/v1/auth/login, the history of
+	// the world is for the builders and admins
+	g.Use(auth.Middleware(), auth.Require(auth.RoleBuilder))
+
+	g.GET("", h.List)                         // [get] /api/v1/{room,mob,item}/:id/revisions
+	g.GET("/diff", h.Diff)                    // [get] /api/v1/{room,mob,item}/:id/revisions/diff
+	g.POST("/:revisionID/restore", h.Restore) // [post] /api/v1/{room,mob,item}/:id/revisions/:revisionID/restore
+}
@@ -0,0 +1,30 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"fs/internal/auth"
+	"fs/internal/handler"
+)
+
+func init() {
+	apiV1RouterFns = append(apiV1RouterFns, func(group *gin.RouterGroup) {
+		roomExitRouter(group, handler.NewRoomExitHandler())
+	})
+}
+
+func roomExitRouter(group *gin.RouterGroup, h handler.RoomExitHandler) {
+	g := group.Group("/room")
+
+	// All the following routes require an access token from /api/v1/auth/login, every role can read,
+	// only builders and admins can change the world
+	g.Use(auth.Middleware())
+	builder := auth.Require(auth.RoleBuilder)
+
+	g.POST("/:id/exits", builder, h.Create)                         // [post] /api/v1/room/:id/exits
+	g.DELETE("/:id/exits/:direction", builder, h.DeleteByDirection) // [delete] /api/v1/room/:id/exits/:direction
+	g.PUT("/:id/exits/:direction", builder, h.UpdateByDirection)    // [put] /api/v1/room/:id/exits/:direction
+	g.GET("/:id/exits/:direction", h.GetByDirection)                // [get] /api/v1/room/:id/exits/:direction
+	g.GET("/:id/exits", h.List)                                     // [get] /api/v1/room/:id/exits
+	g.POST("/exits/migrate", builder, h.MigrateWays)                // [post] /api/v1/room/exits/migrate
+}
@@ -33,7 +33,11 @@ func NewRouter() *gin.Engine {
 	r := gin.New()
 
 	r.Use(gin.Recovery())
-	r.Use(middleware.Cors())
+	// the versions of rooms, mobs and items are read from ETag and sent back in If-Match
+	r.Use(middleware.Cors(
+		middleware.WithAllowHeaders("Origin", "Authorization", "Content-Type", "Accept", "X-Requested-With", "X-CSRF-Token", "If-Match"),
+		middleware.WithExposeHeaders("Content-Length", "Authorization", "Content-Type", "ETag"),
+	))
 
 	if config.Get().HTTP.Timeout > 0 {
 		// if you need more fine-grained control over your routes, set the timeout in your routes, unsetting the timeout globally here.
@@ -99,7 +103,7 @@ func NewRouter() *gin.Engine {
 	r.GET("/codes", handlerfunc.ListCodes)
 
 	if config.Get().App.Env != "prod" {
-		r.GET("/config", gin.WrapF(errcode.ShowConfig([]byte(config.Show()))))
+		r.GET("/config", gin.WrapF(errcode.ShowConfig([]byte(config.Show(`"signKey"`)))))
 		// register swagger routes, generate code via swag init
 		docs.SwaggerInfo.BasePath = ""
 		// access path /swagger/index.html
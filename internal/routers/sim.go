@@ -0,0 +1,24 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"fs/internal/auth"
+	"fs/internal/handler"
+)
+
+func init() {
+	apiV1RouterFns = append(apiV1RouterFns, func(group *gin.RouterGroup) {
+		simRouter(group, handler.NewSimHandler())
+	})
+}
+
+func simRouter(group *gin.RouterGroup, h handler.SimHandler) {
+	g := group.Group("/sim")
+
+	// All the following routes require an access token from /api/v1/auth/login, the simulations
+	// are for balancing the world so only builders and admins can run them
+	g.Use(auth.Middleware(), auth.Require(auth.RoleBuilder))
+
+	g.POST("/combat", h.Combat) // [post] /api/v1/sim/combat
+}
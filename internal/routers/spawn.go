@@ -0,0 +1,32 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"fs/internal/auth"
+	"fs/internal/handler"
+)
+
+func init() {
+	apiV1RouterFns = append(apiV1RouterFns, func(group *gin.RouterGroup) {
+		spawnRouter(group, handler.NewSpawnHandler())
+	})
+}
+
+func spawnRouter(group *gin.RouterGroup, h handler.SpawnHandler) {
+	g := group.Group("/spawn")
+
+	// JWT authentication reference: https://go-sponge.com/component/transport/gin.html#jwt-authorization-middleware
+
+	// All the following routes require an access token from /api/v1/auth/login, every role can read,
+	// only builders and admins can change the world
+	g.Use(auth.Middleware())
+	builder := auth.Require(auth.RoleBuilder)
+
+	g.POST("/", builder, h.Create)          // [post] /api/v1/spawn
+	g.DELETE("/:id", builder, h.DeleteByID) // [delete] /api/v1/spawn/:id
+	g.PUT("/:id", builder, h.UpdateByID)    // [put] /api/v1/spawn/:id
+	g.GET("/:id", h.GetByID)                // [get] /api/v1/spawn/:id
+	g.POST("/list", h.List)                 // [post] /api/v1/spawn/list
+	g.POST("/list/ids", h.ListByIDs)        // [post] /api/v1/spawn/list/ids
+}
@@ -0,0 +1,25 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"fs/internal/auth"
+	"fs/internal/handler"
+)
+
+func init() {
+	apiV1RouterFns = append(apiV1RouterFns, func(group *gin.RouterGroup) {
+		trashRouter(group, handler.NewTrashHandler())
+	})
+}
+
+func trashRouter(group *gin.RouterGroup, h handler.TrashHandler) {
+	g := group.Group("/trash")
+
+	// All the following routes require an access token from /api/v1/auth/login, builders take
+	// their records out of the trash with /api/v1/{entity}/:id/restore, only admins look into it
+	g.Use(auth.Middleware(), auth.Require(auth.RoleAdmin))
+
+	g.GET("/:entity", h.List) // [get] /api/v1/trash/:entity
+	g.DELETE("", h.Purge)     // [delete] /api/v1/trash
+}
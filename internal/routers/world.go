@@ -0,0 +1,31 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"fs/internal/auth"
+	"fs/internal/handler"
+)
+
+func init() {
+	apiV1RouterFns = append(apiV1RouterFns, func(group *gin.RouterGroup) {
+		worldRouter(group, handler.NewWorldHandler())
+	})
+}
+
+func worldRouter(group *gin.RouterGroup, h handler.WorldHandler) {
+	g := group.Group("/world")
+
+	// All the following routes require an access token from /api/v1/auth/login, every role can read
+	g.Use(auth.Middleware())
+
+	g.GET("/validate", h.Validate) // [get] /api/v1/world/validate
+	g.GET("/map", h.Map)           // [get] /api/v1/world/map
+
+	g.GET("/export", auth.Require(auth.RoleBuilder), h.Export) // [get] /api/v1/world/export
+	g.POST("/import", auth.Require(auth.RoleAdmin), h.Import)  // [post] /api/v1/world/import
+
+	r := group.Group("/room")
+	r.Use(auth.Middleware())
+	r.GET("/:id/path", h.Path) // [get] /api/v1/room/:id/path
+}
@@ -0,0 +1,224 @@
+// Package rule checks the mob and item payloads of the api before they are written: the ranges of
+// the stats, the cross-field rules and the unique id of the record. The defaults can be changed in
+// the rules section of fs.yml, and every field breaking a rule is returned to the client.
+package rule
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"fs/internal/config"
+	"fs/internal/model"
+)
+
+// the rules a field can break
+const (
+	RuleRange    = "range"    // the value is out of its range
+	RuleRequires = "requires" // the value is set, another field it requires is not
+	RuleRequired = "required" // the value is empty
+	RuleUnique   = "unique"   // the value is used by another record
+)
+
+// Range the values allowed for an int field, min and max included
+type Range struct {
+	Min int
+	Max int
+}
+
+// Violation a field of a payload breaking a rule
+type Violation struct {
+	Field   string `json:"field"`   // json name of the field
+	Rule    string `json:"rule"`    // range, requires, required or unique
+	Message string `json:"message"` // e.g. "must be between 1 and 100000"
+}
+
+// ExistsFunc check if a record other than exceptID has the value in its unique field, the ones in the trash included
+type ExistsFunc func(ctx context.Context, value string, exceptID uint64) (bool, error)
+
+// Rules the rules of the mob or of the item payloads, the fields are named by their json name
+type Rules struct {
+	Ranges   map[string]Range
+	Requires map[string]string // a field that is not zero requires another field that is not zero
+	Unique   string            // the string field identifying the record, required and unique
+
+	exists ExistsFunc
+	fields map[string]string // json name to struct field name of the model
+	order  []string          // json names in the order of the model, for the order of the violations
+}
+
+// DefaultMob the rules of the mobs when fs.yml does not change them
+var DefaultMob = Rules{
+	Ranges: map[string]Range{
+		"hp":      {Min: 1, Max: 100000},
+		"mp":      {Min: 0, Max: 100000},
+		"attack":  {Min: 0, Max: 1000},
+		"defence": {Min: 0, Max: 1000},
+		"dodge":   {Min: 0, Max: 1000},
+	},
+	Unique: "mobID",
+}
+
+// DefaultItem the rules of the items when fs.yml does not change them, the stats of an item are
+// added to the ones of the character wearing it, they are negative for a cursed item
+var DefaultItem = Rules{
+	Ranges: map[string]Range{
+		"hp":      {Min: -1000, Max: 1000},
+		"mp":      {Min: -1000, Max: 1000},
+		"attack":  {Min: -100, Max: 500},
+		"defence": {Min: -100, Max: 500},
+		"dodge":   {Min: -100, Max: 100},
+		"str":     {Min: -10, Max: 10},
+		"cor":     {Min: -10, Max: 10},
+		"inte":    {Min: -10, Max: 10},
+		"dex":     {Min: -10, Max: 10},
+		"con":     {Min: -10, Max: 10},
+		"kar":     {Min: -10, Max: 10},
+	},
+	Requires: map[string]string{"mp": "inte"},
+	Unique:   "itemID",
+}
+
+// NewMob the rules of the mobs, the defaults changed by cfg
+func NewMob(cfg config.RuleSet, exists ExistsFunc) (*Rules, error) {
+	return newRules(&model.Mob{}, DefaultMob, cfg, exists)
+}
+
+// NewItem the rules of the items, the defaults changed by cfg
+func NewItem(cfg config.RuleSet, exists ExistsFunc) (*Rules, error) {
+	return newRules(&model.Item{}, DefaultItem, cfg, exists)
+}
+
+// newRules the ranges of cfg replace the default ones field by field, the requires of cfg replace
+// all the default ones if set. The fields must be the int fields of the model.
+func newRules(table interface{}, def Rules, cfg config.RuleSet, exists ExistsFunc) (*Rules, error) {
+	r := &Rules{
+		Ranges:   make(map[string]Range, len(def.Ranges)+len(cfg.Ranges)),
+		Requires: def.Requires,
+		Unique:   def.Unique,
+		exists:   exists,
+		fields:   map[string]string{},
+	}
+
+	ints := map[string]bool{}
+	t := reflect.TypeOf(table).Elem()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := jsonName(field)
+		if field.Anonymous || name == "" {
+			continue
+		}
+		r.fields[name] = field.Name
+		r.order = append(r.order, name)
+		if field.Type.Kind() == reflect.Int && field.Name != "Version" {
+			ints[name] = true
+		}
+	}
+
+	for name, rg := range def.Ranges {
+		r.Ranges[name] = rg
+	}
+	for name, rg := range cfg.Ranges {
+		if !ints[name] {
+			return nil, fmt.Errorf("unknown field %q in the ranges of %s", name, t.Name())
+		}
+		if rg.Min > rg.Max {
+			return nil, fmt.Errorf("min %d is greater than max %d in the range of %s.%s", rg.Min, rg.Max, t.Name(), name)
+		}
+		r.Ranges[name] = Range{Min: rg.Min, Max: rg.Max}
+	}
+
+	if cfg.Requires != nil {
+		r.Requires = make(map[string]string, len(cfg.Requires))
+		for name, required := range cfg.Requires {
+			if !ints[name] || !ints[required] {
+				return nil, fmt.Errorf("unknown field in the requires %s: %s of %s", name, required, t.Name())
+			}
+			r.Requires[name] = required
+		}
+	}
+	return r, nil
+}
+
+// CheckCreate check every field of a record about to be created, table is a pointer to the model
+func (r *Rules) CheckCreate(ctx context.Context, table interface{}) ([]Violation, error) {
+	return r.check(ctx, table, func(string) bool { return true })
+}
+
+// CheckUpdate check a record as it is after an update or a patch, table is a pointer to the model
+// with the stored fields and the written ones, named by their struct field name. Only the rules of
+// the written fields are checked, so a record breaking a rule that has changed since it was written
+// can still be updated.
+func (r *Rules) CheckUpdate(ctx context.Context, table interface{}, written []string) ([]Violation, error) {
+	return r.check(ctx, table, func(name string) bool { return slices.Contains(written, r.fields[name]) })
+}
+
+func (r *Rules) check(ctx context.Context, table interface{}, isWritten func(name string) bool) ([]Violation, error) {
+	v := reflect.ValueOf(table).Elem()
+	intOf := func(name string) int { return int(v.FieldByName(r.fields[name]).Int()) }
+	var violations []Violation
+
+	for _, name := range r.order {
+		rg, ok := r.Ranges[name]
+		if !ok || !isWritten(name) {
+			continue
+		}
+		if n := intOf(name); n < rg.Min || n > rg.Max {
+			violations = append(violations, Violation{
+				Field:   name,
+				Rule:    RuleRange,
+				Message: fmt.Sprintf("must be between %d and %d", rg.Min, rg.Max),
+			})
+		}
+	}
+
+	for _, name := range r.order {
+		required, ok := r.Requires[name]
+		if ok && (isWritten(name) || isWritten(required)) && intOf(name) != 0 && intOf(required) == 0 {
+			violations = append(violations, Violation{
+				Field:   name,
+				Rule:    RuleRequires,
+				Message: "requires " + required + " to be set",
+			})
+		}
+	}
+
+	if r.Unique != "" && isWritten(r.Unique) {
+		value := v.FieldByName(r.fields[r.Unique]).String()
+		if strings.TrimSpace(value) == "" {
+			violations = append(violations, Violation{Field: r.Unique, Rule: RuleRequired, Message: "is required"})
+		} else if r.exists != nil {
+			exists, err := r.exists(ctx, value, v.FieldByName("ID").Uint())
+			if err != nil {
+				return nil, err
+			}
+			if exists {
+				violations = append(violations, r.UniqueViolation(table))
+			}
+		}
+	}
+
+	return violations, nil
+}
+
+// UniqueViolation the violation of the unique field of a record, also for a record the unique index of the
+// database refused, e.g. written by another request between the check and the write
+func (r *Rules) UniqueViolation(table interface{}) Violation {
+	value := reflect.ValueOf(table).Elem().FieldByName(r.fields[r.Unique]).String()
+	return Violation{
+		Field:   r.Unique,
+		Rule:    RuleUnique,
+		Message: value + " is already used, the records in the trash included",
+	}
+}
+
+// jsonName the json name of a struct field, empty if it is not encoded
+func jsonName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "-" {
+		return ""
+	}
+	return name
+}
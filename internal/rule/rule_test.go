@@ -0,0 +1,93 @@
+package rule_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"fs/internal/config"
+	"fs/internal/model"
+	"fs/internal/rule"
+)
+
+func TestNew(t *testing.T) {
+	r, err := rule.NewItem(config.RuleSet{
+		Ranges:   map[string]config.Range{"attack": {Min: 0, Max: 50}},
+		Requires: map[string]string{"str": "con"},
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, rule.Range{Min: 0, Max: 50}, r.Ranges["attack"])
+	assert.Equal(t, rule.DefaultItem.Ranges["kar"], r.Ranges["kar"])
+	assert.Equal(t, map[string]string{"str": "con"}, r.Requires)
+	assert.Equal(t, map[string]string{"mp": "inte"}, rule.DefaultItem.Requires)
+
+	for _, cfg := range []config.RuleSet{
+		{Ranges: map[string]config.Range{"itemname": {Max: 1}}},   // not an int field
+		{Ranges: map[string]config.Range{"version": {Max: 1}}},    // not a stat
+		{Ranges: map[string]config.Range{"hp": {Min: 2, Max: 1}}}, // empty range
+		{Requires: map[string]string{"mp": "wis"}},
+	} {
+		_, err = rule.NewItem(cfg, nil)
+		assert.Error(t, err, cfg)
+	}
+}
+
+func TestRules_CheckCreate(t *testing.T) {
+	var exceptID uint64
+	r, err := rule.NewMob(config.RuleSet{}, func(_ context.Context, value string, id uint64) (bool, error) {
+		exceptID = id
+		return value == "guard", nil
+	})
+	require.NoError(t, err)
+
+	violations, err := r.CheckCreate(context.Background(), &model.Mob{MobID: "cat", Hp: 100})
+	require.NoError(t, err)
+	assert.Empty(t, violations)
+
+	violations, err = r.CheckCreate(context.Background(), &model.Mob{MobID: "guard", Dodge: 1001})
+	require.NoError(t, err)
+	assert.Equal(t, []rule.Violation{
+		{Field: "hp", Rule: rule.RuleRange, Message: "must be between 1 and 100000"},
+		{Field: "dodge", Rule: rule.RuleRange, Message: "must be between 0 and 1000"},
+		{Field: "mobID", Rule: rule.RuleUnique, Message: "guard is already used, the records in the trash included"},
+	}, violations)
+	assert.Zero(t, exceptID)
+
+	violations, err = r.CheckCreate(context.Background(), &model.Mob{MobID: " ", Hp: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []rule.Violation{{Field: "mobID", Rule: rule.RuleRequired, Message: "is required"}}, violations)
+}
+
+func TestRules_CheckUpdate(t *testing.T) {
+	ctx := context.Background()
+	errDB := errors.New("db error")
+	var exceptID uint64
+	r, err := rule.NewItem(config.RuleSet{}, func(_ context.Context, _ string, id uint64) (bool, error) {
+		exceptID = id
+		return false, errDB
+	})
+	require.NoError(t, err)
+
+	// the rules of the fields not written are not checked, an item out of a range set later can be renamed
+	item := &model.Item{ID: 3, ItemID: "sword", ItemName: "Sword", Attack: 900, Mp: 5}
+	violations, err := r.CheckUpdate(ctx, item, []string{"ItemName"})
+	require.NoError(t, err)
+	assert.Empty(t, violations)
+
+	// a cross-field rule is checked when either field is written
+	violations, err = r.CheckUpdate(ctx, item, []string{"Inte"})
+	require.NoError(t, err)
+	assert.Equal(t, []rule.Violation{{Field: "mp", Rule: rule.RuleRequires, Message: "requires inte to be set"}}, violations)
+	item.Inte = 2
+	violations, err = r.CheckUpdate(ctx, item, []string{"Mp", "Attack"})
+	require.NoError(t, err)
+	assert.Equal(t, []rule.Violation{{Field: "attack", Rule: rule.RuleRange, Message: "must be between -100 and 500"}}, violations)
+
+	// the record itself is not a duplicate
+	_, err = r.CheckUpdate(ctx, item, []string{"ItemID"})
+	assert.ErrorIs(t, err, errDB)
+	assert.Equal(t, uint64(3), exceptID)
+}
@@ -0,0 +1,37 @@
+package server
+
+import (
+	"github.com/go-dev-frame/sponge/pkg/app"
+
+	"fs/internal/game"
+)
+
+var _ app.IServer = (*gameServer)(nil)
+
+type gameServer struct {
+	addr   string
+	server *game.Server
+}
+
+// Start telnet game service
+func (s *gameServer) Start() error {
+	return s.server.ListenAndServe(s.addr)
+}
+
+// Stop telnet game service
+func (s *gameServer) Stop() error {
+	return s.server.Shutdown()
+}
+
+// String comment
+func (s *gameServer) String() string {
+	return "telnet service address is " + s.addr
+}
+
+// NewGameServer creates a new telnet game server
+func NewGameServer(addr string, opts ...game.Option) app.IServer {
+	return &gameServer{
+		addr:   addr,
+		server: game.NewServer(game.NewWorld(), opts...),
+	}
+}
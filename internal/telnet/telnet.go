@@ -0,0 +1,274 @@
+// Package telnet implements the part of the telnet protocol (RFC 854) a MUD client
+// relies on: option negotiation, line buffering with CR/LF normalisation and
+// echo control for password prompts.
+package telnet
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"sync"
+)
+
+// telnet commands
+const (
+	SE   byte = 240 // end of sub-negotiation
+	NOP  byte = 241 // no operation
+	GA   byte = 249 // go ahead
+	SB   byte = 250 // begin sub-negotiation
+	WILL byte = 251
+	WONT byte = 252
+	DO   byte = 253
+	DONT byte = 254
+	IAC  byte = 255 // interpret as command
+)
+
+// telnet options
+const (
+	OptEcho            byte = 1
+	OptSuppressGoAhead byte = 3
+)
+
+// MaxLineLength the longest line accepted from a client, the rest of the line is discarded
+const MaxLineLength = 512
+
+const (
+	cr        = '\r'
+	lf        = '\n'
+	nul       = 0
+	backspace = 8
+	del       = 127
+)
+
+// Conn a telnet connection
+type Conn struct {
+	net.Conn
+
+	r  *bufio.Reader
+	wm sync.Mutex
+
+	// options enabled on our side (WILL)
+	local map[byte]bool
+	// options we agree to enable on our side when the client asks
+	supported map[byte]bool
+	// options already refused, they are never refused twice
+	refused map[byte]bool
+
+	// the previous line ended with CR, a following LF or NUL belongs to it
+	lastCR bool
+}
+
+// NewConn wrap a net.Conn as a telnet connection
+func NewConn(c net.Conn) *Conn {
+	return &Conn{
+		Conn:  c,
+		r:     bufio.NewReader(c),
+		local: map[byte]bool{},
+		supported: map[byte]bool{
+			OptEcho:            true,
+			OptSuppressGoAhead: true,
+		},
+		refused: map[byte]bool{},
+	}
+}
+
+// Negotiate announce the options the server uses by default,
+// character mode is not requested, the client keeps line buffering.
+func (c *Conn) Negotiate() error {
+	return c.setLocal(OptSuppressGoAhead, true)
+}
+
+// SetEcho switch server side echo. A client stops echoing locally when the
+// server says WILL ECHO, the server then simply does not echo, which hides passwords.
+func (c *Conn) SetEcho(hide bool) error {
+	return c.setLocal(OptEcho, hide)
+}
+
+func (c *Conn) setLocal(opt byte, enable bool) error {
+	if c.local[opt] == enable {
+		return nil
+	}
+	c.local[opt] = enable
+	cmd := WONT
+	if enable {
+		cmd = WILL
+	}
+	return c.writeCommand(cmd, opt)
+}
+
+// ReadLine read a line of text from the client, telnet commands are processed and
+// removed, CR LF, CR NUL and a lone LF all end a line, backspaces are applied.
+func (c *Conn) ReadLine() (string, error) {
+	line := make([]byte, 0, 64)
+	for {
+		b, err := c.r.ReadByte()
+		if err != nil {
+			return "", err
+		}
+
+		if c.lastCR {
+			c.lastCR = false
+			if b == lf || b == nul {
+				continue
+			}
+		}
+
+		switch b {
+		case IAC:
+			data, err := c.readCommand()
+			if err != nil {
+				return "", err
+			}
+			if data && len(line) < MaxLineLength {
+				line = append(line, IAC)
+			}
+		case cr:
+			c.lastCR = true
+			return string(line), nil
+		case lf:
+			return string(line), nil
+		case backspace, del:
+			if len(line) > 0 {
+				line = line[:len(line)-1]
+			}
+		case nul:
+		default:
+			if len(line) < MaxLineLength {
+				line = append(line, b)
+			}
+		}
+	}
+}
+
+// ReadPassword write the prompt and read a line with echo disabled
+func (c *Conn) ReadPassword(prompt string) (string, error) {
+	if err := c.SetEcho(true); err != nil {
+		return "", err
+	}
+	if _, err := c.WriteString(prompt); err != nil {
+		return "", err
+	}
+	line, err := c.ReadLine()
+	if err != nil {
+		return "", err
+	}
+	if err = c.SetEcho(false); err != nil {
+		return "", err
+	}
+	_, err = c.WriteString("\n")
+	return line, err
+}
+
+// readCommand process the command following an IAC, data is true when the
+// sequence was an escaped 255 data byte.
+func (c *Conn) readCommand() (data bool, err error) {
+	cmd, err := c.r.ReadByte()
+	if err != nil {
+		return false, err
+	}
+
+	switch cmd {
+	case IAC:
+		return true, nil
+	case WILL, WONT, DO, DONT:
+		opt, err := c.r.ReadByte()
+		if err != nil {
+			return false, err
+		}
+		return false, c.answer(cmd, opt)
+	case SB:
+		return false, c.skipSubNegotiation()
+	}
+
+	// NOP, GA, AYT and the other single byte commands carry no state
+	return false, nil
+}
+
+// answer reply to an option request, replies are only sent when the state
+// changes, which prevents negotiation loops (RFC 1143).
+func (c *Conn) answer(cmd byte, opt byte) error {
+	switch cmd {
+	case DO:
+		if c.supported[opt] {
+			return c.setLocal(opt, true)
+		}
+		return c.refuse(WONT, opt)
+	case DONT:
+		return c.setLocal(opt, false)
+	case WILL:
+		// the server does not need any client side option
+		return c.refuse(DONT, opt)
+	}
+	return nil
+}
+
+func (c *Conn) refuse(cmd byte, opt byte) error {
+	if c.refused[opt] {
+		return nil
+	}
+	c.refused[opt] = true
+	return c.writeCommand(cmd, opt)
+}
+
+func (c *Conn) skipSubNegotiation() error {
+	for {
+		b, err := c.r.ReadByte()
+		if err != nil {
+			return err
+		}
+		if b != IAC {
+			continue
+		}
+		b, err = c.r.ReadByte()
+		if err != nil {
+			return err
+		}
+		if b == SE {
+			return nil
+		}
+	}
+}
+
+// Write send text to the client, LF is converted to CR LF and 255 is escaped
+func (c *Conn) Write(p []byte) (int, error) {
+	buf := make([]byte, 0, len(p)+8)
+	for i, b := range p {
+		switch b {
+		case lf:
+			if i == 0 || p[i-1] != cr {
+				buf = append(buf, cr)
+			}
+			buf = append(buf, lf)
+		case IAC:
+			buf = append(buf, IAC, IAC)
+		default:
+			buf = append(buf, b)
+		}
+	}
+	if _, err := c.writeRaw(buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// WriteString send text to the client
+func (c *Conn) WriteString(s string) (int, error) {
+	return c.Write([]byte(s))
+}
+
+// Printf send formatted text to the client
+func (c *Conn) Printf(format string, a ...interface{}) error {
+	_, err := c.WriteString(fmt.Sprintf(format, a...))
+	return err
+}
+
+func (c *Conn) writeCommand(cmd byte, opt byte) error {
+	_, err := c.writeRaw([]byte{IAC, cmd, opt})
+	return err
+}
+
+func (c *Conn) writeRaw(p []byte) (int, error) {
+	c.wm.Lock()
+	defer c.wm.Unlock()
+	return c.Conn.Write(p)
+}
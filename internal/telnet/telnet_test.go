@@ -0,0 +1,97 @@
+package telnet
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestConn(t *testing.T) (*Conn, net.Conn) {
+	server, client := net.Pipe()
+	t.Cleanup(func() {
+		_ = server.Close()
+		_ = client.Close()
+	})
+	return NewConn(server), client
+}
+
+// read everything the server sends until it has been quiet for a moment
+func drain(client net.Conn) []byte {
+	var out []byte
+	buf := make([]byte, 256)
+	for {
+		_ = client.SetReadDeadline(time.Now().Add(50 * time.Millisecond))
+		n, err := client.Read(buf)
+		out = append(out, buf[:n]...)
+		if err != nil {
+			return out
+		}
+	}
+}
+
+func TestConn_ReadLine(t *testing.T) {
+	c, client := newTestConn(t)
+
+	go func() {
+		_, _ = client.Write([]byte("look\r\nsay hi\r\x00north\nab\x08c\r\n"))
+	}()
+
+	for _, want := range []string{"look", "say hi", "north", "ac"} {
+		got, err := c.ReadLine()
+		assert.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+}
+
+func TestConn_ReadLine_Negotiation(t *testing.T) {
+	c, client := newTestConn(t)
+
+	done := make(chan string)
+	go func() {
+		line, _ := c.ReadLine()
+		done <- line
+	}()
+
+	// IAC DO ECHO is accepted, IAC WILL NAWS is refused, sub-negotiation is dropped, IAC IAC is data
+	_, _ = client.Write([]byte{IAC, DO, OptEcho})
+	assert.Equal(t, []byte{IAC, WILL, OptEcho}, drain(client))
+	_, _ = client.Write([]byte{IAC, WILL, 31})
+	assert.Equal(t, []byte{IAC, DONT, 31}, drain(client))
+	_, _ = client.Write([]byte{IAC, WILL, 31})
+	assert.Empty(t, drain(client))
+	_, _ = client.Write([]byte{'h', IAC, SB, 31, 0, 80, 0, 24, IAC, SE, 'i', IAC, IAC, '\r', '\n'})
+
+	assert.Equal(t, "hi\xff", <-done)
+}
+
+func TestConn_ReadPassword(t *testing.T) {
+	c, client := newTestConn(t)
+
+	done := make(chan string)
+	go func() {
+		line, _ := c.ReadPassword("Password: ")
+		done <- line
+	}()
+
+	got := make([]byte, 3+len("Password: "))
+	_, err := io.ReadFull(client, got)
+	assert.NoError(t, err)
+	assert.Equal(t, append([]byte{IAC, WILL, OptEcho}, "Password: "...), got)
+
+	_, _ = client.Write([]byte("secret\r\n"))
+	assert.Equal(t, append([]byte{IAC, WONT, OptEcho}, "\r\n"...), drain(client))
+	assert.Equal(t, "secret", <-done)
+}
+
+func TestConn_Write(t *testing.T) {
+	c, client := newTestConn(t)
+
+	go func() {
+		_, _ = c.WriteString("a\nb\r\n\xff")
+	}()
+
+	assert.Equal(t, []byte("a\r\nb\r\n\xff\xff"), drain(client))
+}
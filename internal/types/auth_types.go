@@ -0,0 +1,45 @@
+package types
+
+// LoginRequest request params
+type LoginRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// RefreshTokenRequest request params
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
+}
+
+// AuthTokens the tokens of a login, send the access token as "Authorization: Bearer <token>"
+type AuthTokens struct {
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken"` // exchanged for new tokens at /api/v1/auth/refresh
+	Role         string `json:"role"`         // player, builder or admin
+}
+
+// LoginReply only for api docs
+type LoginReply struct {
+	Code int        `json:"code"` // return code
+	Msg  string     `json:"msg"`  // return information description
+	Data AuthTokens `json:"data"` // return data
+}
+
+// RefreshTokenReply only for api docs
+type RefreshTokenReply struct {
+	Code int        `json:"code"` // return code
+	Msg  string     `json:"msg"`  // return information description
+	Data AuthTokens `json:"data"` // return data
+}
+
+// SetAccountRoleRequest request params
+type SetAccountRoleRequest struct {
+	Role string `json:"role" binding:"oneof=player builder admin"` // see auth.Roles
+}
+
+// SetAccountRoleReply only for api docs
+type SetAccountRoleReply struct {
+	Code int      `json:"code"` // return code
+	Msg  string   `json:"msg"`  // return information description
+	Data struct{} `json:"data"` // return data
+}
@@ -0,0 +1,36 @@
+package types
+
+import (
+	"fs/internal/equip"
+)
+
+// PreviewEquipmentRequest request params
+type PreviewEquipmentRequest struct {
+	Base        equip.Stats `json:"base"`                         // stats of the character without items
+	ItemIDs     []uint64    `json:"itemIDs" binding:"max=20"`     // item prototypes to wear
+	InstanceIDs []uint64    `json:"instanceIDs" binding:"max=20"` // item instances to wear, their stat overrides apply
+}
+
+// EquippedItemDetail an item worn in a slot
+type EquippedItemDetail struct {
+	Slot       string      `json:"slot"`
+	ItemID     uint64      `json:"itemID"`               // item.id of the prototype
+	InstanceID uint64      `json:"instanceID,omitempty"` // item_instance.id, 0 if a prototype was given
+	ItemName   string      `json:"itemName"`
+	Classifier string      `json:"classifier"`
+	Stats      equip.Stats `json:"stats"` // bonuses of the item
+}
+
+// EquipmentPreview the outfit and the stats of the character wearing it
+type EquipmentPreview struct {
+	Slots []*EquippedItemDetail `json:"slots"` // in slot order
+	Bonus equip.Stats           `json:"bonus"` // sum of the bonuses of the worn items
+	Stats equip.Stats           `json:"stats"` // base stats plus the bonuses
+}
+
+// PreviewEquipmentReply only for api docs
+type PreviewEquipmentReply struct {
+	Code int              `json:"code"` // return code
+	Msg  string           `json:"msg"`  // return information description
+	Data EquipmentPreview `json:"data"` // return data
+}
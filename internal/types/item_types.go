@@ -27,7 +27,7 @@ type CreateItemRequest struct {
 	Dex        int    `json:"dex" binding:""`
 	Con        int    `json:"con" binding:""`
 	Kar        int    `json:"kar" binding:""`
-	Classifier string `json:"classifier" binding:""`
+	Classifier string `json:"classifier" binding:"omitempty,oneof=w s h a g l b n r c o"` // see equip.Classifiers
 }
 
 // UpdateItemByIDRequest request params
@@ -49,7 +49,32 @@ type UpdateItemByIDRequest struct {
 	Dex        int    `json:"dex" binding:""`
 	Con        int    `json:"con" binding:""`
 	Kar        int    `json:"kar" binding:""`
-	Classifier string `json:"classifier" binding:""`
+	Classifier string `json:"classifier" binding:"omitempty,oneof=w s h a g l b n r c o"` // see equip.Classifiers
+
+	Version int `json:"version" binding:""` // version read with GetByID, can also be sent in the If-Match header
+}
+
+// PatchItemByIDRequest request params, a JSON merge patch (RFC 7396), the fields present are written
+// even if they are zero or empty, a null field is reset to its zero value, absent fields are untouched
+type PatchItemByIDRequest struct {
+	ItemID     *string `json:"itemID" binding:""`
+	ItemName   *string `json:"itemName" binding:""`
+	ItemCname  *string `json:"itemCname" binding:""`
+	ItemDesc   *string `json:"itemDesc" binding:""`
+	Hp         *int    `json:"hp" binding:""`
+	Mp         *int    `json:"mp" binding:""`
+	Attack     *int    `json:"attack" binding:""`
+	Defence    *int    `json:"defence" binding:""`
+	Dodge      *int    `json:"dodge" binding:""`
+	Str        *int    `json:"str" binding:""`
+	Cor        *int    `json:"cor" binding:""`
+	Inte       *int    `json:"inte" binding:""`
+	Dex        *int    `json:"dex" binding:""`
+	Con        *int    `json:"con" binding:""`
+	Kar        *int    `json:"kar" binding:""`
+	Classifier *string `json:"classifier" binding:"omitempty,oneof=w s h a g l b n r c o"` // see equip.Classifiers
+
+	Version int `json:"version" binding:""` // version read with GetByID, can also be sent in the If-Match header
 }
 
 // ItemObjDetail detail
@@ -72,6 +97,8 @@ type ItemObjDetail struct {
 	Con        int    `json:"con"`
 	Kar        int    `json:"kar"`
 	Classifier string `json:"classifier"`
+
+	Version int `json:"version"` // also in the ETag header of GetByID, send it back to update the item
 }
 
 // CreateItemReply only for api docs
@@ -90,13 +117,31 @@ type DeleteItemByIDReply struct {
 	Data struct{} `json:"data"` // return data
 }
 
-// UpdateItemByIDReply only for api docs
-type UpdateItemByIDReply struct {
+// RestoreItemByIDReply only for api docs
+type RestoreItemByIDReply struct {
 	Code int      `json:"code"` // return code
 	Msg  string   `json:"msg"`  // return information description
 	Data struct{} `json:"data"` // return data
 }
 
+// UpdateItemByIDReply only for api docs
+type UpdateItemByIDReply struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		Version int `json:"version"` // new version of the item
+	} `json:"data"` // return data
+}
+
+// PatchItemByIDReply only for api docs
+type PatchItemByIDReply struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		Version int `json:"version"` // new version of the item
+	} `json:"data"` // return data
+}
+
 // GetItemByIDReply only for api docs
 type GetItemByIDReply struct {
 	Code int    `json:"code"` // return code
@@ -119,3 +164,17 @@ type ListItemsReply struct {
 		Items []ItemObjDetail `json:"items"`
 	} `json:"data"` // return data
 }
+
+// ListItemsByIDsRequest request params
+type ListItemsByIDsRequest struct {
+	IDs []uint64 `json:"ids" binding:"min=1,max=100"` // id list, 100 at most
+}
+
+// ListItemsByIDsReply only for api docs
+type ListItemsByIDsReply struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		Items []ItemObjDetail `json:"items"`
+	} `json:"data"` // return data
+}
@@ -4,6 +4,8 @@ import (
 	"time"
 
 	"github.com/go-dev-frame/sponge/pkg/sgorm/query"
+
+	"fs/internal/reference"
 )
 
 var _ time.Time
@@ -38,6 +40,25 @@ type UpdateMobByIDRequest struct {
 	Attack     int    `json:"attack" binding:""`
 	Defence    int    `json:"defence" binding:""`
 	Dodge      int    `json:"dodge" binding:""`
+
+	Version int `json:"version" binding:""` // version read with GetByID, can also be sent in the If-Match header
+}
+
+// PatchMobByIDRequest request params, a JSON merge patch (RFC 7396), the fields present are written
+// even if they are zero or empty, a null field is reset to its zero value, absent fields are untouched
+type PatchMobByIDRequest struct {
+	MobID      *string `json:"mobID" binding:""`
+	MobName    *string `json:"mobName" binding:""`
+	MobCname   *string `json:"mobCname" binding:""`
+	MobDesc    *string `json:"mobDesc" binding:""`
+	Attackable *bool   `json:"attackable" binding:""`
+	Hp         *int    `json:"hp" binding:""`
+	Mp         *int    `json:"mp" binding:""`
+	Attack     *int    `json:"attack" binding:""`
+	Defence    *int    `json:"defence" binding:""`
+	Dodge      *int    `json:"dodge" binding:""`
+
+	Version int `json:"version" binding:""` // version read with GetByID, can also be sent in the If-Match header
 }
 
 // MobObjDetail detail
@@ -54,6 +75,8 @@ type MobObjDetail struct {
 	Attack     int    `json:"attack"`
 	Defence    int    `json:"defence"`
 	Dodge      int    `json:"dodge"`
+
+	Version int `json:"version"` // also in the ETag header of GetByID, send it back to update the mob
 }
 
 // CreateMobReply only for api docs
@@ -67,6 +90,15 @@ type CreateMobReply struct {
 
 // DeleteMobByIDReply only for api docs
 type DeleteMobByIDReply struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		References []reference.Reference `json:"references"` // removed with ?cascade=true
+	} `json:"data"` // return data
+}
+
+// RestoreMobByIDReply only for api docs
+type RestoreMobByIDReply struct {
 	Code int      `json:"code"` // return code
 	Msg  string   `json:"msg"`  // return information description
 	Data struct{} `json:"data"` // return data
@@ -74,9 +106,20 @@ type DeleteMobByIDReply struct {
 
 // UpdateMobByIDReply only for api docs
 type UpdateMobByIDReply struct {
-	Code int      `json:"code"` // return code
-	Msg  string   `json:"msg"`  // return information description
-	Data struct{} `json:"data"` // return data
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		Version int `json:"version"` // new version of the mob
+	} `json:"data"` // return data
+}
+
+// PatchMobByIDReply only for api docs
+type PatchMobByIDReply struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		Version int `json:"version"` // new version of the mob
+	} `json:"data"` // return data
 }
 
 // GetMobByIDReply only for api docs
@@ -101,3 +144,17 @@ type ListMobsReply struct {
 		Mobs []MobObjDetail `json:"mobs"`
 	} `json:"data"` // return data
 }
+
+// ListMobsByIDsRequest request params
+type ListMobsByIDsRequest struct {
+	IDs []uint64 `json:"ids" binding:"min=1,max=100"` // id list, 100 at most
+}
+
+// ListMobsByIDsReply only for api docs
+type ListMobsByIDsReply struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		Mobs []MobObjDetail `json:"mobs"`
+	} `json:"data"` // return data
+}
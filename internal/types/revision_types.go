@@ -0,0 +1,49 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+
+	"fs/internal/revision"
+)
+
+// RevisionObjDetail detail
+type RevisionObjDetail struct {
+	ID uint64 `json:"id"` // convert to uint64 id
+
+	EntityType string          `json:"entityType"` // room, mob or item
+	EntityID   string          `json:"entityID"`
+	Action     string          `json:"action"` // create, update or delete
+	Actor      string          `json:"actor"`  // name of the account that made the change, empty if not made through the api
+	CreatedAt  time.Time       `json:"createdAt"`
+	Before     json.RawMessage `json:"before"` // the record before the change, null for a create
+	After      json.RawMessage `json:"after"`  // the record after the change, null for a delete
+}
+
+// ListRevisionsReply only for api docs
+type ListRevisionsReply struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		Revisions []RevisionObjDetail `json:"revisions"` // newest first
+		Total     int64               `json:"total"`
+	} `json:"data"` // return data
+}
+
+// DiffRevisionsReply only for api docs
+type DiffRevisionsReply struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		From    uint64            `json:"from"`
+		To      uint64            `json:"to"`
+		Changes []revision.Change `json:"changes"` // fields of the record that differ, ordered by field
+	} `json:"data"` // return data
+}
+
+// RestoreRevisionReply only for api docs
+type RestoreRevisionReply struct {
+	Code int      `json:"code"` // return code
+	Msg  string   `json:"msg"`  // return information description
+	Data struct{} `json:"data"` // return data
+}
@@ -0,0 +1,104 @@
+package types
+
+import (
+	"time"
+)
+
+var _ time.Time
+
+// Tip: suggested filling in the binding rules https://github.com/go-playground/validator in request struct fields tag.
+
+// CreateRoomExitRequest request params
+type CreateRoomExitRequest struct {
+	Direction string `json:"direction" binding:"required"` // north, south, east, west, northeast, northwest, southeast, southwest, up, down or their abbreviations
+	ToRoomID  string `json:"toRoomID" binding:"required"`  // id of the room the exit leads to
+	Door      *bool  `json:"door" binding:""`
+	Locked    *bool  `json:"locked" binding:""`
+	Hidden    *bool  `json:"hidden" binding:""`
+	Cost      int    `json:"cost" binding:"gte=0"` // travel cost for weighted path finding, default is 1
+}
+
+// UpdateRoomExitRequest request params
+type UpdateRoomExitRequest struct {
+	ToRoomID string `json:"toRoomID" binding:""`
+	Door     *bool  `json:"door" binding:""`
+	Locked   *bool  `json:"locked" binding:""`
+	Hidden   *bool  `json:"hidden" binding:""`
+	Cost     int    `json:"cost" binding:"gte=0"`
+}
+
+// RoomExitObjDetail detail
+type RoomExitObjDetail struct {
+	ID        uint64 `json:"id"`
+	RoomID    string `json:"roomID"`
+	Direction string `json:"direction"`
+	ToRoomID  string `json:"toRoomID"`
+	Door      *bool  `json:"door"`
+	Locked    *bool  `json:"locked"`
+	Hidden    *bool  `json:"hidden"`
+	Cost      int    `json:"cost"`
+}
+
+// CreateRoomExitReply only for api docs
+type CreateRoomExitReply struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		ID uint64 `json:"id"` // id
+	} `json:"data"` // return data
+}
+
+// DeleteRoomExitReply only for api docs
+type DeleteRoomExitReply struct {
+	Code int      `json:"code"` // return code
+	Msg  string   `json:"msg"`  // return information description
+	Data struct{} `json:"data"` // return data
+}
+
+// UpdateRoomExitReply only for api docs
+type UpdateRoomExitReply struct {
+	Code int      `json:"code"` // return code
+	Msg  string   `json:"msg"`  // return information description
+	Data struct{} `json:"data"` // return data
+}
+
+// GetRoomExitReply only for api docs
+type GetRoomExitReply struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		Exit RoomExitObjDetail `json:"exit"`
+	} `json:"data"` // return data
+}
+
+// ListRoomExitsReply only for api docs
+type ListRoomExitsReply struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		Exits []RoomExitObjDetail `json:"exits"`
+	} `json:"data"` // return data
+}
+
+// MigrateRoomWaysReport result of converting Room.Way into exits
+type MigrateRoomWaysReport struct {
+	DryRun   bool                  `json:"dryRun"`   // true if nothing was written
+	Rooms    int                   `json:"rooms"`    // number of rooms scanned
+	Created  []RoomExitObjDetail   `json:"created"`  // exits created, or that would be created in dry run
+	Skipped  []RoomWayMigrateIssue `json:"skipped"`  // exits already present or pointing to unknown rooms
+	Unparsed []RoomWayMigrateIssue `json:"unparsed"` // Way entries that could not be understood
+}
+
+// RoomWayMigrateIssue a Way entry that was not migrated
+type RoomWayMigrateIssue struct {
+	RoomID string `json:"roomID"`
+	Entry  string `json:"entry"`
+	Reason string `json:"reason"`
+}
+
+// MigrateRoomWaysReply only for api docs
+type MigrateRoomWaysReply struct {
+	Code int                   `json:"code"` // return code
+	Msg  string                `json:"msg"`  // return information description
+	Data MigrateRoomWaysReport `json:"data"` // return data
+}
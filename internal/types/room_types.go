@@ -4,6 +4,8 @@ import (
 	"time"
 
 	"github.com/go-dev-frame/sponge/pkg/sgorm/query"
+
+	"fs/internal/reference"
 )
 
 var _ time.Time
@@ -25,6 +27,19 @@ type UpdateRoomByIDRequest struct {
 	Desc  string `json:"desc" binding:""`
 	Way   string `json:"way" binding:""`
 	Mobs  string `json:"mobs" binding:""`
+
+	Version int `json:"version" binding:""` // version read with GetByID, can also be sent in the If-Match header
+}
+
+// PatchRoomByIDRequest request params, a JSON merge patch (RFC 7396), the fields present are written
+// even if they are zero or empty, a null field is reset to its zero value, absent fields are untouched
+type PatchRoomByIDRequest struct {
+	Title *string `json:"title" binding:""`
+	Desc  *string `json:"desc" binding:""`
+	Way   *string `json:"way" binding:""`
+	Mobs  *string `json:"mobs" binding:""`
+
+	Version int `json:"version" binding:""` // version read with GetByID, can also be sent in the If-Match header
 }
 
 // RoomObjDetail detail
@@ -34,6 +49,10 @@ type RoomObjDetail struct {
 	Desc  string `json:"desc"`
 	Way   string `json:"way"`
 	Mobs  string `json:"mobs"`
+
+	Version int `json:"version"` // also in the ETag header of GetByID, send it back to update the room
+
+	MobDetails []*MobObjDetail `json:"mobDetails,omitempty"` // the mobs listed in Mobs, only with ?expand=mobs
 }
 
 // CreateRoomReply only for api docs
@@ -47,6 +66,15 @@ type CreateRoomReply struct {
 
 // DeleteRoomByIDReply only for api docs
 type DeleteRoomByIDReply struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		References []reference.Reference `json:"references"` // removed with ?cascade=true
+	} `json:"data"` // return data
+}
+
+// RestoreRoomByIDReply only for api docs
+type RestoreRoomByIDReply struct {
 	Code int      `json:"code"` // return code
 	Msg  string   `json:"msg"`  // return information description
 	Data struct{} `json:"data"` // return data
@@ -54,9 +82,20 @@ type DeleteRoomByIDReply struct {
 
 // UpdateRoomByIDReply only for api docs
 type UpdateRoomByIDReply struct {
-	Code int      `json:"code"` // return code
-	Msg  string   `json:"msg"`  // return information description
-	Data struct{} `json:"data"` // return data
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		Version int `json:"version"` // new version of the room
+	} `json:"data"` // return data
+}
+
+// PatchRoomByIDReply only for api docs
+type PatchRoomByIDReply struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		Version int `json:"version"` // new version of the room
+	} `json:"data"` // return data
 }
 
 // GetRoomByIDReply only for api docs
@@ -81,3 +120,17 @@ type ListRoomsReply struct {
 		Rooms []RoomObjDetail `json:"rooms"`
 	} `json:"data"` // return data
 }
+
+// ListRoomsByIDsRequest request params
+type ListRoomsByIDsRequest struct {
+	IDs []string `json:"ids" binding:"min=1,max=100"` // id list, 100 at most
+}
+
+// ListRoomsByIDsReply only for api docs
+type ListRoomsByIDsReply struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		Rooms []RoomObjDetail `json:"rooms"`
+	} `json:"data"` // return data
+}
@@ -0,0 +1,32 @@
+package types
+
+import (
+	"fs/internal/combat"
+	"fs/internal/equip"
+)
+
+// SimulateCombatRequest request params
+type SimulateCombatRequest struct {
+	MobID     uint64      `json:"mobID" binding:"gt=0"`                         // mob.id of the opponent, it wears the items located at it
+	Base      equip.Stats `json:"base"`                                         // stats of the character without items, all 0 means a new character
+	ItemIDs   []uint64    `json:"itemIDs" binding:"max=20"`                     // item prototypes the character wears
+	Fights    int         `json:"fights" binding:"omitempty,min=1,max=10000"`   // number of fights, default 1000
+	MaxRounds int         `json:"maxRounds" binding:"omitempty,min=1,max=1000"` // a fight still going after this many rounds is a draw, default 200
+	Seed      int64       `json:"seed"`                                         // the same seed gives the same result
+}
+
+// CombatSimulation the stats both sides fought with and how the fights went
+type CombatSimulation struct {
+	Character    combat.Stats `json:"character"`    // base stats plus the items
+	Mob          combat.Stats `json:"mob"`          // the mob plus its items
+	HitChance    int          `json:"hitChance"`    // percent chance that the character hits the mob
+	MobHitChance int          `json:"mobHitChance"` // percent chance that the mob hits the character
+	combat.Simulation
+}
+
+// SimulateCombatReply only for api docs
+type SimulateCombatReply struct {
+	Code int              `json:"code"` // return code
+	Msg  string           `json:"msg"`  // return information description
+	Data CombatSimulation `json:"data"` // return data
+}
@@ -0,0 +1,102 @@
+package types
+
+import (
+	"time"
+
+	"github.com/go-dev-frame/sponge/pkg/sgorm/query"
+)
+
+var _ time.Time
+
+// Tip: suggested filling in the binding rules https://github.com/go-playground/validator in request struct fields tag.
+
+// CreateSpawnRequest request params
+type CreateSpawnRequest struct {
+	RoomID         string `json:"roomID" binding:"required"`
+	MobID          uint64 `json:"mobID" binding:"required"`                 // mob.id of the prototype
+	MaxCount       int    `json:"maxCount" binding:"gte=0"`                 // most alive at the same time, default is 1
+	RespawnSeconds int    `json:"respawnSeconds" binding:"gte=0"`           // seconds before a killed mob comes back, default is 300
+	Chance         int    `json:"chance" binding:"omitempty,min=1,max=100"` // percent chance a respawn succeeds, default is 100
+}
+
+// UpdateSpawnByIDRequest request params
+type UpdateSpawnByIDRequest struct {
+	ID uint64 `json:"id" binding:""` // uint64 id
+
+	RoomID         string `json:"roomID" binding:""`
+	MobID          uint64 `json:"mobID" binding:""`
+	MaxCount       int    `json:"maxCount" binding:"gte=0"`
+	RespawnSeconds int    `json:"respawnSeconds" binding:"gte=0"`
+	Chance         int    `json:"chance" binding:"omitempty,min=1,max=100"`
+}
+
+// SpawnObjDetail detail
+type SpawnObjDetail struct {
+	ID uint64 `json:"id"` // convert to uint64 id
+
+	RoomID         string `json:"roomID"`
+	MobID          uint64 `json:"mobID"`
+	MaxCount       int    `json:"maxCount"`
+	RespawnSeconds int    `json:"respawnSeconds"`
+	Chance         int    `json:"chance"`
+}
+
+// CreateSpawnReply only for api docs
+type CreateSpawnReply struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		ID uint64 `json:"id"` // id
+	} `json:"data"` // return data
+}
+
+// DeleteSpawnByIDReply only for api docs
+type DeleteSpawnByIDReply struct {
+	Code int      `json:"code"` // return code
+	Msg  string   `json:"msg"`  // return information description
+	Data struct{} `json:"data"` // return data
+}
+
+// UpdateSpawnByIDReply only for api docs
+type UpdateSpawnByIDReply struct {
+	Code int      `json:"code"` // return code
+	Msg  string   `json:"msg"`  // return information description
+	Data struct{} `json:"data"` // return data
+}
+
+// GetSpawnByIDReply only for api docs
+type GetSpawnByIDReply struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		Spawn SpawnObjDetail `json:"spawn"`
+	} `json:"data"` // return data
+}
+
+// ListSpawnsRequest request params
+type ListSpawnsRequest struct {
+	query.Params
+}
+
+// ListSpawnsReply only for api docs
+type ListSpawnsReply struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		Spawns []SpawnObjDetail `json:"spawns"`
+	} `json:"data"` // return data
+}
+
+// ListSpawnsByIDsRequest request params
+type ListSpawnsByIDsRequest struct {
+	IDs []uint64 `json:"ids" binding:"min=1,max=100"` // id list, 100 at most
+}
+
+// ListSpawnsByIDsReply only for api docs
+type ListSpawnsByIDsReply struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		Spawns []SpawnObjDetail `json:"spawns"`
+	} `json:"data"` // return data
+}
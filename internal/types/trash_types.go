@@ -0,0 +1,37 @@
+package types
+
+import (
+	"time"
+)
+
+// TrashObjDetail a room, mob or item in the trash
+type TrashObjDetail struct {
+	ID        string    `json:"id"`        // room id, or the uint64 id of a mob or item as text
+	Name      string    `json:"name"`      // title of a room, name of a mob or item
+	DeletedAt time.Time `json:"deletedAt"` // when it was moved to the trash
+	PurgeAt   time.Time `json:"purgeAt"`   // when it can be purged, database.trashDays in the config after DeletedAt
+}
+
+// ListTrashReply only for api docs
+type ListTrashReply struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		Records []TrashObjDetail `json:"records"` // the latest deleted first
+		Total   int64            `json:"total"`
+	} `json:"data"` // return data
+}
+
+// PurgeTrashCounts number of records purged from the trash
+type PurgeTrashCounts struct {
+	Rooms int64 `json:"rooms"`
+	Mobs  int64 `json:"mobs"`
+	Items int64 `json:"items"`
+}
+
+// PurgeTrashReply only for api docs
+type PurgeTrashReply struct {
+	Code int              `json:"code"` // return code
+	Msg  string           `json:"msg"`  // return information description
+	Data PurgeTrashCounts `json:"data"` // return data
+}
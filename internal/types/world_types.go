@@ -0,0 +1,67 @@
+package types
+
+import "fs/internal/bundle"
+
+// WorldExitIssue an exit reported by the world validation
+type WorldExitIssue struct {
+	RoomID    string `json:"roomID"`
+	Direction string `json:"direction"`
+	ToRoomID  string `json:"toRoomID"`
+}
+
+// WorldMobIssue a mob id in Room.Mobs reported by the world validation
+type WorldMobIssue struct {
+	RoomID string `json:"roomID"`
+	MobID  uint64 `json:"mobID"`
+}
+
+// ValidateWorldReport problems found in the rooms, exits and mobs of the world
+type ValidateWorldReport struct {
+	Valid            bool             `json:"valid"`            // true if no problem was found
+	StartRoom        string           `json:"startRoom"`        // room the reachability is checked from
+	StartRoomFound   bool             `json:"startRoomFound"`   // false if the start room does not exist, unreachable rooms are not checked
+	Rooms            int              `json:"rooms"`            // number of rooms checked
+	Exits            int              `json:"exits"`            // number of exits checked
+	DanglingExits    []WorldExitIssue `json:"danglingExits"`    // exits leading to rooms that do not exist
+	OneWayExits      []WorldExitIssue `json:"oneWayExits"`      // exits without an exit back in the reverse direction
+	UnreachableRooms []string         `json:"unreachableRooms"` // rooms that cannot be reached from the start room
+	UnknownMobs      []WorldMobIssue  `json:"unknownMobs"`      // mob ids in Room.Mobs that are not in the mob table
+}
+
+// ValidateWorldReply only for api docs
+type ValidateWorldReply struct {
+	Code int                 `json:"code"` // return code
+	Msg  string              `json:"msg"`  // return information description
+	Data ValidateWorldReport `json:"data"` // return data
+}
+
+// RoomPathStep an exit to take on a path
+type RoomPathStep struct {
+	RoomID    string `json:"roomID"`
+	Direction string `json:"direction"`
+	ToRoomID  string `json:"toRoomID"`
+	Cost      int    `json:"cost"`
+}
+
+// RoomPath a path between two rooms
+type RoomPath struct {
+	From       string         `json:"from"`
+	To         string         `json:"to"`
+	Steps      []RoomPathStep `json:"steps"`      // exits to take in order, empty if from and to are the same room
+	Directions []string       `json:"directions"` // abbreviations of the directions, e.g. ["n", "n", "e"]
+	Cost       int            `json:"cost"`       // total cost of the exits
+}
+
+// GetRoomPathReply only for api docs
+type GetRoomPathReply struct {
+	Code int      `json:"code"` // return code
+	Msg  string   `json:"msg"`  // return information description
+	Data RoomPath `json:"data"` // return data
+}
+
+// ImportWorldReply only for api docs
+type ImportWorldReply struct {
+	Code int           `json:"code"` // return code
+	Msg  string        `json:"msg"`  // return information description
+	Data bundle.Report `json:"data"` // what was written, or would be in a dry run
+}
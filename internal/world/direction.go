@@ -0,0 +1,73 @@
+// Package world holds the rules of the game world that do not depend on storage,
+// such as exit directions and how rooms connect to each other.
+package world
+
+import (
+	"strings"
+)
+
+// Direction the direction of a room exit, the value is stored in room_exit.direction
+type Direction string
+
+// exit directions
+const (
+	North     Direction = "north"
+	South     Direction = "south"
+	East      Direction = "east"
+	West      Direction = "west"
+	Northeast Direction = "northeast"
+	Northwest Direction = "northwest"
+	Southeast Direction = "southeast"
+	Southwest Direction = "southwest"
+	Up        Direction = "up"
+	Down      Direction = "down"
+)
+
+// Directions all directions in display order
+var Directions = []Direction{North, East, South, West, Northeast, Northwest, Southeast, Southwest, Up, Down}
+
+var directionInfo = map[Direction]struct {
+	short   string
+	reverse Direction
+}{
+	North:     {"n", South},
+	South:     {"s", North},
+	East:      {"e", West},
+	West:      {"w", East},
+	Northeast: {"ne", Southwest},
+	Northwest: {"nw", Southeast},
+	Southeast: {"se", Northwest},
+	Southwest: {"sw", Northeast},
+	Up:        {"u", Down},
+	Down:      {"d", Up},
+}
+
+// ParseDirection parse a direction name or its abbreviation, e.g. "north", "N", "ne"
+func ParseDirection(s string) (Direction, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if _, ok := directionInfo[Direction(s)]; ok {
+		return Direction(s), true
+	}
+	for d, info := range directionInfo {
+		if info.short == s {
+			return d, true
+		}
+	}
+	return "", false
+}
+
+// Short abbreviation of the direction, e.g. "n"
+func (d Direction) Short() string {
+	return directionInfo[d].short
+}
+
+// Reverse the opposite direction, e.g. south for north
+func (d Direction) Reverse() Direction {
+	return directionInfo[d].reverse
+}
+
+// Valid check if the direction is known
+func (d Direction) Valid() bool {
+	_, ok := directionInfo[d]
+	return ok
+}
@@ -0,0 +1,37 @@
+package world
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDirection(t *testing.T) {
+	d, ok := ParseDirection("N")
+	assert.True(t, ok)
+	assert.Equal(t, North, d)
+	assert.Equal(t, South, d.Reverse())
+	assert.Equal(t, "n", d.Short())
+
+	d, ok = ParseDirection(" southwest ")
+	assert.True(t, ok)
+	assert.Equal(t, Northeast, d.Reverse())
+
+	_, ok = ParseDirection("sideways")
+	assert.False(t, ok)
+	assert.False(t, Direction("sideways").Valid())
+}
+
+func TestParseWay(t *testing.T) {
+	exits, unparsed := ParseWay("n:hall, east=yard;u->attic north:again west bogus:x")
+	assert.Equal(t, []WayExit{
+		{Direction: North, ToRoomID: "hall"},
+		{Direction: East, ToRoomID: "yard"},
+		{Direction: Up, ToRoomID: "attic"},
+	}, exits)
+	assert.Equal(t, []string{"north:again", "west", "bogus:x"}, unparsed)
+
+	exits, unparsed = ParseWay("")
+	assert.Empty(t, exits)
+	assert.Empty(t, unparsed)
+}
@@ -0,0 +1,139 @@
+package world
+
+import (
+	"sort"
+
+	"github.com/go-dev-frame/sponge/pkg/sgorm"
+
+	"fs/internal/model"
+)
+
+// Exit an edge of the room graph
+type Exit struct {
+	From      string
+	Direction Direction
+	To        string
+	Door      bool
+	Locked    bool
+	Hidden    bool
+	Cost      int // travel cost, at least 1
+}
+
+// Graph rooms connected by their exits, a snapshot that is not changed once built
+type Graph struct {
+	rooms  []string           // room ids sorted
+	titles map[string]string  // room titles by room id
+	exits  map[string][]*Exit // exits by room id, in Directions order
+}
+
+// NewGraph build the graph from rooms and their structured exits, rooms without
+// structured exits fall back to the exits written in Room.Way.
+// Exits of rooms that are not in the list are ignored, exits may point to unknown rooms.
+func NewGraph(rooms []*model.Room, exits []*model.RoomExit) *Graph {
+	g := &Graph{
+		rooms:  make([]string, 0, len(rooms)),
+		titles: make(map[string]string, len(rooms)),
+		exits:  make(map[string][]*Exit, len(rooms)),
+	}
+	for _, room := range rooms {
+		if _, ok := g.titles[room.ID]; !ok {
+			g.titles[room.ID] = room.Title
+			g.rooms = append(g.rooms, room.ID)
+		}
+	}
+	sort.Strings(g.rooms)
+
+	for _, e := range exits {
+		d, ok := ParseDirection(e.Direction)
+		if !ok || !g.HasRoom(e.RoomID) {
+			continue
+		}
+		g.exits[e.RoomID] = append(g.exits[e.RoomID], &Exit{
+			From:      e.RoomID,
+			Direction: d,
+			To:        e.ToRoomID,
+			Door:      isTrue(e.Door),
+			Locked:    isTrue(e.Locked),
+			Hidden:    isTrue(e.Hidden),
+			Cost:      max(e.Cost, 1),
+		})
+	}
+	for _, room := range rooms {
+		if len(g.exits[room.ID]) > 0 {
+			continue
+		}
+		wayExits, _ := ParseWay(room.Way)
+		for _, we := range wayExits {
+			g.exits[room.ID] = append(g.exits[room.ID], &Exit{From: room.ID, Direction: we.Direction, To: we.ToRoomID, Cost: 1})
+		}
+	}
+
+	order := make(map[Direction]int, len(Directions))
+	for i, d := range Directions {
+		order[d] = i
+	}
+	for _, list := range g.exits {
+		sort.SliceStable(list, func(i, j int) bool {
+			return order[list[i].Direction] < order[list[j].Direction]
+		})
+	}
+
+	return g
+}
+
+// HasRoom check if a room is in the graph
+func (g *Graph) HasRoom(id string) bool {
+	_, ok := g.titles[id]
+	return ok
+}
+
+// Title the title of a room
+func (g *Graph) Title(id string) string {
+	return g.titles[id]
+}
+
+// Rooms the ids of all rooms, sorted
+func (g *Graph) Rooms() []string {
+	return g.rooms
+}
+
+// Exits the exits leading out of a room
+func (g *Graph) Exits(id string) []*Exit {
+	return g.exits[id]
+}
+
+// Exit the exit leading out of a room in a direction, nil if there is none
+func (g *Graph) Exit(id string, d Direction) *Exit {
+	for _, e := range g.exits[id] {
+		if e.Direction == d {
+			return e
+		}
+	}
+	return nil
+}
+
+// WithoutHidden a copy of the graph without the hidden exits, what players can see
+func (g *Graph) WithoutHidden() *Graph {
+	v := &Graph{rooms: g.rooms, titles: g.titles, exits: make(map[string][]*Exit, len(g.exits))}
+	for id, list := range g.exits {
+		for _, e := range list {
+			if !e.Hidden {
+				v.exits[id] = append(v.exits[id], e)
+			}
+		}
+	}
+	return v
+}
+
+// NumExits number of exits in the graph
+func (g *Graph) NumExits() int {
+	n := 0
+	for _, list := range g.exits {
+		n += len(list)
+	}
+	return n
+}
+
+func isTrue(b *sgorm.TinyBool) bool {
+	return b != nil && bool(*b)
+}
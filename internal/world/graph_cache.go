@@ -0,0 +1,46 @@
+package world
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// GraphCache keep the room graph in memory, the graph is loaded again after
+// Invalidate is called or when it is older than the ttl.
+type GraphCache struct {
+	load func(ctx context.Context) (*Graph, error)
+	ttl  time.Duration // 0 means the graph only changes on Invalidate
+
+	mu       sync.Mutex
+	graph    *Graph
+	loadedAt time.Time
+}
+
+// NewGraphCache creating the cache, load reads the whole graph from storage
+func NewGraphCache(load func(ctx context.Context) (*Graph, error), ttl time.Duration) *GraphCache {
+	return &GraphCache{load: load, ttl: ttl}
+}
+
+// Get the graph, loading it if needed
+func (c *GraphCache) Get(ctx context.Context) (*Graph, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.graph != nil && (c.ttl <= 0 || time.Since(c.loadedAt) < c.ttl) {
+		return c.graph, nil
+	}
+	g, err := c.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.graph, c.loadedAt = g, time.Now()
+	return g, nil
+}
+
+// Invalidate drop the graph, the next Get loads it again
+func (c *GraphCache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.graph = nil
+}
@@ -0,0 +1,114 @@
+package world
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-dev-frame/sponge/pkg/sgorm"
+
+	"fs/internal/model"
+)
+
+// testGraph
+//
+//	hall <-> yard -> shed(one way)   yard -> well(missing)   attic(unreachable, way: s:hall)
+func testGraph() *Graph {
+	rooms := []*model.Room{
+		{ID: "hall"}, {ID: "yard"}, {ID: "shed"}, {ID: "attic", Way: "s:hall"},
+	}
+	exits := []*model.RoomExit{
+		{RoomID: "yard", Direction: "west", ToRoomID: "hall"},
+		{RoomID: "hall", Direction: "east", ToRoomID: "yard"},
+		{RoomID: "yard", Direction: "north", ToRoomID: "shed"},
+		{RoomID: "yard", Direction: "down", ToRoomID: "well"},
+		{RoomID: "nowhere", Direction: "up", ToRoomID: "hall"},
+	}
+	return NewGraph(rooms, exits)
+}
+
+func TestNewGraph(t *testing.T) {
+	g := testGraph()
+	assert.Equal(t, []string{"attic", "hall", "shed", "yard"}, g.Rooms())
+	assert.Equal(t, 5, g.NumExits())
+
+	exits := g.Exits("yard")
+	assert.Equal(t, []Direction{North, West, Down}, []Direction{exits[0].Direction, exits[1].Direction, exits[2].Direction})
+	assert.Equal(t, "hall", g.Exit("attic", South).To)
+	assert.Nil(t, g.Exit("hall", West))
+	assert.Empty(t, g.Exits("nowhere"))
+}
+
+func TestValidate(t *testing.T) {
+	g := testGraph()
+
+	r := Validate(g, "hall")
+	assert.True(t, r.StartRoomFound)
+	assert.Len(t, r.DanglingExits, 1)
+	assert.Equal(t, "well", r.DanglingExits[0].To)
+	oneWay := []string{}
+	for _, e := range r.OneWayExits {
+		oneWay = append(oneWay, e.From+">"+e.To)
+	}
+	assert.Equal(t, []string{"attic>hall", "yard>shed"}, oneWay)
+	assert.Equal(t, []string{"attic"}, r.UnreachableRooms)
+
+	r = Validate(g, "void")
+	assert.False(t, r.StartRoomFound)
+	assert.Empty(t, r.UnreachableRooms)
+}
+
+func TestFindPath(t *testing.T) {
+	rooms := []*model.Room{{ID: "a"}, {ID: "b"}, {ID: "c"}, {ID: "d"}}
+	yes := sgorm.TinyBool(true)
+	exits := []*model.RoomExit{
+		{RoomID: "a", Direction: "north", ToRoomID: "b", Cost: 5},
+		{RoomID: "b", Direction: "north", ToRoomID: "d"},
+		{RoomID: "a", Direction: "east", ToRoomID: "c"},
+		{RoomID: "c", Direction: "north", ToRoomID: "b", Locked: &yes},
+		{RoomID: "c", Direction: "east", ToRoomID: "d", Cost: 3},
+	}
+	g := NewGraph(rooms, exits)
+
+	directions := func(path []*Exit) []Direction {
+		ds := []Direction{}
+		for _, e := range path {
+			ds = append(ds, e.Direction)
+		}
+		return ds
+	}
+
+	path, ok := FindPath(g, "a", "d", PathOptions{})
+	assert.True(t, ok)
+	assert.Equal(t, []Direction{North, North}, directions(path))
+	assert.Equal(t, 6, PathCost(path))
+
+	path, ok = FindPath(g, "a", "d", PathOptions{Weighted: true})
+	assert.True(t, ok)
+	assert.Equal(t, []Direction{East, North, North}, directions(path))
+	assert.Equal(t, 3, PathCost(path))
+
+	path, ok = FindPath(g, "a", "d", PathOptions{Weighted: true, AvoidLocked: true})
+	assert.True(t, ok)
+	assert.Equal(t, []Direction{East, East}, directions(path))
+
+	_, ok = FindPath(g, "a", "d", PathOptions{AvoidRooms: map[string]bool{"b": true, "c": true}})
+	assert.False(t, ok)
+	_, ok = FindPath(g, "d", "a", PathOptions{})
+	assert.False(t, ok)
+
+	path, ok = FindPath(g, "a", "a", PathOptions{})
+	assert.True(t, ok)
+	assert.Empty(t, path)
+}
+
+func TestGraph_WithoutHidden(t *testing.T) {
+	yes := sgorm.TinyBool(true)
+	g := NewGraph([]*model.Room{{ID: "a"}, {ID: "b"}}, []*model.RoomExit{
+		{RoomID: "a", Direction: "north", ToRoomID: "b"},
+		{RoomID: "a", Direction: "down", ToRoomID: "b", Hidden: &yes},
+	})
+	assert.Len(t, g.Exits("a"), 2)
+	assert.Len(t, g.WithoutHidden().Exits("a"), 1)
+	assert.True(t, g.WithoutHidden().HasRoom("b"))
+}
@@ -0,0 +1,141 @@
+package world
+
+// Pos a grid position, x grows east, y grows south, z grows up
+type Pos struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	Z int `json:"z"`
+}
+
+var directionOffsets = map[Direction]Pos{
+	North:     {0, -1, 0},
+	South:     {0, 1, 0},
+	East:      {1, 0, 0},
+	West:      {-1, 0, 0},
+	Northeast: {1, -1, 0},
+	Northwest: {-1, -1, 0},
+	Southeast: {1, 1, 0},
+	Southwest: {-1, 1, 0},
+	Up:        {0, 0, 1},
+	Down:      {0, 0, -1},
+}
+
+// Offset the grid step of the direction
+func (d Direction) Offset() Pos {
+	return directionOffsets[d]
+}
+
+func (p Pos) add(o Pos) Pos {
+	return Pos{p.X + o.X, p.Y + o.Y, p.Z + o.Z}
+}
+
+// Layout rooms placed on a grid
+type Layout struct {
+	Graph  *Graph
+	Center string         // the room at 0,0,0, empty if the whole world was laid out
+	Pos    map[string]Pos // positions of the placed rooms
+	Rooms  []string       // placed rooms, in the order they were placed
+}
+
+// NewLayout place the rooms on a grid following the exit directions, starting from
+// the center room and going at most radius exits away, radius <= 0 means no limit.
+// When center is empty every room is placed, each group of connected rooms to the
+// right of the previous one. A room whose spot is already taken is placed from
+// another exit if possible, otherwise it is left out of the layout.
+func NewLayout(g *Graph, center string, radius int) *Layout {
+	l := &Layout{Graph: g, Center: center, Pos: map[string]Pos{}}
+	if center != "" {
+		if g.HasRoom(center) {
+			l.place(center, Pos{}, radius, map[Pos]bool{})
+		}
+		return l
+	}
+
+	offsetX := 0
+	for _, id := range g.Rooms() {
+		if _, ok := l.Pos[id]; ok {
+			continue
+		}
+		first := len(l.Rooms)
+		l.place(id, Pos{}, 0, map[Pos]bool{})
+
+		minX, maxX := 0, 0
+		for _, rid := range l.Rooms[first:] {
+			minX, maxX = min(minX, l.Pos[rid].X), max(maxX, l.Pos[rid].X)
+		}
+		for _, rid := range l.Rooms[first:] {
+			p := l.Pos[rid]
+			p.X += offsetX - minX
+			l.Pos[rid] = p
+		}
+		offsetX += maxX - minX + 2
+	}
+	return l
+}
+
+// place breadth first from a room, taken holds the positions used by this group
+func (l *Layout) place(start string, at Pos, radius int, taken map[Pos]bool) {
+	type item struct {
+		id    string
+		depth int
+	}
+	l.Pos[start] = at
+	l.Rooms = append(l.Rooms, start)
+	taken[at] = true
+	queue := []item{{start, 0}}
+
+	for len(queue) > 0 {
+		it := queue[0]
+		queue = queue[1:]
+		if radius > 0 && it.depth >= radius {
+			continue
+		}
+		for _, e := range l.Graph.Exits(it.id) {
+			if _, ok := l.Pos[e.To]; ok || !l.Graph.HasRoom(e.To) {
+				continue
+			}
+			p := l.Pos[it.id].add(e.Direction.Offset())
+			if taken[p] {
+				continue
+			}
+			l.Pos[e.To] = p
+			l.Rooms = append(l.Rooms, e.To)
+			taken[p] = true
+			queue = append(queue, item{e.To, it.depth + 1})
+		}
+	}
+}
+
+// Bounds the smallest and largest positions of the placed rooms
+func (l *Layout) Bounds() (lo Pos, hi Pos) {
+	for i, id := range l.Rooms {
+		p := l.Pos[id]
+		if i == 0 {
+			lo, hi = p, p
+			continue
+		}
+		lo = Pos{min(lo.X, p.X), min(lo.Y, p.Y), min(lo.Z, p.Z)}
+		hi = Pos{max(hi.X, p.X), max(hi.Y, p.Y), max(hi.Z, p.Z)}
+	}
+	return lo, hi
+}
+
+// Exits the exits between placed rooms
+func (l *Layout) Exits() []*Exit {
+	var exits []*Exit
+	for _, id := range l.Rooms {
+		for _, e := range l.Graph.Exits(id) {
+			if _, ok := l.Pos[e.To]; ok {
+				exits = append(exits, e)
+			}
+		}
+	}
+	return exits
+}
+
+// adjacent check if the exit joins two rooms that sit next to each other in its direction
+func (l *Layout) adjacent(e *Exit) bool {
+	from, ok1 := l.Pos[e.From]
+	to, ok2 := l.Pos[e.To]
+	return ok1 && ok2 && from.add(e.Direction.Offset()) == to
+}
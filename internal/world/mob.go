@@ -0,0 +1,32 @@
+package world
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ParseMobIDs split the mob ids listed in Room.Mobs, any non digit character is a separator
+func ParseMobIDs(s string) []uint64 {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r < '0' || r > '9'
+	})
+	ids := make([]uint64, 0, len(fields))
+	for _, f := range fields {
+		id, err := strconv.ParseUint(f, 10, 64)
+		if err == nil && id > 0 {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// RemoveMobID the mob ids of Room.Mobs without id, joined by commas
+func RemoveMobID(s string, id uint64) string {
+	var kept []string
+	for _, v := range ParseMobIDs(s) {
+		if v != id {
+			kept = append(kept, strconv.FormatUint(v, 10))
+		}
+	}
+	return strings.Join(kept, ",")
+}
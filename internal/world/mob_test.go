@@ -0,0 +1,18 @@
+package world
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMobIDs(t *testing.T) {
+	assert.Equal(t, []uint64{1, 2, 30}, ParseMobIDs("1, 2;30"))
+	assert.Equal(t, []uint64{}, ParseMobIDs(""))
+}
+
+func TestRemoveMobID(t *testing.T) {
+	assert.Equal(t, "2,30", RemoveMobID("1, 2;1 30", 1))
+	assert.Equal(t, "", RemoveMobID("1", 1))
+	assert.Equal(t, "2", RemoveMobID("2", 1))
+}
@@ -0,0 +1,138 @@
+package world
+
+import (
+	"container/heap"
+)
+
+// PathOptions options of FindPath
+type PathOptions struct {
+	Weighted    bool            // use the exit costs, otherwise the path with the fewest steps is found
+	AvoidLocked bool            // do not pass locked exits
+	AvoidDoors  bool            // do not pass exits with a door
+	AvoidHidden bool            // do not pass hidden exits
+	AvoidRooms  map[string]bool // do not pass through these rooms
+}
+
+func (o *PathOptions) usable(g *Graph, e *Exit) bool {
+	if !g.HasRoom(e.To) || o.AvoidRooms[e.To] {
+		return false
+	}
+	return !(o.AvoidLocked && e.Locked) && !(o.AvoidDoors && e.Door) && !(o.AvoidHidden && e.Hidden)
+}
+
+// FindPath find the shortest path between two rooms, the exits to take are returned
+// in order, ok is false if there is no path. A path from a room to itself is empty.
+// Ties are broken by the order of Directions so the result is stable.
+func FindPath(g *Graph, from, to string, opts PathOptions) (path []*Exit, ok bool) {
+	if !g.HasRoom(from) || !g.HasRoom(to) {
+		return nil, false
+	}
+	if from == to {
+		return []*Exit{}, true
+	}
+
+	var via map[string]*Exit
+	if opts.Weighted {
+		via = dijkstra(g, from, to, &opts)
+	} else {
+		via = bfs(g, from, to, &opts)
+	}
+	if via[to] == nil {
+		return nil, false
+	}
+
+	for id := to; id != from; id = via[id].From {
+		path = append(path, via[id])
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path, true
+}
+
+// PathCost total cost of the exits
+func PathCost(path []*Exit) int {
+	cost := 0
+	for _, e := range path {
+		cost += e.Cost
+	}
+	return cost
+}
+
+// bfs the exit each reached room was entered through
+func bfs(g *Graph, from, to string, opts *PathOptions) map[string]*Exit {
+	via := map[string]*Exit{}
+	queue := []string{from}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, e := range g.Exits(id) {
+			if e.To == from || via[e.To] != nil || !opts.usable(g, e) {
+				continue
+			}
+			via[e.To] = e
+			if e.To == to {
+				return via
+			}
+			queue = append(queue, e.To)
+		}
+	}
+	return via
+}
+
+// dijkstra the exit each reached room was entered through on the cheapest path
+func dijkstra(g *Graph, from, to string, opts *PathOptions) map[string]*Exit {
+	via := map[string]*Exit{}
+	dist := map[string]int{from: 0}
+	done := map[string]bool{}
+	pq := &roomQueue{{id: from}}
+	seq := 0
+	for pq.Len() > 0 {
+		item := heap.Pop(pq).(roomItem)
+		if done[item.id] {
+			continue
+		}
+		done[item.id] = true
+		if item.id == to {
+			break
+		}
+		for _, e := range g.Exits(item.id) {
+			if done[e.To] || !opts.usable(g, e) {
+				continue
+			}
+			d := item.dist + e.Cost
+			if old, ok := dist[e.To]; ok && old <= d {
+				continue
+			}
+			dist[e.To] = d
+			via[e.To] = e
+			seq++
+			heap.Push(pq, roomItem{id: e.To, dist: d, seq: seq})
+		}
+	}
+	return via
+}
+
+type roomItem struct {
+	id   string
+	dist int
+	seq  int // push order, keeps equal distances first in first out
+}
+
+type roomQueue []roomItem
+
+func (q roomQueue) Len() int { return len(q) }
+func (q roomQueue) Less(i, j int) bool {
+	if q[i].dist != q[j].dist {
+		return q[i].dist < q[j].dist
+	}
+	return q[i].seq < q[j].seq
+}
+func (q roomQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *roomQueue) Push(x interface{}) { *q = append(*q, x.(roomItem)) }
+func (q *roomQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
@@ -0,0 +1,230 @@
+package world
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// map formats
+const (
+	FormatASCII = "ascii"
+	FormatSVG   = "svg"
+	FormatDOT   = "dot"
+)
+
+// Render draw the layout in a format, ok is false if the format is unknown
+func Render(l *Layout, format string) (string, bool) {
+	switch format {
+	case FormatASCII:
+		return RenderASCII(l, l.Center), true
+	case FormatSVG:
+		return RenderSVG(l), true
+	case FormatDOT:
+		return RenderDOT(l), true
+	}
+	return "", false
+}
+
+// RenderASCII draw the level of the marked room, or level 0 if it is not placed.
+// Rooms are "[ ]", the marked room is "[*]", a room with exits up or down shows
+// "^", "v" or "+" inside. Exits between neighbours are drawn with - | / \ and X.
+func RenderASCII(l *Layout, mark string) string {
+	z := 0
+	if p, ok := l.Pos[mark]; ok {
+		z = p.Z
+	}
+	var ids []string
+	for _, id := range l.Rooms {
+		if l.Pos[id].Z == z {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return ""
+	}
+
+	minX, minY, maxX, maxY := l.Pos[ids[0]].X, l.Pos[ids[0]].Y, l.Pos[ids[0]].X, l.Pos[ids[0]].Y
+	for _, id := range ids {
+		p := l.Pos[id]
+		minX, minY, maxX, maxY = min(minX, p.X), min(minY, p.Y), max(maxX, p.X), max(maxY, p.Y)
+	}
+
+	// each room takes 4 columns and 2 rows, the connections sit in between
+	rows := make([][]rune, (maxY-minY)*2+1)
+	for i := range rows {
+		rows[i] = []rune(strings.Repeat(" ", (maxX-minX)*4+3))
+	}
+	set := func(row, col int, r rune) {
+		if row < 0 || row >= len(rows) || col < 0 || col >= len(rows[row]) {
+			return
+		}
+		if cur := rows[row][col]; (cur == '/' && r == '\\') || (cur == '\\' && r == '/') {
+			r = 'X'
+		}
+		rows[row][col] = r
+	}
+
+	for _, id := range ids {
+		p := l.Pos[id]
+		row, col := (p.Y-minY)*2, (p.X-minX)*4
+		set(row, col, '[')
+		set(row, col+1, roomMark(l, id, mark))
+		set(row, col+2, ']')
+	}
+	for _, e := range l.Exits() {
+		from := l.Pos[e.From]
+		if from.Z != z || !l.adjacent(e) {
+			continue
+		}
+		to := l.Pos[e.To]
+		// draw from the upper or left room so both directions of a pair land on the same spot
+		if to.Y < from.Y || (to.Y == from.Y && to.X < from.X) {
+			from, to = to, from
+		}
+		row, col := (from.Y-minY)*2, (from.X-minX)*4
+		switch {
+		case to.Y == from.Y:
+			set(row, col+3, '-')
+		case to.X == from.X:
+			set(row+1, col+1, '|')
+		case to.X > from.X:
+			set(row+1, col+3, '\\')
+		default:
+			set(row+1, col-1, '/')
+		}
+	}
+
+	var sb strings.Builder
+	for _, r := range rows {
+		sb.WriteString(strings.TrimRight(string(r), " "))
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+func roomMark(l *Layout, id string, mark string) rune {
+	if id == mark {
+		return '*'
+	}
+	up, down := l.Graph.Exit(id, Up) != nil, l.Graph.Exit(id, Down) != nil
+	switch {
+	case up && down:
+		return '+'
+	case up:
+		return '^'
+	case down:
+		return 'v'
+	}
+	return ' '
+}
+
+// svg sizes
+const (
+	svgCell   = 120 // distance between rooms
+	svgRoomW  = 90
+	svgRoomH  = 36
+	svgMargin = 20
+	svgLevelH = 30 // height of the level caption
+)
+
+// RenderSVG draw every level of the layout, from the top level down
+func RenderSVG(l *Layout) string {
+	lo, hi := l.Bounds()
+	width := (hi.X-lo.X)*svgCell + svgRoomW + svgMargin*2
+	levelH := (hi.Y-lo.Y)*svgCell + svgRoomH + svgLevelH + svgMargin
+	levels := hi.Z - lo.Z + 1
+	if len(l.Rooms) == 0 {
+		levels = 0
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="sans-serif" font-size="12">`+"\n",
+		width, levels*levelH+svgMargin)
+	center := func(p Pos) (int, int) {
+		return svgMargin + (p.X-lo.X)*svgCell + svgRoomW/2,
+			svgMargin + (hi.Z-p.Z)*levelH + svgLevelH + (p.Y-lo.Y)*svgCell + svgRoomH/2
+	}
+
+	for z := hi.Z; levels > 0 && z >= lo.Z; z-- {
+		fmt.Fprintf(&sb, `<text x="%d" y="%d" font-weight="bold">Level %d</text>`+"\n",
+			svgMargin, svgMargin+(hi.Z-z)*levelH+svgLevelH/2, z)
+	}
+	for _, e := range l.Exits() {
+		from, to := l.Pos[e.From], l.Pos[e.To]
+		if from.Z != to.Z {
+			continue
+		}
+		x1, y1 := center(from)
+		x2, y2 := center(to)
+		dash := ""
+		if e.Locked || e.Hidden {
+			dash = ` stroke-dasharray="4 3"`
+		}
+		fmt.Fprintf(&sb, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="#666"%s><title>%s</title></line>`+"\n",
+			x1, y1, x2, y2, dash, html.EscapeString(e.From+" "+string(e.Direction)+" to "+e.To))
+	}
+	for _, id := range l.Rooms {
+		x, y := center(l.Pos[id])
+		fill := "#fff"
+		if id == l.Center {
+			fill = "#ffd"
+		}
+		title := l.Graph.Title(id)
+		if title == "" {
+			title = id
+		}
+		fmt.Fprintf(&sb, `<g><title>%s</title><rect x="%d" y="%d" width="%d" height="%d" rx="4" fill="%s" stroke="#333"/>`,
+			html.EscapeString(id), x-svgRoomW/2, y-svgRoomH/2, svgRoomW, svgRoomH, fill)
+		fmt.Fprintf(&sb, `<text x="%d" y="%d" text-anchor="middle">%s%s</text></g>`+"\n",
+			x, y+4, html.EscapeString(title), svgUpDown(l, id))
+	}
+	sb.WriteString("</svg>\n")
+	return sb.String()
+}
+
+func svgUpDown(l *Layout, id string) string {
+	mark := ""
+	if l.Graph.Exit(id, Up) != nil {
+		mark += " ↑"
+	}
+	if l.Graph.Exit(id, Down) != nil {
+		mark += " ↓"
+	}
+	return mark
+}
+
+// RenderDOT write the layout as a Graphviz digraph, the positions are kept for neato
+func RenderDOT(l *Layout) string {
+	var sb strings.Builder
+	sb.WriteString("digraph world {\n\tnode [shape=box];\n")
+
+	ids := append([]string{}, l.Rooms...)
+	sort.Strings(ids)
+	for _, id := range ids {
+		p := l.Pos[id]
+		label := l.Graph.Title(id)
+		if label == "" {
+			label = id
+		}
+		fmt.Fprintf(&sb, "\t%s [label=%s, pos=\"%d,%d!\"", strconv.Quote(id), strconv.Quote(label), p.X, -p.Y)
+		if p.Z != 0 {
+			fmt.Fprintf(&sb, ", level=%d", p.Z)
+		}
+		if id == l.Center {
+			sb.WriteString(", style=bold")
+		}
+		sb.WriteString("];\n")
+	}
+	for _, e := range l.Exits() {
+		fmt.Fprintf(&sb, "\t%s -> %s [label=%s", strconv.Quote(e.From), strconv.Quote(e.To), strconv.Quote(e.Direction.Short()))
+		if e.Locked || e.Hidden {
+			sb.WriteString(", style=dashed")
+		}
+		sb.WriteString("];\n")
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
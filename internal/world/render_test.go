@@ -0,0 +1,79 @@
+package world
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"fs/internal/model"
+)
+
+// renderGraph
+//
+//	hall - yard
+//	 |   \
+//	cellar  pond     yard has a ladder up to the loft, far is not connected
+func renderGraph() *Graph {
+	rooms := []*model.Room{
+		{ID: "hall", Title: "Hall"}, {ID: "yard", Title: "Yard"}, {ID: "cellar", Title: "Cellar"},
+		{ID: "pond", Title: "Pond & Reeds"}, {ID: "loft", Title: "Loft"}, {ID: "far", Title: "Far"},
+	}
+	exits := []*model.RoomExit{
+		{RoomID: "hall", Direction: "east", ToRoomID: "yard"},
+		{RoomID: "yard", Direction: "west", ToRoomID: "hall"},
+		{RoomID: "hall", Direction: "south", ToRoomID: "cellar"},
+		{RoomID: "hall", Direction: "southeast", ToRoomID: "pond"},
+		{RoomID: "yard", Direction: "up", ToRoomID: "loft"},
+		{RoomID: "loft", Direction: "down", ToRoomID: "yard"},
+	}
+	return NewGraph(rooms, exits)
+}
+
+func TestNewLayout(t *testing.T) {
+	g := renderGraph()
+
+	l := NewLayout(g, "hall", 0)
+	assert.Equal(t, Pos{}, l.Pos["hall"])
+	assert.Equal(t, Pos{X: 1}, l.Pos["yard"])
+	assert.Equal(t, Pos{Y: 1}, l.Pos["cellar"])
+	assert.Equal(t, Pos{X: 1, Y: 1}, l.Pos["pond"])
+	assert.Equal(t, Pos{X: 1, Z: 1}, l.Pos["loft"])
+	assert.NotContains(t, l.Pos, "far")
+
+	l = NewLayout(g, "hall", 1)
+	assert.NotContains(t, l.Pos, "loft")
+
+	l = NewLayout(g, "", 0)
+	assert.Len(t, l.Rooms, 6)
+	assert.Equal(t, Pos{X: 0}, l.Pos["cellar"])
+	assert.Equal(t, Pos{X: 2}, l.Pos["far"])
+}
+
+func TestRenderASCII(t *testing.T) {
+	l := NewLayout(renderGraph(), "hall", 0)
+
+	out, ok := Render(l, FormatASCII)
+	assert.True(t, ok)
+	assert.Equal(t, "[*]-[^]\n | \\\n[ ] [ ]\n", out)
+	assert.Equal(t, "[*]\n", RenderASCII(l, "loft"))
+	assert.Equal(t, "[v]\n", RenderASCII(NewLayout(renderGraph(), "loft", 0), "")[0:4])
+
+	_, ok = Render(l, "png")
+	assert.False(t, ok)
+}
+
+func TestRenderSVGAndDOT(t *testing.T) {
+	l := NewLayout(renderGraph(), "hall", 0)
+
+	svg := RenderSVG(l)
+	assert.True(t, strings.HasPrefix(svg, "<svg "))
+	assert.Contains(t, svg, "Pond &amp; Reeds")
+	assert.Contains(t, svg, "Level 1")
+	assert.Equal(t, 5, strings.Count(svg, "<rect "))
+
+	dot := RenderDOT(l)
+	assert.Contains(t, dot, `"hall" [label="Hall", pos="0,0!", style=bold];`)
+	assert.Contains(t, dot, `"yard" -> "loft" [label="u"];`)
+	assert.Contains(t, dot, `"loft" [label="Loft", pos="1,0!", level=1];`)
+}
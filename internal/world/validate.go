@@ -0,0 +1,62 @@
+package world
+
+// Report problems found in the room graph
+type Report struct {
+	StartRoom        string
+	StartRoomFound   bool
+	DanglingExits    []*Exit  // exits leading to rooms that do not exist
+	OneWayExits      []*Exit  // exits whose target room has no exit back in the reverse direction
+	UnreachableRooms []string // rooms that cannot be reached from the start room
+}
+
+// Validate check the graph for exits that lead nowhere, exits without a way back
+// and rooms that cannot be reached from the start room. Locked and hidden exits
+// count as connections, a builder placed them on purpose.
+func Validate(g *Graph, startRoom string) *Report {
+	r := &Report{
+		StartRoom:      startRoom,
+		StartRoomFound: g.HasRoom(startRoom),
+	}
+
+	for _, id := range g.Rooms() {
+		for _, e := range g.Exits(id) {
+			if !g.HasRoom(e.To) {
+				r.DanglingExits = append(r.DanglingExits, e)
+				continue
+			}
+			back := g.Exit(e.To, e.Direction.Reverse())
+			if back == nil || back.To != e.From {
+				r.OneWayExits = append(r.OneWayExits, e)
+			}
+		}
+	}
+
+	if !r.StartRoomFound {
+		return r
+	}
+	reached := Reachable(g, startRoom)
+	for _, id := range g.Rooms() {
+		if !reached[id] {
+			r.UnreachableRooms = append(r.UnreachableRooms, id)
+		}
+	}
+
+	return r
+}
+
+// Reachable the rooms that can be reached from a room, the room itself included
+func Reachable(g *Graph, from string) map[string]bool {
+	reached := map[string]bool{from: true}
+	queue := []string{from}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, e := range g.Exits(id) {
+			if g.HasRoom(e.To) && !reached[e.To] {
+				reached[e.To] = true
+				queue = append(queue, e.To)
+			}
+		}
+	}
+	return reached
+}
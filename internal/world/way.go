@@ -0,0 +1,45 @@
+package world
+
+import (
+	"strings"
+)
+
+// WayExit an exit parsed from the legacy free text Room.Way column
+type WayExit struct {
+	Direction Direction `json:"direction"`
+	ToRoomID  string    `json:"toRoomID"`
+}
+
+// ParseWay parse the legacy Room.Way column, entries are "direction:room" or
+// "direction=room" separated by commas, semicolons or spaces, e.g. "n:hall,e:yard".
+// Entries that cannot be parsed are returned as they are in unparsed.
+func ParseWay(way string) (exits []WayExit, unparsed []string) {
+	entries := strings.FieldsFunc(way, func(r rune) bool {
+		return r == ',' || r == ';' || r == ' ' || r == '\t' || r == '|'
+	})
+	seen := map[Direction]bool{}
+	for _, entry := range entries {
+		dir, target, ok := cutWayEntry(entry)
+		if !ok {
+			unparsed = append(unparsed, entry)
+			continue
+		}
+		d, ok := ParseDirection(dir)
+		if !ok || target == "" || seen[d] {
+			unparsed = append(unparsed, entry)
+			continue
+		}
+		seen[d] = true
+		exits = append(exits, WayExit{Direction: d, ToRoomID: target})
+	}
+	return exits, unparsed
+}
+
+func cutWayEntry(entry string) (string, string, bool) {
+	for _, sep := range []string{":", "=", "->"} {
+		if dir, target, ok := strings.Cut(entry, sep); ok {
+			return strings.TrimSpace(dir), strings.TrimSpace(target), true
+		}
+	}
+	return "", "", false
+}
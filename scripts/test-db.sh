@@ -0,0 +1,68 @@
+#!/bin/bash
+
+# run the dao tests of internal/dao/db_test.go against mysql and postgresql, besides sqlite.
+# A database of FS_TEST_MYSQL_DSN or FS_TEST_POSTGRES_DSN that is already set is used as it is,
+# otherwise it is started in docker and removed when the tests end. The tables of the models
+# in the databases are dropped.
+
+mysqlContainer="fs-test-mysql"
+postgresContainer="fs-test-postgres"
+started=()
+
+function checkResult() {
+    result=$1
+    if [ ${result} -ne 0 ]; then
+        exit ${result}
+    fi
+}
+
+function removeContainers() {
+    for name in "${started[@]}"; do
+        docker rm -f ${name} > /dev/null
+    done
+}
+trap removeContainers EXIT
+
+# waitReady run the command until it succeeds, at most 60 times a second apart
+function waitReady() {
+    for i in $(seq 1 60); do
+        if "$@" > /dev/null 2>&1; then
+            return 0
+        fi
+        sleep 1
+    done
+    echo "timed out waiting for: $*"
+    return 1
+}
+
+if [ -z "${FS_TEST_MYSQL_DSN}" ]; then
+    docker rm -f ${mysqlContainer} > /dev/null 2>&1
+    docker run -d --name ${mysqlContainer} -p 33306:3306 \
+        -e MYSQL_ROOT_PASSWORD=123456 -e MYSQL_DATABASE=fs_test mysql:8.0 > /dev/null
+    checkResult $?
+    started+=(${mysqlContainer})
+    export FS_TEST_MYSQL_DSN="root:123456@(127.0.0.1:33306)/fs_test?parseTime=true&loc=Local&charset=utf8mb4&collation=utf8mb4_general_ci"
+fi
+
+if [ -z "${FS_TEST_POSTGRES_DSN}" ]; then
+    docker rm -f ${postgresContainer} > /dev/null 2>&1
+    docker run -d --name ${postgresContainer} -p 35432:5432 \
+        -e POSTGRES_PASSWORD=123456 -e POSTGRES_DB=fs_test postgres:16 > /dev/null
+    checkResult $?
+    started+=(${postgresContainer})
+    export FS_TEST_POSTGRES_DSN="postgres:123456@127.0.0.1:35432/fs_test?sslmode=disable"
+fi
+
+# the servers of the images start once without tcp to initialize, the tcp port is ready after that
+for name in "${started[@]}"; do
+    if [ "${name}" == "${mysqlContainer}" ]; then
+        waitReady docker exec ${name} mysql -h127.0.0.1 -uroot -p123456 -e "SELECT 1" fs_test
+    else
+        waitReady docker exec ${name} pg_isready -h 127.0.0.1 -U postgres -d fs_test
+    fi
+    checkResult $?
+done
+
+# -v lists the subtest of every database, a database that is not reached fails the tests
+go test -count=1 -v -run "^Test_db_" ./internal/dao/
+checkResult $?